@@ -0,0 +1,158 @@
+// Copyright 2021 The Xorm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package xorm
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/xorm-io/xorm/schemas"
+)
+
+// AggrExpr is an aggregate or a window function expression which could be
+// given to Aggregate, or to Select by its String. The columns are quoted by `
+// which is replaced by the quotes of the dialect.
+type AggrExpr struct {
+	expr  string
+	over  string
+	alias string
+}
+
+// As names the result of the expression so that it could be mapped to the
+// field of a result struct or the key of a map
+func (e AggrExpr) As(alias string) AggrExpr {
+	e.alias = alias
+	return e
+}
+
+// Over computes the expression over the window instead of the group
+func (e AggrExpr) Over(w Window) AggrExpr {
+	e.over = w.String()
+	return e
+}
+
+// String returns the SQL of the expression
+func (e AggrExpr) String() string {
+	var buf strings.Builder
+	buf.WriteString(e.expr)
+	if e.over != "" {
+		fmt.Fprintf(&buf, " OVER (%s)", e.over)
+	}
+	if e.alias != "" {
+		buf.WriteString(" AS ")
+		buf.WriteString(schemas.CommonQuoter.Quote(e.alias))
+	}
+	return buf.String()
+}
+
+// Window represents the window of a window function, i.e. PARTITION BY and
+// ORDER BY of OVER
+type Window struct {
+	partitions []string
+	orders     []string
+}
+
+// PartitionBy returns a window partitioned by the columns
+func PartitionBy(columns ...string) Window {
+	return Window{}.PartitionBy(columns...)
+}
+
+// PartitionBy partitions the rows of the window by the columns
+func (w Window) PartitionBy(columns ...string) Window {
+	partitions := make([]string, 0, len(w.partitions)+len(columns))
+	partitions = append(partitions, w.partitions...)
+	for _, col := range columns {
+		partitions = append(partitions, quoteAggrColumn(col))
+	}
+	w.partitions = partitions
+	return w
+}
+
+// OrderBy orders the rows of the window, e.g. "salary DESC"
+func (w Window) OrderBy(order string) Window {
+	w.orders = append(append(make([]string, 0, len(w.orders)+1), w.orders...), order)
+	return w
+}
+
+// String returns the SQL of the window
+func (w Window) String() string {
+	var parts []string
+	if len(w.partitions) > 0 {
+		parts = append(parts, "PARTITION BY "+strings.Join(w.partitions, ", "))
+	}
+	if len(w.orders) > 0 {
+		parts = append(parts, "ORDER BY "+strings.Join(w.orders, ", "))
+	}
+	return strings.Join(parts, " ")
+}
+
+// quoteAggrColumn quotes the column unless it's * or an expression
+func quoteAggrColumn(col string) string {
+	if col == "*" || strings.ContainsAny(col, " (`\"[") {
+		return col
+	}
+	return schemas.CommonQuoter.Quote(col)
+}
+
+func aggrFunc(name, column string) AggrExpr {
+	return AggrExpr{expr: fmt.Sprintf("%s(%s)", name, quoteAggrColumn(column))}
+}
+
+// Count returns the expression count(column), column could be *
+func Count(column string) AggrExpr {
+	return aggrFunc("count", column)
+}
+
+// CountDistinct returns the expression count(DISTINCT column)
+func CountDistinct(column string) AggrExpr {
+	return AggrExpr{expr: fmt.Sprintf("count(DISTINCT %s)", quoteAggrColumn(column))}
+}
+
+// Sum returns the expression sum(column)
+func Sum(column string) AggrExpr {
+	return aggrFunc("sum", column)
+}
+
+// Avg returns the expression avg(column)
+func Avg(column string) AggrExpr {
+	return aggrFunc("avg", column)
+}
+
+// Min returns the expression min(column)
+func Min(column string) AggrExpr {
+	return aggrFunc("min", column)
+}
+
+// Max returns the expression max(column)
+func Max(column string) AggrExpr {
+	return aggrFunc("max", column)
+}
+
+// RowNumber returns the window function ROW_NUMBER()
+func RowNumber() AggrExpr {
+	return AggrExpr{expr: "ROW_NUMBER()"}
+}
+
+// Rank returns the window function RANK()
+func Rank() AggrExpr {
+	return AggrExpr{expr: "RANK()"}
+}
+
+// DenseRank returns the window function DENSE_RANK()
+func DenseRank() AggrExpr {
+	return AggrExpr{expr: "DENSE_RANK()"}
+}
+
+// Lag returns the window function LAG(column, offset) which is the value of
+// the column of the offset rows before
+func Lag(column string, offset int) AggrExpr {
+	return AggrExpr{expr: fmt.Sprintf("LAG(%s, %d)", quoteAggrColumn(column), offset)}
+}
+
+// Lead returns the window function LEAD(column, offset) which is the value of
+// the column of the offset rows after
+func Lead(column string, offset int) AggrExpr {
+	return AggrExpr{expr: fmt.Sprintf("LEAD(%s, %d)", quoteAggrColumn(column), offset)}
+}
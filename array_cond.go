@@ -0,0 +1,46 @@
+// Copyright 2021 The Xorm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package xorm
+
+import (
+	"reflect"
+
+	"github.com/xorm-io/builder"
+	"github.com/xorm-io/xorm/dialects"
+)
+
+// arrayValues expands a single slice argument as the values
+func arrayValues(values []interface{}) []interface{} {
+	if len(values) != 1 {
+		return values
+	}
+	v := reflect.ValueOf(values[0])
+	if v.Kind() != reflect.Slice || v.Type().Elem().Kind() == reflect.Uint8 {
+		return values
+	}
+	var res = make([]interface{}, 0, v.Len())
+	for i := 0; i < v.Len(); i++ {
+		res = append(res, v.Index(i).Interface())
+	}
+	return res
+}
+
+// ArrayContains returns the condition that the array column contains all the
+// values. The databases without arrays store the column as JSON, so it's
+// checked as JSON containment.
+func ArrayContains(col string, values ...interface{}) builder.Cond {
+	values = arrayValues(values)
+	return dialectCond(func(dialect dialects.Dialect) (string, []interface{}, error) {
+		return dialect.ArrayContains(dialect.Quoter().Quote(col), values)
+	})
+}
+
+// ArrayOverlap returns the condition that the array column has any of the values
+func ArrayOverlap(col string, values ...interface{}) builder.Cond {
+	values = arrayValues(values)
+	return dialectCond(func(dialect dialects.Dialect) (string, []interface{}, error) {
+		return dialect.ArrayOverlap(dialect.Quoter().Quote(col), values)
+	})
+}
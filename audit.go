@@ -0,0 +1,438 @@
+// Copyright 2021 The Xorm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package xorm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/xorm-io/builder"
+	"github.com/xorm-io/xorm/internal/json"
+	"github.com/xorm-io/xorm/internal/utils"
+	"github.com/xorm-io/xorm/log"
+	"github.com/xorm-io/xorm/schemas"
+)
+
+// enumerates the operations of the audit logs
+const (
+	AuditInsert = "insert"
+	AuditUpdate = "update"
+	AuditDelete = "delete"
+)
+
+// ErrAuditNoPrimaryKey is returned when the updated or deleted rows of an
+// audited table could not be identified
+var ErrAuditNoPrimaryKey = errors.New("Audited table should have primary keys")
+
+// Auditable is a marker interface, the inserts, updates and deletes of the
+// beans which implement it are recorded into the audit table
+type Auditable interface {
+	Auditable()
+}
+
+var auditableType = reflect.TypeOf((*Auditable)(nil)).Elem()
+
+// AuditChange represents the old and the new value of a column
+type AuditChange struct {
+	Old interface{} `json:"old"`
+	New interface{} `json:"new"`
+}
+
+// AuditLog represents a change recorded into the audit table, which should be
+// created by Sync2(new(AuditLog)) before auditing
+type AuditLog struct {
+	Id        int64
+	Table     string                  `xorm:"'table_name' varchar(255) notnull index(audit_log_entity)"`
+	PK        string                  `xorm:"'pk' varchar(255) index(audit_log_entity)"`
+	Operation string                  `xorm:"varchar(20) notnull"`
+	Changes   map[string]*AuditChange `xorm:"text"`
+	Actor     string                  `xorm:"varchar(255)"`
+	Created   time.Time               `xorm:"created"`
+}
+
+// TableName implements TableName interface
+func (AuditLog) TableName() string {
+	return "audit_log"
+}
+
+type auditActorKey struct{}
+
+// WithAuditActor returns a context carrying the actor of the audit logs
+func WithAuditActor(ctx context.Context, actor string) context.Context {
+	return context.WithValue(ctx, auditActorKey{}, actor)
+}
+
+// AuditActor returns the actor carried by the context
+func AuditActor(ctx context.Context) string {
+	if ctx == nil {
+		return ""
+	}
+	actor, _ := ctx.Value(auditActorKey{}).(string)
+	return actor
+}
+
+// Audit records the changes of the tables besides the beans implementing
+// Auditable, the parameters are the beans or the table names
+func (engine *Engine) Audit(beansOrTableNames ...interface{}) {
+	engine.auditMutex.Lock()
+	defer engine.auditMutex.Unlock()
+	if engine.auditTables == nil {
+		engine.auditTables = make(map[string]bool)
+	}
+	for _, v := range beansOrTableNames {
+		if name, ok := v.(string); ok {
+			engine.auditTables[name] = true
+		} else {
+			engine.auditTables[engine.TableName(v)] = true
+		}
+	}
+}
+
+func isAuditable(bean interface{}) bool {
+	if _, ok := bean.(Auditable); ok {
+		return true
+	}
+	v := reflect.Indirect(reflect.ValueOf(bean))
+	if v.Kind() != reflect.Slice {
+		return false
+	}
+	elemType := v.Type().Elem()
+	return elemType.Implements(auditableType) || reflect.PtrTo(elemType).Implements(auditableType)
+}
+
+// isAudited returns true if the changes of the bean or the table should be recorded
+func (engine *Engine) isAudited(bean interface{}, tableName string) bool {
+	if isAuditable(bean) {
+		return true
+	}
+	if tableName == "" {
+		return false
+	}
+	engine.auditMutex.RLock()
+	defer engine.auditMutex.RUnlock()
+	return engine.auditTables[tableName]
+}
+
+// isAudited returns true if the changes of the bean on the table of the
+// statement should be recorded
+func (session *Session) isAudited(bean interface{}) bool {
+	if table := session.statement.RefTable; table != nil && table.Type != nil &&
+		reflect.PtrTo(table.Type).Implements(auditableType) {
+		return true
+	}
+	return session.engine.isAudited(bean, session.statement.TableName())
+}
+
+// auditTableName returns the table name of a struct bean or a slice of them
+func (engine *Engine) auditTableName(bean interface{}) string {
+	engine.auditMutex.RLock()
+	audited := len(engine.auditTables) > 0
+	engine.auditMutex.RUnlock()
+	if !audited {
+		return ""
+	}
+	v := reflect.Indirect(reflect.ValueOf(bean))
+	if v.Kind() == reflect.Slice {
+		if v.Len() == 0 {
+			return ""
+		}
+		v = reflect.Indirect(v.Index(0))
+		if v.Kind() == reflect.Interface {
+			v = reflect.Indirect(v.Elem())
+		}
+	}
+	if v.Kind() != reflect.Struct {
+		return ""
+	}
+	return engine.TableName(v.Interface())
+}
+
+// needAudit returns true if the changes of one of the beans should be recorded
+func (session *Session) needAudit(beans ...interface{}) bool {
+	if session.dryRun || session.auditing {
+		return false
+	}
+	tableName := session.statement.TableName()
+	for _, bean := range beans {
+		name := tableName
+		if name == "" {
+			name = session.engine.auditTableName(bean)
+		}
+		if session.engine.isAudited(bean, name) {
+			return true
+		}
+	}
+	return false
+}
+
+// audit runs the operation and writes the audit logs captured by it in the
+// same transaction, a transaction is began if the session is not in one
+func (session *Session) audit(operation func() (int64, error)) (int64, error) {
+	var began bool
+	if session.isAutoCommit {
+		if err := session.Begin(); err != nil {
+			return 0, err
+		}
+		began = true
+		defer session.Rollback()
+	}
+
+	session.auditing = true
+	affected, err := operation()
+	session.auditing = false
+	logs := session.auditLogs
+	session.auditLogs = nil
+	if err != nil {
+		return affected, err
+	}
+
+	if len(logs) > 0 {
+		session.resetStatement()
+		if _, err := session.insert(&logs); err != nil {
+			return affected, err
+		}
+	}
+	if began {
+		if err := session.Commit(); err != nil {
+			return affected, err
+		}
+	}
+	return affected, nil
+}
+
+func (session *Session) addAuditLog(table *schemas.Table, tableName, operation, pk string, changes map[string]*AuditChange) {
+	redactAuditChanges(table, changes)
+	session.auditLogs = append(session.auditLogs, &AuditLog{
+		Table:     tableName,
+		PK:        pk,
+		Operation: operation,
+		Changes:   changes,
+		Actor:     AuditActor(session.ctx),
+	})
+}
+
+// redactAuditChanges masks the values of the sensitive columns as the
+// structured logger does, the changes of them are still recorded
+func redactAuditChanges(table *schemas.Table, changes map[string]*AuditChange) {
+	if table == nil {
+		return
+	}
+	for _, name := range table.SensitiveColumns() {
+		for k, change := range changes {
+			if !strings.EqualFold(k, name) {
+				continue
+			}
+			if change.Old != nil {
+				change.Old = log.RedactedValue
+			}
+			if change.New != nil {
+				change.New = log.RedactedValue
+			}
+		}
+	}
+}
+
+// auditValue converts the value read from database or bound to a SQL to the
+// value stored in the audit logs
+func auditValue(v interface{}) interface{} {
+	if bs, ok := v.([]byte); ok {
+		return string(bs)
+	}
+	return v
+}
+
+// auditPK returns the primary key of the row, the values of a composite
+// primary key are formatted as a JSON array. It's empty if it's unknown, i.e.
+// the auto increment ids of the beans inserted in batch.
+func auditPK(table *schemas.Table, row map[string]interface{}) string {
+	if table == nil || len(table.PrimaryKeys) == 0 {
+		return ""
+	}
+	var pk = make([]interface{}, 0, len(table.PrimaryKeys))
+	for _, name := range table.PrimaryKeys {
+		if row[name] == nil {
+			return ""
+		}
+		pk = append(pk, row[name])
+	}
+	if len(pk) == 1 {
+		return fmt.Sprint(pk[0])
+	}
+	bs, _ := json.DefaultJSONHandler.Marshal(pk)
+	return string(bs)
+}
+
+// auditInsertMap captures the audit log of an inserted map
+func (session *Session) auditInsertMap(values map[string]interface{}) {
+	if !session.auditing || !session.isAudited(values) {
+		return
+	}
+	tableName := session.statement.TableName()
+	var row = make(map[string]interface{}, len(values))
+	var changes = make(map[string]*AuditChange, len(values))
+	for k, v := range values {
+		row[k] = auditValue(v)
+		changes[k] = &AuditChange{New: row[k]}
+	}
+	session.addAuditLog(session.statement.RefTable, tableName, AuditInsert, auditPK(session.statement.RefTable, row), changes)
+}
+
+// auditInsertMapString captures the audit log of an inserted string map
+func (session *Session) auditInsertMapString(values map[string]string) {
+	if !session.auditing {
+		return
+	}
+	var m = make(map[string]interface{}, len(values))
+	for k, v := range values {
+		m[k] = v
+	}
+	session.auditInsertMap(m)
+}
+
+// auditInsert captures the audit logs of an inserted struct bean or a slice of them
+func (session *Session) auditInsert(bean interface{}) error {
+	if !session.auditing {
+		return nil
+	}
+	tableName := session.statement.TableName()
+	table := session.statement.RefTable
+	if table == nil || !session.isAudited(bean) {
+		return nil
+	}
+
+	var beans []interface{}
+	sliceValue := reflect.Indirect(reflect.ValueOf(bean))
+	if sliceValue.Kind() == reflect.Slice {
+		for i := 0; i < sliceValue.Len(); i++ {
+			beans = append(beans, reflect.Indirect(sliceValue.Index(i)).Addr().Interface())
+		}
+	} else {
+		beans = append(beans, bean)
+	}
+
+	for _, bean := range beans {
+		var row = make(map[string]interface{})
+		var changes = make(map[string]*AuditChange)
+		for _, col := range table.Columns() {
+			if col.MapType == schemas.ONLYFROMDB {
+				continue
+			}
+			fieldValue, err := col.ValueOf(bean)
+			if err != nil {
+				return err
+			}
+			if col.IsAutoIncrement && utils.IsValueZero(*fieldValue) {
+				continue
+			}
+			v, err := session.statement.Value2Interface(col, *fieldValue)
+			if err != nil {
+				return err
+			}
+			row[col.Name] = auditValue(v)
+			changes[col.Name] = &AuditChange{New: row[col.Name]}
+		}
+		session.addAuditLog(table, tableName, AuditInsert, auditPK(table, row), changes)
+	}
+	return nil
+}
+
+// auditRows reads the rows to be updated or deleted by the query
+func (session *Session) auditRows(bean interface{}, sqlStr string, args []interface{}) ([]map[string]interface{}, error) {
+	if !session.auditing || !session.isAudited(bean) {
+		return nil, nil
+	}
+	table := session.statement.RefTable
+	if table == nil || len(table.PrimaryKeys) == 0 {
+		return nil, ErrAuditNoPrimaryKey
+	}
+	return session.queryAuditRows(sqlStr, args...)
+}
+
+// queryAuditRows reads the rows without resetting the statement, since the
+// rows are read in the middle of an update or a delete
+func (session *Session) queryAuditRows(sqlStr string, args ...interface{}) ([]map[string]interface{}, error) {
+	autoResetStatement := session.autoResetStatement
+	session.autoResetStatement = false
+	rows, err := session.queryRows(sqlStr, args...)
+	session.autoResetStatement = autoResetStatement
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	results, err := rows2Interfaces(rows)
+	if err != nil {
+		return nil, err
+	}
+	for _, row := range results {
+		for k, v := range row {
+			row[k] = auditValue(v)
+		}
+	}
+	return results, nil
+}
+
+// auditUpdate captures the audit logs of the updated rows by comparing the
+// old rows with the new ones
+func (session *Session) auditUpdate(table *schemas.Table, tableName string, oldRows []map[string]interface{}) error {
+	if len(oldRows) == 0 {
+		return nil
+	}
+
+	var conds = make([]builder.Cond, 0, len(oldRows))
+	for _, row := range oldRows {
+		var eq = builder.Eq{}
+		for _, name := range table.PrimaryKeys {
+			eq[session.engine.Quote(name)] = row[name]
+		}
+		conds = append(conds, eq)
+	}
+	condSQL, condArgs, err := session.statement.GenCondSQL(builder.Or(conds...))
+	if err != nil {
+		return err
+	}
+	newRows, err := session.queryAuditRows(fmt.Sprintf("SELECT * FROM %s WHERE %s",
+		session.engine.Quote(tableName), condSQL), condArgs...)
+	if err != nil {
+		return err
+	}
+	var newRowsByPK = make(map[string]map[string]interface{}, len(newRows))
+	for _, row := range newRows {
+		newRowsByPK[auditPK(table, row)] = row
+	}
+
+	for _, oldRow := range oldRows {
+		pk := auditPK(table, oldRow)
+		newRow, ok := newRowsByPK[pk]
+		if !ok {
+			continue
+		}
+		var changes = make(map[string]*AuditChange)
+		for k, v := range newRow {
+			if !reflect.DeepEqual(oldRow[k], v) {
+				changes[k] = &AuditChange{Old: oldRow[k], New: v}
+			}
+		}
+		if len(changes) > 0 {
+			session.addAuditLog(table, tableName, AuditUpdate, pk, changes)
+		}
+	}
+	return nil
+}
+
+// auditDelete captures the audit logs of the deleted rows
+func (session *Session) auditDelete(table *schemas.Table, tableName string, oldRows []map[string]interface{}) {
+	for _, row := range oldRows {
+		var changes = make(map[string]*AuditChange, len(row))
+		for k, v := range row {
+			changes[k] = &AuditChange{Old: v}
+		}
+		session.addAuditLog(table, tableName, AuditDelete, auditPK(table, row), changes)
+	}
+}
@@ -18,7 +18,7 @@ import (
 const (
 	// CacheExpired is default cache expired time
 	CacheExpired = 60 * time.Minute
-	// CacheMaxMemory is not use now
+	// CacheMaxMemory is the default memory budget in megabytes of the memory bounded cacher
 	CacheMaxMemory = 256
 	// CacheGcInterval represents interval time to clear all expired nodes
 	CacheGcInterval = 10 * time.Minute
@@ -32,6 +32,8 @@ var (
 	ErrNotStored = errors.New("xorm/cache: not stored")
 	// ErrNotExist record does not exist error
 	ErrNotExist = errors.New("Record does not exist")
+	// ErrTableOptionsNotSupported is returned when the cacher could not be configured per table
+	ErrTableOptionsNotSupported = errors.New("xorm/cache: table options are not supported by the cacher")
 )
 
 // CacheStore is a interface to store cache
@@ -57,6 +59,32 @@ type Cacher interface {
 	ClearBeans(tableName string)
 }
 
+// TableOptions represents the cache options of a table, the zero values mean
+// the ones of the cacher
+type TableOptions struct {
+	Expired        time.Duration
+	MaxElementSize int
+}
+
+// TableCacher is implemented by the cachers which could be configured per table
+type TableCacher interface {
+	SetTableOptions(tableName string, opts TableOptions)
+}
+
+// CacheStats represents the statistics of the cache of a table
+type CacheStats struct {
+	Hits      int64
+	Misses    int64
+	Evictions int64
+	Entries   int64
+	Memory    int64 // estimated bytes of the entries, only counted by the memory bounded cacher
+}
+
+// StatsCacher is implemented by the cachers which report the statistics
+type StatsCacher interface {
+	Stats() map[string]CacheStats
+}
+
 func encodeIds(ids []schemas.PK) (string, error) {
 	buf := new(bytes.Buffer)
 	enc := gob.NewEncoder(buf)
@@ -0,0 +1,134 @@
+// Copyright 2021 The Xorm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package caches
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"strings"
+)
+
+// PubSub represents a message bus between the processes, RedisStore implements it
+type PubSub interface {
+	Publish(channel, message string) error
+	Subscribe(channel string, handler func(message string)) (func(), error)
+}
+
+// DefaultInvalidationChannel is the default channel of the invalidation messages
+const DefaultInvalidationChannel = "invalidation"
+
+// enumerates the operations of the invalidation messages
+const (
+	invalidateIds    = "ids"
+	invalidateBeans  = "beans"
+	invalidateSQL    = "sql"
+	invalidateBean   = "bean"
+	invalidationSep  = "\n"
+	invalidationArgs = 4
+)
+
+// DistributedCacher wraps a cacher and propagates its invalidations, i.e.
+// DelIds, DelBean, ClearIds and ClearBeans, to the cachers of the other
+// processes subscribing the same channel
+type DistributedCacher struct {
+	Cacher
+	pubsub  PubSub
+	channel string
+	nodeID  string
+	cancel  func()
+}
+
+var _ Cacher = &DistributedCacher{}
+
+// NewDistributedCacher creates a distributed cacher, channel is default to
+// DefaultInvalidationChannel if it's empty
+func NewDistributedCacher(cacher Cacher, pubsub PubSub, channel string) (*DistributedCacher, error) {
+	if channel == "" {
+		channel = DefaultInvalidationChannel
+	}
+	var id = make([]byte, 8)
+	if _, err := rand.Read(id); err != nil {
+		return nil, err
+	}
+	c := &DistributedCacher{
+		Cacher:  cacher,
+		pubsub:  pubsub,
+		channel: channel,
+		nodeID:  hex.EncodeToString(id),
+	}
+	cancel, err := pubsub.Subscribe(channel, c.receive)
+	if err != nil {
+		return nil, err
+	}
+	c.cancel = cancel
+	return c, nil
+}
+
+// Close stops receiving the invalidations of the other processes
+func (c *DistributedCacher) Close() {
+	c.cancel()
+}
+
+func (c *DistributedCacher) publish(op, tableName, arg string) {
+	// the cacher is best effort, the entries of the other processes expire finally
+	_ = c.pubsub.Publish(c.channel, strings.Join([]string{c.nodeID, op, tableName, arg}, invalidationSep))
+}
+
+// receive applies the invalidation of the other processes
+func (c *DistributedCacher) receive(message string) {
+	fields := strings.SplitN(message, invalidationSep, invalidationArgs)
+	if len(fields) != invalidationArgs || fields[0] == c.nodeID {
+		return
+	}
+	switch fields[1] {
+	case invalidateIds:
+		c.Cacher.ClearIds(fields[2])
+	case invalidateBeans:
+		c.Cacher.ClearBeans(fields[2])
+	case invalidateSQL:
+		c.Cacher.DelIds(fields[2], fields[3])
+	case invalidateBean:
+		c.Cacher.DelBean(fields[2], fields[3])
+	}
+}
+
+// DelIds deletes the ids of the sql of all the processes
+func (c *DistributedCacher) DelIds(tableName, sql string) {
+	c.Cacher.DelIds(tableName, sql)
+	c.publish(invalidateSQL, tableName, sql)
+}
+
+// DelBean deletes the bean of all the processes
+func (c *DistributedCacher) DelBean(tableName string, id string) {
+	c.Cacher.DelBean(tableName, id)
+	c.publish(invalidateBean, tableName, id)
+}
+
+// ClearIds clears the sql-ids mapping of the table of all the processes
+func (c *DistributedCacher) ClearIds(tableName string) {
+	c.Cacher.ClearIds(tableName)
+	c.publish(invalidateIds, tableName, "")
+}
+
+// ClearBeans clears the beans of the table of all the processes
+func (c *DistributedCacher) ClearBeans(tableName string) {
+	c.Cacher.ClearBeans(tableName)
+	c.publish(invalidateBeans, tableName, "")
+}
+
+// SetTableOptions implements TableCacher if the wrapped cacher implements it
+func (c *DistributedCacher) SetTableOptions(tableName string, opts TableOptions) {
+	if cacher, ok := c.Cacher.(TableCacher); ok {
+		cacher.SetTableOptions(tableName, opts)
+	}
+}
+
+// Stats implements StatsCacher, it's empty if the wrapped cacher doesn't report
+func (c *DistributedCacher) Stats() map[string]CacheStats {
+	if cacher, ok := c.Cacher.(StatsCacher); ok {
+		return cacher.Stats()
+	}
+	return map[string]CacheStats{}
+}
@@ -22,8 +22,25 @@ type LRUCacher struct {
 	MaxElementSize int
 	Expired        time.Duration
 	GcInterval     time.Duration
+	// MaxMemory is the max estimated bytes of the cached entries, the least
+	// recently used entries are evicted if it's exceeded. Zero means no limit.
+	MaxMemory int64
+	memory    int64
+	tables    map[string]*lruTable
 }
 
+// lruTable represents the options and the statistics of a table
+type lruTable struct {
+	opts  TableOptions
+	stats CacheStats
+}
+
+var (
+	_ Cacher      = &LRUCacher{}
+	_ TableCacher = &LRUCacher{}
+	_ StatsCacher = &LRUCacher{}
+)
+
 // NewLRUCacher creates a cacher
 func NewLRUCacher(store CacheStore, maxElementSize int) *LRUCacher {
 	return NewLRUCacher2(store, 3600*time.Second, maxElementSize)
@@ -36,11 +53,24 @@ func NewLRUCacher2(store CacheStore, expired time.Duration, maxElementSize int)
 		GcInterval: CacheGcInterval, MaxElementSize: maxElementSize,
 		sqlIndex: make(map[string]map[string]*list.Element),
 		idIndex:  make(map[string]map[string]*list.Element),
+		tables:   make(map[string]*lruTable),
 	}
 	cacher.RunGC()
 	return cacher
 }
 
+// NewMemoryLRUCacher creates a cacher bounded by the estimated bytes of the
+// entries instead of the number of them, maxMemory is in megabytes and default
+// to CacheMaxMemory if it's not positive
+func NewMemoryLRUCacher(store CacheStore, expired time.Duration, maxMemory int64) *LRUCacher {
+	if maxMemory <= 0 {
+		maxMemory = CacheMaxMemory
+	}
+	cacher := NewLRUCacher2(store, expired, 0)
+	cacher.MaxMemory = maxMemory << 20
+	return cacher
+}
+
 // RunGC run once every m.GcInterval
 func (m *LRUCacher) RunGC() {
 	time.AfterFunc(m.GcInterval, func() {
@@ -54,32 +84,94 @@ func (m *LRUCacher) GC() {
 	m.mutex.Lock()
 	defer m.mutex.Unlock()
 	var removedNum int
-	for e := m.idList.Front(); e != nil; {
-		if removedNum <= CacheGcMaxRemoved &&
-			time.Now().Sub(e.Value.(*idNode).lastVisit) > m.Expired {
+	for e := m.idList.Front(); e != nil && removedNum <= CacheGcMaxRemoved; {
+		next := e.Next()
+		node := e.Value.(*idNode)
+		if time.Now().Sub(node.lastVisit) > m.expired(node.tbName) {
 			removedNum++
-			next := e.Next()
-			node := e.Value.(*idNode)
+			m.table(node.tbName).stats.Evictions++
 			m.delBean(node.tbName, node.id)
-			e = next
-		} else {
-			break
 		}
+		e = next
 	}
 
 	removedNum = 0
-	for e := m.sqlList.Front(); e != nil; {
-		if removedNum <= CacheGcMaxRemoved &&
-			time.Now().Sub(e.Value.(*sqlNode).lastVisit) > m.Expired {
+	for e := m.sqlList.Front(); e != nil && removedNum <= CacheGcMaxRemoved; {
+		next := e.Next()
+		node := e.Value.(*sqlNode)
+		if time.Now().Sub(node.lastVisit) > m.expired(node.tbName) {
 			removedNum++
-			next := e.Next()
-			node := e.Value.(*sqlNode)
+			m.table(node.tbName).stats.Evictions++
 			m.delIds(node.tbName, node.sql)
-			e = next
-		} else {
-			break
 		}
+		e = next
+	}
+}
+
+// table returns the options and the statistics of the table
+func (m *LRUCacher) table(tableName string) *lruTable {
+	if m.tables == nil {
+		m.tables = make(map[string]*lruTable)
+	}
+	t, ok := m.tables[tableName]
+	if !ok {
+		t = &lruTable{}
+		m.tables[tableName] = t
+	}
+	return t
+}
+
+func (m *LRUCacher) expired(tableName string) time.Duration {
+	if t, ok := m.tables[tableName]; ok && t.opts.Expired > 0 {
+		return t.opts.Expired
+	}
+	return m.Expired
+}
+
+func (m *LRUCacher) maxElementSize(tableName string) int {
+	if t, ok := m.tables[tableName]; ok && t.opts.MaxElementSize > 0 {
+		return t.opts.MaxElementSize
+	}
+	return 0
+}
+
+// SetTableOptions sets the expired time and the max element size of a table,
+// the zero values mean the ones of the cacher
+func (m *LRUCacher) SetTableOptions(tableName string, opts TableOptions) {
+	m.mutex.Lock()
+	m.table(tableName).opts = opts
+	m.mutex.Unlock()
+}
+
+// Stats returns the statistics of the tables
+func (m *LRUCacher) Stats() map[string]CacheStats {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	var stats = make(map[string]CacheStats, len(m.tables))
+	for name, t := range m.tables {
+		stats[name] = t.stats
 	}
+	for name, index := range m.idIndex {
+		s := stats[name]
+		s.Entries += int64(len(index))
+		stats[name] = s
+	}
+	for name, index := range m.sqlIndex {
+		s := stats[name]
+		s.Entries += int64(len(index))
+		stats[name] = s
+	}
+	return stats
+}
+
+// hit records a hit or a miss of the table
+func (m *LRUCacher) hit(tableName string, v interface{}) interface{} {
+	if v == nil {
+		m.table(tableName).stats.Misses++
+	} else {
+		m.table(tableName).stats.Hits++
+	}
+	return v
 }
 
 // GetIds returns all bean's ids according to sql and parameter from cache
@@ -93,21 +185,24 @@ func (m *LRUCacher) GetIds(tableName, sql string) interface{} {
 		if el, ok := m.sqlIndex[tableName][sql]; !ok {
 			el = m.sqlList.PushBack(newSQLNode(tableName, sql))
 			m.sqlIndex[tableName][sql] = el
+			m.addMemory(tableName, el, sql, v)
 		} else {
 			lastTime := el.Value.(*sqlNode).lastVisit
 			// if expired, remove the node and return nil
-			if time.Now().Sub(lastTime) > m.Expired {
+			if time.Now().Sub(lastTime) > m.expired(tableName) {
+				m.table(tableName).stats.Evictions++
 				m.delIds(tableName, sql)
-				return nil
+				return m.hit(tableName, nil)
 			}
 			m.sqlList.MoveToBack(el)
 			el.Value.(*sqlNode).lastVisit = time.Now()
 		}
-		return v
+		m.evictMemory()
+		return m.hit(tableName, v)
 	}
 
 	m.delIds(tableName, sql)
-	return nil
+	return m.hit(tableName, nil)
 }
 
 // GetBean returns bean according tableName and id from cache
@@ -122,28 +217,32 @@ func (m *LRUCacher) GetBean(tableName string, id string) interface{} {
 		if el, ok := m.idIndex[tableName][id]; ok {
 			lastTime := el.Value.(*idNode).lastVisit
 			// if expired, remove the node and return nil
-			if time.Now().Sub(lastTime) > m.Expired {
+			if time.Now().Sub(lastTime) > m.expired(tableName) {
+				m.table(tableName).stats.Evictions++
 				m.delBean(tableName, id)
-				return nil
+				return m.hit(tableName, nil)
 			}
 			m.idList.MoveToBack(el)
 			el.Value.(*idNode).lastVisit = time.Now()
 		} else {
 			el = m.idList.PushBack(newIDNode(tableName, id))
 			m.idIndex[tableName][id] = el
+			m.addMemory(tableName, el, tid, v)
 		}
-		return v
+		m.evictMemory()
+		return m.hit(tableName, v)
 	}
 
 	// store bean is not exist, then remove memory's index
 	m.delBean(tableName, id)
-	return nil
+	return m.hit(tableName, nil)
 }
 
 // clearIds clears all sql-ids mapping on table tableName from cache
 func (m *LRUCacher) clearIds(tableName string) {
 	if tis, ok := m.sqlIndex[tableName]; ok {
 		for sql, v := range tis {
+			m.removeMemory(tableName, v)
 			m.sqlList.Remove(v)
 			m.store.Del(sql)
 		}
@@ -161,6 +260,7 @@ func (m *LRUCacher) ClearIds(tableName string) {
 func (m *LRUCacher) clearBeans(tableName string) {
 	if tis, ok := m.idIndex[tableName]; ok {
 		for id, v := range tis {
+			m.removeMemory(tableName, v)
 			m.idList.Remove(v)
 			tid := genID(tableName, id)
 			m.store.Del(tid)
@@ -182,18 +282,32 @@ func (m *LRUCacher) PutIds(tableName, sql string, ids interface{}) {
 	if _, ok := m.sqlIndex[tableName]; !ok {
 		m.sqlIndex[tableName] = make(map[string]*list.Element)
 	}
-	if el, ok := m.sqlIndex[tableName][sql]; !ok {
+	el, ok := m.sqlIndex[tableName][sql]
+	if !ok {
 		el = m.sqlList.PushBack(newSQLNode(tableName, sql))
 		m.sqlIndex[tableName][sql] = el
 	} else {
 		el.Value.(*sqlNode).lastVisit = time.Now()
 	}
 	m.store.Put(sql, ids)
-	if m.sqlList.Len() > m.MaxElementSize {
+	m.removeMemory(tableName, el)
+	m.addMemory(tableName, el, sql, ids)
+	if m.MaxElementSize > 0 && m.sqlList.Len() > m.MaxElementSize {
 		e := m.sqlList.Front()
 		node := e.Value.(*sqlNode)
+		m.table(node.tbName).stats.Evictions++
 		m.delIds(node.tbName, node.sql)
 	}
+	if max := m.maxElementSize(tableName); max > 0 && len(m.sqlIndex[tableName]) > max {
+		for e := m.sqlList.Front(); e != nil; e = e.Next() {
+			if node := e.Value.(*sqlNode); node.tbName == tableName {
+				m.table(tableName).stats.Evictions++
+				m.delIds(tableName, node.sql)
+				break
+			}
+		}
+	}
+	m.evictMemory()
 	m.mutex.Unlock()
 }
 
@@ -203,6 +317,9 @@ func (m *LRUCacher) PutBean(tableName string, id string, obj interface{}) {
 	var el *list.Element
 	var ok bool
 
+	if _, ok = m.idIndex[tableName]; !ok {
+		m.idIndex[tableName] = make(map[string]*list.Element)
+	}
 	if el, ok = m.idIndex[tableName][id]; !ok {
 		el = m.idList.PushBack(newIDNode(tableName, id))
 		m.idIndex[tableName][id] = el
@@ -210,18 +327,33 @@ func (m *LRUCacher) PutBean(tableName string, id string, obj interface{}) {
 		el.Value.(*idNode).lastVisit = time.Now()
 	}
 
-	m.store.Put(genID(tableName, id), obj)
-	if m.idList.Len() > m.MaxElementSize {
+	tid := genID(tableName, id)
+	m.store.Put(tid, obj)
+	m.removeMemory(tableName, el)
+	m.addMemory(tableName, el, tid, obj)
+	if m.MaxElementSize > 0 && m.idList.Len() > m.MaxElementSize {
 		e := m.idList.Front()
 		node := e.Value.(*idNode)
+		m.table(node.tbName).stats.Evictions++
 		m.delBean(node.tbName, node.id)
 	}
+	if max := m.maxElementSize(tableName); max > 0 && len(m.idIndex[tableName]) > max {
+		for e := m.idList.Front(); e != nil; e = e.Next() {
+			if node := e.Value.(*idNode); node.tbName == tableName {
+				m.table(tableName).stats.Evictions++
+				m.delBean(tableName, node.id)
+				break
+			}
+		}
+	}
+	m.evictMemory()
 	m.mutex.Unlock()
 }
 
 func (m *LRUCacher) delIds(tableName, sql string) {
 	if _, ok := m.sqlIndex[tableName]; ok {
 		if el, ok := m.sqlIndex[tableName][sql]; ok {
+			m.removeMemory(tableName, el)
 			delete(m.sqlIndex[tableName], sql)
 			m.sqlList.Remove(el)
 		}
@@ -239,6 +371,7 @@ func (m *LRUCacher) DelIds(tableName, sql string) {
 func (m *LRUCacher) delBean(tableName string, id string) {
 	tid := genID(tableName, id)
 	if el, ok := m.idIndex[tableName][id]; ok {
+		m.removeMemory(tableName, el)
 		delete(m.idIndex[tableName], id)
 		m.idList.Remove(el)
 		m.clearIds(tableName)
@@ -253,16 +386,70 @@ func (m *LRUCacher) DelBean(tableName string, id string) {
 	m.mutex.Unlock()
 }
 
+// addMemory records the estimated bytes of the key and the value of the
+// entry in memory bounded mode
+func (m *LRUCacher) addMemory(tableName string, el *list.Element, key string, value interface{}) {
+	if m.MaxMemory <= 0 {
+		return
+	}
+	size := sizeOf(key) + sizeOf(value)
+	switch node := el.Value.(type) {
+	case *idNode:
+		node.size = size
+	case *sqlNode:
+		node.size = size
+	}
+	m.memory += size
+	m.table(tableName).stats.Memory += size
+}
+
+func (m *LRUCacher) removeMemory(tableName string, el *list.Element) {
+	var size int64
+	switch node := el.Value.(type) {
+	case *idNode:
+		size, node.size = node.size, 0
+	case *sqlNode:
+		size, node.size = node.size, 0
+	}
+	if size == 0 {
+		return
+	}
+	m.memory -= size
+	m.table(tableName).stats.Memory -= size
+}
+
+// evictMemory evicts the least recently used entries until the memory is
+// under the limit
+func (m *LRUCacher) evictMemory() {
+	for m.MaxMemory > 0 && m.memory > m.MaxMemory {
+		idEl, sqlEl := m.idList.Front(), m.sqlList.Front()
+		if idEl == nil && sqlEl == nil {
+			return
+		}
+		if sqlEl == nil || (idEl != nil && idEl.Value.(*idNode).lastVisit.Before(sqlEl.Value.(*sqlNode).lastVisit)) {
+			node := idEl.Value.(*idNode)
+			m.table(node.tbName).stats.Evictions++
+			m.delBean(node.tbName, node.id)
+		} else {
+			node := sqlEl.Value.(*sqlNode)
+			m.table(node.tbName).stats.Evictions++
+			m.delIds(node.tbName, node.sql)
+		}
+	}
+}
+
 type idNode struct {
 	tbName    string
 	id        string
 	lastVisit time.Time
+	size      int64
 }
 
 type sqlNode struct {
 	tbName    string
 	sql       string
 	lastVisit time.Time
+	size      int64
 }
 
 func genSQLKey(sql string, args interface{}) string {
@@ -274,9 +461,9 @@ func genID(prefix string, id string) string {
 }
 
 func newIDNode(tbName string, id string) *idNode {
-	return &idNode{tbName, id, time.Now()}
+	return &idNode{tbName: tbName, id: id, lastVisit: time.Now()}
 }
 
 func newSQLNode(tbName, sql string) *sqlNode {
-	return &sqlNode{tbName, sql, time.Now()}
+	return &sqlNode{tbName: tbName, sql: sql, lastVisit: time.Now()}
 }
@@ -5,7 +5,9 @@
 package caches
 
 import (
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/xorm-io/xorm/schemas"
@@ -50,3 +52,57 @@ func TestLRUCache(t *testing.T) {
 		assert.Nil(t, obj4)
 	}
 }
+
+func TestLRUCacheStats(t *testing.T) {
+	cacher := NewLRUCacher(NewMemoryStore(), 2)
+
+	tableName := "cache_object1"
+	assert.Nil(t, cacher.GetBean(tableName, "1"))
+	cacher.PutBean(tableName, "1", "bean1")
+	assert.EqualValues(t, "bean1", cacher.GetBean(tableName, "1"))
+	cacher.PutBean(tableName, "2", "bean2")
+	cacher.PutBean(tableName, "3", "bean3")
+	cacher.PutIds(tableName, "select * from cache_object1", "1")
+
+	stats := cacher.Stats()
+	assert.EqualValues(t, CacheStats{Hits: 1, Misses: 1, Evictions: 1, Entries: 3}, stats[tableName])
+}
+
+func TestLRUCacheTableOptions(t *testing.T) {
+	cacher := NewLRUCacher(NewMemoryStore(), 10000)
+	cacher.SetTableOptions("small", TableOptions{MaxElementSize: 1})
+	cacher.SetTableOptions("short", TableOptions{Expired: time.Millisecond})
+
+	cacher.PutBean("small", "1", "bean1")
+	cacher.PutBean("small", "2", "bean2")
+	assert.Nil(t, cacher.GetBean("small", "1"))
+	assert.EqualValues(t, "bean2", cacher.GetBean("small", "2"))
+
+	cacher.PutBean("short", "1", "bean1")
+	cacher.PutBean("default", "1", "bean1")
+	time.Sleep(5 * time.Millisecond)
+	assert.Nil(t, cacher.GetBean("short", "1"))
+	assert.EqualValues(t, "bean1", cacher.GetBean("default", "1"))
+
+	stats := cacher.Stats()
+	assert.EqualValues(t, 1, stats["small"].Evictions)
+	assert.EqualValues(t, 1, stats["short"].Evictions)
+	assert.EqualValues(t, 0, stats["default"].Evictions)
+}
+
+func TestMemoryLRUCache(t *testing.T) {
+	cacher := NewMemoryLRUCacher(NewMemoryStore(), time.Hour, 1)
+	// keep the budget small so that the test doesn't allocate too much
+	cacher.MaxMemory = 1024
+
+	tableName := "cache_object1"
+	cacher.PutBean(tableName, "1", strings.Repeat("a", 600))
+	cacher.PutBean(tableName, "2", strings.Repeat("b", 600))
+	assert.Nil(t, cacher.GetBean(tableName, "1"))
+	assert.NotNil(t, cacher.GetBean(tableName, "2"))
+
+	stats := cacher.Stats()[tableName]
+	assert.EqualValues(t, 1, stats.Evictions)
+	assert.EqualValues(t, 1, stats.Entries)
+	assert.True(t, stats.Memory > 600 && stats.Memory <= 1024)
+}
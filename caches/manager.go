@@ -4,7 +4,10 @@
 
 package caches
 
-import "sync"
+import (
+	"reflect"
+	"sync"
+)
 
 // Manager represents a cache manager
 type Manager struct {
@@ -58,3 +61,44 @@ func (mgr *Manager) SetDefaultCacher(cacher Cacher) {
 func (mgr *Manager) GetDefaultCacher() Cacher {
 	return mgr.cacher
 }
+
+// Stats returns the statistics of the tables reported by all the cachers
+func (mgr *Manager) Stats() map[string]CacheStats {
+	mgr.cacherLock.RLock()
+	var cachers = make([]Cacher, 0, len(mgr.cachers)+1)
+	if mgr.cacher != nil {
+		cachers = append(cachers, mgr.cacher)
+	}
+	for _, cacher := range mgr.cachers {
+		if cacher != nil {
+			cachers = append(cachers, cacher)
+		}
+	}
+	mgr.cacherLock.RUnlock()
+
+	var stats = make(map[string]CacheStats)
+	var visited = make(map[Cacher]bool, len(cachers))
+	for _, cacher := range cachers {
+		statsCacher, ok := cacher.(StatsCacher)
+		if !ok {
+			continue
+		}
+		// the cacher may be shared by the tables
+		if reflect.TypeOf(cacher).Comparable() {
+			if visited[cacher] {
+				continue
+			}
+			visited[cacher] = true
+		}
+		for name, s := range statsCacher.Stats() {
+			total := stats[name]
+			total.Hits += s.Hits
+			total.Misses += s.Misses
+			total.Evictions += s.Evictions
+			total.Entries += s.Entries
+			total.Memory += s.Memory
+			stats[name] = total
+		}
+	}
+	return stats
+}
@@ -0,0 +1,458 @@
+// Copyright 2021 The Xorm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package caches
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// ErrRedisNil represents a nil reply of redis
+var ErrRedisNil = errors.New("xorm/cache: redis nil reply")
+
+// ErrRedisClosed is returned when the redis store has been closed
+var ErrRedisClosed = errors.New("xorm/cache: redis store is closed")
+
+// RedisError represents an error reply of redis
+type RedisError string
+
+func (e RedisError) Error() string {
+	return "xorm/cache: redis " + string(e)
+}
+
+// RedisOptions represents the options to connect to a server speaking the
+// redis protocol
+type RedisOptions struct {
+	Addr        string
+	Password    string
+	DB          int
+	KeyPrefix   string        // prefix of all the keys, default is xorm:
+	Expiration  time.Duration // expiration of the keys, zero means no expiration
+	DialTimeout time.Duration // default is 5 seconds
+	// the timeouts of reading a reply and writing a command, default are 3
+	// seconds, the subscriptions wait for the messages without a timeout
+	ReadTimeout     time.Duration
+	WriteTimeout    time.Duration
+	PoolSize        int           // max idle connections, default is 10
+	MaxRetryBackoff time.Duration // max interval to restore a subscription, default is 30 seconds
+}
+
+// the first interval to restore a subscription, it's doubled on every failure
+const minRetryBackoff = 100 * time.Millisecond
+
+// redisConn is a connection speaking the redis protocol
+type redisConn struct {
+	conn         net.Conn
+	r            *bufio.Reader
+	w            *bufio.Writer
+	readTimeout  time.Duration
+	writeTimeout time.Duration
+}
+
+func dialRedis(opts *RedisOptions) (*redisConn, error) {
+	conn, err := net.DialTimeout("tcp", opts.Addr, opts.DialTimeout)
+	if err != nil {
+		return nil, err
+	}
+	c := &redisConn{
+		conn:         conn,
+		r:            bufio.NewReader(conn),
+		w:            bufio.NewWriter(conn),
+		readTimeout:  opts.ReadTimeout,
+		writeTimeout: opts.WriteTimeout,
+	}
+	if opts.Password != "" {
+		if _, err := c.do("AUTH", opts.Password); err != nil {
+			c.conn.Close()
+			return nil, err
+		}
+	}
+	if opts.DB != 0 {
+		if _, err := c.do("SELECT", strconv.Itoa(opts.DB)); err != nil {
+			c.conn.Close()
+			return nil, err
+		}
+	}
+	return c, nil
+}
+
+func (c *redisConn) send(args ...string) error {
+	if c.writeTimeout > 0 {
+		if err := c.conn.SetWriteDeadline(time.Now().Add(c.writeTimeout)); err != nil {
+			return err
+		}
+	}
+	fmt.Fprintf(c.w, "*%d\r\n", len(args))
+	for _, arg := range args {
+		fmt.Fprintf(c.w, "$%d\r\n%s\r\n", len(arg), arg)
+	}
+	return c.w.Flush()
+}
+
+func (c *redisConn) readLine() (string, error) {
+	line, err := c.r.ReadString('\n')
+	if err != nil {
+		return "", err
+	}
+	if len(line) < 3 || line[len(line)-2] != '\r' {
+		return "", fmt.Errorf("xorm/cache: invalid redis reply %q", line)
+	}
+	return line[:len(line)-2], nil
+}
+
+// receive reads a reply, the bulk strings are returned as []byte, the arrays
+// as []interface{} and the integers as int64
+func (c *redisConn) receive() (interface{}, error) {
+	line, err := c.readLine()
+	if err != nil {
+		return nil, err
+	}
+	switch line[0] {
+	case '+':
+		return line[1:], nil
+	case '-':
+		return nil, RedisError(line[1:])
+	case ':':
+		return strconv.ParseInt(line[1:], 10, 64)
+	case '$':
+		n, err := strconv.Atoi(line[1:])
+		if err != nil {
+			return nil, err
+		}
+		if n < 0 {
+			return nil, ErrRedisNil
+		}
+		buf := make([]byte, n+2)
+		if _, err := io.ReadFull(c.r, buf); err != nil {
+			return nil, err
+		}
+		return buf[:n], nil
+	case '*':
+		n, err := strconv.Atoi(line[1:])
+		if err != nil {
+			return nil, err
+		}
+		if n < 0 {
+			return nil, ErrRedisNil
+		}
+		var res = make([]interface{}, n)
+		for i := 0; i < n; i++ {
+			res[i], err = c.receive()
+			if err != nil && err != ErrRedisNil {
+				return nil, err
+			}
+		}
+		return res, nil
+	}
+	return nil, fmt.Errorf("xorm/cache: invalid redis reply %q", line)
+}
+
+func (c *redisConn) do(args ...string) (interface{}, error) {
+	if err := c.send(args...); err != nil {
+		return nil, err
+	}
+	if c.readTimeout > 0 {
+		if err := c.conn.SetReadDeadline(time.Now().Add(c.readTimeout)); err != nil {
+			return nil, err
+		}
+	}
+	return c.receive()
+}
+
+// RedisStore implements CacheStore on a server speaking the redis protocol,
+// so that the cached data could be shared by the processes
+type RedisStore struct {
+	opts RedisOptions
+	pool chan *redisConn
+
+	mutex  sync.Mutex
+	closed bool
+	subs   map[*redisSubscription]bool
+}
+
+var _ CacheStore = &RedisStore{}
+
+// NewRedisStore creates a redis store, the connection is checked by a PING
+func NewRedisStore(opts RedisOptions) (*RedisStore, error) {
+	if opts.KeyPrefix == "" {
+		opts.KeyPrefix = "xorm:"
+	}
+	if opts.DialTimeout <= 0 {
+		opts.DialTimeout = 5 * time.Second
+	}
+	if opts.ReadTimeout <= 0 {
+		opts.ReadTimeout = 3 * time.Second
+	}
+	if opts.WriteTimeout <= 0 {
+		opts.WriteTimeout = 3 * time.Second
+	}
+	if opts.PoolSize <= 0 {
+		opts.PoolSize = 10
+	}
+	if opts.MaxRetryBackoff <= 0 {
+		opts.MaxRetryBackoff = 30 * time.Second
+	}
+	s := &RedisStore{
+		opts: opts,
+		pool: make(chan *redisConn, opts.PoolSize),
+		subs: make(map[*redisSubscription]bool),
+	}
+	if _, err := s.do("PING"); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+func (s *RedisStore) do(args ...string) (interface{}, error) {
+	s.mutex.Lock()
+	closed := s.closed
+	s.mutex.Unlock()
+	if closed {
+		return nil, ErrRedisClosed
+	}
+
+	var c *redisConn
+	select {
+	case c = <-s.pool:
+	default:
+		var err error
+		if c, err = dialRedis(&s.opts); err != nil {
+			return nil, err
+		}
+	}
+
+	res, err := c.do(args...)
+	if _, ok := err.(RedisError); err != nil && !ok && err != ErrRedisNil {
+		// the connection is broken
+		c.conn.Close()
+		return nil, err
+	}
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if s.closed {
+		c.conn.Close()
+		return res, err
+	}
+	select {
+	case s.pool <- c:
+	default:
+		c.conn.Close()
+	}
+	return res, err
+}
+
+// Close closes the idle connections and stops the subscriptions
+func (s *RedisStore) Close() {
+	s.mutex.Lock()
+	if s.closed {
+		s.mutex.Unlock()
+		return
+	}
+	s.closed = true
+	subs := s.subs
+	s.subs = nil
+	s.mutex.Unlock()
+
+	for sub := range subs {
+		sub.close()
+	}
+	for {
+		select {
+		case c := <-s.pool:
+			c.conn.Close()
+		default:
+			return
+		}
+	}
+}
+
+// Put implements CacheStore
+func (s *RedisStore) Put(key string, value interface{}) error {
+	val, err := Encode(value)
+	if err != nil {
+		return err
+	}
+	if s.opts.Expiration > 0 {
+		_, err = s.do("SET", s.opts.KeyPrefix+key, string(val),
+			"PX", strconv.FormatInt(int64(s.opts.Expiration/time.Millisecond), 10))
+	} else {
+		_, err = s.do("SET", s.opts.KeyPrefix+key, string(val))
+	}
+	return err
+}
+
+// Get implements CacheStore
+func (s *RedisStore) Get(key string) (interface{}, error) {
+	res, err := s.do("GET", s.opts.KeyPrefix+key)
+	if err == ErrRedisNil {
+		return nil, ErrNotExist
+	} else if err != nil {
+		return nil, err
+	}
+	data, ok := res.([]byte)
+	if !ok {
+		return nil, fmt.Errorf("xorm/cache: unexpected redis reply %v", res)
+	}
+	var v interface{}
+	if err := Decode(data, &v); err != nil {
+		return nil, err
+	}
+	return v, nil
+}
+
+// Del implements CacheStore
+func (s *RedisStore) Del(key string) error {
+	_, err := s.do("DEL", s.opts.KeyPrefix+key)
+	return err
+}
+
+// Publish publishes the message to the channel
+func (s *RedisStore) Publish(channel, message string) error {
+	_, err := s.do("PUBLISH", s.opts.KeyPrefix+channel, message)
+	return err
+}
+
+// redisSubscription is a subscription of a channel on a dedicated connection
+type redisSubscription struct {
+	mutex  sync.Mutex
+	conn   *redisConn
+	closed bool
+	stop   chan struct{}
+}
+
+// setConn replaces the broken connection, it's false if the subscription has
+// been closed
+func (sub *redisSubscription) setConn(c *redisConn) bool {
+	sub.mutex.Lock()
+	defer sub.mutex.Unlock()
+	if sub.closed {
+		c.conn.Close()
+		return false
+	}
+	sub.conn = c
+	return true
+}
+
+func (sub *redisSubscription) close() {
+	sub.mutex.Lock()
+	defer sub.mutex.Unlock()
+	if sub.closed {
+		return
+	}
+	sub.closed = true
+	close(sub.stop)
+	sub.conn.conn.Close()
+}
+
+// Subscribe subscribes the channel with a dedicated connection, handler is
+// called with the messages in order. The subscription is restored with an
+// exponential backoff if the connection is broken, until the returned
+// function or Close is called.
+func (s *RedisStore) Subscribe(channel string, handler func(message string)) (func(), error) {
+	s.mutex.Lock()
+	closed := s.closed
+	s.mutex.Unlock()
+	if closed {
+		return nil, ErrRedisClosed
+	}
+
+	c, err := s.subscribe(channel)
+	if err != nil {
+		return nil, err
+	}
+
+	sub := &redisSubscription{
+		conn: c,
+		stop: make(chan struct{}),
+	}
+	s.mutex.Lock()
+	if s.closed {
+		s.mutex.Unlock()
+		c.conn.Close()
+		return nil, ErrRedisClosed
+	}
+	s.subs[sub] = true
+	s.mutex.Unlock()
+
+	go func() {
+		for {
+			s.receiveMessages(c, handler)
+
+			var backoff = minRetryBackoff
+			for {
+				timer := time.NewTimer(backoff)
+				select {
+				case <-sub.stop:
+					timer.Stop()
+					return
+				case <-timer.C:
+				}
+
+				if c, err = s.subscribe(channel); err == nil {
+					break
+				}
+				if backoff *= 2; backoff > s.opts.MaxRetryBackoff {
+					backoff = s.opts.MaxRetryBackoff
+				}
+			}
+			if !sub.setConn(c) {
+				return
+			}
+		}
+	}()
+
+	return func() {
+		s.mutex.Lock()
+		delete(s.subs, sub)
+		s.mutex.Unlock()
+		sub.close()
+	}, nil
+}
+
+func (s *RedisStore) subscribe(channel string) (*redisConn, error) {
+	c, err := dialRedis(&s.opts)
+	if err != nil {
+		return nil, err
+	}
+	// the reply is [subscribe, channel, count]
+	if _, err := c.do("SUBSCRIBE", s.opts.KeyPrefix+channel); err != nil {
+		c.conn.Close()
+		return nil, err
+	}
+	// the messages are waited without a timeout
+	if err := c.conn.SetReadDeadline(time.Time{}); err != nil {
+		c.conn.Close()
+		return nil, err
+	}
+	return c, nil
+}
+
+// receiveMessages receives the messages until the connection is broken
+func (s *RedisStore) receiveMessages(c *redisConn, handler func(message string)) {
+	defer c.conn.Close()
+	for {
+		res, err := c.receive()
+		if err != nil {
+			if _, ok := err.(RedisError); ok {
+				continue
+			}
+			return
+		}
+		// the message is [message, channel, payload]
+		if reply, ok := res.([]interface{}); ok && len(reply) == 3 {
+			kind, _ := reply[0].([]byte)
+			payload, _ := reply[2].([]byte)
+			if string(kind) == "message" {
+				handler(string(payload))
+			}
+		}
+	}
+}
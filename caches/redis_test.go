@@ -0,0 +1,299 @@
+// Copyright 2021 The Xorm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package caches
+
+import (
+	"bufio"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeRedis is an in-process server speaking the subset of the redis
+// protocol used by RedisStore
+type fakeRedis struct {
+	listener    net.Listener
+	mutex       sync.Mutex
+	data        map[string]string
+	subscribers map[string][]*redisConn
+	accepted    int
+}
+
+func newFakeRedis(t *testing.T) *fakeRedis {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.NoError(t, err)
+	s := &fakeRedis{
+		listener:    listener,
+		data:        make(map[string]string),
+		subscribers: make(map[string][]*redisConn),
+	}
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			s.mutex.Lock()
+			s.accepted++
+			s.mutex.Unlock()
+			go s.serve(&redisConn{conn: conn, r: bufio.NewReader(conn), w: bufio.NewWriter(conn)})
+		}
+	}()
+	return s
+}
+
+func (s *fakeRedis) Addr() string {
+	return s.listener.Addr().String()
+}
+
+func (s *fakeRedis) Close() {
+	s.listener.Close()
+}
+
+func (s *fakeRedis) Accepted() int {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return s.accepted
+}
+
+// dropSubscribers breaks the connections of the subscribers
+func (s *fakeRedis) dropSubscribers() {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	for _, subscribers := range s.subscribers {
+		for _, c := range subscribers {
+			c.conn.Close()
+		}
+	}
+	s.subscribers = make(map[string][]*redisConn)
+}
+
+func (s *fakeRedis) write(c *redisConn, reply string) {
+	s.mutex.Lock()
+	c.w.WriteString(reply)
+	c.w.Flush()
+	s.mutex.Unlock()
+}
+
+func bulk(s string) string {
+	return "$" + strconv.Itoa(len(s)) + "\r\n" + s + "\r\n"
+}
+
+func (s *fakeRedis) serve(c *redisConn) {
+	defer c.conn.Close()
+	for {
+		res, err := c.receive()
+		if err != nil {
+			return
+		}
+		var args []string
+		for _, arg := range res.([]interface{}) {
+			args = append(args, string(arg.([]byte)))
+		}
+
+		switch strings.ToUpper(args[0]) {
+		case "PING":
+			s.write(c, "+PONG\r\n")
+		case "SET":
+			s.mutex.Lock()
+			s.data[args[1]] = args[2]
+			s.mutex.Unlock()
+			s.write(c, "+OK\r\n")
+		case "GET":
+			s.mutex.Lock()
+			v, ok := s.data[args[1]]
+			s.mutex.Unlock()
+			if !ok {
+				s.write(c, "$-1\r\n")
+			} else {
+				s.write(c, bulk(v))
+			}
+		case "DEL":
+			s.mutex.Lock()
+			delete(s.data, args[1])
+			s.mutex.Unlock()
+			s.write(c, ":1\r\n")
+		case "PUBLISH":
+			s.mutex.Lock()
+			subscribers := s.subscribers[args[1]]
+			s.mutex.Unlock()
+			for _, sub := range subscribers {
+				s.write(sub, "*3\r\n"+bulk("message")+bulk(args[1])+bulk(args[2]))
+			}
+			s.write(c, ":1\r\n")
+		case "SUBSCRIBE":
+			s.mutex.Lock()
+			s.subscribers[args[1]] = append(s.subscribers[args[1]], c)
+			s.mutex.Unlock()
+			s.write(c, "*3\r\n"+bulk("subscribe")+bulk(args[1])+":1\r\n")
+		default:
+			s.write(c, "-ERR unknown command\r\n")
+		}
+	}
+}
+
+func TestRedisStore(t *testing.T) {
+	server := newFakeRedis(t)
+	defer server.Close()
+
+	store, err := NewRedisStore(RedisOptions{Addr: server.Addr()})
+	assert.NoError(t, err)
+
+	var kvs = map[string]interface{}{
+		"a": "b",
+		"c": int64(1),
+	}
+	for k, v := range kvs {
+		assert.NoError(t, store.Put(k, v))
+	}
+
+	for k, v := range kvs {
+		val, err := store.Get(k)
+		assert.NoError(t, err)
+		assert.EqualValues(t, v, val)
+	}
+
+	for k := range kvs {
+		assert.NoError(t, store.Del(k))
+	}
+
+	for k := range kvs {
+		_, err := store.Get(k)
+		assert.EqualValues(t, ErrNotExist, err)
+	}
+
+	_, err = NewRedisStore(RedisOptions{Addr: server.Addr(), Password: "secret"})
+	assert.Error(t, err)
+}
+
+func TestDistributedCacher(t *testing.T) {
+	server := newFakeRedis(t)
+	defer server.Close()
+
+	newCacher := func() *DistributedCacher {
+		store, err := NewRedisStore(RedisOptions{Addr: server.Addr()})
+		assert.NoError(t, err)
+		cacher, err := NewDistributedCacher(NewLRUCacher(store, 10000), store, "")
+		assert.NoError(t, err)
+		return cacher
+	}
+	node1, node2 := newCacher(), newCacher()
+	defer node1.Close()
+	defer node2.Close()
+
+	tableName := "cache_object1"
+	sql := "select * from cache_object1"
+	node1.PutIds(tableName, sql, "1")
+	node1.PutBean(tableName, "1", "bean1")
+
+	// the entries are shared by the store
+	assert.EqualValues(t, "1", node2.GetIds(tableName, sql))
+	assert.EqualValues(t, "bean1", node2.GetBean(tableName, "1"))
+
+	// the invalidations of a node are applied by the others
+	node2.PutBean(tableName, "2", "bean2")
+	node1.DelBean(tableName, "2")
+	assert.Nil(t, node2.GetBean(tableName, "2"))
+
+	local := NewLRUCacher(NewMemoryStore(), 10000)
+	store, err := NewRedisStore(RedisOptions{Addr: server.Addr()})
+	assert.NoError(t, err)
+	node3, err := NewDistributedCacher(local, store, "")
+	assert.NoError(t, err)
+	defer node3.Close()
+
+	local.PutIds(tableName, sql, "1")
+	local.PutBean(tableName, "1", "bean1")
+	node1.ClearIds(tableName)
+	node1.ClearBeans(tableName)
+	assert.Eventually(t, func() bool {
+		return local.GetIds(tableName, sql) == nil && local.GetBean(tableName, "1") == nil
+	}, time.Second, 10*time.Millisecond)
+	assert.Nil(t, node2.GetIds(tableName, sql))
+	assert.Nil(t, node2.GetBean(tableName, "1"))
+}
+
+func TestRedisTimeout(t *testing.T) {
+	// the server never replies
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.NoError(t, err)
+	defer listener.Close()
+	go func() {
+		var conns []net.Conn
+		defer func() {
+			for _, conn := range conns {
+				conn.Close()
+			}
+		}()
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			conns = append(conns, conn)
+		}
+	}()
+
+	start := time.Now()
+	_, err = NewRedisStore(RedisOptions{Addr: listener.Addr().String(), ReadTimeout: 50 * time.Millisecond})
+	if assert.Error(t, err) {
+		netErr, ok := err.(net.Error)
+		assert.True(t, ok && netErr.Timeout())
+	}
+	assert.True(t, time.Since(start) < time.Second)
+}
+
+func TestRedisSubscribe(t *testing.T) {
+	server := newFakeRedis(t)
+	defer server.Close()
+
+	store, err := NewRedisStore(RedisOptions{Addr: server.Addr()})
+	assert.NoError(t, err)
+
+	var (
+		mutex    sync.Mutex
+		messages []string
+	)
+	_, err = store.Subscribe("channel", func(message string) {
+		mutex.Lock()
+		messages = append(messages, message)
+		mutex.Unlock()
+	})
+	assert.NoError(t, err)
+	received := func(n int) func() bool {
+		return func() bool {
+			mutex.Lock()
+			defer mutex.Unlock()
+			return len(messages) >= n
+		}
+	}
+
+	assert.NoError(t, store.Publish("channel", "a"))
+	assert.Eventually(t, received(1), time.Second, 10*time.Millisecond)
+
+	// the subscription is restored
+	server.dropSubscribers()
+	assert.Eventually(t, func() bool {
+		assert.NoError(t, store.Publish("channel", "b"))
+		return received(2)()
+	}, 2*time.Second, 50*time.Millisecond)
+
+	// the subscriptions are stopped by Close
+	server.dropSubscribers()
+	store.Close()
+	accepted := server.Accepted()
+	time.Sleep(3 * minRetryBackoff)
+	assert.EqualValues(t, accepted, server.Accepted())
+
+	_, err = store.Get("a")
+	assert.EqualValues(t, ErrRedisClosed, err)
+	_, err = store.Subscribe("channel", func(string) {})
+	assert.EqualValues(t, ErrRedisClosed, err)
+}
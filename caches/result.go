@@ -0,0 +1,204 @@
+// Copyright 2021 The Xorm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package caches
+
+import (
+	"container/list"
+	"sync"
+	"time"
+)
+
+// CacheMaxResults is the default max number of the results of ResultCache
+const CacheMaxResults = 10000
+
+// ResultCache stores the results of the queries in memory, the entries expire
+// after their ttl or are invalidated by one of the tables they reference. The
+// least recently used entries are evicted when there are more than
+// MaxElementSize entries or their estimated bytes exceed MaxMemory.
+type ResultCache struct {
+	mutex   sync.Mutex
+	entries map[string]*resultEntry
+	tables  map[string]map[string]bool // table name -> keys
+	gcTime  time.Time
+	lruList *list.List // the keys, the least recently used first
+	memory  int64
+
+	maxElementSize int
+	maxMemory      int64
+
+	// the generation is increased by every invalidation so that the results
+	// read before an invalidation are not stored after it
+	generation  uint64
+	invalidated map[string]uint64
+	cleared     uint64
+}
+
+type resultEntry struct {
+	value   interface{}
+	tables  []string
+	expires time.Time
+	el      *list.Element
+	size    int64
+}
+
+// NewResultCache creates a result cache holding CacheMaxResults entries
+// within CacheMaxMemory megabytes
+func NewResultCache() *ResultCache {
+	return &ResultCache{
+		entries: make(map[string]*resultEntry),
+		tables:  make(map[string]map[string]bool),
+		gcTime:  time.Now(),
+		lruList: list.New(),
+
+		maxElementSize: CacheMaxResults,
+		maxMemory:      CacheMaxMemory << 20,
+
+		invalidated: make(map[string]uint64),
+	}
+}
+
+// SetLimits sets the max number of the entries and the max estimated bytes
+// of them, zero means no limit
+func (c *ResultCache) SetLimits(maxElementSize int, maxMemory int64) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.maxElementSize = maxElementSize
+	c.maxMemory = maxMemory
+	c.evict()
+}
+
+// Get returns the result of the key if it's not expired
+func (c *ResultCache) Get(key string) (interface{}, bool) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	entry, ok := c.entries[key]
+	if !ok {
+		return nil, false
+	}
+	if time.Now().After(entry.expires) {
+		c.del(key)
+		return nil, false
+	}
+	c.lruList.MoveToBack(entry.el)
+	return entry.value, true
+}
+
+// Generation returns the current generation which should be got before the
+// query and given to Put
+func (c *ResultCache) Generation() uint64 {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	return c.generation
+}
+
+// Put stores the result of the key for ttl, the tables are the ones
+// referenced by the query. It's ignored if one of the tables has been
+// invalidated since the generation.
+func (c *ResultCache) Put(key string, tables []string, value interface{}, ttl time.Duration, generation uint64) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	if c.cleared > generation {
+		return
+	}
+	for _, table := range tables {
+		if c.invalidated[table] > generation {
+			return
+		}
+	}
+	c.gc()
+	c.del(key)
+	entry := &resultEntry{
+		value:   value,
+		tables:  tables,
+		expires: time.Now().Add(ttl),
+		el:      c.lruList.PushBack(key),
+		size:    sizeOf(key) + sizeOf(value),
+	}
+	c.memory += entry.size
+	c.entries[key] = entry
+	for _, table := range tables {
+		keys, ok := c.tables[table]
+		if !ok {
+			keys = make(map[string]bool)
+			c.tables[table] = keys
+		}
+		keys[key] = true
+	}
+	c.evict()
+}
+
+// evict removes the least recently used entries until they're within the limits
+func (c *ResultCache) evict() {
+	for (c.maxElementSize > 0 && len(c.entries) > c.maxElementSize) ||
+		(c.maxMemory > 0 && c.memory > c.maxMemory) {
+		el := c.lruList.Front()
+		if el == nil {
+			return
+		}
+		c.del(el.Value.(string))
+	}
+}
+
+// gc removes the expired results once every CacheGcInterval
+func (c *ResultCache) gc() {
+	now := time.Now()
+	if now.Sub(c.gcTime) < CacheGcInterval {
+		return
+	}
+	c.gcTime = now
+	for key, entry := range c.entries {
+		if now.After(entry.expires) {
+			c.del(key)
+		}
+	}
+}
+
+func (c *ResultCache) del(key string) {
+	entry, ok := c.entries[key]
+	if !ok {
+		return
+	}
+	delete(c.entries, key)
+	c.lruList.Remove(entry.el)
+	c.memory -= entry.size
+	for _, table := range entry.tables {
+		delete(c.tables[table], key)
+		if len(c.tables[table]) == 0 {
+			delete(c.tables, table)
+		}
+	}
+}
+
+// Invalidate removes the results referencing the table
+func (c *ResultCache) Invalidate(table string) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.generation++
+	c.invalidated[table] = c.generation
+	for key := range c.tables[table] {
+		c.del(key)
+	}
+}
+
+// Clear removes all the results
+func (c *ResultCache) Clear() {
+	c.mutex.Lock()
+	c.entries = make(map[string]*resultEntry)
+	c.tables = make(map[string]map[string]bool)
+	c.lruList.Init()
+	c.memory = 0
+	c.generation++
+	c.cleared = c.generation
+	c.invalidated = make(map[string]uint64)
+	c.mutex.Unlock()
+}
+
+// Len returns the number of the results, the expired ones are included until
+// they're accessed
+func (c *ResultCache) Len() int {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	return len(c.entries)
+}
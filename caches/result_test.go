@@ -0,0 +1,85 @@
+// Copyright 2021 The Xorm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package caches
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestResultCache(t *testing.T) {
+	cache := NewResultCache()
+
+	cache.Put("key1", []string{"table1"}, 1, time.Hour, cache.Generation())
+	cache.Put("key2", []string{"table1", "table2"}, 2, time.Hour, cache.Generation())
+	cache.Put("key3", []string{"table3"}, 3, time.Millisecond, cache.Generation())
+	assert.EqualValues(t, 3, cache.Len())
+
+	v, ok := cache.Get("key1")
+	assert.True(t, ok)
+	assert.EqualValues(t, 1, v)
+
+	time.Sleep(2 * time.Millisecond)
+	_, ok = cache.Get("key3")
+	assert.False(t, ok)
+
+	cache.Invalidate("table2")
+	_, ok = cache.Get("key2")
+	assert.False(t, ok)
+	_, ok = cache.Get("key1")
+	assert.True(t, ok)
+
+	// the results read before an invalidation are not stored
+	generation := cache.Generation()
+	cache.Invalidate("table1")
+	cache.Put("key1", []string{"table1"}, 1, time.Hour, generation)
+	_, ok = cache.Get("key1")
+	assert.False(t, ok)
+
+	cache.Put("key1", []string{"table1"}, 1, time.Hour, cache.Generation())
+	_, ok = cache.Get("key1")
+	assert.True(t, ok)
+
+	generation = cache.Generation()
+	cache.Clear()
+	assert.EqualValues(t, 0, cache.Len())
+	cache.Put("key4", []string{"table4"}, 4, time.Hour, generation)
+	_, ok = cache.Get("key4")
+	assert.False(t, ok)
+}
+
+func TestResultCacheLimits(t *testing.T) {
+	cache := NewResultCache()
+	cache.SetLimits(2, 0)
+
+	cache.Put("key1", []string{"table1"}, 1, time.Hour, cache.Generation())
+	cache.Put("key2", []string{"table1"}, 2, time.Hour, cache.Generation())
+	_, ok := cache.Get("key1")
+	assert.True(t, ok)
+
+	// the least recently used one is evicted
+	cache.Put("key3", []string{"table1"}, 3, time.Hour, cache.Generation())
+	assert.EqualValues(t, 2, cache.Len())
+	_, ok = cache.Get("key2")
+	assert.False(t, ok)
+	_, ok = cache.Get("key1")
+	assert.True(t, ok)
+
+	// the memory is bounded
+	cache = NewResultCache()
+	cache.SetLimits(0, 2*sizeOf("key4")+sizeOf(make([]byte, 100)))
+	cache.Put("key4", []string{"table1"}, make([]byte, 100), time.Hour, cache.Generation())
+	assert.EqualValues(t, 1, cache.Len())
+	cache.Put("key5", []string{"table1"}, make([]byte, 100), time.Hour, cache.Generation())
+	assert.EqualValues(t, 1, cache.Len())
+	_, ok = cache.Get("key5")
+	assert.True(t, ok)
+
+	cache.Invalidate("table1")
+	assert.EqualValues(t, 0, cache.Len())
+	assert.EqualValues(t, 0, cache.memory)
+}
@@ -0,0 +1,71 @@
+// Copyright 2021 The Xorm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package caches
+
+import "reflect"
+
+// sizeOf estimates the bytes of the value in memory, the referenced values
+// are included and counted once
+func sizeOf(v interface{}) int64 {
+	if v == nil {
+		return 0
+	}
+	value := reflect.ValueOf(v)
+	return int64(value.Type().Size()) + heapSize(value, make(map[uintptr]bool))
+}
+
+// heapSize returns the bytes referenced by the value besides itself
+func heapSize(v reflect.Value, seen map[uintptr]bool) int64 {
+	switch v.Kind() {
+	case reflect.Ptr:
+		if v.IsNil() || seen[v.Pointer()] {
+			return 0
+		}
+		seen[v.Pointer()] = true
+		return int64(v.Type().Elem().Size()) + heapSize(v.Elem(), seen)
+	case reflect.Interface:
+		if v.IsNil() {
+			return 0
+		}
+		return int64(v.Elem().Type().Size()) + heapSize(v.Elem(), seen)
+	case reflect.String:
+		return int64(v.Len())
+	case reflect.Slice:
+		if v.IsNil() || seen[v.Pointer()] {
+			return 0
+		}
+		seen[v.Pointer()] = true
+		size := int64(v.Cap()) * int64(v.Type().Elem().Size())
+		for i := 0; i < v.Len(); i++ {
+			size += heapSize(v.Index(i), seen)
+		}
+		return size
+	case reflect.Array:
+		var size int64
+		for i := 0; i < v.Len(); i++ {
+			size += heapSize(v.Index(i), seen)
+		}
+		return size
+	case reflect.Map:
+		if v.IsNil() || seen[v.Pointer()] {
+			return 0
+		}
+		seen[v.Pointer()] = true
+		var size int64
+		iter := v.MapRange()
+		for iter.Next() {
+			size += int64(iter.Key().Type().Size()) + heapSize(iter.Key(), seen)
+			size += int64(iter.Value().Type().Size()) + heapSize(iter.Value(), seen)
+		}
+		return size
+	case reflect.Struct:
+		var size int64
+		for i := 0; i < v.NumField(); i++ {
+			size += heapSize(v.Field(i), seen)
+		}
+		return size
+	}
+	return 0
+}
@@ -0,0 +1,106 @@
+// Copyright 2021 The Xorm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Command xorm-reverse generates Go structs with xorm tags from an existing
+// database.
+//
+//	xorm-reverse -driver mysql -dsn "root:@/test?charset=utf8" -o models/models.go
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"strings"
+
+	_ "github.com/denisenkom/go-mssqldb"
+	_ "github.com/go-sql-driver/mysql"
+	_ "github.com/lib/pq"
+	_ "github.com/mattn/go-sqlite3"
+	"github.com/xorm-io/xorm"
+	"github.com/xorm-io/xorm/names"
+	"github.com/xorm-io/xorm/reverse"
+)
+
+var (
+	driver     = flag.String("driver", "mysql", "the driver name of the database, mysql, postgres, sqlite3 or mssql")
+	dsn        = flag.String("dsn", "", "the data source name of the database")
+	pkg        = flag.String("package", "models", "the package name of the generated file")
+	output     = flag.String("o", "", "the generated file, stdout if it's empty")
+	tmplFile   = flag.String("template", "", "the text/template file to render the structs, the builtin template if it's empty")
+	tables     = flag.String("tables", "", "the comma separated table names to generate, all tables if it's empty")
+	mapperName = flag.String("mapper", "snake", "the name mapper, snake, same or gonic")
+	prefix     = flag.String("prefix", "", "the table name prefix which will be trimmed from the struct names")
+)
+
+func newMapper(name string) (names.Mapper, error) {
+	switch name {
+	case "snake":
+		return names.SnakeMapper{}, nil
+	case "same":
+		return names.SameMapper{}, nil
+	case "gonic":
+		return names.LintGonicMapper, nil
+	}
+	return nil, fmt.Errorf("unknown mapper %s", name)
+}
+
+func run() error {
+	if *dsn == "" {
+		return fmt.Errorf("-dsn is required")
+	}
+
+	mapper, err := newMapper(*mapperName)
+	if err != nil {
+		return err
+	}
+
+	var opts = reverse.Options{
+		Package:      *pkg,
+		TableMapper:  mapper,
+		ColumnMapper: mapper,
+	}
+	if *prefix != "" {
+		opts.TableMapper = names.NewPrefixMapper(mapper, *prefix)
+	}
+	if *tables != "" {
+		opts.Tables = strings.Split(*tables, ",")
+	}
+	if *tmplFile != "" {
+		content, err := ioutil.ReadFile(*tmplFile)
+		if err != nil {
+			return err
+		}
+		opts.Template = string(content)
+		opts.NoFormat = !strings.HasSuffix(*output, ".go")
+	}
+
+	engine, err := xorm.NewEngine(*driver, *dsn)
+	if err != nil {
+		return err
+	}
+	defer engine.Close()
+
+	var w io.Writer = os.Stdout
+	if *output != "" {
+		f, err := os.Create(*output)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		w = f
+	}
+
+	return reverse.FromEngine(engine, w, &opts)
+}
+
+func main() {
+	flag.Parse()
+	if err := run(); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
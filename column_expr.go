@@ -0,0 +1,136 @@
+// Copyright 2021 The Xorm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package xorm
+
+import (
+	"github.com/xorm-io/builder"
+	"github.com/xorm-io/xorm/schemas"
+)
+
+// ColumnExpr is a column handle obtained from the metadata of a bean, so that
+// the conditions follow the renaming of the struct fields
+type ColumnExpr struct {
+	name   string // the column name mapped by the column mapper
+	quoted string
+	quoter schemas.Quoter
+}
+
+// Col returns the column handle of the bean's field. It returns
+// ErrFieldIsNotExist if the field is not mapped to a column.
+func (engine *Engine) Col(bean interface{}, fieldName string) (ColumnExpr, error) {
+	table, err := engine.TableInfo(bean)
+	if err != nil {
+		return ColumnExpr{}, err
+	}
+	for _, col := range table.Columns() {
+		if col.FieldName == fieldName {
+			quoter := engine.dialect.Quoter()
+			return ColumnExpr{
+				name:   col.Name,
+				quoted: quoter.Quote(col.Name),
+				quoter: quoter,
+			}, nil
+		}
+	}
+	return ColumnExpr{}, ErrFieldIsNotExist{FieldName: fieldName, TableName: table.Name}
+}
+
+// MustCol is like Col but panics if the field is not mapped to a column
+func (engine *Engine) MustCol(bean interface{}, fieldName string) ColumnExpr {
+	col, err := engine.Col(bean, fieldName)
+	if err != nil {
+		panic(err)
+	}
+	return col
+}
+
+// Name returns the unquoted column name
+func (col ColumnExpr) Name() string {
+	return col.name
+}
+
+// String returns the quoted column name
+func (col ColumnExpr) String() string {
+	return col.quoted
+}
+
+// Of returns the column qualified by the table name or alias
+func (col ColumnExpr) Of(tableOrAlias string) ColumnExpr {
+	return ColumnExpr{
+		name:   col.name,
+		quoted: col.quoter.Quote(tableOrAlias) + "." + col.quoted,
+		quoter: col.quoter,
+	}
+}
+
+// Eq returns the condition column = value
+func (col ColumnExpr) Eq(value interface{}) builder.Cond {
+	return builder.Eq{col.quoted: value}
+}
+
+// Neq returns the condition column <> value
+func (col ColumnExpr) Neq(value interface{}) builder.Cond {
+	return builder.Neq{col.quoted: value}
+}
+
+// Gt returns the condition column > value
+func (col ColumnExpr) Gt(value interface{}) builder.Cond {
+	return builder.Gt{col.quoted: value}
+}
+
+// Gte returns the condition column >= value
+func (col ColumnExpr) Gte(value interface{}) builder.Cond {
+	return builder.Gte{col.quoted: value}
+}
+
+// Lt returns the condition column < value
+func (col ColumnExpr) Lt(value interface{}) builder.Cond {
+	return builder.Lt{col.quoted: value}
+}
+
+// Lte returns the condition column <= value
+func (col ColumnExpr) Lte(value interface{}) builder.Cond {
+	return builder.Lte{col.quoted: value}
+}
+
+// In returns the condition column IN (values...)
+func (col ColumnExpr) In(values ...interface{}) builder.Cond {
+	return builder.In(col.quoted, values...)
+}
+
+// NotIn returns the condition column NOT IN (values...)
+func (col ColumnExpr) NotIn(values ...interface{}) builder.Cond {
+	return builder.NotIn(col.quoted, values...)
+}
+
+// Like returns the condition column LIKE '%value%'
+func (col ColumnExpr) Like(value string) builder.Cond {
+	return builder.Like{col.quoted, value}
+}
+
+// Between returns the condition column BETWEEN less AND more
+func (col ColumnExpr) Between(less, more interface{}) builder.Cond {
+	return builder.Between{Col: col.quoted, LessVal: less, MoreVal: more}
+}
+
+// IsNull returns the condition column IS NULL
+func (col ColumnExpr) IsNull() builder.Cond {
+	return builder.IsNull{col.quoted}
+}
+
+// NotNull returns the condition column IS NOT NULL
+func (col ColumnExpr) NotNull() builder.Cond {
+	return builder.NotNull{col.quoted}
+}
+
+// Asc returns the ascending order by clause of the column
+func (col ColumnExpr) Asc() string {
+	return col.quoted + " ASC"
+}
+
+// Desc returns the descending order by clause of the column
+func (col ColumnExpr) Desc() string {
+	return col.quoted + " DESC"
+}
@@ -0,0 +1,70 @@
+// Copyright 2021 The Xorm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package convert
+
+import (
+	"database/sql/driver"
+	"reflect"
+	"sync"
+
+	"github.com/xorm-io/xorm/schemas"
+)
+
+// Converter converts the values of a type from and to database. Unlike
+// Conversion, it's registered outside of the type, so that the types of
+// third-party packages could be stored too.
+type Converter interface {
+	// SQLType returns the default SQL type of the columns of the type
+	SQLType() schemas.SQLType
+	// ToDB converts a value of the type to a driver value
+	ToDB(v interface{}) (driver.Value, error)
+	// FromDB converts src read from database and stores it into dest, which
+	// is a pointer to a value of the type
+	FromDB(src interface{}, dest interface{}) error
+}
+
+// Converters represents a registry of the converters
+type Converters struct {
+	mutex      sync.RWMutex
+	converters map[reflect.Type]Converter
+}
+
+// NewConverters creates a converter registry
+func NewConverters() *Converters {
+	return &Converters{
+		converters: make(map[reflect.Type]Converter),
+	}
+}
+
+// Register registers the converter of the type, a nil converter removes it
+func (c *Converters) Register(t reflect.Type, converter Converter) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	if converter == nil {
+		delete(c.converters, t)
+		return
+	}
+	c.converters[t] = converter
+}
+
+// Get returns the converter of the type, the converter of the element type is
+// returned for a pointer type
+func (c *Converters) Get(t reflect.Type) Converter {
+	if c == nil || t == nil {
+		return nil
+	}
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+	if len(c.converters) == 0 {
+		return nil
+	}
+	if converter, ok := c.converters[t]; ok {
+		return converter
+	}
+	if t.Kind() == reflect.Ptr {
+		return c.converters[t.Elem()]
+	}
+	return nil
+}
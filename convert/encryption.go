@@ -0,0 +1,201 @@
+// Copyright 2021 The Xorm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package convert
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+)
+
+var (
+	// ErrNoKeyProvider is returned when an encrypted column is used without a key provider
+	ErrNoKeyProvider = errors.New("no key provider for the encrypted column")
+	// ErrInvalidCiphertext represents the value of an encrypted column is not a valid envelope
+	ErrInvalidCiphertext = errors.New("invalid ciphertext of the encrypted column")
+	// ErrEncryptedCondition is returned when a non-deterministic encrypted column is used as a condition
+	ErrEncryptedCondition = errors.New("encrypted column could not be used as a condition unless it's deterministic")
+	// ErrEncryptedStringCondition is returned when an encrypted column is used in a string condition
+	ErrEncryptedStringCondition = errors.New("encrypted column could not be used in a condition except the bean, use the bean as the condition instead")
+)
+
+// KeyProvider provides the AES keys of the encrypted columns. A key is
+// identified by its name, which is the parameter of the encrypted tag, and
+// its id, which is stored in the ciphertext so that the keys could be rotated.
+type KeyProvider interface {
+	// CurrentKey returns the id and the key to encrypt the new values with
+	CurrentKey(name string) (id string, key []byte, err error)
+	// Key returns the key of the id to decrypt the values with
+	Key(name, id string) ([]byte, error)
+}
+
+// the envelope is base64(version | len(id) | id | nonce | ciphertext)
+const envelopeVersion = 2
+
+var deterministicNonceLabel = []byte("xorm deterministic nonce")
+
+func newGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// additionalData binds a ciphertext to the key name, the table and the column,
+// so that it couldn't be copied to another column
+func additionalData(name, table, column string) []byte {
+	var data = make([]byte, 0, len(name)+len(table)+len(column)+2)
+	data = append(data, name...)
+	data = append(data, 0)
+	data = append(data, table...)
+	data = append(data, 0)
+	return append(data, column...)
+}
+
+// deterministicNonce derives the nonce from the additional data and the
+// plaintext, so that the same plaintext of a column is always encrypted to
+// the same ciphertext with the same key
+func deterministicNonce(key, aad, plaintext []byte, size int) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write(deterministicNonceLabel)
+	subKey := mac.Sum(nil)
+
+	mac = hmac.New(sha256.New, subKey)
+	mac.Write(aad)
+	mac.Write([]byte{0})
+	mac.Write(plaintext)
+	return mac.Sum(nil)[:size]
+}
+
+// Encrypt encrypts the plaintext of the column of the table with AES-GCM by
+// the current key of the name. A deterministic encryption uses a nonce derived
+// from the plaintext, which allows equality lookups but reveals the equal values.
+func Encrypt(provider KeyProvider, name, table, column string, deterministic bool, plaintext []byte) (string, error) {
+	if provider == nil {
+		return "", ErrNoKeyProvider
+	}
+	id, key, err := provider.CurrentKey(name)
+	if err != nil {
+		return "", err
+	}
+	if len(id) > 255 {
+		return "", fmt.Errorf("key id %s is too long", id)
+	}
+	gcm, err := newGCM(key)
+	if err != nil {
+		return "", err
+	}
+
+	aad := additionalData(name, table, column)
+	var nonce []byte
+	if deterministic {
+		nonce = deterministicNonce(key, aad, plaintext, gcm.NonceSize())
+	} else {
+		nonce = make([]byte, gcm.NonceSize())
+		if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+			return "", err
+		}
+	}
+
+	var envelope = make([]byte, 0, 2+len(id)+len(nonce)+len(plaintext)+gcm.Overhead())
+	envelope = append(envelope, envelopeVersion, byte(len(id)))
+	envelope = append(envelope, id...)
+	envelope = append(envelope, nonce...)
+	envelope = gcm.Seal(envelope, nonce, plaintext, aad)
+	return base64.StdEncoding.EncodeToString(envelope), nil
+}
+
+// Decrypt decrypts the envelope of the column of the table returned by Encrypt
+// with the key of the id stored in it
+func Decrypt(provider KeyProvider, name, table, column string, envelope string) ([]byte, error) {
+	if provider == nil {
+		return nil, ErrNoKeyProvider
+	}
+	data, err := base64.StdEncoding.DecodeString(envelope)
+	if err != nil || len(data) < 2 || data[0] != envelopeVersion {
+		return nil, ErrInvalidCiphertext
+	}
+	idLen := int(data[1])
+	if len(data) < 2+idLen {
+		return nil, ErrInvalidCiphertext
+	}
+	id := string(data[2 : 2+idLen])
+	data = data[2+idLen:]
+
+	key, err := provider.Key(name, id)
+	if err != nil {
+		return nil, err
+	}
+	gcm, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+	if len(data) < gcm.NonceSize()+gcm.Overhead() {
+		return nil, ErrInvalidCiphertext
+	}
+	return gcm.Open(nil, data[:gcm.NonceSize()], data[gcm.NonceSize():], additionalData(name, table, column))
+}
+
+type staticKey struct {
+	id  string
+	key []byte
+}
+
+// StaticKeyProvider is a KeyProvider holding the keys in memory, the key
+// added last of a name is the current one
+type StaticKeyProvider struct {
+	mutex sync.RWMutex
+	keys  map[string][]staticKey
+}
+
+// NewStaticKeyProvider creates a StaticKeyProvider
+func NewStaticKeyProvider() *StaticKeyProvider {
+	return &StaticKeyProvider{
+		keys: make(map[string][]staticKey),
+	}
+}
+
+// AddKey adds a key of the name and makes it the current one, the key must
+// be 16, 24 or 32 bytes to select AES-128, AES-192 or AES-256
+func (p *StaticKeyProvider) AddKey(name, id string, key []byte) error {
+	if _, err := aes.NewCipher(key); err != nil {
+		return err
+	}
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	p.keys[name] = append(p.keys[name], staticKey{id: id, key: key})
+	return nil
+}
+
+// CurrentKey implements KeyProvider
+func (p *StaticKeyProvider) CurrentKey(name string) (string, []byte, error) {
+	p.mutex.RLock()
+	defer p.mutex.RUnlock()
+	keys := p.keys[name]
+	if len(keys) == 0 {
+		return "", nil, fmt.Errorf("no key named %q", name)
+	}
+	return keys[len(keys)-1].id, keys[len(keys)-1].key, nil
+}
+
+// Key implements KeyProvider
+func (p *StaticKeyProvider) Key(name, id string) ([]byte, error) {
+	p.mutex.RLock()
+	defer p.mutex.RUnlock()
+	for _, k := range p.keys[name] {
+		if k.id == id {
+			return k.key, nil
+		}
+	}
+	return nil, fmt.Errorf("no key named %q with id %q", name, id)
+}
@@ -0,0 +1,261 @@
+// Copyright 2021 The Xorm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package dialects
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/xorm-io/xorm/schemas"
+)
+
+// ErrInvalidArray represents an invalid array literal error
+var ErrInvalidArray = errors.New("invalid array literal")
+
+var arrayTimeLayouts = []string{
+	"2006-01-02 15:04:05.999999999Z07:00",
+	"2006-01-02 15:04:05.999999999Z07",
+	"2006-01-02T15:04:05.999999999Z07:00",
+	"2006-01-02 15:04:05.999999999",
+	"2006-01-02",
+}
+
+// ArrayElemType returns the element SQL type of a Go slice or array type
+func ArrayElemType(t reflect.Type) (schemas.SQLType, bool) {
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Slice && t.Kind() != reflect.Array {
+		return schemas.SQLType{}, false
+	}
+	elem := t.Elem()
+	if elem.Kind() == reflect.String {
+		return schemas.SQLType{Name: schemas.Text}, true
+	}
+	return schemas.Type2SQLType(elem), true
+}
+
+func writeArrayElem(buf *strings.Builder, dialect Dialect, tz *time.Location, elemType string, v reflect.Value) error {
+	if v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			buf.WriteString("NULL")
+			return nil
+		}
+		v = v.Elem()
+	}
+
+	switch v.Kind() {
+	case reflect.String:
+		buf.WriteByte('"')
+		for _, c := range v.String() {
+			if c == '"' || c == '\\' {
+				buf.WriteByte('\\')
+			}
+			buf.WriteRune(c)
+		}
+		buf.WriteByte('"')
+	case reflect.Bool:
+		if v.Bool() {
+			buf.WriteString("t")
+		} else {
+			buf.WriteString("f")
+		}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		buf.WriteString(strconv.FormatInt(v.Int(), 10))
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		buf.WriteString(strconv.FormatUint(v.Uint(), 10))
+	case reflect.Float32, reflect.Float64:
+		buf.WriteString(strconv.FormatFloat(v.Float(), 'g', -1, 64))
+	case reflect.Struct:
+		if !v.Type().ConvertibleTo(schemas.TimeType) {
+			return fmt.Errorf("unsupported array element type %v", v.Type())
+		}
+		t := v.Convert(schemas.TimeType).Interface().(time.Time)
+		if elemType == "" {
+			elemType = schemas.DateTime
+		}
+		buf.WriteByte('"')
+		buf.WriteString(fmt.Sprintf("%v", FormatTime(dialect, elemType, t.In(tz))))
+		buf.WriteByte('"')
+	default:
+		return fmt.Errorf("unsupported array element type %v", v.Type())
+	}
+	return nil
+}
+
+// FormatArray formats a Go slice or array as a postgres array literal, i.e.
+// {"a","b"}. It returns nil if the slice is nil.
+func FormatArray(dialect Dialect, tz *time.Location, col *schemas.Column, v reflect.Value) (interface{}, error) {
+	if v.Kind() == reflect.Slice && v.IsNil() {
+		return nil, nil
+	}
+	if tz == nil {
+		tz = time.Local
+	}
+	if col != nil && col.TimeZone != nil {
+		tz = col.TimeZone
+	}
+	var elemType string
+	if col != nil {
+		elemType = col.ArrayElemType.Name
+	}
+
+	var buf strings.Builder
+	buf.WriteByte('{')
+	for i := 0; i < v.Len(); i++ {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		if err := writeArrayElem(&buf, dialect, tz, elemType, v.Index(i)); err != nil {
+			return nil, err
+		}
+	}
+	buf.WriteByte('}')
+	return buf.String(), nil
+}
+
+// parseArray parses a one-dimensional postgres array literal, the NULL
+// elements are returned as nil
+func parseArray(s string) ([]*string, error) {
+	s = strings.TrimSpace(s)
+	if len(s) < 2 || s[0] != '{' || s[len(s)-1] != '}' {
+		return nil, ErrInvalidArray
+	}
+	s = s[1 : len(s)-1]
+
+	var elems []*string
+	for i := 0; i < len(s); {
+		var buf strings.Builder
+		var quoted bool
+		if s[i] == '"' {
+			quoted = true
+			i++
+			for ; i < len(s) && s[i] != '"'; i++ {
+				if s[i] == '\\' && i+1 < len(s) {
+					i++
+				}
+				buf.WriteByte(s[i])
+			}
+			if i >= len(s) {
+				return nil, ErrInvalidArray
+			}
+			i++
+		} else {
+			for ; i < len(s) && s[i] != ','; i++ {
+				if s[i] == '{' || s[i] == '"' {
+					return nil, ErrInvalidArray
+				}
+				buf.WriteByte(s[i])
+			}
+		}
+
+		elem := buf.String()
+		if !quoted && strings.EqualFold(strings.TrimSpace(elem), "NULL") {
+			elems = append(elems, nil)
+		} else {
+			if !quoted {
+				elem = strings.TrimSpace(elem)
+			}
+			elems = append(elems, &elem)
+		}
+
+		if i < len(s) {
+			if s[i] != ',' {
+				return nil, ErrInvalidArray
+			}
+			i++
+		}
+	}
+	return elems, nil
+}
+
+func parseArrayElem(s string, tz *time.Location, t reflect.Type) (reflect.Value, error) {
+	v := reflect.New(t).Elem()
+	switch t.Kind() {
+	case reflect.String:
+		v.SetString(s)
+	case reflect.Bool:
+		v.SetBool(s == "t" || strings.EqualFold(s, "true"))
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		i, err := strconv.ParseInt(s, 10, 64)
+		if err != nil {
+			return v, err
+		}
+		v.SetInt(i)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		i, err := strconv.ParseUint(s, 10, 64)
+		if err != nil {
+			return v, err
+		}
+		v.SetUint(i)
+	case reflect.Float32, reflect.Float64:
+		f, err := strconv.ParseFloat(s, 64)
+		if err != nil {
+			return v, err
+		}
+		v.SetFloat(f)
+	case reflect.Struct:
+		if !t.ConvertibleTo(schemas.TimeType) {
+			return v, fmt.Errorf("unsupported array element type %v", t)
+		}
+		for _, layout := range arrayTimeLayouts {
+			tm, err := time.ParseInLocation(layout, s, tz)
+			if err == nil {
+				v.Set(reflect.ValueOf(tm).Convert(t))
+				return v, nil
+			}
+		}
+		return v, fmt.Errorf("parse array element %s as time failed", s)
+	default:
+		return v, fmt.Errorf("unsupported array element type %v", t)
+	}
+	return v, nil
+}
+
+// ParseArray parses a postgres array literal into the slice which fieldValue
+// points to or holds
+func ParseArray(s string, tz *time.Location, fieldValue reflect.Value) error {
+	elems, err := parseArray(s)
+	if err != nil {
+		return err
+	}
+	if tz == nil {
+		tz = time.Local
+	}
+
+	sliceType := fieldValue.Type()
+	if sliceType.Kind() != reflect.Slice {
+		return fmt.Errorf("unsupported array field type %v", sliceType)
+	}
+	elemType := sliceType.Elem()
+	isPtr := elemType.Kind() == reflect.Ptr
+	if isPtr {
+		elemType = elemType.Elem()
+	}
+
+	res := reflect.MakeSlice(sliceType, 0, len(elems))
+	for _, elem := range elems {
+		if elem == nil {
+			res = reflect.Append(res, reflect.Zero(sliceType.Elem()))
+			continue
+		}
+		v, err := parseArrayElem(*elem, tz, elemType)
+		if err != nil {
+			return err
+		}
+		if isPtr {
+			p := reflect.New(elemType)
+			p.Elem().Set(v)
+			v = p
+		}
+		res = reflect.Append(res, v)
+	}
+	fieldValue.Set(res)
+	return nil
+}
@@ -0,0 +1,60 @@
+// Copyright 2021 The Xorm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package dialects
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/xorm-io/xorm/schemas"
+)
+
+// EnumCheckName returns the name of the check of the options of the enum column
+func EnumCheckName(colName string) string {
+	return colName + "_enum"
+}
+
+// enumCheckExpr returns the expression which checks the value of the column is
+// one of the options of the enum
+func enumCheckExpr(col *schemas.Column) string {
+	options := make([]string, len(col.EnumOptions))
+	for option, i := range col.EnumOptions {
+		options[i] = "'" + strings.Replace(option, "'", "''", -1) + "'"
+	}
+	return fmt.Sprintf("`%s` IN (%s)", col.Name, strings.Join(options, ", "))
+}
+
+// TableChecks returns the check constraints of the table sorted by name, i.e.
+// the checks of the check tags and the checks of the options of the enum
+// columns except on MySQL which has native enums
+func TableChecks(dialect Dialect, table *schemas.Table) []*schemas.Check {
+	checks := make([]*schemas.Check, 0, len(table.Checks))
+	for _, check := range table.Checks {
+		checks = append(checks, check)
+	}
+	if dialect.URI().DBType != schemas.MYSQL {
+		for _, col := range table.Columns() {
+			if col.SQLType.Name != schemas.Enum || len(col.EnumOptions) == 0 {
+				continue
+			}
+			name := EnumCheckName(col.Name)
+			if _, ok := table.Checks[name]; !ok {
+				checks = append(checks, schemas.NewCheck(name, enumCheckExpr(col)))
+			}
+		}
+	}
+	sort.Slice(checks, func(i, j int) bool {
+		return checks[i].Name < checks[j].Name
+	})
+	return checks
+}
+
+// CheckString generates the definition of the check constraint in CREATE TABLE
+func CheckString(dialect Dialect, tableName string, check *schemas.Check) string {
+	quoter := dialect.Quoter()
+	return fmt.Sprintf("CONSTRAINT %s CHECK (%s)",
+		quoter.Quote(check.XName(tableName)), quoter.Replace(check.Expr))
+}
@@ -0,0 +1,50 @@
+// Copyright 2021 The Xorm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package dialects
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/xorm-io/xorm/schemas"
+)
+
+func TestTableChecks(t *testing.T) {
+	table := schemas.NewTable("user", nil)
+	table.AddColumn(&schemas.Column{
+		Name:        "status",
+		SQLType:     schemas.SQLType{Name: schemas.Enum},
+		Nullable:    true,
+		EnumOptions: map[string]int{"active": 0, "it's": 1},
+	})
+	table.AddCheck(schemas.NewCheck("age", "age >= 0"))
+
+	sqlite := QueryDialect(schemas.SQLITE)
+	assert.NoError(t, sqlite.Init(&URI{DBType: schemas.SQLITE}))
+	checks := TableChecks(sqlite, table)
+	assert.EqualValues(t, []*schemas.Check{
+		schemas.NewCheck("age", "age >= 0"),
+		schemas.NewCheck("status_enum", "`status` IN ('active', 'it''s')"),
+	}, checks)
+	assert.EqualValues(t, "CONSTRAINT `CHK_user_status_enum` CHECK (`status` IN ('active', 'it''s'))",
+		CheckString(sqlite, "user", checks[1]))
+
+	sqls, _ := sqlite.CreateTableSQL(table, "")
+	assert.EqualValues(t, []string{"CREATE TABLE IF NOT EXISTS `user` (`status` TEXT NULL, " +
+		"CONSTRAINT `CHK_user_age` CHECK (age >= 0), " +
+		"CONSTRAINT `CHK_user_status_enum` CHECK (`status` IN ('active', 'it''s')))"}, sqls)
+
+	// mysql has native enums
+	mysql := QueryDialect(schemas.MYSQL)
+	assert.NoError(t, mysql.Init(&URI{DBType: schemas.MYSQL}))
+	assert.EqualValues(t, []*schemas.Check{schemas.NewCheck("age", "age >= 0")}, TableChecks(mysql, table))
+
+	postgres := QueryDialect(schemas.POSTGRES)
+	assert.NoError(t, postgres.Init(&URI{DBType: schemas.POSTGRES, Schema: "public"}))
+	assert.EqualValues(t, `ALTER TABLE "public"."user" ADD CONSTRAINT "CHK_user_age" CHECK (age >= 0)`,
+		postgres.AddCheckSQL("public.user", checks[0]))
+	assert.EqualValues(t, `ALTER TABLE "public"."user" DROP CONSTRAINT "CHK_user_age"`,
+		postgres.DropCheckSQL("public.user", checks[0]))
+}
@@ -6,14 +6,19 @@ package dialects
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/xorm-io/xorm/core"
 	"github.com/xorm-io/xorm/schemas"
 )
 
+// ErrNotSupported represents an operation the dialect doesn't support
+var ErrNotSupported = errors.New("not supported by the dialect")
+
 // URI represents an uri to visit database
 type URI struct {
 	DBType  schemas.DBType
@@ -45,6 +50,8 @@ type Dialect interface {
 	SQLType(*schemas.Column) string
 	FormatBytes(b []byte) string
 	Version(ctx context.Context, queryer core.Queryer) (*schemas.Version, error)
+	SetVersion(version *schemas.Version)
+	ServerVersion() *schemas.Version
 
 	IsReserved(string) bool
 	Quoter() schemas.Quoter
@@ -67,7 +74,29 @@ type Dialect interface {
 	AddColumnSQL(tableName string, col *schemas.Column) string
 	ModifyColumnSQL(tableName string, col *schemas.Column) string
 
+	GetChecks(queryer core.Queryer, ctx context.Context, tableName string) (map[string]*schemas.Check, error)
+	AddCheckSQL(tableName string, check *schemas.Check) string
+	DropCheckSQL(tableName string, check *schemas.Check) string
+
+	GetSequences(queryer core.Queryer, ctx context.Context) ([]string, error)
+	CreateSequenceSQL(seq *schemas.Sequence) string
+	DropSequenceSQL(seqName string) string
+	NextSequenceSQL(seqName string) string
+	RestartSequenceSQL(seqName string, start int64) string
+
+	GetViews(queryer core.Queryer, ctx context.Context) ([]*schemas.Table, error)
+	CreateViewSQL(viewName, query string, materialized bool) []string
+	DropViewSQL(viewName string, materialized bool) string
+	RefreshViewSQL(viewName string) string
+
 	ForUpdateSQL(query string) string
+	Explain(queryer core.Queryer, ctx context.Context, query string, args ...interface{}) (*schemas.PlanNode, error)
+
+	JSONExtract(col string, path *JSONPath) string
+	JSONContains(col string, doc interface{}) (string, []interface{}, error)
+	JSONSet(col string, path *JSONPath, value interface{}) (string, []interface{}, error)
+	ArrayContains(col string, values []interface{}) (string, []interface{}, error)
+	ArrayOverlap(col string, values []interface{}) (string, []interface{}, error)
 
 	Filters() []Filter
 	SetParams(params map[string]string)
@@ -75,9 +104,11 @@ type Dialect interface {
 
 // Base represents a basic dialect and all real dialects could embed this struct
 type Base struct {
-	dialect Dialect
-	uri     *URI
-	quoter  schemas.Quoter
+	dialect      Dialect
+	uri          *URI
+	quoter       schemas.Quoter
+	versionMutex sync.RWMutex
+	version      *schemas.Version
 }
 
 // Quoter returns the current database Quoter
@@ -96,6 +127,21 @@ func (db *Base) URI() *URI {
 	return db.uri
 }
 
+// SetVersion sets the version of the database server, some SQLs are
+// generated according to it
+func (db *Base) SetVersion(version *schemas.Version) {
+	db.versionMutex.Lock()
+	db.version = version
+	db.versionMutex.Unlock()
+}
+
+// ServerVersion returns the version set by SetVersion, nil if it's unknown
+func (db *Base) ServerVersion() *schemas.Version {
+	db.versionMutex.RLock()
+	defer db.versionMutex.RUnlock()
+	return db.version
+}
+
 // FormatBytes formats bytes
 func (db *Base) FormatBytes(bs []byte) string {
 	return fmt.Sprintf("0x%x", bs)
@@ -133,7 +179,11 @@ func (db *Base) IsColumnExist(queryer core.Queryer, ctx context.Context, tableNa
 		quote("TABLE_NAME"),
 		quote("COLUMN_NAME"),
 	)
-	return db.HasRecords(queryer, ctx, query, db.uri.DBName, tableName, colName)
+	dbName := db.uri.DBName
+	if schema := SchemaFromContext(ctx); schema != "" {
+		dbName = schema
+	}
+	return db.HasRecords(queryer, ctx, query, dbName, tableName, colName)
 }
 
 // AddColumnSQL returns a SQL to add a column
@@ -151,9 +201,9 @@ func (db *Base) CreateIndexSQL(tableName string, index *schemas.Index) string {
 		unique = " UNIQUE"
 	}
 	idxName = index.XName(tableName)
-	return fmt.Sprintf("CREATE%s INDEX %v ON %v (%v)", unique,
+	return fmt.Sprintf("CREATE%s INDEX %v ON %v (%v)%s", unique,
 		quoter.Quote(idxName), quoter.Quote(tableName),
-		quoter.Join(index.Cols, ","))
+		IndexKeysString(db.dialect, index), IndexWhereString(db.dialect, index))
 }
 
 // DropIndexSQL returns a SQL to drop index
@@ -174,11 +224,117 @@ func (db *Base) ModifyColumnSQL(tableName string, col *schemas.Column) string {
 	return fmt.Sprintf("ALTER TABLE %s MODIFY COLUMN %s", tableName, s)
 }
 
+// GetChecks returns the check constraints of the table, it returns nil if the
+// dialect can't read them back
+func (db *Base) GetChecks(queryer core.Queryer, ctx context.Context, tableName string) (map[string]*schemas.Check, error) {
+	return nil, nil
+}
+
+// AddCheckSQL returns a SQL to add a check constraint
+func (db *Base) AddCheckSQL(tableName string, check *schemas.Check) string {
+	quoter := db.dialect.Quoter()
+	return fmt.Sprintf("ALTER TABLE %v ADD %v", quoter.Quote(tableName), CheckString(db.dialect, tableName, check))
+}
+
+// DropCheckSQL returns a SQL to drop a check constraint
+func (db *Base) DropCheckSQL(tableName string, check *schemas.Check) string {
+	quote := db.dialect.Quoter().Quote
+	return fmt.Sprintf("ALTER TABLE %v DROP CONSTRAINT %v", quote(tableName), quote(check.XName(tableName)))
+}
+
+// GetSequences returns the names of the sequences, it returns nil if the
+// dialect has no sequences
+func (db *Base) GetSequences(queryer core.Queryer, ctx context.Context) ([]string, error) {
+	return nil, nil
+}
+
+// CreateSequenceSQL returns a SQL to create a sequence
+func (db *Base) CreateSequenceSQL(seq *schemas.Sequence) string {
+	return fmt.Sprintf("CREATE SEQUENCE %v START WITH %d INCREMENT BY %d",
+		db.dialect.Quoter().Quote(seq.Name), seq.Start, seq.Increment)
+}
+
+// DropSequenceSQL returns a SQL to drop a sequence
+func (db *Base) DropSequenceSQL(seqName string) string {
+	return fmt.Sprintf("DROP SEQUENCE %v", db.dialect.Quoter().Quote(seqName))
+}
+
+// NextSequenceSQL returns a SQL to fetch the next value of a sequence
+func (db *Base) NextSequenceSQL(seqName string) string {
+	return fmt.Sprintf("SELECT NEXT VALUE FOR %v", db.dialect.Quoter().Quote(seqName))
+}
+
+// RestartSequenceSQL returns a SQL to restart a sequence from the value
+func (db *Base) RestartSequenceSQL(seqName string, start int64) string {
+	return fmt.Sprintf("ALTER SEQUENCE %v RESTART WITH %d", db.dialect.Quoter().Quote(seqName), start)
+}
+
+// CreateViewSQL returns the SQLs to create or replace a view, the materialized
+// views are created as the plain views unless the dialect supports them
+func (db *Base) CreateViewSQL(viewName, query string, materialized bool) []string {
+	return []string{fmt.Sprintf("CREATE OR REPLACE VIEW %v AS %v", db.dialect.Quoter().Quote(viewName), query)}
+}
+
+// DropViewSQL returns a SQL to drop a view
+func (db *Base) DropViewSQL(viewName string, materialized bool) string {
+	return fmt.Sprintf("DROP VIEW IF EXISTS %v", db.dialect.Quoter().Quote(viewName))
+}
+
+// RefreshViewSQL returns a SQL to refresh a materialized view, it returns
+// empty if the dialect has no materialized views
+func (db *Base) RefreshViewSQL(viewName string) string {
+	return ""
+}
+
 // ForUpdateSQL returns for updateSQL
 func (db *Base) ForUpdateSQL(query string) string {
 	return query + " FOR UPDATE"
 }
 
+// Explain returns ErrNotSupported unless the dialect reports the query plans
+func (db *Base) Explain(queryer core.Queryer, ctx context.Context, query string, args ...interface{}) (*schemas.PlanNode, error) {
+	return nil, ErrNotSupported
+}
+
+// JSONExtract returns the expression of the scalar value of the JSON path as text
+func (db *Base) JSONExtract(col string, path *JSONPath) string {
+	return fmt.Sprintf("JSON_VALUE(%s, %s)", col, path.Literal())
+}
+
+// JSONContains returns the condition that the JSON column contains the document
+func (db *Base) JSONContains(col string, doc interface{}) (string, []interface{}, error) {
+	return jsonContainsByPaths(db.dialect, col, doc, nil)
+}
+
+// JSONSet returns the expression of the JSON column with the path set to value
+func (db *Base) JSONSet(col string, path *JSONPath, value interface{}) (string, []interface{}, error) {
+	return "", nil, ErrNotSupported
+}
+
+// ArrayContains returns the condition that the array column contains all the
+// values, the array is stored as JSON text on the databases without arrays
+func (db *Base) ArrayContains(col string, values []interface{}) (string, []interface{}, error) {
+	return db.dialect.JSONContains(col, values)
+}
+
+// ArrayOverlap returns the condition that the array column has any of the values
+func (db *Base) ArrayOverlap(col string, values []interface{}) (string, []interface{}, error) {
+	if len(values) == 0 {
+		return "1=0", nil, nil
+	}
+	var conds = make([]string, 0, len(values))
+	var args []interface{}
+	for _, v := range values {
+		cond, condArgs, err := db.dialect.JSONContains(col, []interface{}{v})
+		if err != nil {
+			return "", nil, err
+		}
+		conds = append(conds, cond)
+		args = append(args, condArgs...)
+	}
+	return "(" + strings.Join(conds, " OR ") + ")", args, nil
+}
+
 // SetParams set params
 func (db *Base) SetParams(params map[string]string) {
 }
@@ -246,6 +402,14 @@ func ColumnString(dialect Dialect, col *schemas.Column, includePrimaryKey bool)
 		return "", err
 	}
 
+	// the computed column of SQL Server has no type
+	if col.Generated != "" && dialect.URI().DBType == schemas.MSSQL {
+		if _, err := bd.WriteString(generatedString(dialect, col)); err != nil {
+			return "", err
+		}
+		return bd.String(), nil
+	}
+
 	if _, err := bd.WriteString(dialect.SQLType(col)); err != nil {
 		return "", err
 	}
@@ -269,7 +433,14 @@ func ColumnString(dialect Dialect, col *schemas.Column, includePrimaryKey bool)
 		}
 	}
 
-	if col.Default != "" {
+	if col.Generated != "" {
+		if _, err := bd.WriteString(generatedString(dialect, col)); err != nil {
+			return "", err
+		}
+		if err := bd.WriteByte(' '); err != nil {
+			return "", err
+		}
+	} else if col.Default != "" {
 		if _, err := bd.WriteString("DEFAULT "); err != nil {
 			return "", err
 		}
@@ -293,3 +464,25 @@ func ColumnString(dialect Dialect, col *schemas.Column, includePrimaryKey bool)
 
 	return bd.String(), nil
 }
+
+// generatedString generates the expression clause of the generated column
+func generatedString(dialect Dialect, col *schemas.Column) string {
+	expr := dialect.Quoter().Replace(col.Generated)
+	switch dialect.URI().DBType {
+	case schemas.MSSQL:
+		if col.GeneratedStored {
+			return "AS (" + expr + ") PERSISTED"
+		}
+		return "AS (" + expr + ")"
+	case schemas.POSTGRES:
+		// postgres only supports the stored generated columns
+		return "GENERATED ALWAYS AS (" + expr + ") STORED"
+	case schemas.ORACLE:
+		// oracle only supports the virtual columns
+		return "GENERATED ALWAYS AS (" + expr + ") VIRTUAL"
+	}
+	if col.GeneratedStored {
+		return "GENERATED ALWAYS AS (" + expr + ") STORED"
+	}
+	return "GENERATED ALWAYS AS (" + expr + ") VIRTUAL"
+}
@@ -0,0 +1,81 @@
+// Copyright 2021 The Xorm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package dialects
+
+import (
+	"context"
+	"database/sql"
+	"strconv"
+	"strings"
+
+	"github.com/xorm-io/xorm/core"
+)
+
+// rows2Strings reads all the records as strings keyed by lower case column names
+func rows2Strings(rows *sql.Rows) ([]map[string]string, error) {
+	cols, err := rows.Columns()
+	if err != nil {
+		return nil, err
+	}
+
+	var results []map[string]string
+	for rows.Next() {
+		var values = make([]sql.NullString, len(cols))
+		var dest = make([]interface{}, len(cols))
+		for i := range values {
+			dest[i] = &values[i]
+		}
+		if err := rows.Scan(dest...); err != nil {
+			return nil, err
+		}
+		var record = make(map[string]string, len(cols))
+		for i, col := range cols {
+			record[strings.ToLower(col)] = values[i].String
+		}
+		results = append(results, record)
+	}
+	return results, rows.Err()
+}
+
+// queryStrings executes the query and returns all the records as strings
+func queryStrings(queryer core.Queryer, ctx context.Context, query string, args ...interface{}) ([]map[string]string, error) {
+	rows, err := queryer.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	return rows2Strings(rows.Rows)
+}
+
+// withConn runs fn on one connection, for the plan statements which depend on
+// the state of the database session
+func withConn(queryer core.Queryer, ctx context.Context, fn func(conn interface {
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+}) error) error {
+	switch q := queryer.(type) {
+	case *core.Tx:
+		return fn(q.Tx)
+	case *core.DB:
+		conn, err := q.DB.Conn(ctx)
+		if err != nil {
+			return err
+		}
+		defer conn.Close()
+		return fn(conn)
+	}
+	return ErrNotSupported
+}
+
+func parsePlanRows(s string) int64 {
+	if s == "" {
+		return -1
+	}
+	f, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return -1
+	}
+	return int64(f)
+}
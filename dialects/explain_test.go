@@ -0,0 +1,20 @@
+// Copyright 2021 The Xorm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package dialects
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBaseExplain(t *testing.T) {
+	// the third-party dialects which embed Base don't report the query plans
+	var base Base
+	plan, err := base.Explain(nil, context.Background(), "SELECT 1")
+	assert.Nil(t, plan)
+	assert.EqualValues(t, ErrNotSupported, err)
+}
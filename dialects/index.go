@@ -0,0 +1,173 @@
+// Copyright 2021 The Xorm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package dialects
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/xorm-io/xorm/schemas"
+)
+
+// IndexKeysString generates the key parts of the index in CREATE INDEX, the
+// columns are quoted and the expressions are kept
+func IndexKeysString(dialect Dialect, index *schemas.Index) string {
+	quoter := dialect.Quoter()
+	keys := make([]string, 0, len(index.Cols))
+	for _, key := range index.Keys() {
+		var s string
+		if key.IsExpr {
+			s = quoter.Replace(key.Name)
+			// the functional key parts of MySQL are enclosed by parentheses
+			if uri := dialect.URI(); uri != nil && uri.DBType == schemas.MYSQL {
+				s = "(" + s + ")"
+			}
+		} else {
+			s = quoter.Quote(key.Name)
+		}
+		if key.Desc {
+			s += " DESC"
+		}
+		keys = append(keys, s)
+	}
+	return strings.Join(keys, ",")
+}
+
+// supportIndexWhere returns false if the dialect has no partial indexes, i.e.
+// MySQL and Oracle
+func supportIndexWhere(dialect Dialect) bool {
+	uri := dialect.URI()
+	return uri == nil || uri.DBType != schemas.MYSQL && uri.DBType != schemas.ORACLE
+}
+
+// CheckIndexes returns ErrNotSupported if the table has a partial index which
+// the dialect doesn't support
+func CheckIndexes(dialect Dialect, table *schemas.Table) error {
+	if supportIndexWhere(dialect) {
+		return nil
+	}
+	for _, index := range table.Indexes {
+		if index.Where != "" {
+			return fmt.Errorf("the partial index %s of %s: %w", index.Name, table.Name, ErrNotSupported)
+		}
+	}
+	return nil
+}
+
+// IndexWhereString generates the WHERE clause of a partial index, it's empty
+// if the dialect doesn't support the partial indexes, which are rejected by
+// CheckIndexes
+func IndexWhereString(dialect Dialect, index *schemas.Index) string {
+	if index.Where == "" || !supportIndexWhere(dialect) {
+		return ""
+	}
+	return " WHERE " + dialect.Quoter().Replace(index.Where)
+}
+
+// splitTopLevel splits s by the commas which aren't in quotes or parentheses
+func splitTopLevel(s string) []string {
+	var (
+		parts   []string
+		depth   int
+		quote   byte
+		lastIdx int
+	)
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case c == '\'' || c == '"' || c == '`':
+			quote = c
+		case c == '[':
+			quote = ']'
+		case c == '(':
+			depth++
+		case c == ')':
+			depth--
+		case c == ',' && depth == 0:
+			parts = append(parts, strings.TrimSpace(s[lastIdx:i]))
+			lastIdx = i + 1
+		}
+	}
+	if part := strings.TrimSpace(s[lastIdx:]); part != "" {
+		parts = append(parts, part)
+	}
+	return parts
+}
+
+// closingParen returns the index of the parenthesis which closes the one at start
+func closingParen(s string, start int) int {
+	var depth int
+	var quote byte
+	for i := start; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case c == '\'' || c == '"' || c == '`':
+			quote = c
+		case c == '(':
+			depth++
+		case c == ')':
+			depth--
+			if depth == 0 {
+				return i
+			}
+		}
+	}
+	return -1
+}
+
+// trimParens removes the parentheses which enclose the whole expression
+func trimParens(expr string) string {
+	expr = strings.TrimSpace(expr)
+	for len(expr) > 1 && expr[0] == '(' && closingParen(expr, 0) == len(expr)-1 {
+		expr = strings.TrimSpace(expr[1 : len(expr)-1])
+	}
+	return expr
+}
+
+// splitIndexDef returns the key parts and the predicate of a CREATE INDEX SQL
+func splitIndexDef(indexdef string) ([]string, string) {
+	on := strings.Index(strings.ToUpper(indexdef), " ON ")
+	if on < 0 {
+		return nil, ""
+	}
+	start := strings.Index(indexdef[on:], "(")
+	if start < 0 {
+		return nil, ""
+	}
+	start += on
+	end := closingParen(indexdef, start)
+	if end < 0 {
+		return nil, ""
+	}
+
+	keys := splitTopLevel(indexdef[start+1 : end])
+	rest := strings.TrimSpace(strings.TrimSuffix(strings.TrimSpace(indexdef[end+1:]), ";"))
+	if len(rest) > 5 && strings.EqualFold(rest[:5], "WHERE") {
+		return keys, trimParens(rest[5:])
+	}
+	return keys, ""
+}
+
+// indexKeyName returns the column of the key part of an index without the
+// quotes and the sort order, or the expression of the key part
+func indexKeyName(key string) string {
+	key = strings.TrimSpace(key)
+	if strings.Contains(key, "(") {
+		return key
+	}
+	fields := strings.Fields(key)
+	if len(fields) == 0 {
+		return ""
+	}
+	return strings.Trim(fields[0], "`[]\"")
+}
@@ -0,0 +1,103 @@
+// Copyright 2021 The Xorm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package dialects
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/xorm-io/xorm/schemas"
+)
+
+func TestCreateIndexSQL(t *testing.T) {
+	index := schemas.NewIndex("email", schemas.UniqueType)
+	index.AddColumn("lower(`email`)", "created DESC")
+	index.Where = "`deleted` IS NULL"
+
+	var kases = []struct {
+		dbType schemas.DBType
+		sql    string
+	}{
+		{schemas.SQLITE, "CREATE UNIQUE INDEX `UQE_user_email` ON `user` (lower(`email`),`created` DESC) WHERE `deleted` IS NULL"},
+		{schemas.POSTGRES, `CREATE UNIQUE INDEX "UQE_user_email" ON "user" (lower("email"),"created" DESC) WHERE "deleted" IS NULL`},
+		{schemas.MYSQL, "CREATE UNIQUE INDEX `UQE_user_email` ON `user` ((lower(`email`)),`created` DESC)"},
+	}
+	for _, kase := range kases {
+		dialect := QueryDialect(kase.dbType)
+		assert.NoError(t, dialect.Init(&URI{DBType: kase.dbType}))
+		assert.EqualValues(t, kase.sql, dialect.CreateIndexSQL("user", index))
+	}
+}
+
+func TestCheckIndexes(t *testing.T) {
+	table := schemas.NewEmptyTable()
+	table.Name = "user"
+	index := schemas.NewIndex("email", schemas.UniqueType)
+	index.AddColumn("email")
+	table.AddIndex(index)
+
+	var kases = []struct {
+		dbType    schemas.DBType
+		supported bool
+	}{
+		{schemas.SQLITE, true},
+		{schemas.POSTGRES, true},
+		{schemas.MSSQL, true},
+		{schemas.MYSQL, false},
+		{schemas.ORACLE, false},
+	}
+	for _, kase := range kases {
+		dialect := QueryDialect(kase.dbType)
+		assert.NoError(t, dialect.Init(&URI{DBType: kase.dbType}))
+
+		index.Where = ""
+		assert.NoError(t, CheckIndexes(dialect, table))
+
+		index.Where = "`deleted` IS NULL"
+		err := CheckIndexes(dialect, table)
+		if kase.supported {
+			assert.NoError(t, err, kase.dbType)
+		} else {
+			assert.True(t, errors.Is(err, ErrNotSupported), kase.dbType)
+			assert.Contains(t, err.Error(), "email")
+		}
+	}
+}
+
+func TestSplitIndexDef(t *testing.T) {
+	keys, where := splitIndexDef("CREATE UNIQUE INDEX `UQE_user_email` ON `user` (lower(`email`), `created` DESC) WHERE (`deleted` IS NULL) AND a IN (1, 2)")
+	assert.EqualValues(t, []string{"lower(`email`)", "`created` DESC"}, keys)
+	assert.EqualValues(t, "(`deleted` IS NULL) AND a IN (1, 2)", where)
+	assert.EqualValues(t, []string{"lower(`email`)", "created"}, []string{indexKeyName(keys[0]), indexKeyName(keys[1])})
+}
+
+func TestGeneratedColumnString(t *testing.T) {
+	col := &schemas.Column{
+		Name:            "total",
+		SQLType:         schemas.SQLType{Name: schemas.Int},
+		Nullable:        true,
+		Generated:       "`price` * `amount`",
+		GeneratedStored: true,
+	}
+
+	var kases = []struct {
+		dbType schemas.DBType
+		s      string
+	}{
+		{schemas.SQLITE, "`total` INTEGER GENERATED ALWAYS AS (`price` * `amount`) STORED NULL "},
+		{schemas.MYSQL, "`total` INT GENERATED ALWAYS AS (`price` * `amount`) STORED NULL "},
+		{schemas.POSTGRES, `"total" INTEGER GENERATED ALWAYS AS ("price" * "amount") STORED NULL `},
+		{schemas.MSSQL, "[total] AS ([price] * [amount]) PERSISTED"},
+		{schemas.ORACLE, `"total" NUMBER GENERATED ALWAYS AS ("price" * "amount") VIRTUAL NULL `},
+	}
+	for _, kase := range kases {
+		dialect := QueryDialect(kase.dbType)
+		assert.NoError(t, dialect.Init(&URI{DBType: kase.dbType}))
+		s, err := ColumnString(dialect, col, false)
+		assert.NoError(t, err)
+		assert.EqualValues(t, kase.s, s)
+	}
+}
@@ -0,0 +1,232 @@
+// Copyright 2021 The Xorm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package dialects
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+type jsonPathElem struct {
+	key   string
+	index int // valid when key is empty
+}
+
+// JSONPath represents a JSON path like $.a.b[0]. Only the member keys which
+// are identifiers and the array indexes are supported, so that the path could
+// be written into SQL literally.
+type JSONPath struct {
+	elems []jsonPathElem
+}
+
+// ParseJSONPath parses a JSON path, the leading $ could be omitted
+func ParseJSONPath(path string) (*JSONPath, error) {
+	var p JSONPath
+	s := strings.TrimSpace(path)
+	if strings.HasPrefix(s, "$") {
+		s = s[1:]
+	} else if s != "" && s[0] != '[' {
+		s = "." + s
+	}
+	for len(s) > 0 {
+		switch s[0] {
+		case '.':
+			i := 1
+			for i < len(s) && isJSONKeyChar(s[i], i == 1) {
+				i++
+			}
+			if i == 1 {
+				return nil, fmt.Errorf("invalid JSON path %q", path)
+			}
+			p.elems = append(p.elems, jsonPathElem{key: s[1:i]})
+			s = s[i:]
+		case '[':
+			end := strings.IndexByte(s, ']')
+			if end < 0 {
+				return nil, fmt.Errorf("invalid JSON path %q", path)
+			}
+			idx, err := strconv.Atoi(s[1:end])
+			if err != nil || idx < 0 {
+				return nil, fmt.Errorf("invalid JSON path %q", path)
+			}
+			p.elems = append(p.elems, jsonPathElem{index: idx})
+			s = s[end+1:]
+		default:
+			return nil, fmt.Errorf("invalid JSON path %q", path)
+		}
+	}
+	return &p, nil
+}
+
+func isJSONKeyChar(c byte, first bool) bool {
+	return c == '_' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') ||
+		(!first && c >= '0' && c <= '9')
+}
+
+// Key returns a sub path of the member key
+func (p *JSONPath) Key(key string) *JSONPath {
+	elems := make([]jsonPathElem, len(p.elems), len(p.elems)+1)
+	copy(elems, p.elems)
+	return &JSONPath{elems: append(elems, jsonPathElem{key: key})}
+}
+
+// String returns the SQL/JSON path, i.e. $.a.b[0]
+func (p *JSONPath) String() string {
+	var buf strings.Builder
+	buf.WriteString("$")
+	for _, elem := range p.elems {
+		if elem.key != "" {
+			buf.WriteString(".")
+			buf.WriteString(elem.key)
+		} else {
+			buf.WriteString("[")
+			buf.WriteString(strconv.Itoa(elem.index))
+			buf.WriteString("]")
+		}
+	}
+	return buf.String()
+}
+
+// Literal returns the path as a SQL string literal
+func (p *JSONPath) Literal() string {
+	return "'" + p.String() + "'"
+}
+
+// PostgresLiteral returns the path as a postgres text array literal, i.e. '{a,b,0}'
+func (p *JSONPath) PostgresLiteral() string {
+	var parts = make([]string, 0, len(p.elems))
+	for _, elem := range p.elems {
+		if elem.key != "" {
+			parts = append(parts, elem.key)
+		} else {
+			parts = append(parts, strconv.Itoa(elem.index))
+		}
+	}
+	return "'{" + strings.Join(parts, ",") + "}'"
+}
+
+// marshalJSONDoc marshals the document to a JSON string, the strings and
+// bytes which are valid JSON documents are kept as they are.
+func marshalJSONDoc(v interface{}) (string, error) {
+	switch t := v.(type) {
+	case string:
+		if json.Valid([]byte(t)) {
+			return t, nil
+		}
+	case []byte:
+		if json.Valid(t) {
+			return string(t), nil
+		}
+	}
+	return marshalJSONValue(v)
+}
+
+// marshalJSONValue marshals the value to a JSON string, use json.RawMessage
+// to pass a JSON document
+func marshalJSONValue(v interface{}) (string, error) {
+	bs, err := json.Marshal(v)
+	if err != nil {
+		return "", err
+	}
+	return string(bs), nil
+}
+
+func isJSONContainer(doc string) bool {
+	doc = strings.TrimSpace(doc)
+	return strings.HasPrefix(doc, "{") || strings.HasPrefix(doc, "[")
+}
+
+// jsonScalar converts a decoded JSON number to the Go value which compares
+// equally with the extracted value of the databases
+func jsonScalar(v interface{}) interface{} {
+	if n, ok := v.(json.Number); ok {
+		if i, err := n.Int64(); err == nil {
+			return i
+		}
+		f, _ := n.Float64()
+		return f
+	}
+	return v
+}
+
+// jsonContainsByPaths renders the JSON containment as the comparisons of the
+// leaf values for the databases which have no containment operator. arrayHas
+// returns the condition that the array of the path has an element equal to ?,
+// the arrays are not supported if it's nil.
+func jsonContainsByPaths(dialect Dialect, col string, doc interface{}, arrayHas func(col string, path *JSONPath) string) (string, []interface{}, error) {
+	s, err := marshalJSONDoc(doc)
+	if err != nil {
+		return "", nil, err
+	}
+	var v interface{}
+	decoder := json.NewDecoder(bytes.NewReader([]byte(s)))
+	decoder.UseNumber()
+	if err := decoder.Decode(&v); err != nil {
+		return "", nil, err
+	}
+
+	var conds []string
+	var args []interface{}
+	var walk func(path *JSONPath, v interface{}) error
+	walk = func(path *JSONPath, v interface{}) error {
+		switch t := v.(type) {
+		case map[string]interface{}:
+			var keys = make([]string, 0, len(t))
+			for k := range t {
+				keys = append(keys, k)
+			}
+			sort.Strings(keys)
+			for _, k := range keys {
+				if !isJSONKey(k) {
+					return fmt.Errorf("unsupported JSON key %q", k)
+				}
+				if err := walk(path.Key(k), t[k]); err != nil {
+					return err
+				}
+			}
+		case []interface{}:
+			if arrayHas == nil {
+				return ErrNotSupported
+			}
+			for _, elem := range t {
+				switch elem.(type) {
+				case map[string]interface{}, []interface{}:
+					return ErrNotSupported
+				}
+				conds = append(conds, arrayHas(col, path))
+				args = append(args, jsonScalar(elem))
+			}
+		case nil:
+			conds = append(conds, dialect.JSONExtract(col, path)+" IS NULL")
+		default:
+			conds = append(conds, dialect.JSONExtract(col, path)+" = ?")
+			args = append(args, jsonScalar(t))
+		}
+		return nil
+	}
+	if err := walk(&JSONPath{}, v); err != nil {
+		return "", nil, err
+	}
+	if len(conds) == 0 {
+		return "1=1", nil, nil
+	}
+	return "(" + strings.Join(conds, " AND ") + ")", args, nil
+}
+
+func isJSONKey(k string) bool {
+	if k == "" {
+		return false
+	}
+	for i := 0; i < len(k); i++ {
+		if !isJSONKeyChar(k[i], i == 0) {
+			return false
+		}
+	}
+	return true
+}
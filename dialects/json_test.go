@@ -0,0 +1,94 @@
+// Copyright 2021 The Xorm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package dialects
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/xorm-io/xorm/schemas"
+)
+
+func TestParseJSONPath(t *testing.T) {
+	var kases = []struct {
+		path     string
+		expected string
+		postgres string
+	}{
+		{"$", "$", "'{}'"},
+		{"$.theme", "$.theme", "'{theme}'"},
+		{"theme.colors[1]", "$.theme.colors[1]", "'{theme,colors,1}'"},
+		{"$[0].a_1", "$[0].a_1", "'{0,a_1}'"},
+	}
+	for _, kase := range kases {
+		p, err := ParseJSONPath(kase.path)
+		assert.NoError(t, err)
+		assert.EqualValues(t, kase.expected, p.String())
+		assert.EqualValues(t, kase.postgres, p.PostgresLiteral())
+	}
+
+	for _, path := range []string{"$.", "$.a b", "$.a'--", "$[a]", "$[-1]", "$.1a"} {
+		_, err := ParseJSONPath(path)
+		assert.Error(t, err, path)
+	}
+}
+
+func TestJSONSQL(t *testing.T) {
+	path, err := ParseJSONPath("$.theme")
+	assert.NoError(t, err)
+
+	var kases = []struct {
+		dbType   schemas.DBType
+		extract  string
+		contains string
+		set      string
+	}{
+		{schemas.MYSQL, "JSON_UNQUOTE(JSON_EXTRACT(c, '$.theme'))", "JSON_CONTAINS(c, ?)", "JSON_SET(c, '$.theme', JSON_EXTRACT(?, '$'))"},
+		{schemas.POSTGRES, "(c #>> '{theme}')", "c::jsonb @> ?::jsonb", "jsonb_set(c::jsonb, '{theme}', ?::jsonb)"},
+		{schemas.SQLITE, "json_extract(c, '$.theme')", "(json_extract(c, '$.theme') = ?)", "json_set(c, '$.theme', json(?))"},
+		{schemas.MSSQL, "JSON_VALUE(c, '$.theme')", "(JSON_VALUE(c, '$.theme') = ?)", "JSON_MODIFY(c, '$.theme', ?)"},
+		{schemas.ORACLE, "JSON_VALUE(c, '$.theme')", "(JSON_VALUE(c, '$.theme') = ?)", "JSON_TRANSFORM(c, SET '$.theme' = ? FORMAT JSON)"},
+	}
+	for _, kase := range kases {
+		dialect := QueryDialect(kase.dbType)
+		assert.NoError(t, dialect.Init(&URI{DBType: kase.dbType}))
+
+		assert.EqualValues(t, kase.extract, dialect.JSONExtract("c", path))
+
+		sql, _, err := dialect.JSONContains("c", `{"theme":"dark"}`)
+		assert.NoError(t, err)
+		assert.EqualValues(t, kase.contains, sql)
+
+		sql, _, err = dialect.JSONSet("c", path, "dark")
+		assert.NoError(t, err)
+		assert.EqualValues(t, kase.set, sql)
+	}
+}
+
+func TestJSONContainsByPaths(t *testing.T) {
+	dialect := QueryDialect(schemas.SQLITE)
+	assert.NoError(t, dialect.Init(&URI{DBType: schemas.SQLITE}))
+
+	sql, args, err := dialect.JSONContains("c", map[string]interface{}{
+		"size": 12,
+		"tags": []string{"a"},
+		"sub":  map[string]interface{}{"ratio": 1.5, "x": nil},
+	})
+	assert.NoError(t, err)
+	assert.EqualValues(t, "(json_extract(c, '$.size') = ? AND json_extract(c, '$.sub.ratio') = ? AND json_extract(c, '$.sub.x') IS NULL AND "+
+		"EXISTS (SELECT 1 FROM json_each(c, '$.tags') WHERE json_each.value = ?))", sql)
+	assert.EqualValues(t, []interface{}{int64(12), 1.5, "a"}, args)
+
+	oracle := QueryDialect(schemas.ORACLE)
+	assert.NoError(t, oracle.Init(&URI{DBType: schemas.ORACLE}))
+	sql, args, err = oracle.JSONContains("c", `{"size":12,"tags":["a"]}`)
+	assert.NoError(t, err)
+	assert.EqualValues(t, `(JSON_VALUE(c, '$.size') = ? AND JSON_EXISTS(c, '$.tags[*]?(@ == $v)' PASSING ? AS "v"))`, sql)
+	assert.EqualValues(t, []interface{}{int64(12), "a"}, args)
+
+	// the arrays of the containers aren't supported
+	_, _, err = oracle.JSONContains("c", `{"tags":[{"a":1}]}`)
+	assert.EqualValues(t, ErrNotSupported, err)
+}
@@ -6,6 +6,7 @@ package dialects
 
 import (
 	"context"
+	"database/sql"
 	"errors"
 	"fmt"
 	"net/url"
@@ -311,7 +312,7 @@ func (db *mssql) SQLType(c *schemas.Column) string {
 		c.Length = 7
 	case schemas.MediumInt:
 		res = schemas.Int
-	case schemas.Text, schemas.MediumText, schemas.TinyText, schemas.LongText, schemas.Json:
+	case schemas.Text, schemas.MediumText, schemas.TinyText, schemas.LongText, schemas.Json, schemas.Enum, schemas.Set:
 		res = db.defaultVarchar + "(MAX)"
 	case schemas.Double:
 		res = schemas.Real
@@ -401,37 +402,51 @@ func (db *mssql) ModifyColumnSQL(tableName string, col *schemas.Column) string {
 }
 
 func (db *mssql) IndexCheckSQL(tableName, idxName string) (string, []interface{}) {
-	args := []interface{}{idxName}
-	sql := "select name from sysindexes where id=object_id('" + tableName + "') and name=?"
+	args := []interface{}{tableName, idxName}
+	sql := "select name from sysindexes where id=object_id(?) and name=?"
 	return sql, args
 }
 
+// contextTableName qualifies the table name by the schema of the context
+func (db *mssql) contextTableName(ctx context.Context, tableName string) string {
+	if schema := SchemaFromContext(ctx); schema != "" && !strings.Contains(tableName, ".") {
+		return schema + "." + tableName
+	}
+	return tableName
+}
+
 func (db *mssql) IsColumnExist(queryer core.Queryer, ctx context.Context, tableName, colName string) (bool, error) {
 	query := `SELECT "COLUMN_NAME" FROM "INFORMATION_SCHEMA"."COLUMNS" WHERE "TABLE_NAME" = ? AND "COLUMN_NAME" = ?`
+	if schema := SchemaFromContext(ctx); schema != "" {
+		query += ` AND "TABLE_SCHEMA" = ?`
+		return db.HasRecords(queryer, ctx, query, tableName, colName, schema)
+	}
 
 	return db.HasRecords(queryer, ctx, query, tableName, colName)
 }
 
 func (db *mssql) IsTableExist(queryer core.Queryer, ctx context.Context, tableName string) (bool, error) {
-	sql := "select * from sysobjects where id = object_id(N'" + tableName + "') and OBJECTPROPERTY(id, N'IsUserTable') = 1"
-	return db.HasRecords(queryer, ctx, sql)
+	sql := "select * from sysobjects where id = object_id(?) and OBJECTPROPERTY(id, N'IsUserTable') = 1"
+	return db.HasRecords(queryer, ctx, sql, db.contextTableName(ctx, tableName))
 }
 
 func (db *mssql) GetColumns(queryer core.Queryer, ctx context.Context, tableName string) ([]string, map[string]*schemas.Column, error) {
-	args := []interface{}{}
+	args := []interface{}{db.contextTableName(ctx, tableName)}
 	s := `select a.name as name, b.name as ctype,a.max_length,a.precision,a.scale,a.is_nullable as nullable,
 		  "default_is_null" = (CASE WHEN c.text is null THEN 1 ELSE 0 END),
 	      replace(replace(isnull(c.text,''),'(',''),')','') as vdefault,
-		  ISNULL(p.is_primary_key, 0), a.is_identity as is_identity
+		  ISNULL(p.is_primary_key, 0), a.is_identity as is_identity,
+		  cc.definition, ISNULL(cc.is_persisted, 0)
           from sys.columns a 
 		  left join sys.types b on a.user_type_id=b.user_type_id
+		  left join sys.computed_columns cc on cc.object_id = a.object_id AND cc.column_id = a.column_id
           left join sys.syscomments c on a.default_object_id=c.id
 		  LEFT OUTER JOIN (SELECT i.object_id, ic.column_id, i.is_primary_key
 			FROM sys.indexes i
 		  LEFT JOIN sys.index_columns ic ON ic.object_id = i.object_id AND ic.index_id = i.index_id
 			WHERE i.is_primary_key = 1
 		) as p on p.object_id = a.object_id AND p.column_id = a.column_id
-          where a.object_id=object_id('` + tableName + `')`
+          where a.object_id=object_id(?)`
 
 	rows, err := queryer.QueryContext(ctx, s, args...)
 	if err != nil {
@@ -444,8 +459,9 @@ func (db *mssql) GetColumns(queryer core.Queryer, ctx context.Context, tableName
 	for rows.Next() {
 		var name, ctype, vdefault string
 		var maxLen, precision, scale int
-		var nullable, isPK, defaultIsNull, isIncrement bool
-		err = rows.Scan(&name, &ctype, &maxLen, &precision, &scale, &nullable, &defaultIsNull, &vdefault, &isPK, &isIncrement)
+		var nullable, isPK, defaultIsNull, isIncrement, isPersisted bool
+		var computed *string
+		err = rows.Scan(&name, &ctype, &maxLen, &precision, &scale, &nullable, &defaultIsNull, &vdefault, &isPK, &isIncrement, &computed, &isPersisted)
 		if err != nil {
 			return nil, nil, err
 		}
@@ -460,6 +476,10 @@ func (db *mssql) GetColumns(queryer core.Queryer, ctx context.Context, tableName
 		}
 		col.IsPrimaryKey = isPK
 		col.IsAutoIncrement = isIncrement
+		if computed != nil {
+			col.Generated = trimParens(*computed)
+			col.GeneratedStored = isPersisted
+		}
 		ct := strings.ToUpper(ctype)
 		if ct == "DECIMAL" {
 			col.Length = precision
@@ -501,6 +521,10 @@ func (db *mssql) GetColumns(queryer core.Queryer, ctx context.Context, tableName
 func (db *mssql) GetTables(queryer core.Queryer, ctx context.Context) ([]*schemas.Table, error) {
 	args := []interface{}{}
 	s := `select name from sysobjects where xtype ='U'`
+	if schema := SchemaFromContext(ctx); schema != "" {
+		s = `select name from sys.tables where schema_id = SCHEMA_ID(?)`
+		args = append(args, schema)
+	}
 
 	rows, err := queryer.QueryContext(ctx, s, args...)
 	if err != nil {
@@ -522,12 +546,75 @@ func (db *mssql) GetTables(queryer core.Queryer, ctx context.Context) ([]*schema
 	return tables, nil
 }
 
+// GetViews returns the views, SQL Server has no materialized views
+func (db *mssql) GetViews(queryer core.Queryer, ctx context.Context) ([]*schemas.Table, error) {
+	args := []interface{}{}
+	s := "SELECT name, 0 FROM sys.views"
+	if schema := SchemaFromContext(ctx); schema != "" {
+		s += " WHERE schema_id = SCHEMA_ID(?)"
+		args = append(args, schema)
+	}
+	return queryViews(queryer, ctx, s, args...)
+}
+
+// GetChecks returns the check constraints of the table
+func (db *mssql) GetChecks(queryer core.Queryer, ctx context.Context, tableName string) (map[string]*schemas.Check, error) {
+	args := []interface{}{db.contextTableName(ctx, tableName)}
+	s := "SELECT name, definition FROM sys.check_constraints WHERE parent_object_id = OBJECT_ID(?)"
+
+	rows, err := queryer.QueryContext(ctx, s, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	checks := make(map[string]*schemas.Check)
+	for rows.Next() {
+		var name, def string
+		if err = rows.Scan(&name, &def); err != nil {
+			return nil, err
+		}
+		def = strings.TrimSuffix(strings.TrimPrefix(def, "("), ")")
+		checks[name] = schemas.NewCheck(name, def)
+	}
+	return checks, rows.Err()
+}
+
+// GetSequences returns the names of the sequences
+func (db *mssql) GetSequences(queryer core.Queryer, ctx context.Context) ([]string, error) {
+	args := []interface{}{}
+	s := "SELECT name FROM sys.sequences"
+	if schema := SchemaFromContext(ctx); schema != "" {
+		s += " WHERE schema_id = SCHEMA_ID(?)"
+		args = append(args, schema)
+	}
+	return querySequences(queryer, ctx, s, args...)
+}
+
+// CreateSequenceSQL returns a SQL to create a sequence if it doesn't exist in
+// the schema of the sequence name
+func (db *mssql) CreateSequenceSQL(seq *schemas.Sequence) string {
+	escape := func(s string) string { return strings.Replace(s, "'", "''", -1) }
+	cond := fmt.Sprintf("[name] = '%s'", escape(seq.Name))
+	if idx := strings.LastIndex(seq.Name, "."); idx > -1 {
+		cond = fmt.Sprintf("[name] = '%s' AND schema_id = SCHEMA_ID('%s')", escape(seq.Name[idx+1:]), escape(seq.Name[:idx]))
+	}
+	return fmt.Sprintf("IF NOT EXISTS (SELECT [name] FROM sys.sequences WHERE %s) CREATE SEQUENCE %s START WITH %d INCREMENT BY %d",
+		cond, db.Quoter().Quote(seq.Name), seq.Start, seq.Increment)
+}
+
+// CreateViewSQL returns the SQLs to create or replace a view
+func (db *mssql) CreateViewSQL(viewName, query string, materialized bool) []string {
+	return []string{fmt.Sprintf("CREATE OR ALTER VIEW %s AS %s", db.Quoter().Quote(viewName), query)}
+}
+
 func (db *mssql) GetIndexes(queryer core.Queryer, ctx context.Context, tableName string) (map[string]*schemas.Index, error) {
 	args := []interface{}{tableName}
 	s := `SELECT
 IXS.NAME                    AS  [INDEX_NAME],
 C.NAME                      AS  [COLUMN_NAME],
-IXS.is_unique AS [IS_UNIQUE]
+IXS.is_unique AS [IS_UNIQUE],
+IXS.filter_definition AS [FILTER_DEFINITION]
 FROM SYS.INDEXES IXS
 INNER JOIN SYS.INDEX_COLUMNS   IXCS
 ON IXS.OBJECT_ID=IXCS.OBJECT_ID  AND IXS.INDEX_ID = IXCS.INDEX_ID
@@ -535,6 +622,10 @@ INNER   JOIN SYS.COLUMNS C  ON IXS.OBJECT_ID=C.OBJECT_ID
 AND IXCS.COLUMN_ID=C.COLUMN_ID
 WHERE IXS.TYPE_DESC='NONCLUSTERED' and OBJECT_NAME(IXS.OBJECT_ID) =?
 `
+	if schema := SchemaFromContext(ctx); schema != "" {
+		s += " AND OBJECT_SCHEMA_NAME(IXS.OBJECT_ID) = ?"
+		args = append(args, schema)
+	}
 
 	rows, err := queryer.QueryContext(ctx, s, args...)
 	if err != nil {
@@ -546,8 +637,9 @@ WHERE IXS.TYPE_DESC='NONCLUSTERED' and OBJECT_NAME(IXS.OBJECT_ID) =?
 	for rows.Next() {
 		var indexType int
 		var indexName, colName, isUnique string
+		var filter *string
 
-		err = rows.Scan(&indexName, &colName, &isUnique)
+		err = rows.Scan(&indexName, &colName, &isUnique, &filter)
 		if err != nil {
 			return nil, err
 		}
@@ -577,6 +669,9 @@ WHERE IXS.TYPE_DESC='NONCLUSTERED' and OBJECT_NAME(IXS.OBJECT_ID) =?
 			index.Type = indexType
 			index.Name = indexName
 			index.IsRegular = isRegular
+			if filter != nil {
+				index.Where = trimParens(*filter)
+			}
 			indexes[indexName] = index
 		}
 		index.AddColumn(colName)
@@ -610,6 +705,10 @@ func (db *mssql) CreateTableSQL(table *schemas.Table, tableName string) ([]strin
 		sql += " ), "
 	}
 
+	for _, check := range TableChecks(db, table) {
+		sql += CheckString(db, tableName, check) + ", "
+	}
+
 	sql = sql[:len(sql)-2] + ")"
 	sql += ";"
 	return []string{sql}, true
@@ -619,6 +718,112 @@ func (db *mssql) ForUpdateSQL(query string) string {
 	return query
 }
 
+// parseMssqlPlanObject parses the table and index from a showplan argument like
+// OBJECT:([db].[dbo].[user].[IDX_user_name]), SEEK:(...)
+func parseMssqlPlanObject(argument string) (string, string) {
+	idx := strings.Index(argument, "OBJECT:(")
+	if idx < 0 {
+		return "", ""
+	}
+	obj := argument[idx+len("OBJECT:("):]
+	if end := strings.Index(obj, ")"); end > -1 {
+		obj = obj[:end]
+	}
+	if as := strings.Index(obj, " AS "); as > -1 {
+		obj = obj[:as]
+	}
+	parts := strings.Split(obj, "].[")
+	for i := range parts {
+		parts[i] = strings.Trim(parts[i], "[]")
+	}
+	switch len(parts) {
+	case 4:
+		return parts[2], parts[3]
+	case 3:
+		return parts[2], ""
+	}
+	return parts[len(parts)-1], ""
+}
+
+// Explain returns the query plan reported by SHOWPLAN_ALL
+func (db *mssql) Explain(queryer core.Queryer, ctx context.Context, query string, args ...interface{}) (*schemas.PlanNode, error) {
+	var records []map[string]string
+	err := withConn(queryer, ctx, func(conn interface {
+		ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+		QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+	}) error {
+		if _, err := conn.ExecContext(ctx, "SET SHOWPLAN_ALL ON"); err != nil {
+			return err
+		}
+		defer conn.ExecContext(ctx, "SET SHOWPLAN_ALL OFF")
+
+		rows, err := conn.QueryContext(ctx, query, args...)
+		if err != nil {
+			return err
+		}
+		defer rows.Close()
+		records, err = rows2Strings(rows)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	root := schemas.NewPlanNode(schemas.PlanQuery)
+	nodes := make(map[string]*schemas.PlanNode)
+	for _, record := range records {
+		if record["type"] != "PLAN_ROW" {
+			continue
+		}
+		node := schemas.NewPlanNode(schemas.PlanOther)
+		node.Table, node.Index = parseMssqlPlanObject(record["argument"])
+		node.Rows = parsePlanRows(record["estimaterows"])
+		node.Detail = record["physicalop"]
+
+		switch physicalOp := record["physicalop"]; {
+		case physicalOp == "Table Scan" || physicalOp == "Clustered Index Scan":
+			node.Type = schemas.PlanTableScan
+		case physicalOp == "Index Scan":
+			node.Type = schemas.PlanIndexScan
+		case strings.HasSuffix(physicalOp, "Seek") || strings.HasSuffix(physicalOp, "Lookup"):
+			node.Type = schemas.PlanIndexLookup
+		case strings.Contains(record["logicalop"], "Join"):
+			node.Type = schemas.PlanJoin
+		case strings.HasSuffix(physicalOp, "Sort"):
+			node.Type = schemas.PlanSort
+		case strings.HasSuffix(physicalOp, "Aggregate") || strings.Contains(record["logicalop"], "Aggregate"):
+			node.Type = schemas.PlanAggregate
+		}
+
+		parent, ok := nodes[record["parent"]]
+		if !ok {
+			parent = root
+		}
+		parent.AddChild(node)
+		nodes[record["nodeid"]] = node
+	}
+	return root, nil
+}
+
+// JSONContains implements Dialect
+func (db *mssql) JSONContains(col string, doc interface{}) (string, []interface{}, error) {
+	return jsonContainsByPaths(db, col, doc, func(col string, path *JSONPath) string {
+		return fmt.Sprintf("EXISTS (SELECT 1 FROM OPENJSON(%s, %s) WHERE [value] = ?)", col, path.Literal())
+	})
+}
+
+// JSONSet implements Dialect
+func (db *mssql) JSONSet(col string, path *JSONPath, value interface{}) (string, []interface{}, error) {
+	s, err := marshalJSONValue(value)
+	if err != nil {
+		return "", nil, err
+	}
+	if isJSONContainer(s) {
+		return fmt.Sprintf("JSON_MODIFY(%s, %s, JSON_QUERY(?))", col, path.Literal()), []interface{}{s}, nil
+	}
+	return fmt.Sprintf("JSON_MODIFY(%s, %s, ?)", col, path.Literal()), []interface{}{value}, nil
+}
+
 func (db *mssql) Filters() []Filter {
 	return []Filter{}
 }
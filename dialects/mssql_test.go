@@ -31,3 +31,22 @@ func TestParseMSSQL(t *testing.T) {
 		}
 	}
 }
+
+func TestParseMssqlPlanObject(t *testing.T) {
+	var kases = []struct {
+		argument string
+		table    string
+		index    string
+	}{
+		{"OBJECT:([xorm_test].[dbo].[user].[IDX_user_name]), SEEK:([xorm_test].[dbo].[user].[name]=[@1]) ORDERED FORWARD", "user", "IDX_user_name"},
+		{"OBJECT:([xorm_test].[dbo].[user] AS [u])", "user", ""},
+		{"WHERE:([Expr1002]>(5))", "", ""},
+	}
+
+	for _, kase := range kases {
+		table, index := parseMssqlPlanObject(kase.argument)
+		if table != kase.table || index != kase.index {
+			t.Errorf("%q got: %s %s want: %s %s", kase.argument, table, index, kase.table, kase.index)
+		}
+	}
+}
@@ -336,9 +336,17 @@ func (db *mysql) IndexCheckSQL(tableName, idxName string) (string, []interface{}
 	return sql, args
 }
 
+// contextDBName returns the database of the context or the database of the URI
+func (db *mysql) contextDBName(ctx context.Context) string {
+	if schema := SchemaFromContext(ctx); schema != "" {
+		return schema
+	}
+	return db.uri.DBName
+}
+
 func (db *mysql) IsTableExist(queryer core.Queryer, ctx context.Context, tableName string) (bool, error) {
 	sql := "SELECT `TABLE_NAME` from `INFORMATION_SCHEMA`.`TABLES` WHERE `TABLE_SCHEMA`=? and `TABLE_NAME`=?"
-	return db.HasRecords(queryer, ctx, sql, db.uri.DBName, tableName)
+	return db.HasRecords(queryer, ctx, sql, db.contextDBName(ctx), tableName)
 }
 
 func (db *mysql) AddColumnSQL(tableName string, col *schemas.Column) string {
@@ -352,17 +360,26 @@ func (db *mysql) AddColumnSQL(tableName string, col *schemas.Column) string {
 }
 
 func (db *mysql) GetColumns(queryer core.Queryer, ctx context.Context, tableName string) ([]string, map[string]*schemas.Column, error) {
-	args := []interface{}{db.uri.DBName, tableName}
+	args := []interface{}{db.contextDBName(ctx), tableName}
 	alreadyQuoted := "(INSTR(VERSION(), 'maria') > 0 && " +
 		"(SUBSTRING_INDEX(VERSION(), '.', 1) > 10 || " +
 		"(SUBSTRING_INDEX(VERSION(), '.', 1) = 10 && " +
 		"(SUBSTRING_INDEX(SUBSTRING(VERSION(), 4), '.', 1) > 2 || " +
 		"(SUBSTRING_INDEX(SUBSTRING(VERSION(), 4), '.', 1) = 2 && " +
 		"SUBSTRING_INDEX(SUBSTRING(VERSION(), 6), '-', 1) >= 7)))))"
+	version, err := db.serverVersion(queryer, ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+	// the expressions of the generated columns are there since 5.7.6
+	generated := "''"
+	if isMariaDB(version) || isMySQLAtLeast(version, 5, 7, 6) {
+		generated = "IFNULL(`GENERATION_EXPRESSION`, '')"
+	}
 	s := "SELECT `COLUMN_NAME`, `IS_NULLABLE`, `COLUMN_DEFAULT`, `COLUMN_TYPE`," +
 		" `COLUMN_KEY`, `EXTRA`, `COLUMN_COMMENT`, " +
-		alreadyQuoted + " AS NEEDS_QUOTE " +
-		"FROM `INFORMATION_SCHEMA`.`COLUMNS` WHERE `TABLE_SCHEMA` = ? AND `TABLE_NAME` = ?" +
+		alreadyQuoted + " AS NEEDS_QUOTE, " + generated +
+		" FROM `INFORMATION_SCHEMA`.`COLUMNS` WHERE `TABLE_SCHEMA` = ? AND `TABLE_NAME` = ?" +
 		" ORDER BY `COLUMNS`.ORDINAL_POSITION"
 
 	rows, err := queryer.QueryContext(ctx, s, args...)
@@ -377,10 +394,10 @@ func (db *mysql) GetColumns(queryer core.Queryer, ctx context.Context, tableName
 		col := new(schemas.Column)
 		col.Indexes = make(map[string]int)
 
-		var columnName, nullableStr, colType, colKey, extra, comment string
+		var columnName, nullableStr, colType, colKey, extra, comment, generated string
 		var alreadyQuoted, isUnsigned bool
 		var colDefault *string
-		err = rows.Scan(&columnName, &nullableStr, &colDefault, &colType, &colKey, &extra, &comment, &alreadyQuoted)
+		err = rows.Scan(&columnName, &nullableStr, &colDefault, &colType, &colKey, &extra, &comment, &alreadyQuoted, &generated)
 		if err != nil {
 			return nil, nil, err
 		}
@@ -461,6 +478,11 @@ func (db *mysql) GetColumns(queryer core.Queryer, ctx context.Context, tableName
 		if extra == "auto_increment" {
 			col.IsAutoIncrement = true
 		}
+		if generated != "" {
+			col.Generated = generated
+			col.GeneratedStored = strings.Contains(strings.ToUpper(extra), "STORED") ||
+				strings.Contains(strings.ToUpper(extra), "PERSISTENT")
+		}
 
 		if !col.DefaultIsEmpty {
 			if !alreadyQuoted && col.SQLType.IsText() {
@@ -476,7 +498,7 @@ func (db *mysql) GetColumns(queryer core.Queryer, ctx context.Context, tableName
 }
 
 func (db *mysql) GetTables(queryer core.Queryer, ctx context.Context) ([]*schemas.Table, error) {
-	args := []interface{}{db.uri.DBName}
+	args := []interface{}{db.contextDBName(ctx)}
 	s := "SELECT `TABLE_NAME`, `ENGINE`, `AUTO_INCREMENT`, `TABLE_COMMENT` from " +
 		"`INFORMATION_SCHEMA`.`TABLES` WHERE `TABLE_SCHEMA`=? AND (`ENGINE`='MyISAM' OR `ENGINE` = 'InnoDB' OR `ENGINE` = 'TokuDB')"
 
@@ -506,6 +528,12 @@ func (db *mysql) GetTables(queryer core.Queryer, ctx context.Context) ([]*schema
 	return tables, nil
 }
 
+// GetViews returns the views, MySQL has no materialized views
+func (db *mysql) GetViews(queryer core.Queryer, ctx context.Context) ([]*schemas.Table, error) {
+	s := "SELECT `TABLE_NAME`, 0 FROM `INFORMATION_SCHEMA`.`VIEWS` WHERE `TABLE_SCHEMA`=?"
+	return queryViews(queryer, ctx, s, db.contextDBName(ctx))
+}
+
 func (db *mysql) SetQuotePolicy(quotePolicy QuotePolicy) {
 	switch quotePolicy {
 	case QuotePolicyNone:
@@ -523,9 +551,107 @@ func (db *mysql) SetQuotePolicy(quotePolicy QuotePolicy) {
 	}
 }
 
+// serverVersion returns the version of the server, it's queried once
+func (db *mysql) serverVersion(queryer core.Queryer, ctx context.Context) (*schemas.Version, error) {
+	if version := db.ServerVersion(); version != nil {
+		return version, nil
+	}
+	version, err := db.Version(ctx, queryer)
+	if err != nil {
+		return nil, err
+	}
+	db.SetVersion(version)
+	return version, nil
+}
+
+// isMariaDB returns true if the version is the one of MariaDB
+func isMariaDB(v *schemas.Version) bool {
+	return strings.Contains(v.Edition, "MariaDB") || v.Major() >= 10
+}
+
+// isMySQLAtLeast returns true if the server is MySQL but not MariaDB or TiDB,
+// and its version is the given one or later
+func isMySQLAtLeast(v *schemas.Version, major, minor, patch int) bool {
+	if isMariaDB(v) || v.Edition == "TiDB" {
+		return false
+	}
+	var n [3]int
+	fmt.Sscanf(v.Number, "%d.%d.%d", &n[0], &n[1], &n[2])
+	for i, m := range []int{major, minor, patch} {
+		if n[i] != m {
+			return n[i] > m
+		}
+	}
+	return true
+}
+
+// GetChecks returns the check constraints of the table, it returns nil if the
+// server ignores them, i.e. MySQL before 8.0.16 or TiDB
+func (db *mysql) GetChecks(queryer core.Queryer, ctx context.Context, tableName string) (map[string]*schemas.Check, error) {
+	version, err := db.serverVersion(queryer, ctx)
+	if err != nil {
+		return nil, err
+	}
+	if !isMariaDB(version) && !isMySQLAtLeast(version, 8, 0, 16) {
+		return nil, nil
+	}
+
+	args := []interface{}{db.contextDBName(ctx), tableName}
+	s := "SELECT tc.`CONSTRAINT_NAME`, cc.`CHECK_CLAUSE` FROM `INFORMATION_SCHEMA`.`TABLE_CONSTRAINTS` tc " +
+		"JOIN `INFORMATION_SCHEMA`.`CHECK_CONSTRAINTS` cc ON cc.`CONSTRAINT_SCHEMA` = tc.`CONSTRAINT_SCHEMA` " +
+		"AND cc.`CONSTRAINT_NAME` = tc.`CONSTRAINT_NAME` " +
+		"WHERE tc.`CONSTRAINT_TYPE` = 'CHECK' AND tc.`TABLE_SCHEMA` = ? AND tc.`TABLE_NAME` = ?"
+
+	rows, err := queryer.QueryContext(ctx, s, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	checks := make(map[string]*schemas.Check)
+	for rows.Next() {
+		var name, clause string
+		if err = rows.Scan(&name, &clause); err != nil {
+			return nil, err
+		}
+		checks[name] = schemas.NewCheck(name, clause)
+	}
+	return checks, rows.Err()
+}
+
+// CreateSequenceSQL returns empty since MySQL has no sequences
+func (db *mysql) CreateSequenceSQL(seq *schemas.Sequence) string {
+	return ""
+}
+
+// DropSequenceSQL returns empty since MySQL has no sequences
+func (db *mysql) DropSequenceSQL(seqName string) string {
+	return ""
+}
+
+// NextSequenceSQL returns empty since MySQL has no sequences
+func (db *mysql) NextSequenceSQL(seqName string) string {
+	return ""
+}
+
+// RestartSequenceSQL returns empty since MySQL has no sequences
+func (db *mysql) RestartSequenceSQL(seqName string, start int64) string {
+	return ""
+}
+
 func (db *mysql) GetIndexes(queryer core.Queryer, ctx context.Context, tableName string) (map[string]*schemas.Index, error) {
-	args := []interface{}{db.uri.DBName, tableName}
-	s := "SELECT `INDEX_NAME`, `NON_UNIQUE`, `COLUMN_NAME` FROM `INFORMATION_SCHEMA`.`STATISTICS` WHERE `TABLE_SCHEMA` = ? AND `TABLE_NAME` = ?"
+	version, err := db.serverVersion(queryer, ctx)
+	if err != nil {
+		return nil, err
+	}
+	// the functional key parts have expressions but no columns since 8.0.13
+	colExpr := "`COLUMN_NAME`"
+	if isMySQLAtLeast(version, 8, 0, 13) {
+		colExpr = "IFNULL(`COLUMN_NAME`, `EXPRESSION`)"
+	}
+
+	args := []interface{}{db.contextDBName(ctx), tableName}
+	s := "SELECT `INDEX_NAME`, `NON_UNIQUE`, " + colExpr + " FROM `INFORMATION_SCHEMA`.`STATISTICS` WHERE `TABLE_SCHEMA` = ? AND `TABLE_NAME` = ?"
 
 	rows, err := queryer.QueryContext(ctx, s, args...)
 	if err != nil {
@@ -604,6 +730,10 @@ func (db *mysql) CreateTableSQL(table *schemas.Table, tableName string) ([]strin
 			sql += " ), "
 		}
 
+		for _, check := range TableChecks(db, table) {
+			sql += CheckString(db, tableName, check) + ", "
+		}
+
 		sql = sql[:len(sql)-2]
 	}
 	sql += ")"
@@ -626,6 +756,57 @@ func (db *mysql) CreateTableSQL(table *schemas.Table, tableName string) ([]strin
 	return []string{sql}, true
 }
 
+// Explain returns the query plan reported by EXPLAIN
+func (db *mysql) Explain(queryer core.Queryer, ctx context.Context, query string, args ...interface{}) (*schemas.PlanNode, error) {
+	records, err := queryStrings(queryer, ctx, "EXPLAIN "+query, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	root := schemas.NewPlanNode(schemas.PlanQuery)
+	for _, record := range records {
+		node := schemas.NewPlanNode(schemas.PlanOther)
+		node.Table = record["table"]
+		node.Index = record["key"]
+		node.Rows = parsePlanRows(record["rows"])
+		node.Detail = record["extra"]
+
+		switch record["type"] {
+		case "ALL":
+			node.Type = schemas.PlanTableScan
+		case "index", "range", "index_merge":
+			node.Type = schemas.PlanIndexScan
+		case "const", "system", "eq_ref", "ref", "ref_or_null", "fulltext", "unique_subquery", "index_subquery":
+			node.Type = schemas.PlanIndexLookup
+		}
+		root.AddChild(node)
+	}
+	return root, nil
+}
+
+// JSONExtract implements Dialect
+func (db *mysql) JSONExtract(col string, path *JSONPath) string {
+	return fmt.Sprintf("JSON_UNQUOTE(JSON_EXTRACT(%s, %s))", col, path.Literal())
+}
+
+// JSONContains implements Dialect
+func (db *mysql) JSONContains(col string, doc interface{}) (string, []interface{}, error) {
+	s, err := marshalJSONDoc(doc)
+	if err != nil {
+		return "", nil, err
+	}
+	return fmt.Sprintf("JSON_CONTAINS(%s, ?)", col), []interface{}{s}, nil
+}
+
+// JSONSet implements Dialect
+func (db *mysql) JSONSet(col string, path *JSONPath, value interface{}) (string, []interface{}, error) {
+	s, err := marshalJSONValue(value)
+	if err != nil {
+		return "", nil, err
+	}
+	return fmt.Sprintf("JSON_SET(%s, %s, JSON_EXTRACT(?, '$'))", col, path.Literal()), []interface{}{s}, nil
+}
+
 func (db *mysql) Filters() []Filter {
 	return []Filter{}
 }
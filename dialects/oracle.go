@@ -6,6 +6,7 @@ package dialects
 
 import (
 	"context"
+	"database/sql"
 	"errors"
 	"fmt"
 	"regexp"
@@ -552,6 +553,11 @@ func (db *oracle) SQLType(c *schemas.Column) string {
 		res = "CLOB"
 	case schemas.Char, schemas.Varchar, schemas.TinyText:
 		res = "VARCHAR2"
+	case schemas.Enum, schemas.Set:
+		res = "VARCHAR2"
+		if c.Length == 0 {
+			c.Length = 255
+		}
 	default:
 		res = t
 	}
@@ -609,6 +615,10 @@ func (db *oracle) CreateTableSQL(table *schemas.Table, tableName string) ([]stri
 		sql += " ), "
 	}
 
+	for _, check := range TableChecks(db, table) {
+		sql += CheckString(db, tableName, check) + ", "
+	}
+
 	sql = sql[:len(sql)-2] + ")"
 	return []string{sql}, false
 }
@@ -650,7 +660,7 @@ func (db *oracle) IsColumnExist(queryer core.Queryer, ctx context.Context, table
 func (db *oracle) GetColumns(queryer core.Queryer, ctx context.Context, tableName string) ([]string, map[string]*schemas.Column, error) {
 	args := []interface{}{tableName}
 	s := "SELECT column_name,data_default,data_type,data_length,data_precision,data_scale," +
-		"nullable FROM USER_TAB_COLUMNS WHERE table_name = :1"
+		"nullable,virtual_column FROM USER_TAB_COLS WHERE table_name = :1 AND hidden_column = 'NO'"
 
 	rows, err := queryer.QueryContext(ctx, s, args...)
 	if err != nil {
@@ -664,17 +674,22 @@ func (db *oracle) GetColumns(queryer core.Queryer, ctx context.Context, tableNam
 		col := new(schemas.Column)
 		col.Indexes = make(map[string]int)
 
-		var colName, colDefault, nullable, dataType, dataPrecision, dataScale *string
+		var colName, colDefault, nullable, dataType, dataPrecision, dataScale, virtual *string
 		var dataLen int
 
 		err = rows.Scan(&colName, &colDefault, &dataType, &dataLen, &dataPrecision,
-			&dataScale, &nullable)
+			&dataScale, &nullable, &virtual)
 		if err != nil {
 			return nil, nil, err
 		}
 
 		col.Name = strings.Trim(*colName, `" `)
-		if colDefault != nil {
+		if virtual != nil && *virtual == "YES" {
+			// the expression of the virtual column is the default
+			if colDefault != nil {
+				col.Generated = strings.TrimSpace(*colDefault)
+			}
+		} else if colDefault != nil {
 			col.Default = *colDefault
 			col.DefaultIsEmpty = false
 		}
@@ -746,7 +761,8 @@ func (db *oracle) GetColumns(queryer core.Queryer, ctx context.Context, tableNam
 
 func (db *oracle) GetTables(queryer core.Queryer, ctx context.Context) ([]*schemas.Table, error) {
 	args := []interface{}{}
-	s := "SELECT table_name FROM user_tables"
+	// the materialized views have the container tables of the same names
+	s := "SELECT table_name FROM user_tables WHERE table_name NOT IN (SELECT mview_name FROM user_mviews)"
 
 	rows, err := queryer.QueryContext(ctx, s, args...)
 	if err != nil {
@@ -767,10 +783,87 @@ func (db *oracle) GetTables(queryer core.Queryer, ctx context.Context) ([]*schem
 	return tables, nil
 }
 
+// GetViews returns the views and the materialized views with their comments
+func (db *oracle) GetViews(queryer core.Queryer, ctx context.Context) ([]*schemas.Table, error) {
+	return queryViews(queryer, ctx, "SELECT view_name, 0, NULL FROM user_views UNION ALL "+
+		"SELECT m.mview_name, 1, c.comments FROM user_mviews m LEFT JOIN user_mview_comments c ON c.mview_name = m.mview_name")
+}
+
+// GetChecks returns the named check constraints of the table, the NOT NULL
+// constraints named by the system are ignored
+func (db *oracle) GetChecks(queryer core.Queryer, ctx context.Context, tableName string) (map[string]*schemas.Check, error) {
+	args := []interface{}{tableName}
+	s := "SELECT constraint_name, search_condition FROM user_constraints " +
+		"WHERE constraint_type = 'C' AND generated = 'USER NAME' AND table_name = :1"
+
+	rows, err := queryer.QueryContext(ctx, s, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	checks := make(map[string]*schemas.Check)
+	for rows.Next() {
+		var name, cond string
+		if err = rows.Scan(&name, &cond); err != nil {
+			return nil, err
+		}
+		checks[name] = schemas.NewCheck(name, cond)
+	}
+	return checks, rows.Err()
+}
+
+// GetSequences returns the names of the sequences of the schema of the context
+// or the sequences of the user
+func (db *oracle) GetSequences(queryer core.Queryer, ctx context.Context) ([]string, error) {
+	if schema := SchemaFromContext(ctx); schema != "" {
+		return querySequences(queryer, ctx, "SELECT sequence_name FROM all_sequences WHERE sequence_owner = :1", schema)
+	}
+	return querySequences(queryer, ctx, "SELECT sequence_name FROM user_sequences")
+}
+
+// NextSequenceSQL returns a SQL to fetch the next value of a sequence
+func (db *oracle) NextSequenceSQL(seqName string) string {
+	return fmt.Sprintf("SELECT %s.NEXTVAL FROM DUAL", db.Quoter().Quote(seqName))
+}
+
+// RestartSequenceSQL returns a SQL to restart a sequence from the value
+func (db *oracle) RestartSequenceSQL(seqName string, start int64) string {
+	return fmt.Sprintf("ALTER SEQUENCE %s RESTART START WITH %d", db.Quoter().Quote(seqName), start)
+}
+
+// CreateViewSQL returns the SQLs to create or replace a view, the materialized
+// views couldn't be replaced and they're commented by ViewComment
+func (db *oracle) CreateViewSQL(viewName, query string, materialized bool) []string {
+	if !materialized {
+		return db.Base.CreateViewSQL(viewName, query, materialized)
+	}
+	quotedName := db.Quoter().Quote(viewName)
+	return []string{
+		fmt.Sprintf("CREATE MATERIALIZED VIEW %s AS %s", quotedName, query),
+		fmt.Sprintf("COMMENT ON MATERIALIZED VIEW %s IS '%s'", quotedName, ViewComment(query)),
+	}
+}
+
+// DropViewSQL returns a SQL to drop a view
+func (db *oracle) DropViewSQL(viewName string, materialized bool) string {
+	if materialized {
+		return "DROP MATERIALIZED VIEW " + db.Quoter().Quote(viewName)
+	}
+	return "DROP VIEW " + db.Quoter().Quote(viewName)
+}
+
+// RefreshViewSQL returns a SQL to refresh a materialized view
+func (db *oracle) RefreshViewSQL(viewName string) string {
+	return fmt.Sprintf("BEGIN DBMS_MVIEW.REFRESH('%s'); END;", db.Quoter().Quote(viewName))
+}
+
 func (db *oracle) GetIndexes(queryer core.Queryer, ctx context.Context, tableName string) (map[string]*schemas.Index, error) {
 	args := []interface{}{tableName}
-	s := "SELECT t.column_name,i.uniqueness,i.index_name FROM user_ind_columns t,user_indexes i " +
-		"WHERE t.index_name = i.index_name and t.table_name = i.table_name and t.table_name =:1"
+	s := "SELECT t.column_name,i.uniqueness,i.index_name,e.column_expression FROM user_ind_columns t " +
+		"JOIN user_indexes i ON t.index_name = i.index_name and t.table_name = i.table_name " +
+		"LEFT JOIN user_ind_expressions e ON e.index_name = t.index_name and e.column_position = t.column_position " +
+		"WHERE t.table_name =:1"
 
 	rows, err := queryer.QueryContext(ctx, s, args...)
 	if err != nil {
@@ -782,11 +875,16 @@ func (db *oracle) GetIndexes(queryer core.Queryer, ctx context.Context, tableNam
 	for rows.Next() {
 		var indexType int
 		var indexName, colName, uniqueness string
+		var expr *string
 
-		err = rows.Scan(&colName, &uniqueness, &indexName)
+		err = rows.Scan(&colName, &uniqueness, &indexName, &expr)
 		if err != nil {
 			return nil, err
 		}
+		// the key part of a function-based index is a hidden column
+		if expr != nil {
+			colName = *expr
+		}
 
 		indexName = strings.Trim(indexName, `" `)
 
@@ -816,6 +914,91 @@ func (db *oracle) GetIndexes(queryer core.Queryer, ctx context.Context, tableNam
 	return indexes, nil
 }
 
+// Explain returns the query plan reported by EXPLAIN PLAN
+func (db *oracle) Explain(queryer core.Queryer, ctx context.Context, query string, args ...interface{}) (*schemas.PlanNode, error) {
+	const statementID = "xorm_explain"
+	var records []map[string]string
+	err := withConn(queryer, ctx, func(conn interface {
+		ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+		QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+	}) error {
+		if _, err := conn.ExecContext(ctx, "DELETE FROM PLAN_TABLE WHERE STATEMENT_ID = :1", statementID); err != nil {
+			return err
+		}
+		defer conn.ExecContext(ctx, "DELETE FROM PLAN_TABLE WHERE STATEMENT_ID = :1", statementID)
+
+		// bind variables of EXPLAIN PLAN are not evaluated, so args are not needed
+		if _, err := conn.ExecContext(ctx, fmt.Sprintf("EXPLAIN PLAN SET STATEMENT_ID = '%s' FOR %s", statementID, query)); err != nil {
+			return err
+		}
+		rows, err := conn.QueryContext(ctx, "SELECT ID, PARENT_ID, OPERATION, OPTIONS, OBJECT_NAME, OBJECT_TYPE, CARDINALITY FROM PLAN_TABLE WHERE STATEMENT_ID = :1 ORDER BY ID", statementID)
+		if err != nil {
+			return err
+		}
+		defer rows.Close()
+		records, err = rows2Strings(rows)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	root := schemas.NewPlanNode(schemas.PlanQuery)
+	nodes := make(map[string]*schemas.PlanNode)
+	for _, record := range records {
+		operation, options := record["operation"], record["options"]
+		node := schemas.NewPlanNode(schemas.PlanOther)
+		node.Rows = parsePlanRows(record["cardinality"])
+		node.Detail = strings.TrimSpace(operation + " " + options)
+
+		switch {
+		case operation == "TABLE ACCESS":
+			node.Table = record["object_name"]
+			if options == "FULL" {
+				node.Type = schemas.PlanTableScan
+			}
+		case operation == "INDEX":
+			node.Index = record["object_name"]
+			if options == "UNIQUE SCAN" || options == "RANGE SCAN" {
+				node.Type = schemas.PlanIndexLookup
+			} else {
+				node.Type = schemas.PlanIndexScan
+			}
+		case strings.Contains(operation, "JOIN") || operation == "NESTED LOOPS":
+			node.Type = schemas.PlanJoin
+		case operation == "SORT" && options == "ORDER BY":
+			node.Type = schemas.PlanSort
+		case operation == "SORT" || strings.Contains(options, "GROUP BY"):
+			node.Type = schemas.PlanAggregate
+		}
+
+		parent, ok := nodes[record["parent_id"]]
+		if !ok {
+			parent = root
+		}
+		parent.AddChild(node)
+		nodes[record["id"]] = node
+	}
+	return root, nil
+}
+
+// JSONContains implements Dialect, the elements of the arrays are matched by
+// the filter expressions of JSON_EXISTS
+func (db *oracle) JSONContains(col string, doc interface{}) (string, []interface{}, error) {
+	return jsonContainsByPaths(db, col, doc, func(col string, path *JSONPath) string {
+		return fmt.Sprintf(`JSON_EXISTS(%s, '%s[*]?(@ == $v)' PASSING ? AS "v")`, col, path.String())
+	})
+}
+
+// JSONSet implements Dialect
+func (db *oracle) JSONSet(col string, path *JSONPath, value interface{}) (string, []interface{}, error) {
+	s, err := marshalJSONValue(value)
+	if err != nil {
+		return "", nil, err
+	}
+	return fmt.Sprintf("JSON_TRANSFORM(%s, SET %s = ? FORMAT JSON)", col, path.Literal()), []interface{}{s}, nil
+}
+
 func (db *oracle) Filters() []Filter {
 	return []Filter{
 		&SeqFilter{Prefix: ":", Start: 1},
@@ -6,9 +6,11 @@ package dialects
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"net/url"
+	"reflect"
 	"strconv"
 	"strings"
 
@@ -831,6 +833,14 @@ func (db *postgres) getSchema() string {
 	return DefaultPostgresSchema
 }
 
+// contextSchema returns the schema of the context or the schema of the URI
+func (db *postgres) contextSchema(ctx context.Context) string {
+	if schema := SchemaFromContext(ctx); schema != "" {
+		return schema
+	}
+	return db.getSchema()
+}
+
 func (db *postgres) needQuote(name string) bool {
 	if db.IsReserved(name) {
 		return true
@@ -898,6 +908,9 @@ func (db *postgres) SQLType(c *schemas.Column) string {
 		res = schemas.Real
 	case schemas.TinyText, schemas.MediumText, schemas.LongText:
 		res = schemas.Text
+	case schemas.Enum, schemas.Set:
+		// the options of the enum are checked by a check constraint
+		return schemas.Text
 	case schemas.NChar:
 		res = schemas.Char
 	case schemas.NVarchar:
@@ -908,6 +921,12 @@ func (db *postgres) SQLType(c *schemas.Column) string {
 		return schemas.Bytea
 	case schemas.Double:
 		return "DOUBLE PRECISION"
+	case schemas.Array:
+		elem := schemas.Column{SQLType: c.ArrayElemType}
+		if elem.SQLType.Name == "" {
+			elem.SQLType.Name = schemas.Text
+		}
+		return db.SQLType(&elem) + "[]"
 	default:
 		if c.IsAutoIncrement {
 			return schemas.Serial
@@ -967,6 +986,10 @@ func (db *postgres) CreateTableSQL(table *schemas.Table, tableName string) ([]st
 			sql += " ), "
 		}
 
+		for _, check := range TableChecks(db, table) {
+			sql += CheckString(db, tableName, check) + ", "
+		}
+
 		sql = sql[:len(sql)-2]
 	}
 	sql += ")"
@@ -986,12 +1009,13 @@ func (db *postgres) IndexCheckSQL(tableName, idxName string) (string, []interfac
 }
 
 func (db *postgres) IsTableExist(queryer core.Queryer, ctx context.Context, tableName string) (bool, error) {
-	if len(db.getSchema()) == 0 {
+	schema := db.contextSchema(ctx)
+	if len(schema) == 0 {
 		return db.HasRecords(queryer, ctx, `SELECT tablename FROM pg_tables WHERE tablename = $1`, tableName)
 	}
 
 	return db.HasRecords(queryer, ctx, `SELECT tablename FROM pg_tables WHERE schemaname = $1 AND tablename = $2`,
-		db.getSchema(), tableName)
+		schema, tableName)
 }
 
 func (db *postgres) ModifyColumnSQL(tableName string, col *schemas.Column) string {
@@ -1017,17 +1041,21 @@ func (db *postgres) DropIndexSQL(tableName string, index *schemas.Index) string
 			idxName = fmt.Sprintf("IDX_%v_%v", tableName, index.Name)
 		}
 	}
-	if db.getSchema() != "" {
+	// the index is in the schema of the table
+	if len(tableParts) > 1 {
+		idxName = tableParts[len(tableParts)-2] + "." + idxName
+	} else if db.getSchema() != "" {
 		idxName = db.getSchema() + "." + idxName
 	}
 	return fmt.Sprintf("DROP INDEX %v", db.Quoter().Quote(idxName))
 }
 
 func (db *postgres) IsColumnExist(queryer core.Queryer, ctx context.Context, tableName, colName string) (bool, error) {
-	args := []interface{}{db.getSchema(), tableName, colName}
+	schema := db.contextSchema(ctx)
+	args := []interface{}{schema, tableName, colName}
 	query := "SELECT column_name FROM INFORMATION_SCHEMA.COLUMNS WHERE table_schema = $1 AND table_name = $2" +
 		" AND column_name = $3"
-	if len(db.getSchema()) == 0 {
+	if len(schema) == 0 {
 		args = []interface{}{tableName, colName}
 		query = "SELECT column_name FROM INFORMATION_SCHEMA.COLUMNS WHERE table_name = $1" +
 			" AND column_name = $2"
@@ -1042,11 +1070,51 @@ func (db *postgres) IsColumnExist(queryer core.Queryer, ctx context.Context, tab
 	return rows.Next(), nil
 }
 
+// postgresArrayElemType returns the element type of an array by its udt name, i.e. _int8
+func postgresArrayElemType(udtName string) schemas.SQLType {
+	switch strings.TrimPrefix(udtName, "_") {
+	case "int2":
+		return schemas.SQLType{Name: schemas.SmallInt}
+	case "int4":
+		return schemas.SQLType{Name: schemas.Integer}
+	case "int8":
+		return schemas.SQLType{Name: schemas.BigInt}
+	case "float4":
+		return schemas.SQLType{Name: schemas.Real}
+	case "float8":
+		return schemas.SQLType{Name: schemas.Double}
+	case "numeric":
+		return schemas.SQLType{Name: schemas.Numeric}
+	case "bool":
+		return schemas.SQLType{Name: schemas.Bool}
+	case "varchar":
+		return schemas.SQLType{Name: schemas.Varchar}
+	case "bpchar":
+		return schemas.SQLType{Name: schemas.Char}
+	case "timestamp":
+		return schemas.SQLType{Name: schemas.DateTime}
+	case "timestamptz":
+		return schemas.SQLType{Name: schemas.TimeStampz}
+	case "date":
+		return schemas.SQLType{Name: schemas.Date}
+	case "uuid":
+		return schemas.SQLType{Name: schemas.Uuid}
+	case "jsonb":
+		return schemas.SQLType{Name: schemas.Jsonb}
+	}
+	return schemas.SQLType{Name: schemas.Text}
+}
+
 func (db *postgres) GetColumns(queryer core.Queryer, ctx context.Context, tableName string) ([]string, map[string]*schemas.Column, error) {
 	args := []interface{}{tableName}
-	s := `SELECT column_name, column_default, is_nullable, data_type, character_maximum_length, description,
+	// the columns of the materialized views are not in INFORMATION_SCHEMA
+	s := `SELECT f.attname AS column_name, s.column_default,
+    COALESCE(s.is_nullable, CASE WHEN f.attnotnull THEN 'NO' ELSE 'YES' END) AS is_nullable,
+    COALESCE(s.data_type, format_type(f.atttypid, NULL)) AS data_type, COALESCE(s.udt_name, t.typname) AS udt_name,
+    s.character_maximum_length, description,
     CASE WHEN p.contype = 'p' THEN true ELSE false END AS primarykey,
-    CASE WHEN p.contype = 'u' THEN true ELSE false END AS uniquekey
+    CASE WHEN p.contype = 'u' THEN true ELSE false END AS uniquekey,
+    s.generation_expression
 FROM pg_attribute f
     JOIN pg_class c ON c.oid = f.attrelid JOIN pg_type t ON t.oid = f.atttypid
     LEFT JOIN pg_attrdef d ON d.adrelid = c.oid AND d.adnum = f.attnum
@@ -1054,12 +1122,12 @@ FROM pg_attribute f
     LEFT JOIN pg_namespace n ON n.oid = c.relnamespace
     LEFT JOIN pg_constraint p ON p.conrelid = c.oid AND f.attnum = ANY (p.conkey)
     LEFT JOIN pg_class AS g ON p.confrelid = g.oid
-    LEFT JOIN INFORMATION_SCHEMA.COLUMNS s ON s.column_name=f.attname AND c.relname=s.table_name
-WHERE n.nspname= s.table_schema AND c.relkind = 'r'::char AND c.relname = $1%s AND f.attnum > 0 ORDER BY f.attnum;`
+    LEFT JOIN INFORMATION_SCHEMA.COLUMNS s ON s.column_name=f.attname AND c.relname=s.table_name AND s.table_schema = n.nspname
+WHERE c.relkind IN ('r', 'v', 'm') AND c.relname = $1%s AND f.attnum > 0 AND NOT f.attisdropped ORDER BY f.attnum;`
 
-	schema := db.getSchema()
+	schema := db.contextSchema(ctx)
 	if schema != "" {
-		s = fmt.Sprintf(s, " AND s.table_schema = $2")
+		s = fmt.Sprintf(s, " AND n.nspname = $2")
 		args = append(args, schema)
 	} else {
 		s = fmt.Sprintf(s, "")
@@ -1079,9 +1147,9 @@ WHERE n.nspname= s.table_schema AND c.relkind = 'r'::char AND c.relname = $1%s A
 		col.Indexes = make(map[string]int)
 
 		var colName, isNullable, dataType string
-		var maxLenStr, colDefault, description *string
+		var udtName, maxLenStr, colDefault, description, generated *string
 		var isPK, isUnique bool
-		err = rows.Scan(&colName, &colDefault, &isNullable, &dataType, &maxLenStr, &description, &isPK, &isUnique)
+		err = rows.Scan(&colName, &colDefault, &isNullable, &dataType, &udtName, &maxLenStr, &description, &isPK, &isUnique, &generated)
 		if err != nil {
 			return nil, nil, err
 		}
@@ -1131,6 +1199,12 @@ WHERE n.nspname= s.table_schema AND c.relkind = 'r'::char AND c.relname = $1%s A
 			col.Comment = *description
 		}
 
+		// the generated columns of postgres are always stored
+		if generated != nil && *generated != "" {
+			col.Generated = *generated
+			col.GeneratedStored = true
+		}
+
 		if isPK {
 			col.IsPrimaryKey = true
 		}
@@ -1158,6 +1232,9 @@ WHERE n.nspname= s.table_schema AND c.relkind = 'r'::char AND c.relname = $1%s A
 			col.SQLType = schemas.SQLType{Name: schemas.BigInt, DefaultLength: 0, DefaultLength2: 0}
 		case "array":
 			col.SQLType = schemas.SQLType{Name: schemas.Array, DefaultLength: 0, DefaultLength2: 0}
+			if udtName != nil {
+				col.ArrayElemType = postgresArrayElemType(*udtName)
+			}
 		default:
 			startIdx := strings.Index(strings.ToLower(dataType), "string(")
 			if startIdx != -1 && strings.HasSuffix(dataType, ")") {
@@ -1197,7 +1274,7 @@ WHERE n.nspname= s.table_schema AND c.relkind = 'r'::char AND c.relname = $1%s A
 func (db *postgres) GetTables(queryer core.Queryer, ctx context.Context) ([]*schemas.Table, error) {
 	args := []interface{}{}
 	s := "SELECT tablename FROM pg_tables"
-	schema := db.getSchema()
+	schema := db.contextSchema(ctx)
 	if schema != "" {
 		args = append(args, schema)
 		s = s + " WHERE schemaname = $1"
@@ -1223,22 +1300,116 @@ func (db *postgres) GetTables(queryer core.Queryer, ctx context.Context) ([]*sch
 	return tables, nil
 }
 
+// GetViews returns the views and the materialized views with their comments
+func (db *postgres) GetViews(queryer core.Queryer, ctx context.Context) ([]*schemas.Table, error) {
+	args := []interface{}{}
+	s := "SELECT viewname, false, NULL FROM pg_views%[1]s UNION ALL SELECT matviewname, true, " +
+		"obj_description((quote_ident(schemaname) || '.' || quote_ident(matviewname))::regclass, 'pg_class') FROM pg_matviews%[1]s"
+	if schema := db.contextSchema(ctx); schema != "" {
+		args = append(args, schema)
+		s = fmt.Sprintf(s, " WHERE schemaname = $1")
+	} else {
+		s = fmt.Sprintf(s, " WHERE schemaname NOT IN ('pg_catalog', 'information_schema')")
+	}
+	return queryViews(queryer, ctx, s, args...)
+}
+
 func getIndexColName(indexdef string) []string {
 	var colNames []string
 
-	cs := strings.Split(indexdef, "(")
-	for _, v := range strings.Split(strings.Split(cs[1], ")")[0], ",") {
-		colNames = append(colNames, strings.Split(strings.TrimLeft(v, " "), " ")[0])
+	keys, _ := splitIndexDef(indexdef)
+	for _, key := range keys {
+		colNames = append(colNames, indexKeyName(key))
 	}
 
 	return colNames
 }
 
+// GetChecks returns the check constraints of the table
+func (db *postgres) GetChecks(queryer core.Queryer, ctx context.Context, tableName string) (map[string]*schemas.Check, error) {
+	args := []interface{}{tableName}
+	s := "SELECT c.conname, pg_get_constraintdef(c.oid) FROM pg_constraint c " +
+		"JOIN pg_class t ON t.oid = c.conrelid JOIN pg_namespace n ON n.oid = t.relnamespace " +
+		"WHERE c.contype = 'c' AND t.relname = $1"
+	if schema := db.contextSchema(ctx); len(schema) != 0 {
+		args = append(args, schema)
+		s = s + " AND n.nspname = $2"
+	}
+
+	rows, err := queryer.QueryContext(ctx, s, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	checks := make(map[string]*schemas.Check)
+	for rows.Next() {
+		var name, def string
+		if err = rows.Scan(&name, &def); err != nil {
+			return nil, err
+		}
+		// the definition is like CHECK ((age >= 0))
+		def = strings.TrimSpace(strings.TrimPrefix(def, "CHECK"))
+		def = strings.TrimSuffix(strings.TrimPrefix(def, "("), ")")
+		checks[name] = schemas.NewCheck(name, def)
+	}
+	return checks, rows.Err()
+}
+
+// GetSequences returns the names of the sequences
+func (db *postgres) GetSequences(queryer core.Queryer, ctx context.Context) ([]string, error) {
+	args := []interface{}{}
+	s := "SELECT sequence_name FROM information_schema.sequences"
+	if schema := db.contextSchema(ctx); schema != "" {
+		args = append(args, schema)
+		s += " WHERE sequence_schema = $1"
+	}
+	return querySequences(queryer, ctx, s, args...)
+}
+
+// CreateSequenceSQL returns a SQL to create a sequence if it doesn't exist
+func (db *postgres) CreateSequenceSQL(seq *schemas.Sequence) string {
+	return fmt.Sprintf("CREATE SEQUENCE IF NOT EXISTS %s START WITH %d INCREMENT BY %d",
+		db.Quoter().Quote(seq.Name), seq.Start, seq.Increment)
+}
+
+// NextSequenceSQL returns a SQL to fetch the next value of a sequence
+func (db *postgres) NextSequenceSQL(seqName string) string {
+	return fmt.Sprintf("SELECT nextval('%s')", db.Quoter().Quote(seqName))
+}
+
+// CreateViewSQL returns the SQLs to create or replace a view, the materialized
+// view is commented by ViewComment
+func (db *postgres) CreateViewSQL(viewName, query string, materialized bool) []string {
+	if !materialized {
+		return db.Base.CreateViewSQL(viewName, query, materialized)
+	}
+	quotedName := db.Quoter().Quote(viewName)
+	return []string{
+		"DROP MATERIALIZED VIEW IF EXISTS " + quotedName,
+		fmt.Sprintf("CREATE MATERIALIZED VIEW %s AS %s", quotedName, query),
+		fmt.Sprintf("COMMENT ON MATERIALIZED VIEW %s IS '%s'", quotedName, ViewComment(query)),
+	}
+}
+
+// DropViewSQL returns a SQL to drop a view
+func (db *postgres) DropViewSQL(viewName string, materialized bool) string {
+	if !materialized {
+		return db.Base.DropViewSQL(viewName, materialized)
+	}
+	return "DROP MATERIALIZED VIEW IF EXISTS " + db.Quoter().Quote(viewName)
+}
+
+// RefreshViewSQL returns a SQL to refresh a materialized view
+func (db *postgres) RefreshViewSQL(viewName string) string {
+	return "REFRESH MATERIALIZED VIEW " + db.Quoter().Quote(viewName)
+}
+
 func (db *postgres) GetIndexes(queryer core.Queryer, ctx context.Context, tableName string) (map[string]*schemas.Index, error) {
 	args := []interface{}{tableName}
 	s := fmt.Sprintf("SELECT indexname, indexdef FROM pg_indexes WHERE tablename=$1")
-	if len(db.getSchema()) != 0 {
-		args = append(args, db.getSchema())
+	if schema := db.contextSchema(ctx); len(schema) != 0 {
+		args = append(args, schema)
 		s = s + " AND schemaname=$2"
 	}
 
@@ -1281,18 +1452,122 @@ func (db *postgres) GetIndexes(queryer core.Queryer, ctx context.Context, tableN
 			}
 		}
 
-		index := &schemas.Index{Name: indexName, Type: indexType, Cols: make([]string, 0)}
-		for _, colName := range colNames {
-			col := strings.TrimSpace(strings.Replace(colName, `"`, "", -1))
-			fields := strings.Split(col, " ")
-			index.Cols = append(index.Cols, fields[0])
-		}
+		_, where := splitIndexDef(indexdef)
+		index := &schemas.Index{Name: indexName, Type: indexType, Cols: colNames, Where: where}
 		index.IsRegular = isRegular
 		indexes[index.Name] = index
 	}
 	return indexes, nil
 }
 
+type postgresPlan struct {
+	NodeType     string         `json:"Node Type"`
+	RelationName string         `json:"Relation Name"`
+	IndexName    string         `json:"Index Name"`
+	PlanRows     float64        `json:"Plan Rows"`
+	Plans        []postgresPlan `json:"Plans"`
+}
+
+func (plan *postgresPlan) toNode() *schemas.PlanNode {
+	node := schemas.NewPlanNode(schemas.PlanOther)
+	node.Table = plan.RelationName
+	node.Index = plan.IndexName
+	node.Rows = int64(plan.PlanRows)
+	node.Detail = plan.NodeType
+
+	switch plan.NodeType {
+	case "Seq Scan":
+		node.Type = schemas.PlanTableScan
+	case "Index Scan", "Index Only Scan", "Bitmap Index Scan":
+		node.Type = schemas.PlanIndexScan
+	case "Nested Loop", "Hash Join", "Merge Join":
+		node.Type = schemas.PlanJoin
+	case "Sort", "Incremental Sort":
+		node.Type = schemas.PlanSort
+	case "Aggregate", "HashAggregate", "GroupAggregate":
+		node.Type = schemas.PlanAggregate
+	}
+	for i := range plan.Plans {
+		node.AddChild(plan.Plans[i].toNode())
+	}
+	return node
+}
+
+// Explain returns the query plan reported by EXPLAIN (FORMAT JSON)
+func (db *postgres) Explain(queryer core.Queryer, ctx context.Context, query string, args ...interface{}) (*schemas.PlanNode, error) {
+	rows, err := queryer.QueryContext(ctx, "EXPLAIN (FORMAT JSON) "+query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var content string
+	for rows.Next() {
+		var line string
+		if err := rows.Scan(&line); err != nil {
+			return nil, err
+		}
+		content += line
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	var plans []struct {
+		Plan postgresPlan `json:"Plan"`
+	}
+	if err := json.Unmarshal([]byte(content), &plans); err != nil {
+		return nil, err
+	}
+
+	root := schemas.NewPlanNode(schemas.PlanQuery)
+	for i := range plans {
+		root.AddChild(plans[i].Plan.toNode())
+	}
+	return root, nil
+}
+
+// JSONExtract implements Dialect
+func (db *postgres) JSONExtract(col string, path *JSONPath) string {
+	return fmt.Sprintf("(%s #>> %s)", col, path.PostgresLiteral())
+}
+
+// JSONContains implements Dialect
+func (db *postgres) JSONContains(col string, doc interface{}) (string, []interface{}, error) {
+	s, err := marshalJSONDoc(doc)
+	if err != nil {
+		return "", nil, err
+	}
+	return fmt.Sprintf("%s::jsonb @> ?::jsonb", col), []interface{}{s}, nil
+}
+
+// JSONSet implements Dialect
+func (db *postgres) JSONSet(col string, path *JSONPath, value interface{}) (string, []interface{}, error) {
+	s, err := marshalJSONValue(value)
+	if err != nil {
+		return "", nil, err
+	}
+	return fmt.Sprintf("jsonb_set(%s::jsonb, %s, ?::jsonb)", col, path.PostgresLiteral()), []interface{}{s}, nil
+}
+
+// ArrayContains implements Dialect
+func (db *postgres) ArrayContains(col string, values []interface{}) (string, []interface{}, error) {
+	arr, err := FormatArray(db, nil, nil, reflect.ValueOf(values))
+	if err != nil {
+		return "", nil, err
+	}
+	return col + " @> ?", []interface{}{arr}, nil
+}
+
+// ArrayOverlap implements Dialect
+func (db *postgres) ArrayOverlap(col string, values []interface{}) (string, []interface{}, error) {
+	arr, err := FormatArray(db, nil, nil, reflect.ValueOf(values))
+	if err != nil {
+		return "", nil, err
+	}
+	return col + " && ?", []interface{}{arr}, nil
+}
+
 func (db *postgres) Filters() []Filter {
 	return []Filter{&SeqFilter{Prefix: "$", Start: 1}}
 }
@@ -3,8 +3,10 @@ package dialects
 import (
 	"reflect"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/xorm-io/xorm/schemas"
 )
 
 func TestParsePostgres(t *testing.T) {
@@ -112,5 +114,64 @@ func TestGetIndexColName(t *testing.T) {
 		assert.Equal(t, []string{"major"}, colNames)
 	})
 
-	t.Run("Indexes on Expressions", func(t *testing.T) {})
+	t.Run("Indexes on Expressions", func(t *testing.T) {
+		s := "CREATE UNIQUE INDEX test2_lower_idx ON public.test2 USING btree (lower((name)::text), minor DESC) WHERE (deleted IS NULL)"
+		colNames := getIndexColName(s)
+		assert.Equal(t, []string{"lower((name)::text)", "minor"}, colNames)
+		_, where := splitIndexDef(s)
+		assert.Equal(t, "deleted IS NULL", where)
+	})
+}
+
+func TestPostgresArray(t *testing.T) {
+	dialect := QueryDialect(schemas.POSTGRES)
+	assert.NoError(t, dialect.Init(&URI{DBType: schemas.POSTGRES}))
+
+	col := &schemas.Column{SQLType: schemas.SQLType{Name: schemas.Array}, ArrayElemType: schemas.SQLType{Name: schemas.Text}}
+	s, err := FormatArray(dialect, time.UTC, col, reflect.ValueOf([]string{"a", `b"c`, `d\e`, "f,g"}))
+	assert.NoError(t, err)
+	assert.EqualValues(t, `{"a","b\"c","d\\e","f,g"}`, s)
+
+	var strs []string
+	assert.NoError(t, ParseArray(s.(string), time.UTC, reflect.ValueOf(&strs).Elem()))
+	assert.EqualValues(t, []string{"a", `b"c`, `d\e`, "f,g"}, strs)
+
+	s, err = FormatArray(dialect, time.UTC, col, reflect.ValueOf([]int64(nil)))
+	assert.NoError(t, err)
+	assert.Nil(t, s)
+
+	s, err = FormatArray(dialect, time.UTC, col, reflect.ValueOf([]int64{}))
+	assert.NoError(t, err)
+	assert.EqualValues(t, "{}", s)
+
+	var ints []int64
+	assert.NoError(t, ParseArray("{1,NULL, 3}", time.UTC, reflect.ValueOf(&ints).Elem()))
+	assert.EqualValues(t, []int64{1, 0, 3}, ints)
+
+	var bools []bool
+	assert.NoError(t, ParseArray("{t,f}", time.UTC, reflect.ValueOf(&bools).Elem()))
+	assert.EqualValues(t, []bool{true, false}, bools)
+
+	var floats []float64
+	assert.NoError(t, ParseArray("{1.5,2}", time.UTC, reflect.ValueOf(&floats).Elem()))
+	assert.EqualValues(t, []float64{1.5, 2}, floats)
+
+	tm := time.Date(2021, 1, 2, 3, 4, 5, 0, time.UTC)
+	col.ArrayElemType = schemas.SQLType{Name: schemas.DateTime}
+	s, err = FormatArray(dialect, time.UTC, col, reflect.ValueOf([]time.Time{tm}))
+	assert.NoError(t, err)
+	assert.EqualValues(t, `{"2021-01-02 03:04:05"}`, s)
+
+	var times []time.Time
+	assert.NoError(t, ParseArray(s.(string), time.UTC, reflect.ValueOf(&times).Elem()))
+	assert.EqualValues(t, 1, len(times))
+	assert.True(t, tm.Equal(times[0]))
+
+	assert.Error(t, ParseArray("{{1},{2}}", time.UTC, reflect.ValueOf(&ints).Elem()))
+	assert.Error(t, ParseArray("1,2", time.UTC, reflect.ValueOf(&ints).Elem()))
+
+	sql, args, err := dialect.ArrayOverlap("c", []interface{}{"a", 1})
+	assert.NoError(t, err)
+	assert.EqualValues(t, "c && ?", sql)
+	assert.EqualValues(t, []interface{}{`{"a",1}`}, args)
 }
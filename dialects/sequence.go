@@ -0,0 +1,30 @@
+// Copyright 2021 The Xorm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package dialects
+
+import (
+	"context"
+
+	"github.com/xorm-io/xorm/core"
+)
+
+// querySequences returns the names of the sequences selected by the query
+func querySequences(queryer core.Queryer, ctx context.Context, query string, args ...interface{}) ([]string, error) {
+	rows, err := queryer.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	seqs := make([]string, 0)
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return nil, err
+		}
+		seqs = append(seqs, name)
+	}
+	return seqs, rows.Err()
+}
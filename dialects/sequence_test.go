@@ -0,0 +1,81 @@
+// Copyright 2021 The Xorm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package dialects
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/xorm-io/xorm/schemas"
+)
+
+func TestSequenceSQL(t *testing.T) {
+	seq := schemas.NewSequence("SEQ_user_id")
+	seq.Start = 10
+
+	var kases = []struct {
+		dbType                schemas.DBType
+		create, next, restart string
+	}{
+		{
+			schemas.POSTGRES,
+			`CREATE SEQUENCE IF NOT EXISTS "SEQ_user_id" START WITH 10 INCREMENT BY 1`,
+			`SELECT nextval('"SEQ_user_id"')`,
+			`ALTER SEQUENCE "SEQ_user_id" RESTART WITH 5`,
+		},
+		{
+			schemas.ORACLE,
+			`CREATE SEQUENCE "SEQ_user_id" START WITH 10 INCREMENT BY 1`,
+			`SELECT "SEQ_user_id".NEXTVAL FROM DUAL`,
+			`ALTER SEQUENCE "SEQ_user_id" RESTART START WITH 5`,
+		},
+		{
+			schemas.MSSQL,
+			"IF NOT EXISTS (SELECT [name] FROM sys.sequences WHERE [name] = 'SEQ_user_id') CREATE SEQUENCE [SEQ_user_id] START WITH 10 INCREMENT BY 1",
+			"SELECT NEXT VALUE FOR [SEQ_user_id]",
+			"ALTER SEQUENCE [SEQ_user_id] RESTART WITH 5",
+		},
+		{schemas.MYSQL, "", "", ""},
+		{schemas.SQLITE, "", "", ""},
+	}
+	for _, kase := range kases {
+		dialect := QueryDialect(kase.dbType)
+		assert.NoError(t, dialect.Init(&URI{DBType: kase.dbType}))
+		assert.EqualValues(t, kase.create, dialect.CreateSequenceSQL(seq))
+		assert.EqualValues(t, kase.next, dialect.NextSequenceSQL(seq.Name))
+		assert.EqualValues(t, kase.restart, dialect.RestartSequenceSQL(seq.Name, 5))
+	}
+}
+
+func TestSequenceSQLInSchema(t *testing.T) {
+	seq := schemas.NewSequence("tenant.SEQ_user_id")
+
+	var kases = []struct {
+		dbType       schemas.DBType
+		create, next string
+	}{
+		{
+			schemas.POSTGRES,
+			`CREATE SEQUENCE IF NOT EXISTS "tenant"."SEQ_user_id" START WITH 1 INCREMENT BY 1`,
+			`SELECT nextval('"tenant"."SEQ_user_id"')`,
+		},
+		{
+			schemas.ORACLE,
+			`CREATE SEQUENCE "tenant"."SEQ_user_id" START WITH 1 INCREMENT BY 1`,
+			`SELECT "tenant"."SEQ_user_id".NEXTVAL FROM DUAL`,
+		},
+		{
+			schemas.MSSQL,
+			"IF NOT EXISTS (SELECT [name] FROM sys.sequences WHERE [name] = 'SEQ_user_id' AND schema_id = SCHEMA_ID('tenant')) CREATE SEQUENCE [tenant].[SEQ_user_id] START WITH 1 INCREMENT BY 1",
+			"SELECT NEXT VALUE FOR [tenant].[SEQ_user_id]",
+		},
+	}
+	for _, kase := range kases {
+		dialect := QueryDialect(kase.dbType)
+		assert.NoError(t, dialect.Init(&URI{DBType: kase.dbType}))
+		assert.EqualValues(t, kase.create, dialect.CreateSequenceSQL(seq))
+		assert.EqualValues(t, kase.next, dialect.NextSequenceSQL(seq.Name))
+	}
+}
@@ -212,7 +212,7 @@ func (db *sqlite3) SQLType(c *schemas.Column) string {
 	case schemas.TimeStampz:
 		return schemas.Text
 	case schemas.Char, schemas.Varchar, schemas.NVarchar, schemas.TinyText,
-		schemas.Text, schemas.MediumText, schemas.LongText, schemas.Json:
+		schemas.Text, schemas.MediumText, schemas.LongText, schemas.Json, schemas.Enum, schemas.Set:
 		return schemas.Text
 	case schemas.Bit, schemas.TinyInt, schemas.SmallInt, schemas.MediumInt, schemas.Int, schemas.Integer, schemas.BigInt,
 		schemas.UnsignedBigInt, schemas.UnsignedInt:
@@ -251,13 +251,47 @@ func (db *sqlite3) IndexCheckSQL(tableName, idxName string) (string, []interface
 	return "SELECT name FROM sqlite_master WHERE type='index' and name = ?", args
 }
 
+// masterTable returns the sqlite_master of the attached database of the context
+func (db *sqlite3) masterTable(ctx context.Context) string {
+	if schema := SchemaFromContext(ctx); schema != "" {
+		return db.Quoter().Quote(schema) + ".sqlite_master"
+	}
+	return "sqlite_master"
+}
+
+// splitSchema splits the attached database from the table name
+func splitSchema(tableName string) (string, string) {
+	if idx := strings.LastIndex(tableName, "."); idx > 0 {
+		return tableName[:idx], tableName[idx+1:]
+	}
+	return "", tableName
+}
+
 func (db *sqlite3) IsTableExist(queryer core.Queryer, ctx context.Context, tableName string) (bool, error) {
-	return db.HasRecords(queryer, ctx, "SELECT name FROM sqlite_master WHERE type='table' and name = ?", tableName)
+	return db.HasRecords(queryer, ctx, "SELECT name FROM "+db.masterTable(ctx)+" WHERE type='table' and name = ?", tableName)
+}
+
+// CreateIndexSQL returns a SQL to create the index, the index of a table of an
+// attached database is qualified by the database instead of the table
+func (db *sqlite3) CreateIndexSQL(tableName string, index *schemas.Index) string {
+	schema, tableName := splitSchema(tableName)
+	if schema == "" {
+		return db.Base.CreateIndexSQL(tableName, index)
+	}
+	quoter := db.Quoter()
+	var unique string
+	if index.Type == schemas.UniqueType {
+		unique = " UNIQUE"
+	}
+	return fmt.Sprintf("CREATE%s INDEX %v ON %v (%v)%s", unique,
+		quoter.Quote(schema+"."+index.XName(tableName)), quoter.Quote(tableName),
+		IndexKeysString(db, index), IndexWhereString(db, index))
 }
 
 func (db *sqlite3) DropIndexSQL(tableName string, index *schemas.Index) string {
 	// var unique string
 	idxName := index.Name
+	schema, tableName := splitSchema(tableName)
 
 	if !strings.HasPrefix(idxName, "UQE_") &&
 		!strings.HasPrefix(idxName, "IDX_") {
@@ -267,6 +301,9 @@ func (db *sqlite3) DropIndexSQL(tableName string, index *schemas.Index) string {
 			idxName = fmt.Sprintf("IDX_%v_%v", tableName, index.Name)
 		}
 	}
+	if schema != "" {
+		idxName = schema + "." + idxName
+	}
 	return fmt.Sprintf("DROP INDEX %v", db.Quoter().Quote(idxName))
 }
 
@@ -298,6 +335,10 @@ func (db *sqlite3) CreateTableSQL(table *schemas.Table, tableName string) ([]str
 			sql += " ), "
 		}
 
+		for _, check := range TableChecks(db, table) {
+			sql += CheckString(db, tableName, check) + ", "
+		}
+
 		sql = sql[:len(sql)-2]
 	}
 	sql += ")"
@@ -359,12 +400,45 @@ func splitColStr(colStr string) []string {
 	return results
 }
 
+// cutGenerated cuts the GENERATED ALWAYS AS (expr) STORED clause out of the
+// column definition, it returns the expression and whether it's stored
+func cutGenerated(colStr string) (string, string, bool) {
+	upper := strings.ToUpper(colStr)
+	idx := strings.Index(upper, " GENERATED ALWAYS AS")
+	if idx < 0 {
+		if idx = strings.Index(upper, " AS ("); idx < 0 {
+			return colStr, "", false
+		}
+	}
+	start := strings.Index(colStr[idx:], "(")
+	if start < 0 {
+		return colStr, "", false
+	}
+	start += idx
+	end := closingParen(colStr, start)
+	if end < 0 {
+		return colStr, "", false
+	}
+
+	var stored bool
+	rest := strings.TrimSpace(colStr[end+1:])
+	if upperRest := strings.ToUpper(rest); strings.HasPrefix(upperRest, "STORED") {
+		rest, stored = rest[len("STORED"):], true
+	} else if strings.HasPrefix(upperRest, "VIRTUAL") {
+		rest = rest[len("VIRTUAL"):]
+	}
+	return strings.TrimSpace(colStr[:idx] + " " + strings.TrimSpace(rest)), strings.TrimSpace(colStr[start+1 : end]), stored
+}
+
 func parseString(colStr string) (*schemas.Column, error) {
+	colStr, generated, stored := cutGenerated(colStr)
 	fields := splitColStr(colStr)
 	col := new(schemas.Column)
 	col.Indexes = make(map[string]int)
 	col.Nullable = true
 	col.DefaultIsEmpty = true
+	col.Generated = generated
+	col.GeneratedStored = stored
 
 	for idx, field := range fields {
 		if idx == 0 {
@@ -393,13 +467,14 @@ func parseString(colStr string) (*schemas.Column, error) {
 	return col, nil
 }
 
-func (db *sqlite3) GetColumns(queryer core.Queryer, ctx context.Context, tableName string) ([]string, map[string]*schemas.Column, error) {
+// tableSQL returns the CREATE TABLE SQL of the table
+func (db *sqlite3) tableSQL(queryer core.Queryer, ctx context.Context, tableName string) (string, error) {
 	args := []interface{}{tableName}
-	s := "SELECT sql FROM sqlite_master WHERE type='table' and name = ?"
+	s := "SELECT sql FROM " + db.masterTable(ctx) + " WHERE type IN ('table', 'view') and name = ?"
 
 	rows, err := queryer.QueryContext(ctx, s, args...)
 	if err != nil {
-		return nil, nil, err
+		return "", err
 	}
 	defer rows.Close()
 
@@ -407,24 +482,59 @@ func (db *sqlite3) GetColumns(queryer core.Queryer, ctx context.Context, tableNa
 	for rows.Next() {
 		err = rows.Scan(&name)
 		if err != nil {
-			return nil, nil, err
+			return "", err
 		}
 		break
 	}
 
 	if name == "" {
-		return nil, nil, errors.New("no table named " + tableName)
+		return "", errors.New("no table named " + tableName)
 	}
+	return name, nil
+}
 
-	nStart := strings.Index(name, "(")
-	nEnd := strings.LastIndex(name, ")")
-	reg := regexp.MustCompile(`[^\(,\)]*(\([^\(]*\))?`)
-	colCreates := reg.FindAllString(name[nStart+1:nEnd], -1)
+// splitTableDefs splits the column and the constraint definitions of a CREATE
+// TABLE SQL
+func splitTableDefs(sql string) []string {
+	nStart := strings.Index(sql, "(")
+	nEnd := strings.LastIndex(sql, ")")
+	if nStart < 0 || nEnd < nStart {
+		return nil
+	}
+	return splitTopLevel(sql[nStart+1 : nEnd])
+}
+
+// isConstraintDef returns true if the definition of CREATE TABLE is a table
+// constraint but not a column
+func isConstraintDef(def string) bool {
+	def = strings.ToUpper(def)
+	for _, prefix := range []string{"CONSTRAINT ", "CHECK", "UNIQUE", "FOREIGN KEY"} {
+		if strings.HasPrefix(def, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+func (db *sqlite3) GetColumns(queryer core.Queryer, ctx context.Context, tableName string) ([]string, map[string]*schemas.Column, error) {
+	name, err := db.tableSQL(queryer, ctx, tableName)
+	if err != nil {
+		return nil, nil, err
+	}
+	// the columns of a view aren't declared
+	if sqliteViewReg.MatchString(name) {
+		return db.viewColumns(queryer, ctx, tableName)
+	}
+
+	colCreates := splitTableDefs(name)
 	cols := make(map[string]*schemas.Column)
 	colSeq := make([]string, 0)
 
+	reg := regexp.MustCompile(`,\s`)
 	for _, colStr := range colCreates {
-		reg = regexp.MustCompile(`,\s`)
+		if isConstraintDef(colStr) {
+			continue
+		}
 		colStr = reg.ReplaceAllString(colStr, ",")
 		if strings.HasPrefix(strings.TrimSpace(colStr), "PRIMARY KEY") {
 			parts := strings.Split(strings.TrimSpace(colStr), "(")
@@ -450,9 +560,112 @@ func (db *sqlite3) GetColumns(queryer core.Queryer, ctx context.Context, tableNa
 	return colSeq, cols, nil
 }
 
+var sqliteViewReg = regexp.MustCompile(`(?i)^\s*CREATE\s+(TEMP\s+|TEMPORARY\s+)?VIEW\s`)
+
+// viewColumns returns the columns of the view, the types of the expressions
+// aren't declared and they are read as NUMERIC
+func (db *sqlite3) viewColumns(queryer core.Queryer, ctx context.Context, viewName string) ([]string, map[string]*schemas.Column, error) {
+	quoter := db.Quoter()
+	s := "PRAGMA table_info(" + quoter.Quote(viewName) + ")"
+	if schema := SchemaFromContext(ctx); schema != "" {
+		s = "PRAGMA " + quoter.Quote(schema) + ".table_info(" + quoter.Quote(viewName) + ")"
+	}
+	rows, err := queryer.QueryContext(ctx, s)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer rows.Close()
+
+	cols := make(map[string]*schemas.Column)
+	colSeq := make([]string, 0)
+	for rows.Next() {
+		var (
+			cid, notNull, pk int
+			name, tp         string
+			dflt             *string
+		)
+		if err := rows.Scan(&cid, &name, &tp, &notNull, &dflt, &pk); err != nil {
+			return nil, nil, err
+		}
+		if tp == "" {
+			tp = schemas.Numeric
+		}
+		col := &schemas.Column{
+			Name:           name,
+			SQLType:        schemas.SQLType{Name: strings.ToUpper(tp)},
+			Nullable:       notNull == 0,
+			Indexes:        make(map[string]int),
+			DefaultIsEmpty: true,
+		}
+		cols[col.Name] = col
+		colSeq = append(colSeq, col.Name)
+	}
+	return colSeq, cols, rows.Err()
+}
+
+var sqliteCheckReg = regexp.MustCompile(`(?is)^CONSTRAINT\s+(\S+)\s+CHECK\s*\((.*)\)$`)
+
+// GetChecks returns the named check constraints of the table
+func (db *sqlite3) GetChecks(queryer core.Queryer, ctx context.Context, tableName string) (map[string]*schemas.Check, error) {
+	name, err := db.tableSQL(queryer, ctx, tableName)
+	if err != nil {
+		return nil, err
+	}
+
+	checks := make(map[string]*schemas.Check)
+	for _, def := range splitTableDefs(name) {
+		matches := sqliteCheckReg.FindStringSubmatch(def)
+		if len(matches) != 3 {
+			continue
+		}
+		checkName := strings.Trim(matches[1], "`[]\"")
+		checks[checkName] = schemas.NewCheck(checkName, strings.TrimSpace(matches[2]))
+	}
+	return checks, nil
+}
+
+// AddCheckSQL returns empty since SQLite can't add a check constraint to a table
+func (db *sqlite3) AddCheckSQL(tableName string, check *schemas.Check) string {
+	return ""
+}
+
+// DropCheckSQL returns empty since SQLite can't drop a check constraint of a table
+func (db *sqlite3) DropCheckSQL(tableName string, check *schemas.Check) string {
+	return ""
+}
+
+// CreateSequenceSQL returns empty since SQLite has no sequences
+func (db *sqlite3) CreateSequenceSQL(seq *schemas.Sequence) string {
+	return ""
+}
+
+// DropSequenceSQL returns empty since SQLite has no sequences
+func (db *sqlite3) DropSequenceSQL(seqName string) string {
+	return ""
+}
+
+// NextSequenceSQL returns empty since SQLite has no sequences
+func (db *sqlite3) NextSequenceSQL(seqName string) string {
+	return ""
+}
+
+// RestartSequenceSQL returns empty since SQLite has no sequences
+func (db *sqlite3) RestartSequenceSQL(seqName string, start int64) string {
+	return ""
+}
+
+// CreateViewSQL returns the SQLs to create or replace a view
+func (db *sqlite3) CreateViewSQL(viewName, query string, materialized bool) []string {
+	quotedName := db.Quoter().Quote(viewName)
+	return []string{
+		"DROP VIEW IF EXISTS " + quotedName,
+		fmt.Sprintf("CREATE VIEW %s AS %s", quotedName, query),
+	}
+}
+
 func (db *sqlite3) GetTables(queryer core.Queryer, ctx context.Context) ([]*schemas.Table, error) {
 	args := []interface{}{}
-	s := "SELECT name FROM sqlite_master WHERE type='table'"
+	s := "SELECT name FROM " + db.masterTable(ctx) + " WHERE type='table'"
 
 	rows, err := queryer.QueryContext(ctx, s, args...)
 	if err != nil {
@@ -475,9 +688,14 @@ func (db *sqlite3) GetTables(queryer core.Queryer, ctx context.Context) ([]*sche
 	return tables, nil
 }
 
+// GetViews returns the views, SQLite has no materialized views
+func (db *sqlite3) GetViews(queryer core.Queryer, ctx context.Context) ([]*schemas.Table, error) {
+	return queryViews(queryer, ctx, "SELECT name, 0 FROM "+db.masterTable(ctx)+" WHERE type='view'")
+}
+
 func (db *sqlite3) GetIndexes(queryer core.Queryer, ctx context.Context, tableName string) (map[string]*schemas.Index, error) {
 	args := []interface{}{tableName}
-	s := "SELECT sql FROM sqlite_master WHERE type='index' and tbl_name = ?"
+	s := "SELECT sql FROM " + db.masterTable(ctx) + " WHERE type='index' and tbl_name = ?"
 
 	rows, err := queryer.QueryContext(ctx, s, args...)
 	if err != nil {
@@ -520,14 +738,12 @@ func (db *sqlite3) GetIndexes(queryer core.Queryer, ctx context.Context, tableNa
 			index.Type = schemas.IndexType
 		}
 
-		nStart := strings.Index(sql, "(")
-		nEnd := strings.Index(sql, ")")
-		colIndexes := strings.Split(sql[nStart+1:nEnd], ",")
-
-		index.Cols = make([]string, 0)
-		for _, col := range colIndexes {
-			index.Cols = append(index.Cols, strings.Trim(col, "` []"))
+		keys, where := splitIndexDef(sql)
+		index.Cols = make([]string, 0, len(keys))
+		for _, key := range keys {
+			index.Cols = append(index.Cols, indexKeyName(key))
 		}
+		index.Where = where
 		index.IsRegular = isRegular
 		indexes[index.Name] = index
 	}
@@ -535,6 +751,99 @@ func (db *sqlite3) GetIndexes(queryer core.Queryer, ctx context.Context, tableNa
 	return indexes, nil
 }
 
+// Explain returns the query plan reported by EXPLAIN QUERY PLAN
+func (db *sqlite3) Explain(queryer core.Queryer, ctx context.Context, query string, args ...interface{}) (*schemas.PlanNode, error) {
+	records, err := queryStrings(queryer, ctx, "EXPLAIN QUERY PLAN "+query, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	root := schemas.NewPlanNode(schemas.PlanQuery)
+	nodes := map[string]*schemas.PlanNode{"0": root}
+	for _, record := range records {
+		node := parseSQLite3PlanDetail(record["detail"])
+		parent, ok := nodes[record["parent"]]
+		if !ok {
+			parent = root
+		}
+		parent.AddChild(node)
+		if id, ok := record["id"]; ok {
+			nodes[id] = node
+		}
+	}
+	return root, nil
+}
+
+// parseSQLite3PlanDetail parses details like "SEARCH TABLE user USING INDEX IDX_user_name (name=?)"
+func parseSQLite3PlanDetail(detail string) *schemas.PlanNode {
+	node := schemas.NewPlanNode(schemas.PlanOther)
+	node.Detail = detail
+
+	fields := strings.Fields(detail)
+	if len(fields) == 0 {
+		return node
+	}
+	switch verb := fields[0]; verb {
+	case "SCAN", "SEARCH":
+		fields = fields[1:]
+		if len(fields) > 0 && fields[0] == "TABLE" {
+			fields = fields[1:]
+		}
+		if len(fields) > 0 {
+			node.Table = fields[0]
+		}
+		using := strings.Index(detail, " USING ")
+		if using < 0 {
+			node.Type = schemas.PlanTableScan
+			break
+		}
+		rest := strings.Fields(detail[using+len(" USING "):])
+		for i, f := range rest {
+			if f == "INDEX" && i+1 < len(rest) {
+				node.Index = rest[i+1]
+				break
+			}
+			if f == "KEY" {
+				node.Index = "PRIMARY KEY"
+				break
+			}
+		}
+		if verb == "SEARCH" {
+			node.Type = schemas.PlanIndexLookup
+		} else {
+			node.Type = schemas.PlanIndexScan
+		}
+	case "USE":
+		if strings.Contains(detail, "ORDER BY") {
+			node.Type = schemas.PlanSort
+		} else {
+			node.Type = schemas.PlanAggregate
+		}
+	}
+	return node
+}
+
+// JSONExtract implements Dialect
+func (db *sqlite3) JSONExtract(col string, path *JSONPath) string {
+	return fmt.Sprintf("json_extract(%s, %s)", col, path.Literal())
+}
+
+// JSONContains implements Dialect
+func (db *sqlite3) JSONContains(col string, doc interface{}) (string, []interface{}, error) {
+	return jsonContainsByPaths(db, col, doc, func(col string, path *JSONPath) string {
+		return fmt.Sprintf("EXISTS (SELECT 1 FROM json_each(%s, %s) WHERE json_each.value = ?)", col, path.Literal())
+	})
+}
+
+// JSONSet implements Dialect
+func (db *sqlite3) JSONSet(col string, path *JSONPath, value interface{}) (string, []interface{}, error) {
+	s, err := marshalJSONValue(value)
+	if err != nil {
+		return "", nil, err
+	}
+	return fmt.Sprintf("json_set(%s, %s, json(?))", col, path.Literal()), []interface{}{s}, nil
+}
+
 func (db *sqlite3) Filters() []Filter {
 	return []Filter{}
 }
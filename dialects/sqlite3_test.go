@@ -8,6 +8,7 @@ import (
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/xorm-io/xorm/schemas"
 )
 
 func TestSplitColStr(t *testing.T) {
@@ -33,3 +34,51 @@ func TestSplitColStr(t *testing.T) {
 		assert.EqualValues(t, kase.fields, splitColStr(kase.colStr))
 	}
 }
+
+func TestSplitTableDefs(t *testing.T) {
+	sql := "CREATE TABLE `user` (`id` INTEGER PRIMARY KEY AUTOINCREMENT NOT NULL, " +
+		"`price` NUMERIC(10, 2) DEFAULT '1,2' NULL, " +
+		"CONSTRAINT `CHK_user_price` CHECK (price > 0 AND length(`name`) IN (1, 2)))"
+	assert.EqualValues(t, []string{
+		"`id` INTEGER PRIMARY KEY AUTOINCREMENT NOT NULL",
+		"`price` NUMERIC(10, 2) DEFAULT '1,2' NULL",
+		"CONSTRAINT `CHK_user_price` CHECK (price > 0 AND length(`name`) IN (1, 2))",
+	}, splitTableDefs(sql))
+}
+
+func TestParseSQLite3PlanDetail(t *testing.T) {
+	var kases = []struct {
+		detail string
+		tp     string
+		table  string
+		index  string
+	}{
+		{"SCAN TABLE user", schemas.PlanTableScan, "user", ""},
+		{"SCAN user", schemas.PlanTableScan, "user", ""},
+		{"SEARCH user USING INDEX IDX_user_name (name=?)", schemas.PlanIndexLookup, "user", "IDX_user_name"},
+		{"SEARCH TABLE user USING INTEGER PRIMARY KEY (rowid=?)", schemas.PlanIndexLookup, "user", "PRIMARY KEY"},
+		{"SCAN user USING COVERING INDEX IDX_user_name", schemas.PlanIndexScan, "user", "IDX_user_name"},
+		{"USE TEMP B-TREE FOR ORDER BY", schemas.PlanSort, "", ""},
+	}
+
+	for _, kase := range kases {
+		node := parseSQLite3PlanDetail(kase.detail)
+		assert.EqualValues(t, kase.tp, node.Type, kase.detail)
+		assert.EqualValues(t, kase.table, node.Table, kase.detail)
+		assert.EqualValues(t, kase.index, node.Index, kase.detail)
+	}
+}
+
+func TestCutGenerated(t *testing.T) {
+	colStr, expr, stored := cutGenerated("`full` TEXT GENERATED ALWAYS AS (first || ' (' || last) STORED NOT NULL")
+	assert.EqualValues(t, "`full` TEXT NOT NULL", colStr)
+	assert.EqualValues(t, "first || ' (' || last", expr)
+	assert.True(t, stored)
+
+	col, err := parseString("`total` INTEGER AS (price * amount) NULL")
+	assert.NoError(t, err)
+	assert.EqualValues(t, "total", col.Name)
+	assert.EqualValues(t, "price * amount", col.Generated)
+	assert.False(t, col.GeneratedStored)
+	assert.True(t, col.Nullable)
+}
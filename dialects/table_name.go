@@ -5,9 +5,12 @@
 package dialects
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"reflect"
 	"strings"
+	"unicode"
 
 	"github.com/xorm-io/xorm/internal/utils"
 	"github.com/xorm-io/xorm/names"
@@ -24,6 +27,48 @@ func TableNameWithSchema(dialect Dialect, tableName string) string {
 	return tableName
 }
 
+// ErrInvalidSchema is returned when the schema isn't an identifier
+var ErrInvalidSchema = errors.New("the schema should be an identifier")
+
+// ValidateSchema returns ErrInvalidSchema unless the schema is an identifier,
+// i.e. letters, digits, _ and $ which don't begin with a digit or $
+func ValidateSchema(schema string) error {
+	for i, r := range schema {
+		if unicode.IsLetter(r) || r == '_' || i > 0 && (unicode.IsDigit(r) || r == '$') {
+			continue
+		}
+		return ErrInvalidSchema
+	}
+	return nil
+}
+
+type schemaContextKey struct{}
+
+// WithSchema returns a copy of ctx carrying the schema, the tables of the
+// sessions of the context are qualified by it instead of the schema of the URI.
+// The schema is the database of MySQL and the attached database of SQLite.
+func WithSchema(ctx context.Context, schema string) context.Context {
+	return context.WithValue(ctx, schemaContextKey{}, strings.TrimSpace(schema))
+}
+
+// SchemaFromContext returns the schema carried by the context
+func SchemaFromContext(ctx context.Context) string {
+	if ctx == nil {
+		return ""
+	}
+	schema, _ := ctx.Value(schemaContextKey{}).(string)
+	return schema
+}
+
+// TableNameInSchema qualifies the table name by the schema, or by the schema of
+// the URI if schema is empty
+func TableNameInSchema(dialect Dialect, schema, tableName string) string {
+	if schema != "" && !strings.Contains(tableName, ".") {
+		return fmt.Sprintf("%s.%s", schema, tableName)
+	}
+	return TableNameWithSchema(dialect, tableName)
+}
+
 // TableNameNoSchema returns table name with given tableName
 func TableNameNoSchema(dialect Dialect, mapper names.Mapper, tableName interface{}) string {
 	quote := dialect.Quoter().Quote
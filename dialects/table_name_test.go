@@ -5,9 +5,11 @@
 package dialects
 
 import (
+	"context"
 	"testing"
 
 	"github.com/xorm-io/xorm/names"
+	"github.com/xorm-io/xorm/schemas"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -28,3 +30,24 @@ func TestFullTableName(t *testing.T) {
 	assert.EqualValues(t, "mcc", FullTableName(dialect, names.SnakeMapper{}, &MCC{}))
 	assert.EqualValues(t, "mcc", FullTableName(dialect, names.SnakeMapper{}, "mcc"))
 }
+
+func TestTableNameInSchema(t *testing.T) {
+	dialect := QueryDialect(schemas.POSTGRES)
+	assert.NoError(t, dialect.Init(&URI{DBType: schemas.POSTGRES, Schema: "public"}))
+
+	assert.EqualValues(t, "public.mcc", TableNameInSchema(dialect, "", "mcc"))
+	assert.EqualValues(t, "tenant.mcc", TableNameInSchema(dialect, "tenant", "mcc"))
+	assert.EqualValues(t, "other.mcc", TableNameInSchema(dialect, "tenant", "other.mcc"))
+
+	assert.EqualValues(t, "", SchemaFromContext(context.Background()))
+	assert.EqualValues(t, "tenant", SchemaFromContext(WithSchema(context.Background(), "tenant")))
+}
+
+func TestValidateSchema(t *testing.T) {
+	for _, schema := range []string{"", "tenant", "tenant_1", "_tenant$a", "租户"} {
+		assert.NoError(t, ValidateSchema(schema), schema)
+	}
+	for _, schema := range []string{"1tenant", "$tenant", "tenant.a", "tenant-a", "tenant a", "t') DROP TABLE x --"} {
+		assert.EqualValues(t, ErrInvalidSchema, ValidateSchema(schema), schema)
+	}
+}
@@ -0,0 +1,54 @@
+// Copyright 2021 The Xorm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package dialects
+
+import (
+	"context"
+	"crypto/sha1"
+	"database/sql"
+	"fmt"
+
+	"github.com/xorm-io/xorm/core"
+	"github.com/xorm-io/xorm/schemas"
+)
+
+// ViewComment returns the comment of the materialized view which identifies
+// its query, so that the view is created again only if the query is changed
+func ViewComment(query string) string {
+	return fmt.Sprintf("xorm:%x", sha1.Sum([]byte(query)))
+}
+
+// queryViews returns the views of the query whose rows are the names of the
+// views, whether they are materialized and optionally their comments
+func queryViews(queryer core.Queryer, ctx context.Context, query string, args ...interface{}) ([]*schemas.Table, error) {
+	rows, err := queryer.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	cols, err := rows.Columns()
+	if err != nil {
+		return nil, err
+	}
+	views := make([]*schemas.Table, 0)
+	for rows.Next() {
+		var (
+			view    = schemas.NewEmptyTable()
+			comment sql.NullString
+			dest    = []interface{}{&view.Name, &view.Materialized}
+		)
+		if len(cols) > 2 {
+			dest = append(dest, &comment)
+		}
+		if err := rows.Scan(dest...); err != nil {
+			return nil, err
+		}
+		view.IsView = true
+		view.Comment = comment.String
+		views = append(views, view)
+	}
+	return views, rows.Err()
+}
@@ -0,0 +1,86 @@
+// Copyright 2021 The Xorm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package dialects
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/xorm-io/xorm/schemas"
+)
+
+func TestViewSQL(t *testing.T) {
+	const query = "SELECT id FROM t"
+
+	var kases = []struct {
+		dbType                 schemas.DBType
+		create, createMaterial []string
+		drop, dropMaterial     string
+		refresh                string
+	}{
+		{
+			schemas.POSTGRES,
+			[]string{`CREATE OR REPLACE VIEW "v" AS SELECT id FROM t`},
+			[]string{
+				`DROP MATERIALIZED VIEW IF EXISTS "v"`,
+				`CREATE MATERIALIZED VIEW "v" AS SELECT id FROM t`,
+				`COMMENT ON MATERIALIZED VIEW "v" IS '` + ViewComment(query) + `'`,
+			},
+			`DROP VIEW IF EXISTS "v"`,
+			`DROP MATERIALIZED VIEW IF EXISTS "v"`,
+			`REFRESH MATERIALIZED VIEW "v"`,
+		},
+		{
+			schemas.ORACLE,
+			[]string{`CREATE OR REPLACE VIEW "v" AS SELECT id FROM t`},
+			[]string{
+				`CREATE MATERIALIZED VIEW "v" AS SELECT id FROM t`,
+				`COMMENT ON MATERIALIZED VIEW "v" IS '` + ViewComment(query) + `'`,
+			},
+			`DROP VIEW "v"`,
+			`DROP MATERIALIZED VIEW "v"`,
+			`BEGIN DBMS_MVIEW.REFRESH('"v"'); END;`,
+		},
+		{
+			schemas.MSSQL,
+			[]string{"CREATE OR ALTER VIEW [v] AS SELECT id FROM t"},
+			[]string{"CREATE OR ALTER VIEW [v] AS SELECT id FROM t"},
+			"DROP VIEW IF EXISTS [v]",
+			"DROP VIEW IF EXISTS [v]",
+			"",
+		},
+		{
+			schemas.MYSQL,
+			[]string{"CREATE OR REPLACE VIEW `v` AS SELECT id FROM t"},
+			[]string{"CREATE OR REPLACE VIEW `v` AS SELECT id FROM t"},
+			"DROP VIEW IF EXISTS `v`",
+			"DROP VIEW IF EXISTS `v`",
+			"",
+		},
+		{
+			schemas.SQLITE,
+			[]string{"DROP VIEW IF EXISTS `v`", "CREATE VIEW `v` AS SELECT id FROM t"},
+			[]string{"DROP VIEW IF EXISTS `v`", "CREATE VIEW `v` AS SELECT id FROM t"},
+			"DROP VIEW IF EXISTS `v`",
+			"DROP VIEW IF EXISTS `v`",
+			"",
+		},
+	}
+	for _, kase := range kases {
+		dialect := QueryDialect(kase.dbType)
+		assert.NoError(t, dialect.Init(&URI{DBType: kase.dbType}))
+		assert.EqualValues(t, kase.create, dialect.CreateViewSQL("v", query, false))
+		assert.EqualValues(t, kase.createMaterial, dialect.CreateViewSQL("v", query, true))
+		assert.EqualValues(t, kase.drop, dialect.DropViewSQL("v", false))
+		assert.EqualValues(t, kase.dropMaterial, dialect.DropViewSQL("v", true))
+		assert.EqualValues(t, kase.refresh, dialect.RefreshViewSQL("v"))
+	}
+}
+
+func TestViewComment(t *testing.T) {
+	assert.EqualValues(t, ViewComment("SELECT id FROM t"), ViewComment("SELECT id FROM t"))
+	assert.NotEqual(t, ViewComment("SELECT id FROM t"), ViewComment("SELECT id FROM t WHERE id > 1"))
+	assert.Regexp(t, "^xorm:[0-9a-f]{40}$", ViewComment("SELECT id FROM t"))
+}
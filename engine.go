@@ -15,10 +15,12 @@ import (
 	"runtime"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/xorm-io/xorm/caches"
 	"github.com/xorm-io/xorm/contexts"
+	"github.com/xorm-io/xorm/convert"
 	"github.com/xorm-io/xorm/core"
 	"github.com/xorm-io/xorm/dialects"
 	"github.com/xorm-io/xorm/internal/json"
@@ -33,6 +35,7 @@ import (
 // Commonly, an application only need one engine
 type Engine struct {
 	cacherMgr      *caches.Manager
+	resultCache    *caches.ResultCache
 	defaultContext context.Context
 	dialect        dialects.Dialect
 	engineGroup    *EngineGroup
@@ -47,6 +50,13 @@ type Engine struct {
 	DatabaseTZ *time.Location // The timezone of the database
 
 	logSessionID bool // create session id
+
+	auditMutex  sync.RWMutex
+	auditTables map[string]bool // the tables audited besides the Auditable beans
+
+	sharders map[string]names.Sharder // the sharders of the tables
+
+	versionMutex sync.Mutex // serializes the detections of the version
 }
 
 // NewEngine new a db manager according to the parameter. Currently support four
@@ -75,6 +85,7 @@ func newEngine(driverName, dataSourceName string, dialect dialects.Dialect, db *
 		TZLocation:     time.Local,
 		defaultContext: context.Background(),
 		cacherMgr:      cacherMgr,
+		resultCache:    caches.NewResultCache(),
 		tagParser:      tagParser,
 		driverName:     driverName,
 		dataSourceName: dataSourceName,
@@ -138,6 +149,19 @@ func (engine *Engine) GetCacher(tableName string) caches.Cacher {
 	return engine.cacherMgr.GetCacher(tableName)
 }
 
+// SetSharder shards the table of the bean by the sharder, the table of a bean
+// which implements names.Sharder is sharded by the bean itself
+func (engine *Engine) SetSharder(beanOrTableName interface{}, sharder names.Sharder) {
+	if engine.sharders == nil {
+		engine.sharders = make(map[string]names.Sharder)
+	}
+	if name, ok := beanOrTableName.(string); ok {
+		engine.sharders[name] = sharder
+	} else {
+		engine.sharders[engine.TableName(beanOrTableName)] = sharder
+	}
+}
+
 // SetQuotePolicy sets the special quote policy
 func (engine *Engine) SetQuotePolicy(quotePolicy dialects.QuotePolicy) {
 	engine.dialect.SetQuotePolicy(quotePolicy)
@@ -286,6 +310,49 @@ func (engine *Engine) NoCache() *Session {
 	return session.NoCache()
 }
 
+// With adds a common table expression of the subquery
+func (engine *Engine) With(name string, subQuery interface{}) *Session {
+	session := engine.NewSession()
+	session.isAutoClose = true
+	return session.With(name, subQuery)
+}
+
+// WithRecursive adds a recursive common table expression of the subquery
+func (engine *Engine) WithRecursive(name string, subQuery interface{}) *Session {
+	session := engine.NewSession()
+	session.isAutoClose = true
+	return session.WithRecursive(name, subQuery)
+}
+
+// CacheFor caches the result of the next read for the duration
+func (engine *Engine) CacheFor(ttl time.Duration) *Session {
+	session := engine.NewSession()
+	session.isAutoClose = true
+	return session.CacheFor(ttl)
+}
+
+// SetResultCacheLimits bounds the results cached by CacheFor, the least
+// recently used ones are evicted when there are more than maxElementSize
+// results or their estimated size exceeds maxMemory megabytes. Zero means no
+// limit, the defaults are caches.CacheMaxResults and caches.CacheMaxMemory.
+func (engine *Engine) SetResultCacheLimits(maxElementSize int, maxMemory int64) {
+	engine.resultCache.SetLimits(maxElementSize, maxMemory<<20)
+}
+
+// Shards restricts the shards which Find of a sharded table runs on
+func (engine *Engine) Shards(shards ...string) *Session {
+	session := engine.NewSession()
+	session.isAutoClose = true
+	return session.Shards(shards...)
+}
+
+// Schema creates a session whose tables are qualified by the schema
+func (engine *Engine) Schema(schema string) *Session {
+	session := engine.NewSession()
+	session.isAutoClose = true
+	return session.Schema(schema)
+}
+
 // NoCascade If you do not want to auto cascade load object
 func (engine *Engine) NoCascade() *Session {
 	session := engine.NewSession()
@@ -293,12 +360,26 @@ func (engine *Engine) NoCascade() *Session {
 	return session.NoCascade()
 }
 
-// MapCacher Set a table use a special cacher
-func (engine *Engine) MapCacher(bean interface{}, cacher caches.Cacher) error {
-	engine.SetCacher(dialects.FullTableName(engine.dialect, engine.GetTableMapper(), bean, true), cacher)
+// MapCacher Set a table use a special cacher, the options of the table could
+// be given if the cacher implements caches.TableCacher
+func (engine *Engine) MapCacher(bean interface{}, cacher caches.Cacher, opts ...caches.TableOptions) error {
+	tableName := dialects.FullTableName(engine.dialect, engine.GetTableMapper(), bean, true)
+	if len(opts) > 0 {
+		tableCacher, ok := cacher.(caches.TableCacher)
+		if !ok {
+			return caches.ErrTableOptionsNotSupported
+		}
+		tableCacher.SetTableOptions(tableName, opts[0])
+	}
+	engine.SetCacher(tableName, cacher)
 	return nil
 }
 
+// CacheStats returns the cache statistics of the tables
+func (engine *Engine) CacheStats() map[string]caches.CacheStats {
+	return engine.cacherMgr.Stats()
+}
+
 // NewDB provides an interface to operate database directly
 func (engine *Engine) NewDB() (*core.DB, error) {
 	return core.Open(engine.driverName, engine.dataSourceName)
@@ -359,23 +440,33 @@ func (engine *Engine) NoAutoCondition(no ...bool) *Session {
 	return session.NoAutoCondition(no...)
 }
 
-func (engine *Engine) loadTableInfo(table *schemas.Table) error {
-	colSeq, cols, err := engine.dialect.GetColumns(engine.db, engine.defaultContext, table.Name)
+func (engine *Engine) loadTableInfo(ctx context.Context, table *schemas.Table) error {
+	colSeq, cols, err := engine.dialect.GetColumns(engine.db, ctx, table.Name)
 	if err != nil {
 		return err
 	}
 	for _, name := range colSeq {
 		table.AddColumn(cols[name])
 	}
-	indexes, err := engine.dialect.GetIndexes(engine.db, engine.defaultContext, table.Name)
+	indexes, err := engine.dialect.GetIndexes(engine.db, ctx, table.Name)
 	if err != nil {
 		return err
 	}
 	table.Indexes = indexes
 
+	checks, err := engine.dialect.GetChecks(engine.db, ctx, table.Name)
+	if err != nil {
+		return err
+	}
+	table.Checks = checks
+
 	var seq int
 	for _, index := range indexes {
 		for _, name := range index.Cols {
+			// the expressions aren't columns of the table
+			if schemas.ParseIndexKey(name).IsExpr {
+				continue
+			}
 			parts := strings.Split(strings.TrimSpace(name), " ")
 			if len(parts) > 1 {
 				if parts[1] == "DESC" {
@@ -403,13 +494,33 @@ func (engine *Engine) DBMetas() ([]*schemas.Table, error) {
 	}
 
 	for _, table := range tables {
-		if err = engine.loadTableInfo(table); err != nil {
+		if err = engine.loadTableInfo(engine.defaultContext, table); err != nil {
 			return nil, err
 		}
 	}
 	return tables, nil
 }
 
+// DBViews returns the views and the materialized views of the database with
+// their columns, they aren't in DBMetas
+func (engine *Engine) DBViews() ([]*schemas.Table, error) {
+	views, err := engine.dialect.GetViews(engine.db, engine.defaultContext)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, view := range views {
+		colSeq, cols, err := engine.dialect.GetColumns(engine.db, engine.defaultContext, view.Name)
+		if err != nil {
+			return nil, err
+		}
+		for _, name := range colSeq {
+			view.AddColumn(cols[name])
+		}
+	}
+	return views, nil
+}
+
 // DumpAllToFile dump database all table structs and data to a file
 func (engine *Engine) DumpAllToFile(fp string, tp ...schemas.DBType) error {
 	f, err := os.Create(fp)
@@ -563,6 +674,10 @@ func (engine *Engine) dumpTables(tables []*schemas.Table, w io.Writer, tp ...sch
 	}
 
 	for i, table := range tables {
+		if table.IsView {
+			// the views have no rows of their own
+			continue
+		}
 		dstTable := table
 		if table.Type != nil {
 			dstTable, err = dstTableCache.Parse(reflect.New(table.Type).Elem())
@@ -598,6 +713,9 @@ func (engine *Engine) dumpTables(tables []*schemas.Table, w io.Writer, tp ...sch
 			fmt.Fprintf(w, "SET IDENTITY_INSERT [%s] ON;\n", dstTable.Name)
 		}
 
+		if err := dialects.CheckIndexes(dstDialect, dstTable); err != nil {
+			return err
+		}
 		for _, index := range dstTable.Indexes {
 			_, err = io.WriteString(w, dstDialect.CreateIndexSQL(dstTable.Name, index)+";\n")
 			if err != nil {
@@ -687,9 +805,29 @@ func (engine *Engine) dumpTables(tables []*schemas.Table, w io.Writer, tp ...sch
 			}
 		}
 
-		// FIXME: Hack for postgres
-		if dstDialect.URI().DBType == schemas.POSTGRES && table.AutoIncrColumn() != nil {
-			_, err = io.WriteString(w, "SELECT setval('"+dstTableName+"_id_seq', COALESCE((SELECT MAX("+table.AutoIncrColumn().Name+") + 1 FROM "+dstDialect.Quoter().Quote(dstTableName)+"), 1), false);\n")
+		// the sequences are created unless they exist and restarted after the
+		// dumped values so that the inserts after importing don't collide with them
+		for _, col := range dstTable.SequenceColumns() {
+			max, err := maxColumnValue(engine.defaultContext, engine.DB(), engine.dialect.Quoter(), originalTableName, col.Name)
+			if err != nil {
+				return err
+			}
+			seq := schemas.NewSequence(dialects.TableNameWithSchema(dstDialect, col.Sequence))
+			seq.Start = max + 1
+			for _, s := range []string{dstDialect.CreateSequenceSQL(seq), dstDialect.RestartSequenceSQL(seq.Name, seq.Start)} {
+				if s == "" {
+					continue
+				}
+				if _, err = io.WriteString(w, s+";\n"); err != nil {
+					return err
+				}
+			}
+		}
+
+		if dstDialect.URI().DBType == schemas.POSTGRES && dstTable.AutoIncrColumn() != nil {
+			quoter := dstDialect.Quoter()
+			aiColName := dstTable.AutoIncrColumn().Name
+			_, err = io.WriteString(w, "SELECT setval(pg_get_serial_sequence('"+quoter.Quote(dstTableName)+"', '"+aiColName+"'), COALESCE((SELECT MAX("+quoter.Quote(aiColName)+") + 1 FROM "+quoter.Quote(dstTableName)+"), 1), false);\n")
 			if err != nil {
 				return err
 			}
@@ -921,7 +1059,30 @@ func (engine *Engine) Having(conditions string) *Session {
 
 // DBVersion returns the database version
 func (engine *Engine) DBVersion() (*schemas.Version, error) {
-	return engine.dialect.Version(engine.defaultContext, engine.db)
+	version, err := engine.dialect.Version(engine.defaultContext, engine.db)
+	if err != nil {
+		return nil, err
+	}
+	engine.dialect.SetVersion(version)
+	return version, nil
+}
+
+// detectVersion gets the version of the database for the databases whose
+// SQLs depend on it, it's retried by the next call if it failed
+func (engine *Engine) detectVersion() {
+	switch engine.dialect.URI().DBType {
+	case schemas.MSSQL, schemas.ORACLE:
+	default:
+		return
+	}
+	engine.versionMutex.Lock()
+	defer engine.versionMutex.Unlock()
+	if engine.dialect.ServerVersion() != nil {
+		return
+	}
+	if _, err := engine.DBVersion(); err != nil {
+		engine.logger.Warnf("detect the version of database failed: %v", err)
+	}
 }
 
 // TableInfo get table info according to bean's content
@@ -992,6 +1153,18 @@ func (engine *Engine) UnMapType(t reflect.Type) {
 	engine.tagParser.ClearCacheTable(t)
 }
 
+// SetKeyProvider sets the key provider of the columns tagged with encrypted
+func (engine *Engine) SetKeyProvider(provider convert.KeyProvider) {
+	engine.tagParser.SetKeyProvider(provider)
+}
+
+// RegisterConverter registers the converter of a type, the fields of the type
+// or the pointer of it are converted by it from and to database. A nil
+// converter unregisters the type.
+func (engine *Engine) RegisterConverter(t reflect.Type, converter convert.Converter) {
+	engine.tagParser.RegisterConverter(t, converter)
+}
+
 // Sync the new struct changes to database, this method will automatically add
 // table, column, index, unique. but will not delete or change anything.
 // If you change some field, you should change the database manually.
@@ -1053,7 +1226,7 @@ func (engine *Engine) Sync(beans ...interface{}) error {
 					return err
 				}
 				if index.Type == schemas.UniqueType {
-					isExist, err := session.isIndexExist2(tableNameNoSchema, index.Cols, true)
+					isExist, err := session.isIndexExist2(tableNameNoSchema, index)
 					if err != nil {
 						return err
 					}
@@ -1068,7 +1241,7 @@ func (engine *Engine) Sync(beans ...interface{}) error {
 						}
 					}
 				} else if index.Type == schemas.IndexType {
-					isExist, err := session.isIndexExist2(tableNameNoSchema, index.Cols, false)
+					isExist, err := session.isIndexExist2(tableNameNoSchema, index)
 					if err != nil {
 						return err
 					}
@@ -1138,6 +1311,54 @@ func (engine *Engine) DropTables(beans ...interface{}) error {
 	return session.Commit()
 }
 
+// CreateViews creates or replaces the views of the beans which implement
+// schemas.View
+func (engine *Engine) CreateViews(beans ...interface{}) error {
+	session := engine.NewSession()
+	defer session.Close()
+
+	err := session.Begin()
+	if err != nil {
+		return err
+	}
+
+	for _, bean := range beans {
+		err = session.createView(bean)
+		if err != nil {
+			session.Rollback()
+			return err
+		}
+	}
+	return session.Commit()
+}
+
+// DropViews drops the views of the beans or the view names
+func (engine *Engine) DropViews(beanOrViewNames ...interface{}) error {
+	session := engine.NewSession()
+	defer session.Close()
+
+	err := session.Begin()
+	if err != nil {
+		return err
+	}
+
+	for _, bean := range beanOrViewNames {
+		err = session.dropView(bean)
+		if err != nil {
+			session.Rollback()
+			return err
+		}
+	}
+	return session.Commit()
+}
+
+// RefreshMaterializedView refreshes the rows of a materialized view
+func (engine *Engine) RefreshMaterializedView(beanOrViewName interface{}) error {
+	session := engine.NewSession()
+	defer session.Close()
+	return session.RefreshMaterializedView(beanOrViewName)
+}
+
 // DropIndexes drop indexes of a table
 func (engine *Engine) DropIndexes(bean interface{}) error {
 	session := engine.NewSession()
@@ -1287,6 +1508,41 @@ func (engine *Engine) SumsInt(bean interface{}, colNames ...string) ([]int64, er
 	return session.SumsInt(bean, colNames...)
 }
 
+// Avg returns the average of the column. bean's non-empty fields are conditions.
+func (engine *Engine) Avg(bean interface{}, colName string) (float64, error) {
+	session := engine.NewSession()
+	defer session.Close()
+	return session.Avg(bean, colName)
+}
+
+// Min scans the minimum of the column into res. bean's non-empty fields are conditions.
+func (engine *Engine) Min(bean interface{}, colName string, res interface{}) error {
+	session := engine.NewSession()
+	defer session.Close()
+	return session.Min(bean, colName, res)
+}
+
+// Max scans the maximum of the column into res. bean's non-empty fields are conditions.
+func (engine *Engine) Max(bean interface{}, colName string, res interface{}) error {
+	session := engine.NewSession()
+	defer session.Close()
+	return session.Max(bean, colName, res)
+}
+
+// CountDistinct counts the distinct values of the column. bean's non-empty fields are conditions.
+func (engine *Engine) CountDistinct(bean interface{}, colName string) (int64, error) {
+	session := engine.NewSession()
+	defer session.Close()
+	return session.CountDistinct(bean, colName)
+}
+
+// Aggregate adds the aggregate or window function expressions to the selected columns
+func (engine *Engine) Aggregate(exprs ...AggrExpr) *Session {
+	session := engine.NewSession()
+	session.isAutoClose = true
+	return session.Aggregate(exprs...)
+}
+
 // ImportFile SQL DDL file
 func (engine *Engine) ImportFile(ddlPath string) ([]sql.Result, error) {
 	session := engine.NewSession()
@@ -1358,10 +1614,6 @@ func (engine *Engine) Unscoped() *Session {
 	return session.Unscoped()
 }
 
-func (engine *Engine) tbNameWithSchema(v string) string {
-	return dialects.TableNameWithSchema(engine.dialect, v)
-}
-
 // Context creates a session with the context
 func (engine *Engine) Context(ctx context.Context) *Session {
 	session := engine.NewSession()
@@ -1401,3 +1653,14 @@ func (engine *Engine) Transaction(f func(*Session) (interface{}, error)) (interf
 
 	return result, nil
 }
+
+// ToSQL runs f on a dry run session and returns the SQLs it would execute
+func (engine *Engine) ToSQL(f func(*Session) error) ([]SQLStatement, error) {
+	session := engine.NewSession()
+	defer session.Close()
+
+	if err := f(session.DryRun()); err != nil && err != ErrDryRun {
+		return session.DryRunSQLs(), err
+	}
+	return session.DryRunSQLs(), nil
+}
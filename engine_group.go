@@ -6,10 +6,12 @@ package xorm
 
 import (
 	"context"
+	"reflect"
 	"time"
 
 	"github.com/xorm-io/xorm/caches"
 	"github.com/xorm-io/xorm/contexts"
+	"github.com/xorm-io/xorm/convert"
 	"github.com/xorm-io/xorm/dialects"
 	"github.com/xorm-io/xorm/log"
 	"github.com/xorm-io/xorm/names"
@@ -120,6 +122,22 @@ func (eg *EngineGroup) SetColumnMapper(mapper names.Mapper) {
 	}
 }
 
+// RegisterConverter registers the converter of a type
+func (eg *EngineGroup) RegisterConverter(t reflect.Type, converter convert.Converter) {
+	eg.Engine.RegisterConverter(t, converter)
+	for i := 0; i < len(eg.slaves); i++ {
+		eg.slaves[i].RegisterConverter(t, converter)
+	}
+}
+
+// SetKeyProvider sets the key provider of the encrypted columns
+func (eg *EngineGroup) SetKeyProvider(provider convert.KeyProvider) {
+	eg.Engine.SetKeyProvider(provider)
+	for i := 0; i < len(eg.slaves); i++ {
+		eg.slaves[i].SetKeyProvider(provider)
+	}
+}
+
 // SetConnMaxLifetime sets the maximum amount of time a connection may be reused.
 func (eg *EngineGroup) SetConnMaxLifetime(d time.Duration) {
 	eg.Engine.SetConnMaxLifetime(d)
@@ -23,4 +23,17 @@ var (
 	ErrCacheFailed = errors.New("Cache failed")
 	// ErrConditionType condition type unsupported
 	ErrConditionType = errors.New("Unsupported condition type")
+	// ErrDialectRequired is returned when a dialect specified condition is written without a session
+	ErrDialectRequired = errors.New("The condition should be written by a session")
+	// ErrDryRun is returned by the queries of a dry run session since no rows could be read
+	ErrDryRun = errors.New("Dry run, the SQL is not executed")
+	// ErrShardAggregate is returned when an aggregate except the sum runs on all the shards
+	ErrShardAggregate = errors.New("Only the sums could run on all the shards, the shard should be given by the bean or Table")
+	// ErrShardMapOrder is returned when the rows of the shards are found into a map with an order or a limit
+	ErrShardMapOrder = errors.New("The rows of the shards could not be found into a map with an order or a limit")
+	// ErrJoinedColumn is returned when a column of a join result is neither a
+	// column of the table nor a nested struct field of a joined table
+	ErrJoinedColumn = errors.New("The columns of a join result should be the columns of the table or the nested struct fields of the joined tables")
+	// ErrViewReadOnly is returned when a bean of a view is inserted, updated or deleted
+	ErrViewReadOnly = errors.New("The view is read only")
 )
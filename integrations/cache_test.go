@@ -8,6 +8,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/xorm-io/xorm"
 	"github.com/xorm-io/xorm/caches"
 
 	"github.com/stretchr/testify/assert"
@@ -179,3 +180,345 @@ func TestCacheGet(t *testing.T) {
 
 	testEngine.SetDefaultCacher(oldCacher)
 }
+
+type clearCountCacher struct {
+	caches.Cacher
+	clears int
+	puts   int
+}
+
+func (c *clearCountCacher) PutBean(tableName string, id string, obj interface{}) {
+	c.puts++
+	c.Cacher.PutBean(tableName, id, obj)
+}
+
+func (c *clearCountCacher) ClearBeans(tableName string) {
+	c.clears++
+	c.Cacher.ClearBeans(tableName)
+}
+
+func TestCacheTx(t *testing.T) {
+	assert.NoError(t, PrepareEngine())
+
+	type MailBox5 struct {
+		Id       int64
+		Username string
+	}
+
+	oldCacher := testEngine.GetDefaultCacher()
+	cacher := &clearCountCacher{Cacher: caches.NewLRUCacher2(caches.NewMemoryStore(), time.Hour, 10000)}
+	testEngine.SetDefaultCacher(cacher)
+	defer testEngine.SetDefaultCacher(oldCacher)
+
+	assert.NoError(t, testEngine.Sync2(new(MailBox5)))
+
+	var box = MailBox5{Username: "user1"}
+	_, err := testEngine.Insert(&box)
+	assert.NoError(t, err)
+
+	var box1 MailBox5
+	has, err := testEngine.ID(box.Id).Get(&box1)
+	assert.NoError(t, err)
+	assert.True(t, has)
+
+	session := testEngine.NewSession()
+	defer session.Close()
+
+	// the invalidations are applied on commit
+	assert.NoError(t, session.Begin())
+	_, err = session.ID(box.Id).Update(&MailBox5{Username: "user2"})
+	assert.NoError(t, err)
+	assert.EqualValues(t, 0, cacher.clears)
+
+	// the cache is bypassed in the transaction
+	var box2 MailBox5
+	has, err = session.ID(box.Id).Get(&box2)
+	assert.NoError(t, err)
+	assert.True(t, has)
+	assert.EqualValues(t, "user2", box2.Username)
+
+	assert.NoError(t, session.Commit())
+	assert.EqualValues(t, 1, cacher.clears)
+
+	var box3 MailBox5
+	has, err = testEngine.ID(box.Id).Get(&box3)
+	assert.NoError(t, err)
+	assert.True(t, has)
+	assert.EqualValues(t, "user2", box3.Username)
+
+	// the invalidations are discarded on rollback
+	assert.NoError(t, session.Begin())
+	_, err = session.ID(box.Id).Update(&MailBox5{Username: "user3"})
+	assert.NoError(t, err)
+	assert.NoError(t, session.Rollback())
+	assert.EqualValues(t, 1, cacher.clears)
+
+	var box4 MailBox5
+	has, err = testEngine.ID(box.Id).Get(&box4)
+	assert.NoError(t, err)
+	assert.True(t, has)
+	assert.EqualValues(t, "user2", box4.Username)
+
+	// the rows read in a transaction are not put into the cache
+	var other = MailBox5{Username: "other"}
+	_, err = testEngine.Insert(&other)
+	assert.NoError(t, err)
+	puts := cacher.puts
+	assert.NoError(t, session.Begin())
+	var box5 MailBox5
+	has, err = session.ID(other.Id).Get(&box5)
+	assert.NoError(t, err)
+	assert.True(t, has)
+	var boxes []MailBox5
+	assert.NoError(t, session.Find(&boxes))
+	assert.EqualValues(t, 2, len(boxes))
+	assert.NoError(t, session.Commit())
+	assert.EqualValues(t, puts, cacher.puts)
+
+	// so are the results
+	db := testEngine.(*xorm.Engine).DB()
+	assert.NoError(t, session.Begin())
+	count, err := session.CacheFor(time.Hour).Count(new(MailBox5))
+	assert.NoError(t, err)
+	assert.EqualValues(t, 2, count)
+	assert.NoError(t, session.Commit())
+	_, err = db.Exec("INSERT INTO " + testEngine.TableName(new(MailBox5), true) + " (username) VALUES ('raw')")
+	assert.NoError(t, err)
+	count, err = testEngine.CacheFor(time.Hour).Count(new(MailBox5))
+	assert.NoError(t, err)
+	assert.EqualValues(t, 3, count)
+}
+
+func TestCacheStats(t *testing.T) {
+	assert.NoError(t, PrepareEngine())
+
+	type MailBox6 struct {
+		Id       int64
+		Username string
+	}
+
+	assert.NoError(t, testEngine.Sync2(new(MailBox6)))
+
+	cacher := caches.NewLRUCacher2(caches.NewMemoryStore(), time.Hour, 10000)
+	assert.NoError(t, testEngine.MapCacher(new(MailBox6), cacher, caches.TableOptions{MaxElementSize: 1}))
+	defer testEngine.MapCacher(new(MailBox6), testEngine.GetDefaultCacher())
+
+	assert.EqualValues(t, caches.ErrTableOptionsNotSupported,
+		testEngine.MapCacher(new(MailBox6), &clearCountCacher{Cacher: cacher}, caches.TableOptions{MaxElementSize: 1}))
+
+	var boxes = []*MailBox6{{Username: "user1"}, {Username: "user2"}}
+	_, err := testEngine.Insert(boxes[0], boxes[1])
+	assert.NoError(t, err)
+
+	for i := 0; i < 2; i++ {
+		var box MailBox6
+		has, err := testEngine.ID(boxes[0].Id).Get(&box)
+		assert.NoError(t, err)
+		assert.True(t, has)
+	}
+	var box MailBox6
+	has, err := testEngine.ID(boxes[1].Id).Get(&box)
+	assert.NoError(t, err)
+	assert.True(t, has)
+
+	stats := testEngine.(*xorm.Engine).CacheStats()["mail_box6"]
+	assert.True(t, stats.Hits > 0)
+	assert.True(t, stats.Misses > 0)
+	assert.True(t, stats.Evictions > 0)
+	assert.True(t, stats.Entries > 0)
+}
+
+func TestCacheFor(t *testing.T) {
+	assert.NoError(t, PrepareEngine())
+
+	type ResultCache1 struct {
+		Id   int64
+		Name string
+	}
+
+	type ResultCache2 struct {
+		Id      int64
+		CacheId int64
+		Score   int
+	}
+
+	assertSync(t, new(ResultCache1), new(ResultCache2))
+
+	// the writes of db are not through xorm
+	db := testEngine.(*xorm.Engine).DB()
+
+	_, err := testEngine.Insert(&ResultCache1{Name: "a"})
+	assert.NoError(t, err)
+
+	count, err := testEngine.CacheFor(time.Hour).Count(new(ResultCache1))
+	assert.NoError(t, err)
+	assert.EqualValues(t, 1, count)
+
+	// a write not through xorm is not seen until the ttl
+	_, err = db.Exec("INSERT INTO " + testEngine.TableName(new(ResultCache1), true) + " (name) VALUES ('b')")
+	assert.NoError(t, err)
+	count, err = testEngine.CacheFor(time.Hour).Count(new(ResultCache1))
+	assert.NoError(t, err)
+	assert.EqualValues(t, 1, count)
+
+	var beans []ResultCache1
+	assert.NoError(t, testEngine.CacheFor(time.Hour).Asc("id").Find(&beans))
+	assert.EqualValues(t, 2, len(beans))
+
+	// the results are invalidated by an insert through xorm
+	_, err = testEngine.Insert(&ResultCache1{Name: "c"})
+	assert.NoError(t, err)
+	count, err = testEngine.CacheFor(time.Hour).Count(new(ResultCache1))
+	assert.NoError(t, err)
+	assert.EqualValues(t, 3, count)
+
+	beans = beans[:0]
+	assert.NoError(t, testEngine.CacheFor(time.Hour).Asc("id").Find(&beans))
+	assert.EqualValues(t, 3, len(beans))
+
+	var bean ResultCache1
+	has, err := testEngine.CacheFor(time.Hour).Where("name = ?", "a").Get(&bean)
+	assert.NoError(t, err)
+	assert.True(t, has)
+	assert.EqualValues(t, "a", bean.Name)
+
+	// the results are invalidated by an update and a raw exec
+	_, err = testEngine.ID(bean.Id).Update(&ResultCache1{Name: "d"})
+	assert.NoError(t, err)
+	bean = ResultCache1{}
+	has, err = testEngine.CacheFor(time.Hour).Where("name = ?", "a").Get(&bean)
+	assert.NoError(t, err)
+	assert.False(t, has)
+
+	results, err := testEngine.CacheFor(time.Hour).QueryString("SELECT * FROM " + testEngine.TableName(new(ResultCache1), true))
+	assert.NoError(t, err)
+	assert.EqualValues(t, 3, len(results))
+	_, err = testEngine.Exec("DELETE FROM "+testEngine.TableName(new(ResultCache1), true)+" WHERE name = ?", "d")
+	assert.NoError(t, err)
+	results, err = testEngine.CacheFor(time.Hour).QueryString("SELECT * FROM " + testEngine.TableName(new(ResultCache1), true))
+	assert.NoError(t, err)
+	assert.EqualValues(t, 2, len(results))
+
+	// the results of a join are invalidated by a write of the joined table
+	joinCount := func() int64 {
+		count, err := testEngine.CacheFor(time.Hour).Table(new(ResultCache1)).
+			Join("INNER", testEngine.TableName(new(ResultCache2), true), "`result_cache1`.id = `result_cache2`.cache_id").
+			Count()
+		assert.NoError(t, err)
+		return count
+	}
+	assert.EqualValues(t, 0, joinCount())
+	_, err = testEngine.Insert(&ResultCache2{CacheId: beans[1].Id, Score: 1})
+	assert.NoError(t, err)
+	assert.EqualValues(t, 1, joinCount())
+
+	// the results expire after the ttl
+	_, err = db.Exec("DELETE FROM " + testEngine.TableName(new(ResultCache2), true))
+	assert.NoError(t, err)
+	sum, err := testEngine.CacheFor(time.Millisecond).Sum(new(ResultCache2), "score")
+	assert.NoError(t, err)
+	assert.EqualValues(t, 0, sum)
+	_, err = db.Exec("INSERT INTO " + testEngine.TableName(new(ResultCache2), true) + " (cache_id, score) VALUES (1, 2)")
+	assert.NoError(t, err)
+	time.Sleep(10 * time.Millisecond)
+	sum, err = testEngine.CacheFor(time.Millisecond).Sum(new(ResultCache2), "score")
+	assert.NoError(t, err)
+	assert.EqualValues(t, 2, sum)
+}
+
+func TestCacheForCopy(t *testing.T) {
+	assert.NoError(t, PrepareEngine())
+
+	type ResultCache3 struct {
+		Id   int64
+		Name string
+		Tags []string `xorm:"json"`
+	}
+
+	assertSync(t, new(ResultCache3))
+
+	_, err := testEngine.Insert(&ResultCache3{Name: "a", Tags: []string{"x", "y"}})
+	assert.NoError(t, err)
+
+	// the changes of the found beans don't change the cached ones
+	var beans []*ResultCache3
+	assert.NoError(t, testEngine.CacheFor(time.Hour).Find(&beans))
+	if assert.EqualValues(t, 1, len(beans)) {
+		beans[0].Name = "b"
+		beans[0].Tags[0] = "z"
+	}
+	for i := 0; i < 2; i++ {
+		beans = nil
+		assert.NoError(t, testEngine.CacheFor(time.Hour).Find(&beans))
+		if assert.EqualValues(t, 1, len(beans)) {
+			assert.EqualValues(t, "a", beans[0].Name)
+			assert.EqualValues(t, []string{"x", "y"}, beans[0].Tags)
+		}
+		beans[0].Tags[1] = "z"
+	}
+
+	for i := 0; i < 2; i++ {
+		var bean ResultCache3
+		has, err := testEngine.CacheFor(time.Hour).Get(&bean)
+		assert.NoError(t, err)
+		assert.True(t, has)
+		assert.EqualValues(t, []string{"x", "y"}, bean.Tags)
+		bean.Tags[0] = "z"
+	}
+
+	for i := 0; i < 2; i++ {
+		results, err := testEngine.CacheFor(time.Hour).Query("SELECT name FROM " + testEngine.TableName(new(ResultCache3), true))
+		assert.NoError(t, err)
+		if assert.EqualValues(t, 1, len(results)) {
+			assert.EqualValues(t, "a", string(results[0]["name"]))
+			results[0]["name"][0] = 'b'
+			results[0]["tags"] = []byte("z")
+		}
+	}
+}
+
+func TestCacheForKey(t *testing.T) {
+	assert.NoError(t, PrepareEngine())
+
+	type ResultCache4 struct {
+		Id int64
+		A  string
+		B  string
+	}
+
+	assertSync(t, new(ResultCache4))
+
+	_, err := testEngine.Insert(&ResultCache4{A: "a b", B: "c"})
+	assert.NoError(t, err)
+
+	// the args are not confused by the cache key
+	cond := colMapper.Obj2Table("A") + " = ? AND " + colMapper.Obj2Table("B") + " = ?"
+	count, err := testEngine.CacheFor(time.Minute).Where(cond, "a b", "c").Count(new(ResultCache4))
+	assert.NoError(t, err)
+	assert.EqualValues(t, 1, count)
+	count, err = testEngine.CacheFor(time.Minute).Where(cond, "a", "b c").Count(new(ResultCache4))
+	assert.NoError(t, err)
+	assert.EqualValues(t, 0, count)
+
+	// the least recently used results are evicted
+	engine := testEngine.(*xorm.Engine)
+	engine.SetResultCacheLimits(1, 0)
+	defer engine.SetResultCacheLimits(caches.CacheMaxResults, caches.CacheMaxMemory)
+
+	count, err = testEngine.CacheFor(time.Minute).Count(new(ResultCache4))
+	assert.NoError(t, err)
+	assert.EqualValues(t, 1, count)
+	_, err = engine.DB().Exec("INSERT INTO " + testEngine.TableName(new(ResultCache4), true) + " (" +
+		colMapper.Obj2Table("A") + ", " + colMapper.Obj2Table("B") + ") VALUES ('d', 'e')")
+	assert.NoError(t, err)
+	count, err = testEngine.CacheFor(time.Minute).Count(new(ResultCache4))
+	assert.NoError(t, err)
+	assert.EqualValues(t, 1, count)
+
+	count, err = testEngine.CacheFor(time.Minute).Where(cond, "d", "e").Count(new(ResultCache4))
+	assert.NoError(t, err)
+	assert.EqualValues(t, 1, count)
+	count, err = testEngine.CacheFor(time.Minute).Count(new(ResultCache4))
+	assert.NoError(t, err)
+	assert.EqualValues(t, 2, count)
+}
@@ -8,10 +8,12 @@ import (
 	"context"
 	"fmt"
 	"os"
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/xorm-io/xorm"
+	"github.com/xorm-io/xorm/log"
 	"github.com/xorm-io/xorm/schemas"
 
 	_ "github.com/denisenkom/go-mssqldb"
@@ -193,6 +195,32 @@ func TestDumpTables2(t *testing.T) {
 	assert.NoError(t, testEngine.(*xorm.Engine).DumpTablesToFile([]*schemas.Table{tb}, fp))
 }
 
+func TestDumpSequence(t *testing.T) {
+	assert.NoError(t, PrepareEngine())
+
+	type TestDumpSequenceStruct struct {
+		Id   int64 `xorm:"pk autoincr sequence"`
+		Name string
+	}
+
+	assertSync(t, new(TestDumpSequenceStruct))
+
+	cnt, err := testEngine.Insert([]TestDumpSequenceStruct{{Id: 1, Name: "1"}, {Id: 2, Name: "2"}, {Id: 3, Name: "3"}})
+	assert.NoError(t, err)
+	assert.EqualValues(t, 3, cnt)
+
+	tb, err := testEngine.TableInfo(new(TestDumpSequenceStruct))
+	assert.NoError(t, err)
+
+	// the sequence of the dumped table is created unless it exists and
+	// restarted after the dumped values
+	var buf strings.Builder
+	assert.NoError(t, testEngine.(*xorm.Engine).DumpTables([]*schemas.Table{tb}, &buf, schemas.POSTGRES))
+	seqName := schemas.SequenceName(tb.Name, "id")
+	assert.Contains(t, buf.String(), fmt.Sprintf("CREATE SEQUENCE IF NOT EXISTS \"%s\" START WITH 4 INCREMENT BY 1;\n"+
+		"ALTER SEQUENCE \"%s\" RESTART WITH 4;\n", seqName, seqName))
+}
+
 func TestSetSchema(t *testing.T) {
 	assert.NoError(t, PrepareEngine())
 
@@ -262,3 +290,60 @@ func TestGetColumns(t *testing.T) {
 	assert.Equal(t, comment, hasComment)
 	assert.Zero(t, noComment)
 }
+
+func TestStructuredLogger(t *testing.T) {
+	assert.NoError(t, PrepareEngine())
+
+	type TestStructuredLog struct {
+		Id       int64
+		Name     string
+		Password string `xorm:"varchar(64) sensitive"`
+	}
+
+	assertSync(t, new(TestStructuredLog))
+
+	type record struct {
+		level   log.LogLevel
+		msg     string
+		keyvals map[string]interface{}
+	}
+	var records []record
+	logger := log.NewStructuredLogger(log.KVSinkFunc(func(ctx context.Context, level log.LogLevel, msg string, keyvals ...interface{}) {
+		var kv = make(map[string]interface{})
+		for i := 0; i+1 < len(keyvals); i += 2 {
+			kv[keyvals[i].(string)] = keyvals[i+1]
+		}
+		records = append(records, record{level, msg, kv})
+	}))
+	logger.ShowSQL(true)
+
+	engine := testEngine.(*xorm.Engine)
+	oldLogger := engine.Logger()
+	engine.SetLogger(logger)
+	defer engine.SetLogger(oldLogger)
+
+	_, err := engine.Insert(&TestStructuredLog{Name: "lunny", Password: "secret"})
+	assert.NoError(t, err)
+
+	var found bool
+	for _, r := range records {
+		if r.msg != "[SQL]" || !strings.HasPrefix(strings.ToUpper(r.keyvals[log.FieldSQL].(string)), "INSERT") {
+			continue
+		}
+		found = true
+		args := r.keyvals[log.FieldArgs].([]interface{})
+		assert.EqualValues(t, []interface{}{"lunny", log.RedactedValue}, args)
+		assert.EqualValues(t, 1, r.keyvals[log.FieldRows])
+		_, ok := r.keyvals[log.FieldDuration].(time.Duration)
+		assert.True(t, ok)
+	}
+	assert.True(t, found)
+
+	records = nil
+	var s TestStructuredLog
+	has, err := engine.NoCache().Where("password = ?", "secret").Get(&s)
+	assert.NoError(t, err)
+	assert.True(t, has)
+	assert.EqualValues(t, 1, len(records))
+	assert.EqualValues(t, []interface{}{log.RedactedValue}, records[0].keyvals[log.FieldArgs])
+}
@@ -0,0 +1,138 @@
+// Copyright 2021 The Xorm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package integrations
+
+import (
+	"database/sql"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/xorm-io/xorm"
+)
+
+type AggrEmployee struct {
+	Id       int64
+	Name     string
+	DepartId int64
+	Salary   int
+}
+
+func prepareAggregate(t *testing.T) {
+	assert.NoError(t, PrepareEngine())
+	assertSync(t, new(AggrEmployee))
+
+	_, err := testEngine.Insert([]AggrEmployee{
+		{Name: "a", DepartId: 1, Salary: 100},
+		{Name: "b", DepartId: 1, Salary: 300},
+		{Name: "c", DepartId: 1, Salary: 300},
+		{Name: "d", DepartId: 2, Salary: 200},
+	})
+	assert.NoError(t, err)
+}
+
+func TestAggregateFuncs(t *testing.T) {
+	prepareAggregate(t)
+
+	avg, err := testEngine.Avg(new(AggrEmployee), "salary")
+	assert.NoError(t, err)
+	assert.EqualValues(t, 225, avg)
+
+	var min, max int
+	assert.NoError(t, testEngine.Min(new(AggrEmployee), "salary", &min))
+	assert.EqualValues(t, 100, min)
+	assert.NoError(t, testEngine.Where("depart_id = ?", 2).Max(new(AggrEmployee), "salary", &max))
+	assert.EqualValues(t, 200, max)
+
+	var none sql.NullInt64
+	assert.NoError(t, testEngine.Where("depart_id = ?", 3).Max(new(AggrEmployee), "salary", &none))
+	assert.False(t, none.Valid)
+
+	cnt, err := testEngine.CountDistinct(new(AggrEmployee), "salary")
+	assert.NoError(t, err)
+	assert.EqualValues(t, 3, cnt)
+
+	avg, err = testEngine.Where("depart_id = ?", 3).Avg(new(AggrEmployee), "salary")
+	assert.NoError(t, err)
+	assert.EqualValues(t, 0, avg)
+}
+
+func TestAggregateGroupBy(t *testing.T) {
+	prepareAggregate(t)
+
+	type DepartStat struct {
+		DepartId  int64
+		Total     int
+		Salaries  int
+		MaxSalary int
+		AvgSalary float64
+	}
+
+	var stats []DepartStat
+	err := testEngine.Table(new(AggrEmployee)).
+		Aggregate(
+			xorm.Count("*").As("total"),
+			xorm.CountDistinct("salary").As("salaries"),
+			xorm.Max("salary").As("max_salary"),
+			xorm.Avg("salary").As("avg_salary"),
+		).
+		GroupBy("depart_id").
+		Asc("depart_id").
+		Find(&stats)
+	assert.NoError(t, err)
+	assert.EqualValues(t, []DepartStat{
+		{DepartId: 1, Total: 3, Salaries: 2, MaxSalary: 300, AvgSalary: float64(700) / 3},
+		{DepartId: 2, Total: 1, Salaries: 1, MaxSalary: 200, AvgSalary: 200},
+	}, stats)
+
+	results, err := testEngine.Table(new(AggrEmployee)).Cols("depart_id").
+		Aggregate(xorm.Sum("salary").As("salaries")).
+		GroupBy("depart_id").
+		Having("sum(salary) > 300").
+		QueryString()
+	assert.NoError(t, err)
+	assert.EqualValues(t, []map[string]string{{"depart_id": "1", "salaries": "700"}}, results)
+}
+
+func TestAggregateWindow(t *testing.T) {
+	prepareAggregate(t)
+
+	type EmployeeRank struct {
+		Name       string
+		RowNumber  int
+		Rank       int
+		DenseRank  int
+		PrevSalary sql.NullInt64
+	}
+
+	window := xorm.PartitionBy("depart_id").OrderBy("salary DESC, name")
+
+	var ranks []EmployeeRank
+	err := testEngine.Table(new(AggrEmployee)).Cols("name").
+		Aggregate(
+			xorm.RowNumber().Over(window).As("row_number"),
+			xorm.Rank().Over(xorm.PartitionBy("depart_id").OrderBy("salary DESC")).As("rank"),
+			xorm.DenseRank().Over(xorm.PartitionBy("depart_id").OrderBy("salary")).As("dense_rank"),
+			xorm.Lag("salary", 1).Over(window).As("prev_salary"),
+		).
+		Asc("name").
+		Find(&ranks)
+	assert.NoError(t, err)
+	assert.EqualValues(t, []EmployeeRank{
+		{Name: "a", RowNumber: 3, Rank: 3, DenseRank: 1, PrevSalary: sql.NullInt64{Int64: 300, Valid: true}},
+		{Name: "b", RowNumber: 1, Rank: 1, DenseRank: 2},
+		{Name: "c", RowNumber: 2, Rank: 1, DenseRank: 2, PrevSalary: sql.NullInt64{Int64: 300, Valid: true}},
+		{Name: "d", RowNumber: 1, Rank: 1, DenseRank: 1},
+	}, ranks)
+
+	// the window functions could be used in Select too
+	results, err := testEngine.Table(new(AggrEmployee)).
+		Select("name, " + xorm.Lead("name", 1).Over(xorm.Window{}.OrderBy("id")).As("next_name").String()).
+		Asc("id").
+		QueryString()
+	assert.NoError(t, err)
+	assert.EqualValues(t, 4, len(results))
+	assert.EqualValues(t, "b", results[0]["next_name"])
+	assert.EqualValues(t, "d", results[2]["next_name"])
+}
@@ -0,0 +1,78 @@
+// Copyright 2021 The Xorm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package integrations
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/xorm-io/xorm"
+	"github.com/xorm-io/xorm/schemas"
+)
+
+func TestArrayColumn(t *testing.T) {
+	assert.NoError(t, PrepareEngine())
+
+	type ArrayColumnStruct struct {
+		Id     int64
+		Tags   []string    `xorm:"array"`
+		Scores []int64     `xorm:"array"`
+		Ratios []float64   `xorm:"array"`
+		Flags  []bool      `xorm:"array"`
+		Times  []time.Time `xorm:"array"`
+	}
+
+	assertSync(t, new(ArrayColumnStruct))
+
+	tm := time.Date(2021, 1, 2, 3, 4, 5, 0, testEngine.GetTZLocation())
+	_, err := testEngine.Insert([]ArrayColumnStruct{
+		{
+			Tags:   []string{"go", `a "quoted", tag`},
+			Scores: []int64{1, 2, 3},
+			Ratios: []float64{0.5},
+			Flags:  []bool{true, false},
+			Times:  []time.Time{tm},
+		},
+		{
+			Tags:   []string{"rust"},
+			Scores: []int64{4},
+		},
+	})
+	assert.NoError(t, err)
+
+	var bean ArrayColumnStruct
+	has, err := testEngine.ID(1).Get(&bean)
+	assert.NoError(t, err)
+	assert.True(t, has)
+	assert.EqualValues(t, []string{"go", `a "quoted", tag`}, bean.Tags)
+	assert.EqualValues(t, []int64{1, 2, 3}, bean.Scores)
+	assert.EqualValues(t, []float64{0.5}, bean.Ratios)
+	assert.EqualValues(t, []bool{true, false}, bean.Flags)
+	assert.EqualValues(t, 1, len(bean.Times))
+	assert.EqualValues(t, tm.Unix(), bean.Times[0].Unix())
+
+	_, err = testEngine.ID(2).Cols("tags").Update(&ArrayColumnStruct{Tags: []string{"rust", "go"}})
+	assert.NoError(t, err)
+
+	if testEngine.Dialect().URI().DBType == schemas.SQLITE {
+		// the array is stored as JSON which needs JSON1 to be queried
+		if _, err := testEngine.Exec("SELECT json('{}')"); err != nil {
+			return
+		}
+	}
+
+	cnt, err := testEngine.Where(xorm.ArrayContains("tags", "go")).Count(new(ArrayColumnStruct))
+	assert.NoError(t, err)
+	assert.EqualValues(t, 2, cnt)
+
+	cnt, err = testEngine.Where(xorm.ArrayContains("scores", []int64{1, 3})).Count(new(ArrayColumnStruct))
+	assert.NoError(t, err)
+	assert.EqualValues(t, 1, cnt)
+
+	cnt, err = testEngine.Where(xorm.ArrayOverlap("scores", 3, 4)).Count(new(ArrayColumnStruct))
+	assert.NoError(t, err)
+	assert.EqualValues(t, 2, cnt)
+}
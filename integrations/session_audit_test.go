@@ -0,0 +1,240 @@
+// Copyright 2021 The Xorm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package integrations
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/xorm-io/xorm"
+	"github.com/xorm-io/xorm/log"
+	"github.com/xorm-io/xorm/schemas"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type AuditedAccount struct {
+	Id      int64
+	Name    string
+	Balance int
+}
+
+func (AuditedAccount) Auditable() {}
+
+type AuditedByName struct {
+	Id   int64
+	Name string
+}
+
+func TestAudit(t *testing.T) {
+	assert.NoError(t, PrepareEngine())
+	assertSync(t, new(xorm.AuditLog), new(AuditedAccount), new(AuditedByName))
+
+	ctx := xorm.WithAuditActor(context.Background(), "alice")
+
+	var account = AuditedAccount{Name: "lunny", Balance: 100}
+	cnt, err := testEngine.Context(ctx).Insert(&account)
+	assert.NoError(t, err)
+	assert.EqualValues(t, 1, cnt)
+
+	cnt, err = testEngine.Context(ctx).ID(account.Id).Update(&AuditedAccount{Balance: 80})
+	assert.NoError(t, err)
+	assert.EqualValues(t, 1, cnt)
+
+	// nothing changed, no log
+	cnt, err = testEngine.ID(account.Id).Update(&AuditedAccount{Balance: 80})
+	assert.NoError(t, err)
+	assert.EqualValues(t, 1, cnt)
+
+	cnt, err = testEngine.ID(account.Id).Delete(new(AuditedAccount))
+	assert.NoError(t, err)
+	assert.EqualValues(t, 1, cnt)
+
+	var logs []xorm.AuditLog
+	assert.NoError(t, testEngine.Asc("id").Find(&logs))
+	if assert.EqualValues(t, 3, len(logs)) {
+		pk := fmt.Sprint(account.Id)
+		assert.EqualValues(t, xorm.AuditInsert, logs[0].Operation)
+		assert.EqualValues(t, "audited_account", logs[0].Table)
+		assert.EqualValues(t, pk, logs[0].PK)
+		assert.EqualValues(t, "alice", logs[0].Actor)
+		assert.False(t, logs[0].Created.IsZero())
+		if assert.NotNil(t, logs[0].Changes["name"]) {
+			assert.Nil(t, logs[0].Changes["name"].Old)
+			assert.EqualValues(t, "lunny", logs[0].Changes["name"].New)
+		}
+
+		assert.EqualValues(t, xorm.AuditUpdate, logs[1].Operation)
+		assert.EqualValues(t, pk, logs[1].PK)
+		assert.EqualValues(t, "alice", logs[1].Actor)
+		assert.EqualValues(t, 1, len(logs[1].Changes))
+		if assert.NotNil(t, logs[1].Changes["balance"]) {
+			assert.EqualValues(t, 100, logs[1].Changes["balance"].Old)
+			assert.EqualValues(t, 80, logs[1].Changes["balance"].New)
+		}
+
+		assert.EqualValues(t, xorm.AuditDelete, logs[2].Operation)
+		assert.EqualValues(t, pk, logs[2].PK)
+		assert.EqualValues(t, "", logs[2].Actor)
+		if assert.NotNil(t, logs[2].Changes["balance"]) {
+			assert.EqualValues(t, 80, logs[2].Changes["balance"].Old)
+			assert.Nil(t, logs[2].Changes["balance"].New)
+		}
+	}
+
+	// the logs are rolled back with the changes
+	session := testEngine.NewSession()
+	defer session.Close()
+	assert.NoError(t, session.Begin())
+	_, err = session.Insert(&AuditedAccount{Name: "rollback"})
+	assert.NoError(t, err)
+	assert.NoError(t, session.Rollback())
+
+	cnt, err = testEngine.Count(new(xorm.AuditLog))
+	assert.NoError(t, err)
+	assert.EqualValues(t, 3, cnt)
+
+	// the tables could be registered
+	_, err = testEngine.Insert(&AuditedByName{Name: "a"})
+	assert.NoError(t, err)
+	cnt, err = testEngine.Count(new(xorm.AuditLog))
+	assert.NoError(t, err)
+	assert.EqualValues(t, 3, cnt)
+
+	testEngine.(*xorm.Engine).Audit(new(AuditedByName))
+	_, err = testEngine.Insert([]AuditedByName{{Name: "b"}, {Name: "c"}})
+	assert.NoError(t, err)
+	_, err = testEngine.Table(new(AuditedByName)).Where("name = ?", "b").Update(map[string]interface{}{"name": "d"})
+	assert.NoError(t, err)
+
+	logs = nil
+	assert.NoError(t, testEngine.Where("table_name = ?", "audited_by_name").Asc("id").Find(&logs))
+	if assert.EqualValues(t, 3, len(logs)) {
+		assert.EqualValues(t, xorm.AuditInsert, logs[0].Operation)
+		assert.EqualValues(t, "", logs[0].PK)
+		assert.EqualValues(t, "b", logs[0].Changes["name"].New)
+		assert.EqualValues(t, xorm.AuditInsert, logs[1].Operation)
+		assert.EqualValues(t, "c", logs[1].Changes["name"].New)
+		assert.EqualValues(t, xorm.AuditUpdate, logs[2].Operation)
+		assert.EqualValues(t, "b", logs[2].Changes["name"].Old)
+		assert.EqualValues(t, "d", logs[2].Changes["name"].New)
+	}
+
+	// the deleted rows are read with the conditions, the order and the limit of the delete
+	if testEngine.Dialect().URI().DBType == schemas.MSSQL {
+		return
+	}
+	cnt, err = testEngine.Where("name <> ?", "a").Desc("id").Limit(1).Delete(new(AuditedByName))
+	assert.NoError(t, err)
+	assert.EqualValues(t, 1, cnt)
+
+	logs = nil
+	assert.NoError(t, testEngine.Where("table_name = ? AND operation = ?", "audited_by_name", xorm.AuditDelete).NoCache().Find(&logs))
+	if assert.EqualValues(t, 1, len(logs)) {
+		assert.EqualValues(t, "c", logs[0].Changes["name"].Old)
+	}
+}
+
+type AuditedSoftAccount struct {
+	Id       int64
+	Name     string
+	Password string    `xorm:"varchar(64) sensitive"`
+	Deleted  time.Time `xorm:"deleted"`
+}
+
+func (AuditedSoftAccount) Auditable() {}
+
+func TestAuditKeepStatement(t *testing.T) {
+	assert.NoError(t, PrepareEngine())
+	assertSync(t, new(xorm.AuditLog), new(AuditedSoftAccount))
+
+	var accounts = []*AuditedSoftAccount{
+		{Name: "a", Password: "secret"},
+		{Name: "b", Password: "secret"},
+	}
+	for _, account := range accounts {
+		_, err := testEngine.Insert(account)
+		assert.NoError(t, err)
+	}
+
+	// the sensitive arguments are still redacted after the old rows are read
+	var args [][]interface{}
+	logger := log.NewStructuredLogger(log.KVSinkFunc(func(ctx context.Context, level log.LogLevel, msg string, keyvals ...interface{}) {
+		var kv = make(map[string]interface{})
+		for i := 0; i+1 < len(keyvals); i += 2 {
+			kv[keyvals[i].(string)] = keyvals[i+1]
+		}
+		if sql, ok := kv[log.FieldSQL].(string); ok && strings.HasPrefix(sql, "UPDATE") {
+			args = append(args, kv[log.FieldArgs].([]interface{}))
+		}
+	}))
+	logger.ShowSQL(true)
+	engine := testEngine.(*xorm.Engine)
+	oldLogger := engine.Logger()
+	engine.SetLogger(logger)
+	cnt, err := testEngine.ID(accounts[0].Id).Update(&AuditedSoftAccount{Password: "changed"})
+	engine.SetLogger(oldLogger)
+	assert.NoError(t, err)
+	assert.EqualValues(t, 1, cnt)
+	if assert.EqualValues(t, 1, len(args)) {
+		assert.EqualValues(t, log.RedactedValue, args[0][0])
+	}
+
+	// soft delete
+	cnt, err = testEngine.ID(accounts[0].Id).Delete(new(AuditedSoftAccount))
+	assert.NoError(t, err)
+	assert.EqualValues(t, 1, cnt)
+	cnt, err = testEngine.Count(new(AuditedSoftAccount))
+	assert.NoError(t, err)
+	assert.EqualValues(t, 1, cnt)
+	cnt, err = testEngine.Unscoped().Count(new(AuditedSoftAccount))
+	assert.NoError(t, err)
+	assert.EqualValues(t, 2, cnt)
+
+	// unscoped delete
+	cnt, err = testEngine.ID(accounts[1].Id).Unscoped().Delete(new(AuditedSoftAccount))
+	assert.NoError(t, err)
+	assert.EqualValues(t, 1, cnt)
+	cnt, err = testEngine.Unscoped().Count(new(AuditedSoftAccount))
+	assert.NoError(t, err)
+	assert.EqualValues(t, 1, cnt)
+
+	var logs []xorm.AuditLog
+	assert.NoError(t, testEngine.Asc("id").NoCache().Find(&logs))
+	if assert.EqualValues(t, 5, len(logs)) {
+		// the sensitive values are redacted
+		for _, l := range logs[:2] {
+			if assert.NotNil(t, l.Changes["password"]) {
+				assert.EqualValues(t, log.RedactedValue, l.Changes["password"].New)
+			}
+		}
+		assert.EqualValues(t, xorm.AuditUpdate, logs[2].Operation)
+		if assert.NotNil(t, logs[2].Changes["password"]) {
+			assert.EqualValues(t, log.RedactedValue, logs[2].Changes["password"].Old)
+			assert.EqualValues(t, log.RedactedValue, logs[2].Changes["password"].New)
+		}
+		for _, l := range logs {
+			for _, change := range l.Changes {
+				assert.NotContains(t, []interface{}{"secret", "changed"}, change.Old)
+				assert.NotContains(t, []interface{}{"secret", "changed"}, change.New)
+			}
+		}
+
+		// the soft delete is an update of the deleted column
+		assert.EqualValues(t, xorm.AuditUpdate, logs[3].Operation)
+		assert.EqualValues(t, fmt.Sprint(accounts[0].Id), logs[3].PK)
+		if assert.EqualValues(t, 1, len(logs[3].Changes)) {
+			assert.NotNil(t, logs[3].Changes["deleted"])
+		}
+		assert.EqualValues(t, xorm.AuditDelete, logs[4].Operation)
+		assert.EqualValues(t, fmt.Sprint(accounts[1].Id), logs[4].PK)
+		if assert.NotNil(t, logs[4].Changes["password"]) {
+			assert.EqualValues(t, log.RedactedValue, logs[4].Changes["password"].Old)
+		}
+	}
+}
@@ -11,6 +11,7 @@ import (
 
 	"github.com/stretchr/testify/assert"
 	"github.com/xorm-io/builder"
+	"github.com/xorm-io/xorm"
 )
 
 func TestBuilder(t *testing.T) {
@@ -225,3 +226,56 @@ func TestFindAndCount(t *testing.T) {
 	assert.NoError(t, err)
 	assert.EqualValues(t, 1, total)
 }
+
+func TestColumnExpr(t *testing.T) {
+	assert.NoError(t, PrepareEngine())
+
+	type ColumnExprStruct struct {
+		Id       int64
+		UserName string
+		Age      int `xorm:"'user_age'"`
+	}
+
+	assertSync(t, new(ColumnExprStruct))
+
+	_, err := testEngine.Insert([]ColumnExprStruct{
+		{UserName: "lunny", Age: 10},
+		{UserName: "xlw", Age: 20},
+		{UserName: "test", Age: 30},
+	})
+	assert.NoError(t, err)
+
+	engine := testEngine.(*xorm.Engine)
+	age := engine.MustCol(new(ColumnExprStruct), "Age")
+	assert.EqualValues(t, "user_age", age.Name())
+	name := engine.MustCol(new(ColumnExprStruct), "UserName")
+	assert.EqualValues(t, testEngine.GetColumnMapper().Obj2Table("UserName"), name.Name())
+
+	var beans []ColumnExprStruct
+	err = testEngine.Where(age.Gt(10)).And(name.Neq("test")).OrderBy(age.Desc()).Find(&beans)
+	assert.NoError(t, err)
+	assert.EqualValues(t, 1, len(beans))
+	assert.EqualValues(t, "xlw", beans[0].UserName)
+
+	cnt, err := testEngine.Where(age.Between(10, 20)).Count(new(ColumnExprStruct))
+	assert.NoError(t, err)
+	assert.EqualValues(t, 2, cnt)
+
+	cnt, err = testEngine.Where(name.In("lunny", "test")).Count(new(ColumnExprStruct))
+	assert.NoError(t, err)
+	assert.EqualValues(t, 2, cnt)
+
+	quoter := testEngine.Dialect().Quoter()
+	tableName := testEngine.TableName(new(ColumnExprStruct))
+	assert.EqualValues(t, quoter.Quote("c")+"."+quoter.Quote("user_age"), age.Of("c").String())
+	cnt, err = testEngine.Table(tableName).Alias("c").Where(age.Of("c").Gte(20)).Count()
+	assert.NoError(t, err)
+	assert.EqualValues(t, 2, cnt)
+
+	_, err = engine.Col(new(ColumnExprStruct), "Name")
+	assert.Error(t, err)
+	assert.IsType(t, xorm.ErrFieldIsNotExist{}, err)
+	assert.Panics(t, func() {
+		engine.MustCol(new(ColumnExprStruct), "Name")
+	})
+}
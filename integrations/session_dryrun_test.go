@@ -0,0 +1,76 @@
+// Copyright 2021 The Xorm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package integrations
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/xorm-io/xorm"
+	"github.com/xorm-io/xorm/schemas"
+)
+
+func TestDryRun(t *testing.T) {
+	assert.NoError(t, PrepareEngine())
+
+	type DryRunStruct struct {
+		Id      int64
+		Name    string
+		Version int `xorm:"version"`
+	}
+
+	assertSync(t, new(DryRunStruct))
+
+	_, err := testEngine.Insert(&DryRunStruct{Name: "lunny"})
+	assert.NoError(t, err)
+
+	sqls, err := testEngine.(*xorm.Engine).ToSQL(func(session *xorm.Session) error {
+		cnt, err := session.Insert(&DryRunStruct{Name: "xlw"})
+		if err != nil {
+			return err
+		}
+		assert.EqualValues(t, 0, cnt)
+
+		if _, err := session.ID(1).Update(&DryRunStruct{Name: "lunny2", Version: 1}); err != nil {
+			return err
+		}
+		if _, err := session.ID(1).Delete(new(DryRunStruct)); err != nil {
+			return err
+		}
+
+		var beans []DryRunStruct
+		err = session.Where("name = ?", "lunny").Find(&beans)
+		assert.EqualValues(t, xorm.ErrDryRun, err)
+		assert.EqualValues(t, 0, len(beans))
+
+		_, err = session.Count(new(DryRunStruct))
+		return err
+	})
+	assert.NoError(t, err)
+	assert.EqualValues(t, 5, len(sqls))
+
+	assert.True(t, strings.HasPrefix(sqls[0].SQL, "INSERT INTO"), sqls[0].SQL)
+	switch testEngine.Dialect().URI().DBType {
+	case schemas.POSTGRES:
+		// the insert which returns the id is recorded too
+		assert.Contains(t, sqls[0].SQL, "RETURNING")
+	case schemas.MSSQL:
+		assert.Contains(t, sqls[0].SQL, "OUTPUT")
+	}
+	assert.True(t, strings.HasPrefix(sqls[1].SQL, "UPDATE"), sqls[1].SQL)
+	assert.Contains(t, sqls[1].SQL, "version")
+	assert.True(t, strings.HasPrefix(sqls[2].SQL, "DELETE FROM"), sqls[2].SQL)
+	assert.True(t, strings.HasPrefix(sqls[3].SQL, "SELECT"), sqls[3].SQL)
+	assert.EqualValues(t, []interface{}{"lunny"}, sqls[3].Args)
+	assert.Contains(t, sqls[4].SQL, "count(")
+
+	// nothing should be written
+	var beans []DryRunStruct
+	assert.NoError(t, testEngine.Find(&beans))
+	assert.EqualValues(t, 1, len(beans))
+	assert.EqualValues(t, "lunny", beans[0].Name)
+	assert.EqualValues(t, 1, beans[0].Version)
+}
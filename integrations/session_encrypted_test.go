@@ -0,0 +1,151 @@
+// Copyright 2021 The Xorm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package integrations
+
+import (
+	"bytes"
+	"encoding/base64"
+	"testing"
+	"time"
+
+	"github.com/xorm-io/builder"
+	"github.com/xorm-io/xorm"
+	"github.com/xorm-io/xorm/convert"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type UserEncrypted struct {
+	Id       int64
+	Name     string
+	Email    string    `xorm:"encrypted(pii,deterministic)"`
+	Phone    *string   `xorm:"encrypted(pii)"`
+	Age      int       `xorm:"encrypted"`
+	Birthday time.Time `xorm:"encrypted"`
+	Secret   []byte    `xorm:"encrypted"`
+}
+
+func TestEncryptedColumn(t *testing.T) {
+	assert.NoError(t, PrepareEngine())
+
+	keys := convert.NewStaticKeyProvider()
+	assert.NoError(t, keys.AddKey("", "1", bytes.Repeat([]byte{1}, 32)))
+	assert.NoError(t, keys.AddKey("pii", "1", bytes.Repeat([]byte{2}, 16)))
+	testEngine.SetKeyProvider(keys)
+	defer testEngine.SetKeyProvider(nil)
+
+	assertSync(t, new(UserEncrypted))
+
+	phone := "123456"
+	birthday := time.Date(2000, 1, 2, 3, 4, 5, 0, time.UTC)
+	var user = UserEncrypted{
+		Name:     "lunny",
+		Email:    "lunny@example.com",
+		Phone:    &phone,
+		Age:      18,
+		Birthday: birthday,
+		Secret:   []byte("secret"),
+	}
+	cnt, err := testEngine.Insert(&user)
+	assert.NoError(t, err)
+	assert.EqualValues(t, 1, cnt)
+
+	var email, rawPhone string
+	has, err := testEngine.Table(new(UserEncrypted)).Cols("email").Get(&email)
+	assert.NoError(t, err)
+	assert.True(t, has)
+	assert.NotEqual(t, user.Email, email)
+	has, err = testEngine.Table(new(UserEncrypted)).Cols("phone").Get(&rawPhone)
+	assert.NoError(t, err)
+	assert.True(t, has)
+	assert.NotEqual(t, phone, rawPhone)
+
+	var user2 UserEncrypted
+	has, err = testEngine.ID(user.Id).Get(&user2)
+	assert.NoError(t, err)
+	assert.True(t, has)
+	assert.EqualValues(t, user.Email, user2.Email)
+	if assert.NotNil(t, user2.Phone) {
+		assert.EqualValues(t, phone, *user2.Phone)
+	}
+	assert.EqualValues(t, 18, user2.Age)
+	assert.EqualValues(t, birthday.Unix(), user2.Birthday.Unix())
+	assert.EqualValues(t, "secret", string(user2.Secret))
+
+	// deterministic column could be used for equality lookups
+	var user3 = UserEncrypted{Email: user.Email}
+	has, err = testEngine.Get(&user3)
+	assert.NoError(t, err)
+	assert.True(t, has)
+	assert.EqualValues(t, user.Id, user3.Id)
+
+	// the others couldn't
+	_, err = testEngine.Get(&UserEncrypted{Phone: &phone})
+	assert.EqualError(t, err, convert.ErrEncryptedCondition.Error())
+	_, err = testEngine.Count(&UserEncrypted{Age: 18})
+	assert.EqualError(t, err, convert.ErrEncryptedCondition.Error())
+
+	// neither in the string conditions
+	_, err = testEngine.Where("email = ?", user.Email).Get(new(UserEncrypted))
+	assert.EqualError(t, err, convert.ErrEncryptedStringCondition.Error())
+	_, err = testEngine.Where("name = ?", user.Name).Or("`phone` = ?", phone).Count(new(UserEncrypted))
+	assert.EqualError(t, err, convert.ErrEncryptedStringCondition.Error())
+	err = testEngine.In("age", 18).Find(new([]UserEncrypted))
+	assert.EqualError(t, err, convert.ErrEncryptedStringCondition.Error())
+	// nor in the builder conditions
+	_, err = testEngine.Where(builder.Eq{colMapper.Obj2Table("Email"): user.Email}).Get(new(UserEncrypted))
+	assert.EqualError(t, err, convert.ErrEncryptedStringCondition.Error())
+	emailCol := testEngine.(*xorm.Engine).MustCol(new(UserEncrypted), "Email")
+	_, err = testEngine.Where(emailCol.Eq(user.Email)).Count(new(UserEncrypted))
+	assert.EqualError(t, err, convert.ErrEncryptedStringCondition.Error())
+	_, err = testEngine.Alias("u").Where(builder.Eq{"name": user.Name}.Or(emailCol.Of("u").Like("lunny"))).Count(new(UserEncrypted))
+	assert.EqualError(t, err, convert.ErrEncryptedStringCondition.Error())
+	cnt, err = testEngine.Where(builder.Eq{colMapper.Obj2Table("Name"): user.Name}).Count(new(UserEncrypted))
+	assert.NoError(t, err)
+	assert.EqualValues(t, 1, cnt)
+	cnt, err = testEngine.Where("name = ?", user.Name).Count(new(UserEncrypted))
+	assert.NoError(t, err)
+	assert.EqualValues(t, 1, cnt)
+	cnt, err = testEngine.Where("name = 'email'").Count(new(UserEncrypted))
+	assert.NoError(t, err)
+	assert.EqualValues(t, 0, cnt)
+
+	// rotate the key, the old values could still be decrypted
+	assert.NoError(t, keys.AddKey("pii", "2", bytes.Repeat([]byte{3}, 32)))
+	cnt, err = testEngine.ID(user.Id).Cols("phone").Update(&UserEncrypted{Phone: &user.Name})
+	assert.NoError(t, err)
+	assert.EqualValues(t, 1, cnt)
+
+	var user4 UserEncrypted
+	has, err = testEngine.ID(user.Id).Get(&user4)
+	assert.NoError(t, err)
+	assert.True(t, has)
+	assert.EqualValues(t, user.Email, user4.Email)
+	if assert.NotNil(t, user4.Phone) {
+		assert.EqualValues(t, user.Name, *user4.Phone)
+	}
+
+	// the ciphertexts are bound to their columns even with the same key
+	_, err = testEngine.Exec("UPDATE "+testEngine.Quote(testEngine.TableName(new(UserEncrypted)))+
+		" SET "+testEngine.Quote(colMapper.Obj2Table("Phone"))+" = ? WHERE "+testEngine.Quote(colMapper.Obj2Table("Id"))+" = ?",
+		email, user.Id)
+	assert.NoError(t, err)
+	_, err = testEngine.ID(user.Id).NoCache().Get(new(UserEncrypted))
+	assert.Error(t, err)
+
+	// the envelopes of the other versions are invalid
+	tableName := testEngine.TableName(new(UserEncrypted))
+	envelope, err := convert.Encrypt(keys, "pii", tableName, colMapper.Obj2Table("Email"), true, []byte(user.Email))
+	assert.NoError(t, err)
+	data, err := base64.StdEncoding.DecodeString(envelope)
+	assert.NoError(t, err)
+	data[0] = 1
+	_, err = convert.Decrypt(keys, "pii", tableName, colMapper.Obj2Table("Email"), base64.StdEncoding.EncodeToString(data))
+	assert.EqualError(t, err, convert.ErrInvalidCiphertext.Error())
+
+	testEngine.SetKeyProvider(nil)
+	_, err = testEngine.ID(user.Id).NoCache().Get(new(UserEncrypted))
+	assert.EqualError(t, err, convert.ErrNoKeyProvider.Error())
+}
@@ -0,0 +1,49 @@
+// Copyright 2021 The Xorm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package integrations
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExplain(t *testing.T) {
+	assert.NoError(t, PrepareEngine())
+
+	type ExplainStruct struct {
+		Id   int64
+		Name string `xorm:"index"`
+		Age  int
+	}
+
+	assertSync(t, new(ExplainStruct))
+
+	_, err := testEngine.Insert(&ExplainStruct{Name: "lunny", Age: 10}, &ExplainStruct{Name: "xlw", Age: 20})
+	assert.NoError(t, err)
+
+	tableName := testEngine.TableName(new(ExplainStruct))
+
+	plan, err := testEngine.NewSession().Explain(&ExplainStruct{Name: "lunny"})
+	assert.NoError(t, err)
+	assert.NotNil(t, plan)
+	assert.True(t, plan.UsesIndex(""), plan.String())
+	assert.False(t, plan.HasTableScan(tableName), plan.String())
+
+	var beans []ExplainStruct
+	plan, err = testEngine.Where("age > ?", 5).ExplainFind(&beans)
+	assert.NoError(t, err)
+	assert.True(t, plan.HasTableScan(tableName), plan.String())
+	assert.EqualValues(t, 0, len(beans))
+
+	plan, err = testEngine.NewSession().ExplainCount(new(ExplainStruct))
+	assert.NoError(t, err)
+	assert.NotEmpty(t, plan.Children)
+
+	// explain should not execute the query
+	cnt, err := testEngine.Count(new(ExplainStruct))
+	assert.NoError(t, err)
+	assert.EqualValues(t, 2, cnt)
+}
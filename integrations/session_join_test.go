@@ -0,0 +1,122 @@
+// Copyright 2021 The Xorm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package integrations
+
+import (
+	"testing"
+
+	"github.com/xorm-io/xorm"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type NestedJoinUser struct {
+	Id     int64
+	Name   string
+	TeamId int64
+}
+
+type NestedJoinTeam struct {
+	Id   int64
+	Name string
+}
+
+func TestJoinNestedStructs(t *testing.T) {
+	assert.NoError(t, PrepareEngine())
+	assertSync(t, new(NestedJoinUser), new(NestedJoinTeam))
+
+	_, err := testEngine.Insert(&NestedJoinTeam{Id: 1, Name: "team1"})
+	assert.NoError(t, err)
+	_, err = testEngine.Insert([]NestedJoinUser{
+		{Id: 1, Name: "user1", TeamId: 1},
+		{Id: 2, Name: "user2", TeamId: 2},
+	})
+	assert.NoError(t, err)
+
+	// the nested fields are mapped to the tables by their names
+	type UserTeam struct {
+		NestedJoinUser NestedJoinUser
+		NestedJoinTeam *NestedJoinTeam
+	}
+
+	var rows []UserTeam
+	err = testEngine.Table(new(NestedJoinUser)).
+		Join("LEFT", new(NestedJoinTeam), "`nested_join_team`.`id` = `nested_join_user`.`team_id`").
+		Asc("nested_join_user.id").
+		Find(&rows)
+	assert.NoError(t, err)
+	assert.EqualValues(t, 2, len(rows))
+	assert.EqualValues(t, NestedJoinUser{Id: 1, Name: "user1", TeamId: 1}, rows[0].NestedJoinUser)
+	assert.NotNil(t, rows[0].NestedJoinTeam)
+	assert.EqualValues(t, NestedJoinTeam{Id: 1, Name: "team1"}, *rows[0].NestedJoinTeam)
+	assert.EqualValues(t, NestedJoinUser{Id: 2, Name: "user2", TeamId: 2}, rows[1].NestedJoinUser)
+	// the LEFT JOIN misses are nil
+	assert.Nil(t, rows[1].NestedJoinTeam)
+
+	// or to the aliases
+	type AliasedUserTeam struct {
+		User *NestedJoinUser `xorm:"'u'"`
+		Team *NestedJoinTeam `xorm:"'t'"`
+	}
+
+	var aliased []*AliasedUserTeam
+	err = testEngine.Table(new(NestedJoinUser)).Alias("u").
+		Join("INNER", []string{testEngine.TableName(new(NestedJoinTeam)), "t"}, "`t`.`id` = `u`.`team_id`").
+		Where("`t`.`name` = ?", "team1").
+		Find(&aliased)
+	assert.NoError(t, err)
+	assert.EqualValues(t, 1, len(aliased))
+	assert.EqualValues(t, "user1", aliased[0].User.Name)
+	assert.EqualValues(t, "team1", aliased[0].Team.Name)
+
+	// the other fields are the columns of the table
+	type UserWithTeam struct {
+		Id             int64
+		Name           string
+		NestedJoinTeam *NestedJoinTeam
+	}
+
+	var withTeams []UserWithTeam
+	err = testEngine.Table(new(NestedJoinUser)).
+		Join("LEFT", new(NestedJoinTeam), "`nested_join_team`.`id` = `nested_join_user`.`team_id`").
+		Asc("nested_join_user.id").
+		Find(&withTeams)
+	assert.NoError(t, err)
+	if assert.EqualValues(t, 2, len(withTeams)) {
+		assert.EqualValues(t, 1, withTeams[0].Id)
+		assert.EqualValues(t, "user1", withTeams[0].Name)
+		assert.EqualValues(t, "team1", withTeams[0].NestedJoinTeam.Name)
+		assert.EqualValues(t, 2, withTeams[1].Id)
+		assert.EqualValues(t, "user2", withTeams[1].Name)
+		assert.Nil(t, withTeams[1].NestedJoinTeam)
+	}
+
+	type ExtendedUserTeam struct {
+		NestedJoinUser `xorm:"extends"`
+		Team           NestedJoinTeam `xorm:"'t'"`
+	}
+
+	var extended []ExtendedUserTeam
+	err = testEngine.Table(new(NestedJoinUser)).
+		Join("INNER", []string{testEngine.TableName(new(NestedJoinTeam)), "t"}, "`t`.`id` = `nested_join_user`.`team_id`").
+		Find(&extended)
+	assert.NoError(t, err)
+	if assert.EqualValues(t, 1, len(extended)) {
+		assert.EqualValues(t, NestedJoinUser{Id: 1, Name: "user1", TeamId: 1}, extended[0].NestedJoinUser)
+		assert.EqualValues(t, NestedJoinTeam{Id: 1, Name: "team1"}, extended[0].Team)
+	}
+
+	// the columns which are not of the table are refused
+	type UserTeamScore struct {
+		Score          int
+		NestedJoinTeam *NestedJoinTeam
+	}
+
+	var scores []UserTeamScore
+	err = testEngine.Table(new(NestedJoinUser)).
+		Join("LEFT", new(NestedJoinTeam), "`nested_join_team`.`id` = `nested_join_user`.`team_id`").
+		Find(&scores)
+	assert.EqualValues(t, xorm.ErrJoinedColumn, err)
+}
@@ -0,0 +1,84 @@
+// Copyright 2021 The Xorm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package integrations
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/xorm-io/builder"
+	"github.com/xorm-io/xorm"
+	"github.com/xorm-io/xorm/schemas"
+)
+
+func TestJSONCond(t *testing.T) {
+	assert.NoError(t, PrepareEngine())
+
+	if testEngine.Dialect().URI().DBType == schemas.SQLITE {
+		// mattn/go-sqlite3 needs the sqlite_json build tag to enable JSON1
+		if _, err := testEngine.Exec("SELECT json('{}')"); err != nil {
+			t.Skip()
+		}
+	}
+
+	type JSONCondSettings struct {
+		Theme string   `json:"theme"`
+		Size  int      `json:"size"`
+		Tags  []string `json:"tags"`
+	}
+
+	type JSONCondStruct struct {
+		Id       int64
+		Name     string
+		Settings JSONCondSettings `xorm:"json"`
+	}
+
+	assertSync(t, new(JSONCondStruct))
+
+	_, err := testEngine.Insert([]JSONCondStruct{
+		{Name: "lunny", Settings: JSONCondSettings{Theme: "dark", Size: 12, Tags: []string{"a", "b"}}},
+		{Name: "xlw", Settings: JSONCondSettings{Theme: "light", Size: 14, Tags: []string{"b"}}},
+	})
+	assert.NoError(t, err)
+
+	var beans []JSONCondStruct
+	err = testEngine.Where(xorm.JSONPath("settings", "$.theme").Eq("dark")).Find(&beans)
+	assert.NoError(t, err)
+	assert.EqualValues(t, 1, len(beans))
+	assert.EqualValues(t, "lunny", beans[0].Name)
+
+	cnt, err := testEngine.Where(builder.Or(
+		xorm.JSONPath("settings", "$.size").Gt(12),
+		builder.Eq{"name": "lunny"},
+	)).Count(new(JSONCondStruct))
+	assert.NoError(t, err)
+	assert.EqualValues(t, 2, cnt)
+
+	cnt, err = testEngine.Where(xorm.JSONContains("settings", `{"tags":["a"]}`)).Count(new(JSONCondStruct))
+	assert.NoError(t, err)
+	assert.EqualValues(t, 1, cnt)
+
+	cnt, err = testEngine.Where(xorm.JSONContains("settings", map[string]interface{}{"theme": "light", "size": 14})).Count(new(JSONCondStruct))
+	assert.NoError(t, err)
+	assert.EqualValues(t, 1, cnt)
+
+	cnt, err = testEngine.JSONSet("settings", "$.theme", "blue").Where("name = ?", "xlw").Update(new(JSONCondStruct))
+	assert.NoError(t, err)
+	assert.EqualValues(t, 1, cnt)
+
+	var bean JSONCondStruct
+	has, err := testEngine.Where("name = ?", "xlw").Get(&bean)
+	assert.NoError(t, err)
+	assert.True(t, has)
+	assert.EqualValues(t, "blue", bean.Settings.Theme)
+	assert.EqualValues(t, 14, bean.Settings.Size)
+	assert.EqualValues(t, []string{"b"}, bean.Settings.Tags)
+
+	_, err = testEngine.Where(xorm.JSONPath("settings", "$.the me").Eq("dark")).Count(new(JSONCondStruct))
+	assert.Error(t, err)
+
+	_, _, err = builder.ToSQL(xorm.JSONPath("settings", "$.theme").Eq("dark"))
+	assert.EqualValues(t, xorm.ErrDialectRequired, err)
+}
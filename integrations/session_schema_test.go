@@ -5,11 +5,14 @@
 package integrations
 
 import (
+	"context"
 	"fmt"
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/xorm-io/xorm"
 	"github.com/xorm-io/xorm/schemas"
 )
 
@@ -519,3 +522,206 @@ func TestModifyColum(t *testing.T) {
 	_, err := testEngine.Exec(alterSQL)
 	assert.NoError(t, err)
 }
+
+type SyncCheck1 struct {
+	Id     int64
+	Age    int    `xorm:"check(age >= 0)"`
+	Status string `xorm:"enum('active','disabled')"`
+}
+
+type SyncCheck2 struct {
+	Id     int64
+	Age    int    `xorm:"check(age >= 0) check(age < 200)"`
+	Status string `xorm:"enum('active','disabled')"`
+}
+
+func (SyncCheck2) TableName() string {
+	return "sync_check1"
+}
+
+type SyncCheck3 struct {
+	Id     int64
+	Age    int    `xorm:"check(age >= 18)"`
+	Status string `xorm:"enum('active','disabled')"`
+}
+
+func (SyncCheck3) TableName() string {
+	return "sync_check1"
+}
+
+func TestSyncCheck(t *testing.T) {
+	assert.NoError(t, PrepareEngine())
+	assert.NoError(t, testEngine.Sync2(new(SyncCheck1)))
+
+	_, err := testEngine.Insert(&SyncCheck1{Age: 20, Status: "active"})
+	assert.NoError(t, err)
+	_, err = testEngine.Insert(&SyncCheck1{Age: -1, Status: "active"})
+	assert.Error(t, err)
+	_, err = testEngine.Insert(&SyncCheck1{Age: 20, Status: "unknown"})
+	assert.Error(t, err)
+
+	tables, err := testEngine.DBMetas()
+	assert.NoError(t, err)
+	assert.EqualValues(t, 1, len(tables))
+	if tables[0].Checks == nil {
+		t.Skip("checks can't be read back from", testEngine.Dialect().URI().DBType)
+	}
+	assert.NotNil(t, tables[0].Checks["CHK_sync_check1_age"])
+
+	// the new check is added and the existing checks are kept
+	assert.NoError(t, testEngine.Sync2(new(SyncCheck2)))
+	tables, err = testEngine.DBMetas()
+	assert.NoError(t, err)
+	if testEngine.Dialect().URI().DBType != schemas.SQLITE {
+		assert.NotNil(t, tables[0].Checks["CHK_sync_check1_age_2"])
+	}
+	assert.NotNil(t, tables[0].Checks["CHK_sync_check1_age"])
+
+	// the check removed from the struct is dropped
+	assert.NoError(t, testEngine.Sync2(new(SyncCheck1)))
+	tables, err = testEngine.DBMetas()
+	assert.NoError(t, err)
+	assert.Nil(t, tables[0].Checks["CHK_sync_check1_age_2"])
+
+	// the check whose expression is changed is dropped and added again
+	assert.NoError(t, testEngine.Sync2(new(SyncCheck3)))
+	tables, err = testEngine.DBMetas()
+	assert.NoError(t, err)
+	check := tables[0].Checks["CHK_sync_check1_age"]
+	assert.NotNil(t, check)
+	if testEngine.Dialect().URI().DBType == schemas.SQLITE {
+		// SQLite can't change the checks of a table
+		assert.True(t, check.SameExpr(schemas.NewCheck("age", "age >= 0")))
+		return
+	}
+	assert.True(t, check.SameExpr(schemas.NewCheck("age", "age >= 18")))
+	assert.NotNil(t, tables[0].Checks["CHK_sync_check1_status_enum"])
+	_, err = testEngine.Insert(&SyncCheck3{Age: 10, Status: "active"})
+	assert.Error(t, err)
+	_, err = testEngine.Insert(&SyncCheck3{Age: 20, Status: "active"})
+	assert.NoError(t, err)
+}
+
+type SyncGenerated struct {
+	Id        int64
+	FirstName string
+	LastName  string
+	FullName  string `xorm:"generated(first_name || ' ' || last_name) stored"`
+	Email     string `xorm:"index(lower(email))"`
+	Code      string `xorm:"unique(code) where deleted IS NULL"`
+	Deleted   *int
+}
+
+func TestSyncGenerated(t *testing.T) {
+	switch testEngine.Dialect().URI().DBType {
+	case schemas.SQLITE, schemas.POSTGRES:
+	default:
+		t.Skip("the expressions of the test are for sqlite and postgres")
+	}
+
+	assert.NoError(t, PrepareEngine())
+	assert.NoError(t, testEngine.Sync2(new(SyncGenerated)))
+	// the expression and the partial indexes are kept
+	assert.NoError(t, testEngine.Sync2(new(SyncGenerated)))
+
+	// the generated column isn't inserted but read back
+	_, err := testEngine.Insert(&SyncGenerated{FirstName: "a", LastName: "b", FullName: "x", Email: "A@B", Code: "c"})
+	assert.NoError(t, err)
+	var user SyncGenerated
+	has, err := testEngine.Get(&user)
+	assert.NoError(t, err)
+	assert.True(t, has)
+	assert.EqualValues(t, "a b", user.FullName)
+
+	_, err = testEngine.ID(user.Id).Update(&SyncGenerated{LastName: "c", FullName: "x"})
+	assert.NoError(t, err)
+	var users []SyncGenerated
+	assert.NoError(t, testEngine.Find(&users))
+	assert.EqualValues(t, 1, len(users))
+	assert.EqualValues(t, "a c", users[0].FullName)
+
+	// the unique index only covers the rows which aren't deleted
+	deleted := 1
+	_, err = testEngine.Insert(&SyncGenerated{FirstName: "a", Code: "d", Deleted: &deleted})
+	assert.NoError(t, err)
+	_, err = testEngine.Insert(&SyncGenerated{FirstName: "b", Code: "d", Deleted: &deleted})
+	assert.NoError(t, err)
+	_, err = testEngine.Insert(&SyncGenerated{FirstName: "c", Code: "c"})
+	assert.Error(t, err)
+
+	tables, err := testEngine.DBMetas()
+	assert.NoError(t, err)
+	assert.EqualValues(t, 1, len(tables))
+	assert.NotEmpty(t, tables[0].GetColumn("full_name").Generated)
+	assert.True(t, tables[0].GetColumn("full_name").GeneratedStored)
+	assert.NotNil(t, tables[0].Indexes["email"])
+	assert.True(t, tables[0].Indexes["email"].HasExpr())
+	assert.NotNil(t, tables[0].Indexes["code"])
+	assert.NotEmpty(t, tables[0].Indexes["code"].Where)
+}
+
+type SyncSequence0 struct {
+	Id   int64 `xorm:"pk"`
+	Name string
+}
+
+func (SyncSequence0) TableName() string {
+	return "sync_sequence"
+}
+
+type SyncSequence struct {
+	Id   int64 `xorm:"pk autoincr sequence"`
+	Name string
+}
+
+func (SyncSequence) TableName() string {
+	return "sync_sequence"
+}
+
+func TestSyncSequence(t *testing.T) {
+	assert.NoError(t, PrepareEngine())
+
+	dialect := testEngine.Dialect()
+	seqName := schemas.SequenceName("sync_sequence", "id")
+	hasSequences := dialect.NextSequenceSQL(seqName) != ""
+	if hasSequences {
+		// the sequence is left by the former tests
+		_, _ = testEngine.Exec(dialect.DropSequenceSQL(seqName))
+	}
+
+	assert.NoError(t, testEngine.Sync2(new(SyncSequence0)))
+	_, err := testEngine.Insert(&SyncSequence0{Id: 5, Name: "a"})
+	assert.NoError(t, err)
+
+	// the sequence of the existing table starts after the values and it isn't
+	// created again
+	assert.NoError(t, testEngine.Sync2(new(SyncSequence)))
+	assert.NoError(t, testEngine.Sync2(new(SyncSequence)))
+
+	var bean = SyncSequence{Name: "b"}
+	_, err = testEngine.Insert(&bean)
+	assert.NoError(t, err)
+	assert.EqualValues(t, 6, bean.Id)
+
+	var beans = []SyncSequence{{Name: "c"}, {Name: "d"}}
+	_, err = testEngine.Insert(&beans)
+	assert.NoError(t, err)
+	if hasSequences {
+		assert.EqualValues(t, 7, beans[0].Id)
+		assert.EqualValues(t, 8, beans[1].Id)
+	}
+
+	var ids []int64
+	assert.NoError(t, testEngine.Table("sync_sequence").Asc("id").Cols("id").Find(&ids))
+	assert.EqualValues(t, []int64{5, 6, 7, 8}, ids)
+
+	// the sequence is dropped with the table
+	assert.NoError(t, testEngine.DropTables(new(SyncSequence)))
+	if hasSequences {
+		seqs, err := dialect.GetSequences(testEngine.(*xorm.Engine).DB(), context.Background())
+		assert.NoError(t, err)
+		for _, seq := range seqs {
+			assert.False(t, strings.EqualFold(seqName, seq), seq)
+		}
+	}
+}
@@ -0,0 +1,236 @@
+// Copyright 2021 The Xorm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package integrations
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/xorm-io/xorm"
+	"github.com/xorm-io/xorm/internal/statements"
+	"github.com/xorm-io/xorm/names"
+)
+
+type ShardOrder struct {
+	Id        int64
+	Amount    int
+	CreatedAt time.Time
+}
+
+var shardTenantSharder = &names.FieldSharder{
+	Field:  "Tenant",
+	Values: []interface{}{"a", "b"},
+}
+
+type ShardTenantEvent struct {
+	Id     int64
+	Tenant string
+	Name   string `xorm:"index"`
+}
+
+func (ShardTenantEvent) ShardTable(table string, bean interface{}) (string, error) {
+	return shardTenantSharder.ShardTable(table, bean)
+}
+
+func (ShardTenantEvent) ShardTables(table string) ([]string, error) {
+	return shardTenantSharder.ShardTables(table)
+}
+
+func TestShardByTime(t *testing.T) {
+	assert.NoError(t, PrepareEngine())
+
+	var (
+		sep     = time.Date(2026, 9, 10, 0, 0, 0, 0, time.UTC)
+		oct     = time.Date(2026, 10, 19, 0, 0, 0, 0, time.UTC)
+		sharder = &names.TimeSharder{
+			Field:  "CreatedAt",
+			Layout: "2006_01",
+			From:   sep,
+			To:     oct,
+		}
+		sepTable = "shard_order_2026_09"
+		octTable = "shard_order_2026_10"
+	)
+	testEngine.(*xorm.Engine).SetSharder(new(ShardOrder), sharder)
+	assert.NoError(t, testEngine.Sync2(new(ShardOrder)))
+
+	for _, table := range []string{sepTable, octTable} {
+		exist, err := testEngine.IsTableExist(table)
+		assert.NoError(t, err)
+		assert.True(t, exist, table)
+	}
+	exist, err := testEngine.IsTableExist(new(ShardOrder))
+	assert.NoError(t, err)
+	assert.False(t, exist)
+
+	cnt, err := testEngine.Insert([]*ShardOrder{
+		{Amount: 1, CreatedAt: sep},
+		{Amount: 2, CreatedAt: oct},
+		{Amount: 3, CreatedAt: oct},
+	})
+	assert.NoError(t, err)
+	assert.EqualValues(t, 3, cnt)
+
+	cnt, err = testEngine.Insert(&ShardOrder{Amount: 4, CreatedAt: sep})
+	assert.NoError(t, err)
+	assert.EqualValues(t, 1, cnt)
+
+	cnt, err = testEngine.Table(sepTable).Count(new(ShardOrder))
+	assert.NoError(t, err)
+	assert.EqualValues(t, 2, cnt)
+	cnt, err = testEngine.Table(octTable).Count(new(ShardOrder))
+	assert.NoError(t, err)
+	assert.EqualValues(t, 2, cnt)
+
+	var order = ShardOrder{CreatedAt: oct}
+	has, err := testEngine.NoAutoCondition().Where("amount = ?", 3).Get(&order)
+	assert.NoError(t, err)
+	assert.True(t, has)
+	assert.EqualValues(t, 3, order.Amount)
+
+	cnt, err = testEngine.ID(order.Id).Cols("amount").Update(&ShardOrder{Amount: 5, CreatedAt: oct})
+	assert.NoError(t, err)
+	assert.EqualValues(t, 1, cnt)
+
+	var orders []ShardOrder
+	assert.NoError(t, testEngine.Asc("amount").Find(&orders))
+	assert.EqualValues(t, 4, len(orders))
+	assert.EqualValues(t, []int{1, 2, 4, 5}, shardAmounts(orders))
+
+	// the order and the limit are applied to the rows of all the shards
+	orders = orders[:0]
+	assert.NoError(t, testEngine.Desc("amount").Limit(2, 1).Find(&orders))
+	assert.EqualValues(t, []int{4, 2}, shardAmounts(orders))
+	orders = orders[:0]
+	assert.NoError(t, testEngine.Asc("created_at").Desc("amount").Find(&orders))
+	assert.EqualValues(t, []int{4, 1, 5, 2}, shardAmounts(orders))
+	orders = orders[:0]
+	assert.NoError(t, testEngine.Limit(10, 3).Find(&orders))
+	assert.EqualValues(t, 1, len(orders))
+	err = testEngine.OrderBy("amount + 1").Find(&orders)
+	assert.EqualError(t, err, statements.ErrShardOrder.Error())
+	var orderMap = make(map[int64]ShardOrder)
+	err = testEngine.Limit(2).Find(&orderMap)
+	assert.EqualError(t, err, xorm.ErrShardMapOrder.Error())
+
+	// the shard of a bean without the time is unknown
+	_, err = testEngine.ID(order.Id).Get(new(ShardOrder))
+	assert.EqualError(t, err, names.ErrZeroShardKey.Error())
+	_, err = testEngine.ID(order.Id).Update(&ShardOrder{Amount: 6})
+	assert.EqualError(t, err, names.ErrZeroShardKey.Error())
+	_, err = testEngine.ID(order.Id).Delete(new(ShardOrder))
+	assert.EqualError(t, err, names.ErrZeroShardKey.Error())
+	err = testEngine.Iterate(new(ShardOrder), func(i int, bean interface{}) error {
+		return nil
+	})
+	assert.EqualError(t, err, names.ErrZeroShardKey.Error())
+
+	// or the statistics run on all the shards
+	cnt, err = testEngine.Count(new(ShardOrder))
+	assert.NoError(t, err)
+	assert.EqualValues(t, 4, cnt)
+	cnt, err = testEngine.Where("amount > ?", 1).Count(new(ShardOrder))
+	assert.NoError(t, err)
+	assert.EqualValues(t, 3, cnt)
+	cnt, err = testEngine.Count(&ShardOrder{CreatedAt: sep})
+	assert.NoError(t, err)
+	assert.EqualValues(t, 2, cnt)
+	has, err = testEngine.Exist(&ShardOrder{Amount: 5})
+	assert.NoError(t, err)
+	assert.True(t, has)
+	has, err = testEngine.Exist(&ShardOrder{Amount: 3})
+	assert.NoError(t, err)
+	assert.False(t, has)
+	sum, err := testEngine.SumInt(new(ShardOrder), "amount")
+	assert.NoError(t, err)
+	assert.EqualValues(t, 12, sum)
+	sums, err := testEngine.Where("amount > ?", 1).Sums(new(ShardOrder), "amount", "id")
+	assert.NoError(t, err)
+	assert.EqualValues(t, 11, sums[0])
+	sum, err = testEngine.Shards(octTable).SumInt(new(ShardOrder), "amount")
+	assert.NoError(t, err)
+	assert.EqualValues(t, 7, sum)
+	_, err = testEngine.Avg(new(ShardOrder), "amount")
+	assert.EqualError(t, err, xorm.ErrShardAggregate.Error())
+
+	orders = nil
+	assert.NoError(t, testEngine.Shards(sharder.Range("shard_order", oct, oct)...).Asc("amount").Find(&orders))
+	assert.EqualValues(t, 2, len(orders))
+	assert.EqualValues(t, 2, orders[0].Amount)
+	assert.EqualValues(t, 5, orders[1].Amount)
+
+	orders = nil
+	assert.NoError(t, testEngine.Where("amount > ?", 3).Asc("amount").Find(&orders))
+	assert.EqualValues(t, 2, len(orders))
+
+	cnt, err = testEngine.NoAutoCondition().Where("amount = ?", 4).Delete(&ShardOrder{CreatedAt: sep})
+	assert.NoError(t, err)
+	assert.EqualValues(t, 1, cnt)
+
+	cnt, err = testEngine.Table(sepTable).Count(new(ShardOrder))
+	assert.NoError(t, err)
+	assert.EqualValues(t, 1, cnt)
+}
+
+func TestShardByBean(t *testing.T) {
+	assert.NoError(t, PrepareEngine())
+	assert.NoError(t, testEngine.Sync2(new(ShardTenantEvent)))
+
+	for _, table := range []string{"shard_tenant_event_a", "shard_tenant_event_b"} {
+		exist, err := testEngine.IsTableExist(table)
+		assert.NoError(t, err)
+		assert.True(t, exist, table)
+	}
+
+	_, err := testEngine.Insert(&ShardTenantEvent{Tenant: "a", Name: "login"},
+		&ShardTenantEvent{Tenant: "b", Name: "logout"})
+	assert.NoError(t, err)
+
+	var event = ShardTenantEvent{Tenant: "b"}
+	has, err := testEngine.Get(&event)
+	assert.NoError(t, err)
+	assert.True(t, has)
+	assert.EqualValues(t, "logout", event.Name)
+
+	var events []ShardTenantEvent
+	assert.NoError(t, testEngine.Shards("shard_tenant_event_a").Find(&events))
+	assert.EqualValues(t, 1, len(events))
+	assert.EqualValues(t, "login", events[0].Name)
+
+	events = nil
+	assert.NoError(t, testEngine.Find(&events))
+	assert.EqualValues(t, 2, len(events))
+
+	events = nil
+	assert.NoError(t, testEngine.Find(&events, &ShardTenantEvent{Tenant: "b"}))
+	assert.EqualValues(t, 1, len(events))
+	assert.EqualValues(t, "logout", events[0].Name)
+
+	events = nil
+	assert.NoError(t, testEngine.Where("id > ?", 0).Find(&events, &ShardTenantEvent{Name: "login"}))
+	assert.EqualValues(t, 1, len(events))
+	assert.EqualValues(t, "a", events[0].Tenant)
+
+	assert.NoError(t, testEngine.DropIndexes(new(ShardTenantEvent)))
+	assert.NoError(t, testEngine.CreateIndexes(new(ShardTenantEvent)))
+	assert.NoError(t, testEngine.CreateUniques(new(ShardTenantEvent)))
+	tables, err := testEngine.DBMetas()
+	assert.NoError(t, err)
+	for _, table := range tables {
+		if strings.HasPrefix(table.Name, "shard_tenant_event_") {
+			assert.EqualValues(t, 1, len(table.Indexes), table.Name)
+		}
+	}
+}
+
+func shardAmounts(orders []ShardOrder) []int {
+	var amounts = make([]int, 0, len(orders))
+	for _, o := range orders {
+		amounts = append(amounts, o.Amount)
+	}
+	return amounts
+}
@@ -0,0 +1,154 @@
+// Copyright 2021 The Xorm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package integrations
+
+import (
+	"sort"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/xorm-io/builder"
+)
+
+type SubQueryDepart struct {
+	Id   int64
+	Name string
+}
+
+type SubQueryUser struct {
+	Id       int64
+	Name     string
+	DepartId int64
+	Age      int
+}
+
+func prepareSubQuery(t *testing.T) {
+	assert.NoError(t, PrepareEngine())
+	assertSync(t, new(SubQueryDepart), new(SubQueryUser))
+
+	_, err := testEngine.Insert([]SubQueryDepart{
+		{Id: 1, Name: "dev"},
+		{Id: 2, Name: "ops"},
+	})
+	assert.NoError(t, err)
+	_, err = testEngine.Insert([]SubQueryUser{
+		{Id: 1, Name: "user1", DepartId: 1, Age: 20},
+		{Id: 2, Name: "user2", DepartId: 1, Age: 30},
+		{Id: 3, Name: "user3", DepartId: 2, Age: 40},
+		{Id: 4, Name: "user4", DepartId: 3, Age: 50},
+	})
+	assert.NoError(t, err)
+}
+
+func TestSubQueryTable(t *testing.T) {
+	prepareSubQuery(t)
+
+	var users []SubQueryUser
+	err := testEngine.Table([]interface{}{
+		testEngine.Table(new(SubQueryUser)).Where("age > ?", 25),
+		"u",
+	}).Where("depart_id = ?", 1).Find(&users)
+	assert.NoError(t, err)
+	assert.EqualValues(t, 1, len(users))
+	assert.EqualValues(t, "user2", users[0].Name)
+
+	cnt, err := testEngine.Table(testEngine.Table(new(SubQueryUser)).Where("age > ?", 25)).
+		Alias("u").Where("depart_id < ?", 3).Count()
+	assert.NoError(t, err)
+	assert.EqualValues(t, 2, cnt)
+}
+
+func TestSubQueryJoin(t *testing.T) {
+	prepareSubQuery(t)
+
+	var users []SubQueryUser
+	err := testEngine.Table(new(SubQueryUser)).Alias("u").
+		Join("INNER", []interface{}{
+			testEngine.Table(new(SubQueryDepart)).Cols("id").Where("name = ?", "dev"),
+			"d",
+		}, "`d`.`id` = `u`.`depart_id`").
+		Where("`u`.`age` > ?", 25).
+		Select("`u`.*").
+		Find(&users)
+	assert.NoError(t, err)
+	assert.EqualValues(t, 1, len(users))
+	assert.EqualValues(t, "user2", users[0].Name)
+}
+
+func TestSubQueryIn(t *testing.T) {
+	prepareSubQuery(t)
+
+	var users []SubQueryUser
+	err := testEngine.In("depart_id", testEngine.Table(new(SubQueryDepart)).Cols("id").Where("name = ?", "ops")).
+		Where("age > ?", 10).
+		Find(&users)
+	assert.NoError(t, err)
+	assert.EqualValues(t, 1, len(users))
+	assert.EqualValues(t, "user3", users[0].Name)
+
+	users = users[:0]
+	err = testEngine.NotIn("depart_id", testEngine.Table(new(SubQueryDepart)).Cols("id")).Find(&users)
+	assert.NoError(t, err)
+	assert.EqualValues(t, 1, len(users))
+	assert.EqualValues(t, "user4", users[0].Name)
+
+	// a builder is still supported
+	users = users[:0]
+	err = testEngine.In("depart_id", builder.Select("id").From(testEngine.TableName(new(SubQueryDepart), true))).
+		Asc("id").Find(&users)
+	assert.NoError(t, err)
+	assert.EqualValues(t, 3, len(users))
+}
+
+func TestSubQueryUnion(t *testing.T) {
+	prepareSubQuery(t)
+
+	var names []string
+	err := testEngine.Table(new(SubQueryUser)).Cols("name").Where("age < ?", 25).
+		Union(testEngine.Table(new(SubQueryDepart)).Cols("name").Where("id = ?", 2)).
+		UnionAll(testEngine.Table(new(SubQueryDepart)).Cols("name").Where("id = ?", 2)).
+		Find(&names)
+	assert.NoError(t, err)
+	sort.Strings(names)
+	assert.EqualValues(t, []string{"ops", "ops", "user1"}, names)
+
+	cnt, err := testEngine.Table(new(SubQueryUser)).Cols("name").Where("age < ?", 25).
+		Union(testEngine.Table(new(SubQueryDepart)).Cols("name")).
+		Count()
+	assert.NoError(t, err)
+	assert.EqualValues(t, 3, cnt)
+}
+
+func TestSubQueryWith(t *testing.T) {
+	prepareSubQuery(t)
+
+	var users []SubQueryUser
+	err := testEngine.With("old_user", testEngine.Table(new(SubQueryUser)).Where("age > ?", 35)).
+		Table("old_user").Where("depart_id = ?", 2).Find(&users)
+	assert.NoError(t, err)
+	assert.EqualValues(t, 1, len(users))
+	assert.EqualValues(t, "user3", users[0].Name)
+
+	cnt, err := testEngine.With("old_user", testEngine.Table(new(SubQueryUser)).Where("age > ?", 35)).
+		Table("old_user").Count()
+	assert.NoError(t, err)
+	assert.EqualValues(t, 2, cnt)
+
+	var nums []int
+	err = testEngine.WithRecursive("seq(n)",
+		testEngine.SQL("SELECT 1").UnionAll(testEngine.Table("seq").Select("n + 1").Where("n < ?", 5))).
+		Table("seq").Cols("n").Find(&nums)
+	assert.NoError(t, err)
+	assert.EqualValues(t, []int{1, 2, 3, 4, 5}, nums)
+}
+
+func TestSubQuerySQL(t *testing.T) {
+	prepareSubQuery(t)
+
+	sql, args, err := testEngine.Table(new(SubQueryUser)).Cols("id").Where("age > ?", 25).SubQuerySQL()
+	assert.NoError(t, err)
+	assert.Contains(t, sql, "SELECT")
+	assert.EqualValues(t, []interface{}{25}, args)
+}
@@ -0,0 +1,131 @@
+// Copyright 2021 The Xorm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package integrations
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/xorm-io/xorm"
+	"github.com/xorm-io/xorm/dialects"
+	"github.com/xorm-io/xorm/schemas"
+)
+
+type SchemaTenantUser struct {
+	Id   int64
+	Name string `xorm:"index"`
+}
+
+// prepareTenantSchemas creates the empty schemas and returns the engine to use
+func prepareTenantSchemas(t *testing.T, tenants ...string) xorm.EngineInterface {
+	switch testEngine.Dialect().URI().DBType {
+	case schemas.SQLITE:
+		// the attached databases belong to the connection
+		engine, err := xorm.NewEngine(testEngine.DriverName(), ":memory:")
+		assert.NoError(t, err)
+		engine.SetMaxOpenConns(1)
+		for _, tenant := range tenants {
+			_, err = engine.Exec("ATTACH DATABASE ':memory:' AS " + tenant)
+			assert.NoError(t, err)
+		}
+		return engine
+	case schemas.POSTGRES:
+		for _, tenant := range tenants {
+			_, err := testEngine.Exec("DROP SCHEMA IF EXISTS " + tenant + " CASCADE")
+			assert.NoError(t, err)
+			_, err = testEngine.Exec("CREATE SCHEMA " + tenant)
+			assert.NoError(t, err)
+		}
+		return testEngine
+	case schemas.MYSQL:
+		for _, tenant := range tenants {
+			_, err := testEngine.Exec("DROP DATABASE IF EXISTS " + tenant)
+			assert.NoError(t, err)
+			_, err = testEngine.Exec("CREATE DATABASE " + tenant)
+			assert.NoError(t, err)
+		}
+		return testEngine
+	}
+	t.Skip("schemas are not created for", testEngine.Dialect().URI().DBType)
+	return nil
+}
+
+func TestSessionSchema(t *testing.T) {
+	assert.NoError(t, PrepareEngine())
+	engine := prepareTenantSchemas(t, "tenant_a", "tenant_b")
+
+	assert.NoError(t, engine.Schema("tenant_a").Sync2(new(SchemaTenantUser)))
+	assert.NoError(t, engine.Schema("tenant_b").Sync2(new(SchemaTenantUser)))
+	// sync again to check the tables and the indexes of the schema
+	assert.NoError(t, engine.Schema("tenant_a").Sync2(new(SchemaTenantUser)))
+
+	exist, err := engine.Schema("tenant_a").IsTableExist(new(SchemaTenantUser))
+	assert.NoError(t, err)
+	assert.True(t, exist)
+	exist, err = engine.IsTableExist(new(SchemaTenantUser))
+	assert.NoError(t, err)
+	assert.False(t, exist)
+
+	_, err = engine.Schema("tenant_a").Insert(&SchemaTenantUser{Name: "a1"})
+	assert.NoError(t, err)
+	_, err = engine.Schema("tenant_b").Insert([]SchemaTenantUser{{Name: "b1"}, {Name: "b2"}})
+	assert.NoError(t, err)
+
+	cnt, err := engine.Schema("tenant_a").Count(new(SchemaTenantUser))
+	assert.NoError(t, err)
+	assert.EqualValues(t, 1, cnt)
+
+	// the schema is kept by the session across the statements
+	session := engine.NewSession().Schema("tenant_b")
+	defer session.Close()
+	var users []SchemaTenantUser
+	assert.NoError(t, session.Asc("name").Find(&users))
+	assert.EqualValues(t, 2, len(users))
+	assert.EqualValues(t, "b1", users[0].Name)
+
+	cnt, err = session.ID(users[0].Id).Update(&SchemaTenantUser{Name: "b3"})
+	assert.NoError(t, err)
+	assert.EqualValues(t, 1, cnt)
+
+	var user SchemaTenantUser
+	has, err := session.Where("name = ?", "b3").Get(&user)
+	assert.NoError(t, err)
+	assert.True(t, has)
+
+	cnt, err = session.Delete(&SchemaTenantUser{Name: "b2"})
+	assert.NoError(t, err)
+	assert.EqualValues(t, 1, cnt)
+
+	// the schema could be carried by the context
+	ctx := dialects.WithSchema(context.Background(), "tenant_b")
+	cnt, err = engine.Context(ctx).Count(new(SchemaTenantUser))
+	assert.NoError(t, err)
+	assert.EqualValues(t, 1, cnt)
+
+	cnt, err = engine.Schema("tenant_a").Where("name = ?", "a1").Count(new(SchemaTenantUser))
+	assert.NoError(t, err)
+	assert.EqualValues(t, 1, cnt)
+
+	// the schema which isn't an identifier is rejected
+	_, err = engine.Schema("tenant_a') --").Count(new(SchemaTenantUser))
+	assert.EqualValues(t, dialects.ErrInvalidSchema, err)
+	_, err = engine.Schema("tenant_a.x").IsTableExist(new(SchemaTenantUser))
+	assert.EqualValues(t, dialects.ErrInvalidSchema, err)
+	ctx = dialects.WithSchema(context.Background(), "tenant b")
+	assert.EqualValues(t, dialects.ErrInvalidSchema, engine.Context(ctx).Sync2(new(SchemaTenantUser)))
+	_, err = engine.Context(ctx).Exec("DELETE FROM schema_tenant_user")
+	assert.EqualValues(t, dialects.ErrInvalidSchema, err)
+
+	session = engine.NewSession().Schema("tenant b")
+	defer session.Close()
+	_, err = session.Insert(&SchemaTenantUser{Name: "b4"})
+	assert.EqualValues(t, dialects.ErrInvalidSchema, err)
+	_, err = session.Insert(&SchemaTenantUser{Name: "b4"})
+	assert.EqualValues(t, dialects.ErrInvalidSchema, err)
+	cnt, err = session.Schema("tenant_b").Count(new(SchemaTenantUser))
+	assert.NoError(t, err)
+	assert.EqualValues(t, 1, cnt)
+}
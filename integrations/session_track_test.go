@@ -0,0 +1,147 @@
+// Copyright 2021 The Xorm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package integrations
+
+import (
+	"testing"
+	"time"
+
+	"github.com/xorm-io/xorm"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type TrackedUser struct {
+	Id      int64
+	Name    string
+	Age     int
+	Enabled bool
+	Updated time.Time `xorm:"updated"`
+}
+
+func TestTrack(t *testing.T) {
+	assert.NoError(t, PrepareEngine())
+	assertSync(t, new(TrackedUser))
+
+	_, err := testEngine.Insert([]TrackedUser{
+		{Name: "a", Age: 10, Enabled: true},
+		{Name: "b", Age: 20, Enabled: true},
+	})
+	assert.NoError(t, err)
+
+	session := testEngine.NewSession()
+	defer session.Close()
+	session.Track()
+
+	var user TrackedUser
+	has, err := session.Where("name = ?", "a").Get(&user)
+	assert.NoError(t, err)
+	assert.True(t, has)
+
+	changes, err := session.Changes(&user)
+	assert.NoError(t, err)
+	assert.EqualValues(t, 0, len(changes))
+
+	// nothing changed, nothing updated
+	cnt, err := session.Update(&user)
+	assert.NoError(t, err)
+	assert.EqualValues(t, 0, cnt)
+
+	// the zero values are written, only the row of the bean is updated
+	user.Age = 0
+	user.Enabled = false
+	changes, err = session.Changes(&user)
+	assert.NoError(t, err)
+	assert.EqualValues(t, 2, len(changes))
+	if assert.NotNil(t, changes["age"]) {
+		assert.EqualValues(t, 10, changes["age"].Old)
+		assert.EqualValues(t, 0, changes["age"].New)
+	}
+
+	cnt, err = session.Update(&user)
+	assert.NoError(t, err)
+	assert.EqualValues(t, 1, cnt)
+
+	changes, err = session.Changes(&user)
+	assert.NoError(t, err)
+	assert.EqualValues(t, 0, len(changes))
+
+	var users []TrackedUser
+	assert.NoError(t, testEngine.Asc("id").Find(&users))
+	if assert.EqualValues(t, 2, len(users)) {
+		assert.EqualValues(t, 0, users[0].Age)
+		assert.False(t, users[0].Enabled)
+		assert.EqualValues(t, "a", users[0].Name)
+		assert.EqualValues(t, 20, users[1].Age)
+		assert.True(t, users[1].Enabled)
+	}
+
+	// the beans loaded by Find are tracked too
+	users = nil
+	assert.NoError(t, session.Asc("id").Find(&users))
+	assert.EqualValues(t, 2, len(users))
+	users[1].Name = "c"
+	cnt, err = session.Update(&users[1])
+	assert.NoError(t, err)
+	assert.EqualValues(t, 1, cnt)
+
+	var user2 TrackedUser
+	has, err = testEngine.ID(users[1].Id).Get(&user2)
+	assert.NoError(t, err)
+	assert.True(t, has)
+	assert.EqualValues(t, "c", user2.Name)
+	assert.EqualValues(t, 20, user2.Age)
+
+	_, err = session.Changes(&TrackedUser{Id: 100})
+	assert.EqualError(t, err, xorm.ErrNotTracked.Error())
+	_, err = testEngine.NewSession().Changes(&user)
+	assert.EqualError(t, err, xorm.ErrNotTracked.Error())
+
+	// the rows of the same primary key in different tables are tracked apart
+	const copyTable = "tracked_user_copy"
+	assert.NoError(t, testEngine.Table(copyTable).CreateTable(new(TrackedUser)))
+	_, err = testEngine.Table(copyTable).Insert(&TrackedUser{Id: user.Id, Name: "copy", Age: 30})
+	assert.NoError(t, err)
+
+	var loaded, copied TrackedUser
+	has, err = session.ID(user.Id).Get(&loaded)
+	assert.NoError(t, err)
+	assert.True(t, has)
+	has, err = session.Table(copyTable).ID(user.Id).Get(&copied)
+	assert.NoError(t, err)
+	assert.True(t, has)
+	assert.EqualValues(t, 30, copied.Age)
+
+	changes, err = session.Table(copyTable).Changes(&copied)
+	assert.NoError(t, err)
+	assert.EqualValues(t, 0, len(changes))
+
+	loaded.Age = 30
+	changes, err = session.Changes(&loaded)
+	assert.NoError(t, err)
+	if assert.EqualValues(t, 1, len(changes)) {
+		assert.EqualValues(t, 0, changes["age"].Old)
+	}
+	cnt, err = session.Update(&loaded)
+	assert.NoError(t, err)
+	assert.EqualValues(t, 1, cnt)
+
+	users = nil
+	assert.NoError(t, session.Table(copyTable).Find(&users))
+	if assert.EqualValues(t, 1, len(users)) {
+		users[0].Name = "copied"
+		changes, err = session.Table(copyTable).Changes(&users[0])
+		assert.NoError(t, err)
+		if assert.EqualValues(t, 1, len(changes)) {
+			assert.EqualValues(t, "copy", changes["name"].Old)
+		}
+	}
+
+	var user3 TrackedUser
+	has, err = testEngine.ID(user.Id).NoCache().Get(&user3)
+	assert.NoError(t, err)
+	assert.True(t, has)
+	assert.EqualValues(t, 30, user3.Age)
+}
@@ -0,0 +1,159 @@
+// Copyright 2021 The Xorm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package integrations
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/xorm-io/builder"
+	"github.com/xorm-io/xorm"
+)
+
+type ViewOrder struct {
+	Id     int64
+	Name   string
+	Amount int
+}
+
+type ViewBigOrder struct {
+	Id     int64
+	Name   string
+	Amount int
+}
+
+func (ViewBigOrder) ViewSQL() interface{} {
+	return fmt.Sprintf("SELECT `%s`, `%s`, `%s` FROM `%s` WHERE `%s` > 10",
+		colMapper.Obj2Table("Id"), colMapper.Obj2Table("Name"), colMapper.Obj2Table("Amount"),
+		tableMapper.Obj2Table("ViewOrder"), colMapper.Obj2Table("Amount"))
+}
+
+type ViewSmallOrder struct {
+	Id     int64
+	Name   string
+	Amount int
+}
+
+func (ViewSmallOrder) ViewSQL() interface{} {
+	return builder.Select(colMapper.Obj2Table("Id"), colMapper.Obj2Table("Name"), colMapper.Obj2Table("Amount")).
+		From(tableMapper.Obj2Table("ViewOrder")).
+		Where(builder.Lte{colMapper.Obj2Table("Amount"): 10})
+}
+
+type ViewTotalOrder struct {
+	Name  string
+	Total int
+}
+
+func (ViewTotalOrder) ViewSQL() interface{} {
+	return fmt.Sprintf("SELECT `%s` AS `%s`, SUM(`%s`) AS `%s` FROM `%s` GROUP BY `%s`",
+		colMapper.Obj2Table("Name"), colMapper.Obj2Table("Name"),
+		colMapper.Obj2Table("Amount"), colMapper.Obj2Table("Total"),
+		tableMapper.Obj2Table("ViewOrder"), colMapper.Obj2Table("Name"))
+}
+
+func (ViewTotalOrder) Materialized() bool {
+	return true
+}
+
+type ViewTotalBigOrder struct {
+	Name  string
+	Total int
+}
+
+func (ViewTotalBigOrder) TableName() string {
+	return tableMapper.Obj2Table("ViewTotalOrder")
+}
+
+func (ViewTotalBigOrder) ViewSQL() interface{} {
+	return fmt.Sprintf("SELECT `%s` AS `%s`, SUM(`%s`) AS `%s` FROM `%s` WHERE `%s` > 10 GROUP BY `%s`",
+		colMapper.Obj2Table("Name"), colMapper.Obj2Table("Name"),
+		colMapper.Obj2Table("Amount"), colMapper.Obj2Table("Total"),
+		tableMapper.Obj2Table("ViewOrder"), colMapper.Obj2Table("Amount"), colMapper.Obj2Table("Name"))
+}
+
+func (ViewTotalBigOrder) Materialized() bool {
+	return true
+}
+
+func TestView(t *testing.T) {
+	assert.NoError(t, PrepareEngine())
+	assertSync(t, new(ViewOrder))
+
+	_, err := testEngine.Insert([]ViewOrder{
+		{Name: "a", Amount: 5},
+		{Name: "a", Amount: 20},
+		{Name: "b", Amount: 30},
+	})
+	assert.NoError(t, err)
+
+	assert.NoError(t, testEngine.Sync2(new(ViewBigOrder), new(ViewSmallOrder), new(ViewTotalOrder)))
+	// sync again replaces the views
+	assert.NoError(t, testEngine.Sync2(new(ViewBigOrder), new(ViewSmallOrder), new(ViewTotalOrder)))
+
+	var bigs []ViewBigOrder
+	assert.NoError(t, testEngine.Asc("id").Find(&bigs))
+	assert.EqualValues(t, 2, len(bigs))
+	assert.EqualValues(t, 20, bigs[0].Amount)
+	assert.EqualValues(t, 30, bigs[1].Amount)
+
+	cnt, err := testEngine.Count(new(ViewSmallOrder))
+	assert.NoError(t, err)
+	assert.EqualValues(t, 1, cnt)
+
+	assert.NoError(t, testEngine.RefreshMaterializedView(new(ViewTotalOrder)))
+
+	var total ViewTotalOrder
+	has, err := testEngine.Where("name = ?", "a").Get(&total)
+	assert.NoError(t, err)
+	assert.True(t, has)
+	assert.EqualValues(t, 25, total.Total)
+
+	_, err = testEngine.Insert(&ViewBigOrder{Name: "c", Amount: 40})
+	assert.EqualError(t, err, xorm.ErrViewReadOnly.Error())
+	_, err = testEngine.ID(bigs[0].Id).Update(&ViewBigOrder{Amount: 50})
+	assert.EqualError(t, err, xorm.ErrViewReadOnly.Error())
+	_, err = testEngine.ID(bigs[0].Id).Delete(new(ViewBigOrder))
+	assert.EqualError(t, err, xorm.ErrViewReadOnly.Error())
+	_, err = testEngine.Table(new(ViewBigOrder)).ID(bigs[0].Id).Update(map[string]interface{}{"amount": 50})
+	assert.EqualError(t, err, xorm.ErrViewReadOnly.Error())
+	_, err = testEngine.Table(new(ViewBigOrder)).Insert(map[string]interface{}{"name": "c", "amount": 40})
+	assert.EqualError(t, err, xorm.ErrViewReadOnly.Error())
+	_, err = testEngine.Table(new(ViewBigOrder)).Insert([]map[string]string{{"name": "c", "amount": "40"}})
+	assert.EqualError(t, err, xorm.ErrViewReadOnly.Error())
+
+	// the changed query of the materialized view is applied
+	assert.NoError(t, testEngine.Sync2(new(ViewTotalBigOrder)))
+	var totalBig ViewTotalBigOrder
+	has, err = testEngine.Where("name = ?", "a").Get(&totalBig)
+	assert.NoError(t, err)
+	assert.True(t, has)
+	assert.EqualValues(t, 20, totalBig.Total)
+
+	// the views are listed apart from the tables
+	tables, err := testEngine.DBMetas()
+	assert.NoError(t, err)
+	assert.EqualValues(t, 1, len(tables))
+	assert.EqualValues(t, tableMapper.Obj2Table("ViewOrder"), tables[0].Name)
+	views, err := testEngine.DBViews()
+	assert.NoError(t, err)
+	var viewCols = make(map[string]int)
+	for _, view := range views {
+		assert.True(t, view.IsView)
+		viewCols[view.Name] = len(view.ColumnsSeq())
+	}
+	assert.EqualValues(t, map[string]int{
+		tableMapper.Obj2Table("ViewBigOrder"):   3,
+		tableMapper.Obj2Table("ViewSmallOrder"): 3,
+		tableMapper.Obj2Table("ViewTotalOrder"): 2,
+	}, viewCols)
+
+	assert.NoError(t, testEngine.DropViews(new(ViewBigOrder), tableMapper.Obj2Table("ViewSmallOrder"), new(ViewTotalOrder)))
+
+	views, err = testEngine.DBViews()
+	assert.NoError(t, err)
+	assert.EqualValues(t, 0, len(views))
+}
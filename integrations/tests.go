@@ -158,6 +158,18 @@ func createEngine(dbType, connStr string) error {
 	tableMapper = testEngine.GetTableMapper()
 	colMapper = testEngine.GetColumnMapper()
 
+	views, err := testEngine.DBViews()
+	if err != nil {
+		return err
+	}
+	var viewNames = make([]interface{}, 0, len(views))
+	for _, view := range views {
+		viewNames = append(viewNames, view.Name)
+	}
+	if err := testEngine.DropViews(viewNames...); err != nil {
+		return err
+	}
+
 	tables, err := testEngine.DBMetas()
 	if err != nil {
 		return err
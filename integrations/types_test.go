@@ -5,11 +5,14 @@
 package integrations
 
 import (
+	"database/sql/driver"
 	"errors"
 	"fmt"
 	"math"
 	"math/big"
+	"reflect"
 	"strconv"
+	"strings"
 	"testing"
 
 	"github.com/xorm-io/xorm"
@@ -379,6 +382,107 @@ func TestCustomType2(t *testing.T) {
 	fmt.Println(users)
 }
 
+// ConvertedPoint has no methods, it's stored by pointConverter
+type ConvertedPoint struct {
+	X, Y int
+}
+
+type pointConverter struct{}
+
+func (pointConverter) SQLType() schemas.SQLType {
+	return schemas.SQLType{Name: schemas.Varchar, DefaultLength: 64}
+}
+
+func (pointConverter) ToDB(v interface{}) (driver.Value, error) {
+	p := v.(ConvertedPoint)
+	return fmt.Sprintf("%d,%d", p.X, p.Y), nil
+}
+
+func (pointConverter) FromDB(src interface{}, dest interface{}) error {
+	var s string
+	switch t := src.(type) {
+	case string:
+		s = t
+	case []byte:
+		s = string(t)
+	default:
+		return fmt.Errorf("unsupported point %v", src)
+	}
+	parts := strings.Split(s, ",")
+	if len(parts) != 2 {
+		return fmt.Errorf("invalid point %s", s)
+	}
+	x, err := strconv.Atoi(parts[0])
+	if err != nil {
+		return err
+	}
+	y, err := strconv.Atoi(parts[1])
+	if err != nil {
+		return err
+	}
+	*dest.(*ConvertedPoint) = ConvertedPoint{x, y}
+	return nil
+}
+
+type UserConverted struct {
+	Id       int64
+	Location ConvertedPoint
+	Home     *ConvertedPoint
+}
+
+func TestRegisterConverter(t *testing.T) {
+	assert.NoError(t, PrepareEngine())
+
+	testEngine.RegisterConverter(reflect.TypeOf(ConvertedPoint{}), pointConverter{})
+	defer testEngine.RegisterConverter(reflect.TypeOf(ConvertedPoint{}), nil)
+
+	assertSync(t, new(UserConverted))
+
+	table, err := testEngine.TableInfo(new(UserConverted))
+	assert.NoError(t, err)
+	assert.EqualValues(t, schemas.Varchar, table.GetColumn("location").SQLType.Name)
+	assert.EqualValues(t, 64, table.GetColumn("location").Length)
+	assert.EqualValues(t, schemas.Varchar, table.GetColumn("home").SQLType.Name)
+
+	cnt, err := testEngine.Insert(&UserConverted{
+		Location: ConvertedPoint{1, 2},
+		Home:     &ConvertedPoint{3, 4},
+	})
+	assert.NoError(t, err)
+	assert.EqualValues(t, 1, cnt)
+
+	var raw string
+	has, err := testEngine.Table(new(UserConverted)).Cols("location").Get(&raw)
+	assert.NoError(t, err)
+	assert.True(t, has)
+	assert.EqualValues(t, "1,2", raw)
+
+	var user UserConverted
+	has, err = testEngine.Get(&user)
+	assert.NoError(t, err)
+	assert.True(t, has)
+	assert.EqualValues(t, ConvertedPoint{1, 2}, user.Location)
+	if assert.NotNil(t, user.Home) {
+		assert.EqualValues(t, ConvertedPoint{3, 4}, *user.Home)
+	}
+
+	cnt, err = testEngine.ID(user.Id).Update(&UserConverted{Location: ConvertedPoint{5, 6}})
+	assert.NoError(t, err)
+	assert.EqualValues(t, 1, cnt)
+
+	var users []UserConverted
+	err = testEngine.Find(&users, &UserConverted{Location: ConvertedPoint{5, 6}})
+	assert.NoError(t, err)
+	if assert.EqualValues(t, 1, len(users)) {
+		assert.EqualValues(t, ConvertedPoint{5, 6}, users[0].Location)
+		assert.EqualValues(t, ConvertedPoint{3, 4}, *users[0].Home)
+	}
+
+	cnt, err = testEngine.Count(&UserConverted{Location: ConvertedPoint{1, 2}})
+	assert.NoError(t, err)
+	assert.EqualValues(t, 0, cnt)
+}
+
 func TestUnsignedUint64(t *testing.T) {
 	type MyUnsignedStruct struct {
 		Id uint64
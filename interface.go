@@ -12,6 +12,7 @@ import (
 
 	"github.com/xorm-io/xorm/caches"
 	"github.com/xorm-io/xorm/contexts"
+	"github.com/xorm-io/xorm/convert"
 	"github.com/xorm-io/xorm/dialects"
 	"github.com/xorm-io/xorm/log"
 	"github.com/xorm-io/xorm/names"
@@ -20,12 +21,16 @@ import (
 
 // Interface defines the interface which Engine, EngineGroup and Session will implementate.
 type Interface interface {
+	Aggregate(exprs ...AggrExpr) *Session
 	AllCols() *Session
 	Alias(alias string) *Session
 	Asc(colNames ...string) *Session
+	Avg(bean interface{}, colName string) (float64, error)
 	BufferSize(size int) *Session
+	CacheFor(ttl time.Duration) *Session
 	Cols(columns ...string) *Session
 	Count(...interface{}) (int64, error)
+	CountDistinct(bean interface{}, colName string) (int64, error)
 	CreateIndexes(bean interface{}) error
 	CreateUniques(bean interface{}) error
 	Decr(column string, arg ...interface{}) *Session
@@ -48,10 +53,13 @@ type Interface interface {
 	IsTableExist(beanOrTableName interface{}) (bool, error)
 	Iterate(interface{}, IterFunc) error
 	Limit(int, ...int) *Session
+	Max(bean interface{}, colName string, res interface{}) error
+	Min(bean interface{}, colName string, res interface{}) error
 	MustCols(columns ...string) *Session
 	NoAutoCondition(...bool) *Session
 	NotIn(string, ...interface{}) *Session
 	Join(joinOperator string, tablename interface{}, condition string, args ...interface{}) *Session
+	JSONSet(column, path string, value interface{}) *Session
 	Omit(columns ...string) *Session
 	OrderBy(order string) *Session
 	Ping() error
@@ -60,6 +68,8 @@ type Interface interface {
 	QueryString(sqlOrArgs ...interface{}) ([]map[string]string, error)
 	Rows(bean interface{}) (*Rows, error)
 	SetExpr(string, interface{}) *Session
+	Shards(shards ...string) *Session
+	Schema(schema string) *Session
 	Select(string) *Session
 	SQL(interface{}, ...interface{}) *Session
 	Sum(bean interface{}, colName string) (float64, error)
@@ -71,6 +81,8 @@ type Interface interface {
 	Update(bean interface{}, condiBeans ...interface{}) (int64, error)
 	UseBool(...string) *Session
 	Where(interface{}, ...interface{}) *Session
+	With(name string, subQuery interface{}) *Session
+	WithRecursive(name string, subQuery interface{}) *Session
 }
 
 // EngineInterface defines the interface which Engine, EngineGroup will implementate.
@@ -82,11 +94,14 @@ type EngineInterface interface {
 	ClearCache(...interface{}) error
 	Context(context.Context) *Session
 	CreateTables(...interface{}) error
+	CreateViews(...interface{}) error
 	DBMetas() ([]*schemas.Table, error)
+	DBViews() ([]*schemas.Table, error)
 	DBVersion() (*schemas.Version, error)
 	Dialect() dialects.Dialect
 	DriverName() string
 	DropTables(...interface{}) error
+	DropViews(...interface{}) error
 	DumpAllToFile(fp string, tp ...schemas.DBType) error
 	GetCacher(string) caches.Cacher
 	GetColumnMapper() names.Mapper
@@ -95,15 +110,18 @@ type EngineInterface interface {
 	GetTZDatabase() *time.Location
 	GetTZLocation() *time.Location
 	ImportFile(fp string) ([]sql.Result, error)
-	MapCacher(interface{}, caches.Cacher) error
+	MapCacher(interface{}, caches.Cacher, ...caches.TableOptions) error
 	NewSession() *Session
 	NoAutoTime() *Session
 	Quote(string) string
+	RefreshMaterializedView(interface{}) error
+	RegisterConverter(reflect.Type, convert.Converter)
 	SetCacher(string, caches.Cacher)
 	SetConnMaxLifetime(time.Duration)
 	SetColumnMapper(names.Mapper)
 	SetTagIdentifier(string)
 	SetDefaultCacher(caches.Cacher)
+	SetKeyProvider(convert.KeyProvider)
 	SetLogger(logger interface{})
 	SetLogLevel(log.LogLevel)
 	SetMapper(names.Mapper)
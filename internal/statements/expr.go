@@ -27,8 +27,9 @@ type Expr struct {
 	Arg     interface{}
 }
 
-// WriteArgs writes args to the writer
-func (expr *Expr) WriteArgs(w *builder.BytesWriter) error {
+// WriteArgs writes args to the writer, the conditions are written with the
+// dialect of the writer
+func (expr *Expr) WriteArgs(w *CondWriter) error {
 	switch arg := expr.Arg.(type) {
 	case *builder.Builder:
 		if _, err := w.WriteString("("); err != nil {
@@ -40,6 +41,10 @@ func (expr *Expr) WriteArgs(w *builder.BytesWriter) error {
 		if _, err := w.WriteString(")"); err != nil {
 			return err
 		}
+	case builder.Cond:
+		if err := arg.WriteTo(w); err != nil {
+			return err
+		}
 	case string:
 		if arg == "" {
 			arg = "''"
@@ -79,7 +84,7 @@ func (exprs exprParams) IsColExist(colName string) bool {
 	return false
 }
 
-func (exprs exprParams) WriteArgs(w *builder.BytesWriter) error {
+func (exprs exprParams) WriteArgs(w *CondWriter) error {
 	for i, expr := range exprs {
 		if err := expr.WriteArgs(w); err != nil {
 			return err
@@ -8,7 +8,6 @@ import (
 	"fmt"
 	"strings"
 
-	"github.com/xorm-io/builder"
 	"github.com/xorm-io/xorm/schemas"
 )
 
@@ -27,7 +26,7 @@ func (statement *Statement) writeInsertOutput(buf *strings.Builder, table *schem
 // GenInsertSQL generates insert beans SQL
 func (statement *Statement) GenInsertSQL(colNames []string, args []interface{}) (string, []interface{}, error) {
 	var (
-		buf       = builder.NewWriter()
+		buf       = NewCondWriter(statement.dialect)
 		exprs     = statement.ExprColumns
 		table     = statement.RefTable
 		tableName = statement.TableName()
@@ -75,7 +74,7 @@ func (statement *Statement) GenInsertSQL(colNames []string, args []interface{})
 				return "", nil, err
 			}
 
-			if err := statement.WriteArgs(buf, args); err != nil {
+			if err := statement.WriteArgs(buf.BytesWriter, args); err != nil {
 				return "", nil, err
 			}
 
@@ -108,7 +107,7 @@ func (statement *Statement) GenInsertSQL(colNames []string, args []interface{})
 				return "", nil, err
 			}
 
-			if err := statement.WriteArgs(buf, args); err != nil {
+			if err := statement.WriteArgs(buf.BytesWriter, args); err != nil {
 				return "", nil, err
 			}
 
@@ -143,7 +142,7 @@ func (statement *Statement) GenInsertSQL(colNames []string, args []interface{})
 // GenInsertMapSQL generates insert map SQL
 func (statement *Statement) GenInsertMapSQL(columns []string, args []interface{}) (string, []interface{}, error) {
 	var (
-		buf       = builder.NewWriter()
+		buf       = NewCondWriter(statement.dialect)
 		exprs     = statement.ExprColumns
 		tableName = statement.TableName()
 	)
@@ -162,7 +161,7 @@ func (statement *Statement) GenInsertMapSQL(columns []string, args []interface{}
 			return "", nil, err
 		}
 
-		if err := statement.WriteArgs(buf, args); err != nil {
+		if err := statement.WriteArgs(buf.BytesWriter, args); err != nil {
 			return "", nil, err
 		}
 
@@ -186,7 +185,7 @@ func (statement *Statement) GenInsertMapSQL(columns []string, args []interface{}
 		if _, err := buf.WriteString(") VALUES ("); err != nil {
 			return "", nil, err
 		}
-		if err := statement.WriteArgs(buf, args); err != nil {
+		if err := statement.WriteArgs(buf.BytesWriter, args); err != nil {
 			return "", nil, err
 		}
 
@@ -21,7 +21,8 @@ func (statement *Statement) GenQuerySQL(sqlOrArgs ...interface{}) (string, []int
 	}
 
 	if statement.RawSQL != "" {
-		return statement.GenRawSQL(), statement.RawParams, nil
+		sqlStr, args := statement.genRawQuerySQL()
+		return sqlStr, args, nil
 	}
 
 	if len(statement.TableName()) <= 0 {
@@ -29,7 +30,9 @@ func (statement *Statement) GenQuerySQL(sqlOrArgs ...interface{}) (string, []int
 	}
 
 	var columnStr = statement.ColumnStr()
-	if len(statement.SelectStr) > 0 {
+	if len(statement.Aggregates) > 0 {
+		columnStr = statement.aggregateColumnStr()
+	} else if len(statement.SelectStr) > 0 {
 		columnStr = statement.SelectStr
 	} else {
 		if statement.JoinStr == "" {
@@ -62,7 +65,7 @@ func (statement *Statement) GenQuerySQL(sqlOrArgs ...interface{}) (string, []int
 	if err != nil {
 		return "", nil, err
 	}
-	args := append(statement.joinArgs, condArgs...)
+	args := append(statement.fromArgs(), condArgs...)
 
 	// for mssql and use limit
 	qs := strings.Count(sqlStr, "?")
@@ -75,33 +78,39 @@ func (statement *Statement) GenQuerySQL(sqlOrArgs ...interface{}) (string, []int
 
 // GenSumSQL generates sum SQL
 func (statement *Statement) GenSumSQL(bean interface{}, columns ...string) (string, []interface{}, error) {
+	return statement.GenAggregateSQL(bean, "COALESCE(sum(%s),0)", columns...)
+}
+
+// GenAggregateSQL generates the SQL of the aggregate function of the columns,
+// format is the aggregate expression of a column, e.g. max(%s)
+func (statement *Statement) GenAggregateSQL(bean interface{}, format string, columns ...string) (string, []interface{}, error) {
 	if statement.RawSQL != "" {
 		return statement.GenRawSQL(), statement.RawParams, nil
 	}
 
 	statement.SetRefBean(bean)
 
-	var sumStrs = make([]string, 0, len(columns))
+	var aggrStrs = make([]string, 0, len(columns))
 	for _, colName := range columns {
 		if !strings.Contains(colName, " ") && !strings.Contains(colName, "(") {
 			colName = statement.quote(colName)
 		} else {
 			colName = statement.ReplaceQuote(colName)
 		}
-		sumStrs = append(sumStrs, fmt.Sprintf("COALESCE(sum(%s),0)", colName))
+		aggrStrs = append(aggrStrs, fmt.Sprintf(format, colName))
 	}
-	sumSelect := strings.Join(sumStrs, ", ")
+	aggrSelect := strings.Join(aggrStrs, ", ")
 
 	if err := statement.mergeConds(bean); err != nil {
 		return "", nil, err
 	}
 
-	sqlStr, condArgs, err := statement.genSelectSQL(sumSelect, true, true)
+	sqlStr, condArgs, err := statement.genSelectSQL(aggrSelect, true, true)
 	if err != nil {
 		return "", nil, err
 	}
 
-	return sqlStr, append(statement.joinArgs, condArgs...), nil
+	return sqlStr, append(statement.fromArgs(), condArgs...), nil
 }
 
 // GenGetSQL generates Get SQL
@@ -116,7 +125,9 @@ func (statement *Statement) GenGetSQL(bean interface{}) (string, []interface{},
 	}
 
 	var columnStr = statement.ColumnStr()
-	if len(statement.SelectStr) > 0 {
+	if len(statement.Aggregates) > 0 {
+		columnStr = statement.aggregateColumnStr()
+	} else if len(statement.SelectStr) > 0 {
 		columnStr = statement.SelectStr
 	} else {
 		// TODO: always generate column names, not use * even if join
@@ -156,7 +167,7 @@ func (statement *Statement) GenGetSQL(bean interface{}) (string, []interface{},
 		return "", nil, err
 	}
 
-	return sqlStr, append(statement.joinArgs, condArgs...), nil
+	return sqlStr, append(statement.fromArgs(), condArgs...), nil
 }
 
 // GenCountSQL generates the SQL for counting
@@ -187,20 +198,33 @@ func (statement *Statement) GenCountSQL(beans ...interface{}) (string, []interfa
 	var subQuerySelect string
 	if statement.GroupByStr != "" {
 		subQuerySelect = statement.GroupByStr
+	} else if len(statement.unions) > 0 {
+		// count the rows of the unions
+		subQuerySelect = statement.ColumnStr()
+		if subQuerySelect == "" {
+			subQuerySelect = "*"
+		}
 	} else {
 		subQuerySelect = selectSQL
 	}
 
+	// the common table expressions are written before the outer query
+	var needSubQuery = statement.GroupByStr != "" || len(statement.unions) > 0
+	var withs = statement.withs
+	if needSubQuery {
+		statement.withs = nil
+	}
 	sqlStr, condArgs, err := statement.genSelectSQL(subQuerySelect, false, false)
+	statement.withs = withs
 	if err != nil {
 		return "", nil, err
 	}
 
-	if statement.GroupByStr != "" {
-		sqlStr = fmt.Sprintf("SELECT %s FROM (%s) sub", selectSQL, sqlStr)
+	if needSubQuery {
+		sqlStr = statement.writeWith(fmt.Sprintf("SELECT %s FROM (%s) sub", selectSQL, sqlStr))
 	}
 
-	return sqlStr, append(statement.joinArgs, condArgs...), nil
+	return sqlStr, append(statement.fromArgs(), condArgs...), nil
 }
 
 func (statement *Statement) genSelectSQL(columnStr string, needLimit, needOrderBy bool) (string, []interface{}, error) {
@@ -223,11 +247,7 @@ func (statement *Statement) genSelectSQL(columnStr string, needLimit, needOrderB
 		whereStr = " WHERE " + condSQL
 	}
 
-	if dialect.URI().DBType == schemas.MSSQL && strings.Contains(statement.TableName(), "..") {
-		fromStr += statement.TableName()
-	} else {
-		fromStr += quote(statement.TableName())
-	}
+	fromStr += statement.fromTable()
 
 	if statement.TableAlias != "" {
 		if dialect.URI().DBType == schemas.ORACLE {
@@ -241,12 +261,17 @@ func (statement *Statement) genSelectSQL(columnStr string, needLimit, needOrderB
 	}
 
 	pLimitN := statement.LimitN
+	offsetFetch := statement.supportOffsetFetch()
 	if dialect.URI().DBType == schemas.MSSQL {
-		if pLimitN != nil {
+		if offsetFetch {
+			if pLimitN != nil && statement.Start == 0 {
+				top = fmt.Sprintf("TOP %d ", *pLimitN)
+			}
+		} else if pLimitN != nil {
 			LimitNValue := *pLimitN
 			top = fmt.Sprintf("TOP %d ", LimitNValue)
 		}
-		if statement.Start > 0 {
+		if statement.Start > 0 && !offsetFetch {
 			var column string
 			if len(statement.RefTable.PKColumns()) == 0 {
 				for _, index := range statement.RefTable.Indexes {
@@ -299,11 +324,15 @@ func (statement *Statement) genSelectSQL(columnStr string, needLimit, needOrderB
 	if statement.HavingStr != "" {
 		fmt.Fprint(&buf, " ", statement.HavingStr)
 	}
+	statement.writeUnions(&buf)
+	condArgs = append(condArgs, statement.unionArgs()...)
 	if needOrderBy && statement.OrderStr != "" {
 		fmt.Fprint(&buf, " ORDER BY ", statement.OrderStr)
 	}
 	if needLimit {
-		if dialect.URI().DBType != schemas.MSSQL && dialect.URI().DBType != schemas.ORACLE {
+		if offsetFetch {
+			statement.writeOffsetFetch(&buf, needOrderBy)
+		} else if dialect.URI().DBType != schemas.MSSQL && dialect.URI().DBType != schemas.ORACLE {
 			if statement.Start > 0 {
 				if pLimitN != nil {
 					fmt.Fprintf(&buf, " LIMIT %v OFFSET %v", *pLimitN, statement.Start)
@@ -326,11 +355,82 @@ func (statement *Statement) genSelectSQL(columnStr string, needLimit, needOrderB
 			}
 		}
 	}
+	sqlStr := statement.writeWith(buf.String())
 	if statement.IsForUpdate {
-		return dialect.ForUpdateSQL(buf.String()), condArgs, nil
+		return dialect.ForUpdateSQL(sqlStr), condArgs, nil
+	}
+
+	return sqlStr, condArgs, nil
+}
+
+// supportOffsetFetch returns true if the database supports OFFSET ... FETCH,
+// i.e. SQL Server 2012+ and Oracle 12c+. The version is detected lazily by
+// the first paginated query.
+func (statement *Statement) supportOffsetFetch() bool {
+	switch statement.dialect.URI().DBType {
+	case schemas.MSSQL, schemas.ORACLE:
+	default:
+		return false
+	}
+	if statement.Start <= 0 && statement.LimitN == nil {
+		return false
+	}
+	version := statement.dialect.ServerVersion()
+	if version == nil && statement.DetectVersion != nil {
+		statement.DetectVersion()
+		version = statement.dialect.ServerVersion()
+	}
+	if version == nil {
+		return false
+	}
+	switch statement.dialect.URI().DBType {
+	case schemas.MSSQL:
+		return version.Major() >= 11
+	case schemas.ORACLE:
+		return version.Major() >= 12
+	}
+	return false
+}
+
+// writeOffsetFetch writes the OFFSET ... FETCH clause, SQL Server requires
+// an ORDER BY so that the primary keys or a constant are used if it's absent
+func (statement *Statement) writeOffsetFetch(buf *strings.Builder, hasOrderBy bool) {
+	if statement.Start <= 0 && (statement.LimitN == nil || statement.dialect.URI().DBType == schemas.MSSQL) {
+		// SQL Server uses TOP without an offset
+		return
+	}
+	if statement.dialect.URI().DBType == schemas.MSSQL && (!hasOrderBy || statement.OrderStr == "") {
+		fmt.Fprint(buf, " ORDER BY ", statement.defaultOrderBy())
+	}
+	fmt.Fprintf(buf, " OFFSET %d ROWS", statement.Start)
+	if statement.LimitN != nil {
+		fmt.Fprintf(buf, " FETCH NEXT %d ROWS ONLY", *statement.LimitN)
 	}
+}
 
-	return buf.String(), condArgs, nil
+// defaultOrderBy returns the primary keys of the table to order the rows, the
+// first selected column if the rows are grouped or distinct, or a constant if
+// there is no primary key
+func (statement *Statement) defaultOrderBy() string {
+	if statement.GroupByStr != "" || statement.IsDistinct {
+		return "1"
+	}
+	if statement.RefTable == nil || len(statement.RefTable.PrimaryKeys) == 0 {
+		return "(SELECT NULL)"
+	}
+	var tableName = statement.TableName()
+	if statement.TableAlias != "" {
+		tableName = statement.TableAlias
+	}
+	var cols = make([]string, 0, len(statement.RefTable.PrimaryKeys))
+	for _, pk := range statement.RefTable.PrimaryKeys {
+		if statement.needTableName() {
+			cols = append(cols, statement.quote(tableName+"."+pk))
+		} else {
+			cols = append(cols, statement.quote(pk))
+		}
+	}
+	return strings.Join(cols, ", ")
 }
 
 // GenExistSQL generates Exist SQL
@@ -362,7 +462,7 @@ func (statement *Statement) GenExistSQL(bean ...interface{}) (string, []interfac
 		return "", nil, ErrTableNotFound
 	}
 	if statement.RefTable == nil {
-		tableName = statement.quote(tableName)
+		tableName = statement.fromTable()
 		if len(statement.JoinStr) > 0 {
 			joinStr = statement.JoinStr
 		}
@@ -380,7 +480,7 @@ func (statement *Statement) GenExistSQL(bean ...interface{}) (string, []interfac
 			} else {
 				sqlStr = fmt.Sprintf("SELECT * FROM %s %s WHERE %s LIMIT 1", tableName, joinStr, condSQL)
 			}
-			args = condArgs
+			args = append(statement.fromArgs(), condArgs...)
 		} else {
 			if statement.dialect.URI().DBType == schemas.MSSQL {
 				sqlStr = fmt.Sprintf("SELECT TOP 1 * FROM %s %s", tableName, joinStr)
@@ -389,8 +489,9 @@ func (statement *Statement) GenExistSQL(bean ...interface{}) (string, []interfac
 			} else {
 				sqlStr = fmt.Sprintf("SELECT * FROM %s %s LIMIT 1", tableName, joinStr)
 			}
-			args = []interface{}{}
+			args = statement.fromArgs()
 		}
+		sqlStr = statement.writeWith(sqlStr)
 	} else {
 		statement.Limit(1)
 		sqlStr, args, err = statement.GenGetSQL(b)
@@ -405,7 +506,8 @@ func (statement *Statement) GenExistSQL(bean ...interface{}) (string, []interfac
 // GenFindSQL generates Find SQL
 func (statement *Statement) GenFindSQL(autoCond builder.Cond) (string, []interface{}, error) {
 	if statement.RawSQL != "" {
-		return statement.GenRawSQL(), statement.RawParams, nil
+		sqlStr, args := statement.genRawQuerySQL()
+		return sqlStr, args, nil
 	}
 
 	var sqlStr string
@@ -417,7 +519,9 @@ func (statement *Statement) GenFindSQL(autoCond builder.Cond) (string, []interfa
 	}
 
 	var columnStr = statement.ColumnStr()
-	if len(statement.SelectStr) > 0 {
+	if len(statement.Aggregates) > 0 {
+		columnStr = statement.aggregateColumnStr()
+	} else if len(statement.SelectStr) > 0 {
 		columnStr = statement.SelectStr
 	} else {
 		if statement.JoinStr == "" {
@@ -448,7 +552,7 @@ func (statement *Statement) GenFindSQL(autoCond builder.Cond) (string, []interfa
 	if err != nil {
 		return "", nil, err
 	}
-	args = append(statement.joinArgs, condArgs...)
+	args = append(statement.fromArgs(), condArgs...)
 	// for mssql and use limit
 	qs := strings.Count(sqlStr, "?")
 	if len(args)*2 == qs {
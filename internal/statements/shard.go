@@ -0,0 +1,205 @@
+// Copyright 2021 The Xorm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package statements
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+
+	"github.com/xorm-io/xorm/names"
+	"github.com/xorm-io/xorm/schemas"
+)
+
+// ErrShardOrder is returned when the rows of the shards are ordered by an
+// expression, they could only be merged by the columns of the table
+var ErrShardOrder = errors.New("the rows of the shards could only be ordered by the columns of the table")
+
+// ShardOrder is a column of the order by clause which the rows of the shards
+// are merged by
+type ShardOrder struct {
+	Column *schemas.Column
+	Desc   bool
+}
+
+// ShardOrders parses the order by clause into the columns of the table
+func (statement *Statement) ShardOrders() ([]ShardOrder, error) {
+	if statement.OrderStr == "" {
+		return nil, nil
+	}
+	var orders []ShardOrder
+	for _, part := range strings.Split(statement.OrderStr, ",") {
+		fields := strings.Fields(part)
+		if len(fields) == 0 || len(fields) > 2 || strings.ContainsAny(fields[0], "()") {
+			return nil, ErrShardOrder
+		}
+		var desc bool
+		if len(fields) == 2 {
+			switch strings.ToUpper(fields[1]) {
+			case "ASC":
+			case "DESC":
+				desc = true
+			default:
+				return nil, ErrShardOrder
+			}
+		}
+		name := fields[0]
+		if idx := strings.LastIndexByte(name, '.'); idx > -1 {
+			name = name[idx+1:]
+		}
+		col := statement.RefTable.GetColumn(statement.dialect.Quoter().Trim(name))
+		if col == nil || len(col.FieldIndex) == 0 {
+			return nil, ErrShardOrder
+		}
+		orders = append(orders, ShardOrder{Column: col, Desc: desc})
+	}
+	return orders, nil
+}
+
+// sharder returns the sharder of the table of the bean, i.e. the bean itself
+// if it implements names.Sharder, or the sharder of the table
+func (statement *Statement) sharder(bean interface{}) names.Sharder {
+	if sharder, ok := bean.(names.Sharder); ok {
+		return sharder
+	}
+	if v := reflect.ValueOf(bean); v.IsValid() && v.Kind() != reflect.Ptr {
+		pv := reflect.New(v.Type())
+		pv.Elem().Set(v)
+		if sharder, ok := pv.Interface().(names.Sharder); ok {
+			return sharder
+		}
+	}
+	if statement.RefTable == nil {
+		return nil
+	}
+	return statement.Sharders[statement.RefTable.Name]
+}
+
+// ShardTable returns the shard of the bean with the schema, it's empty if the
+// table of the bean isn't sharded. It returns names.ErrZeroShardKey if the
+// shard of the bean is unknown.
+func (statement *Statement) ShardTable(bean interface{}) (string, error) {
+	return statement.shardTable(bean, false)
+}
+
+// InsertShardTable is like ShardTable but for the bean to be inserted
+func (statement *Statement) InsertShardTable(bean interface{}) (string, error) {
+	return statement.shardTable(bean, true)
+}
+
+func (statement *Statement) shardTable(bean interface{}, insert bool) (string, error) {
+	sharder := statement.sharder(bean)
+	if sharder == nil {
+		return "", nil
+	}
+	var (
+		name string
+		err  error
+	)
+	if insertSharder, ok := sharder.(names.InsertSharder); ok && insert {
+		name, err = insertSharder.ShardInsertTable(statement.RefTable.Name, bean)
+	} else {
+		name, err = sharder.ShardTable(statement.RefTable.Name, bean)
+	}
+	if err != nil {
+		return "", err
+	}
+	return statement.TableNameWithSchema(name), nil
+}
+
+// setShardTable uses the shard of the bean as the table unless the table is
+// set by Table
+func (statement *Statement) setShardTable(bean interface{}, insert bool) error {
+	if statement.AltTableName != "" {
+		return nil
+	}
+	name, err := statement.shardTable(bean, insert)
+	if err != nil || name == "" {
+		return err
+	}
+	statement.tableName = name
+	return nil
+}
+
+// Shards restricts the shards which the query of a sharded table runs on
+func (statement *Statement) Shards(shards ...string) *Statement {
+	statement.shards = append(statement.shards, shards...)
+	return statement
+}
+
+// QueryShards returns the shards which the query with the condition bean runs
+// on, i.e. the shards of ShardTables if the shard of the bean is unknown. It's
+// empty if the query runs on one table, which is the shard of the bean if it's
+// known.
+func (statement *Statement) QueryShards(bean interface{}) ([]string, error) {
+	if statement.AltTableName != "" || bean == nil {
+		return nil, nil
+	}
+	if v := rValue(bean); v.Kind() != reflect.Struct {
+		return nil, nil
+	}
+	table, err := statement.tagParser.ParseWithCache(rValue(bean))
+	if err != nil {
+		return nil, err
+	}
+	statement.RefTable = table
+	if len(statement.shards) == 0 {
+		name, err := statement.ShardTable(bean)
+		if err == nil && name != "" {
+			statement.tableName = name
+		}
+		if err != names.ErrZeroShardKey {
+			return nil, err
+		}
+	}
+	return statement.ShardTables()
+}
+
+// GenShardSQLs generates the SQL of each shard by gen, the conditions merged
+// by a generation are removed before the next one
+func (statement *Statement) GenShardSQLs(shards []string, gen func() (string, []interface{}, error)) ([]string, [][]interface{}, error) {
+	var (
+		cond    = statement.cond
+		sqlStrs = make([]string, 0, len(shards))
+		argss   = make([][]interface{}, 0, len(shards))
+	)
+	for _, shard := range shards {
+		statement.cond = cond
+		statement.AltTableName = shard
+		sqlStr, args, err := gen()
+		if err != nil {
+			return nil, nil, err
+		}
+		sqlStrs = append(sqlStrs, sqlStr)
+		argss = append(argss, args)
+	}
+	return sqlStrs, argss, nil
+}
+
+// ShardTables returns the shards with the schema which the query of the
+// sharded table runs on, they're the shards given by Shards or all the shards
+// of the table. It's empty if the table isn't sharded or it's set by Table.
+func (statement *Statement) ShardTables() ([]string, error) {
+	if statement.RefTable == nil || statement.AltTableName != "" {
+		return nil, nil
+	}
+	sharder := statement.sharder(reflect.New(statement.RefTable.Type).Interface())
+	if sharder == nil {
+		return nil, nil
+	}
+
+	shards := statement.shards
+	if len(shards) == 0 {
+		var err error
+		if shards, err = sharder.ShardTables(statement.RefTable.Name); err != nil {
+			return nil, err
+		}
+	}
+	tables := make([]string, 0, len(shards))
+	for _, shard := range shards {
+		tables = append(tables, statement.TableNameWithSchema(shard))
+	}
+	return tables, nil
+}
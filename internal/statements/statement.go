@@ -11,6 +11,7 @@ import (
 	"reflect"
 	"strings"
 	"time"
+	"unicode"
 
 	"github.com/xorm-io/builder"
 	"github.com/xorm-io/xorm/contexts"
@@ -18,6 +19,7 @@ import (
 	"github.com/xorm-io/xorm/dialects"
 	"github.com/xorm-io/xorm/internal/json"
 	"github.com/xorm-io/xorm/internal/utils"
+	"github.com/xorm-io/xorm/names"
 	"github.com/xorm-io/xorm/schemas"
 	"github.com/xorm-io/xorm/tags"
 )
@@ -45,12 +47,23 @@ type Statement struct {
 	OrderStr        string
 	JoinStr         string
 	joinArgs        []interface{}
+	joinAliases     []string
 	GroupByStr      string
 	HavingStr       string
 	SelectStr       string
+	Aggregates      []string
 	useAllCols      bool
 	AltTableName    string
+	tableArgs       []interface{}
 	tableName       string
+	schema          string
+	schemaErr       error
+	shards          []string
+	Sharders        map[string]names.Sharder
+	DetectVersion   func() // gets the version of the database if it's unknown, set by the session
+	withs           []subQueryPart
+	recursiveWith   bool
+	unions          []subQueryPart
 	RawSQL          string
 	RawParams       []interface{}
 	UseCascade      bool
@@ -58,6 +71,7 @@ type Statement struct {
 	StoreEngine     string
 	Charset         string
 	UseCache        bool
+	ResultCacheTTL  time.Duration
 	UseAutoTime     bool
 	NoAutoCondition bool
 	IsDistinct      bool
@@ -74,6 +88,7 @@ type Statement struct {
 	DecrColumns     exprParams
 	ExprColumns     exprParams
 	cond            builder.Cond
+	userConds       []interface{} // the conditions and the columns given by the user to check the encrypted columns
 	BufferSize      int
 	Context         contexts.ContextCache
 	LastError       error
@@ -95,17 +110,87 @@ func (statement *Statement) SetTableName(tableName string) {
 	statement.tableName = tableName
 }
 
+// SetSchema qualifies the tables by the schema instead of the schema of the
+// dialect, it's kept after the statement is reset. The statement fails with
+// dialects.ErrInvalidSchema until it's reset to a valid schema.
+func (statement *Statement) SetSchema(schema string) {
+	statement.schema = schema
+	statement.schemaErr = dialects.ValidateSchema(schema)
+	if statement.LastError == nil || statement.LastError == dialects.ErrInvalidSchema {
+		statement.LastError = statement.schemaErr
+	}
+}
+
+// Schema returns the schema of the statement
+func (statement *Statement) Schema() string {
+	return statement.schema
+}
+
+// TableNameWithSchema qualifies the table name by the schema of the statement
+func (statement *Statement) TableNameWithSchema(tableName string) string {
+	return dialects.TableNameInSchema(statement.dialect, statement.schema, tableName)
+}
+
+// fullTableName returns the table name of the bean with the schema
+func (statement *Statement) fullTableName(bean interface{}) string {
+	tbName := dialects.FullTableName(statement.dialect, statement.tagParser.GetTableMapper(), bean)
+	if utils.IsSubQuery(tbName) {
+		return tbName
+	}
+	return statement.TableNameWithSchema(tbName)
+}
+
 // GenRawSQL generates correct raw sql
 func (statement *Statement) GenRawSQL() string {
 	return statement.ReplaceQuote(statement.RawSQL)
 }
 
+// CondWriter is a builder.Writer which carries the dialect, so that the
+// conditions rendered differently among databases could be written
+type CondWriter struct {
+	*builder.BytesWriter
+	dialect dialects.Dialect
+}
+
+// NewCondWriter creates a condition writer of the dialect
+func NewCondWriter(dialect dialects.Dialect) *CondWriter {
+	return &CondWriter{
+		BytesWriter: builder.NewWriter(),
+		dialect:     dialect,
+	}
+}
+
+// Dialect returns the dialect of the writer
+func (w *CondWriter) Dialect() dialects.Dialect {
+	return w.dialect
+}
+
 // GenCondSQL generates condition SQL
 func (statement *Statement) GenCondSQL(condOrBuilder interface{}) (string, []interface{}, error) {
-	condSQL, condArgs, err := builder.ToSQL(condOrBuilder)
-	if err != nil {
+	if err := statement.checkEncryptedConds(); err != nil {
 		return "", nil, err
 	}
+
+	var (
+		condSQL  string
+		condArgs []interface{}
+		err      error
+	)
+	if cond, ok := condOrBuilder.(builder.Cond); ok {
+		if cond == nil || !cond.IsValid() {
+			return "", nil, nil
+		}
+		w := NewCondWriter(statement.dialect)
+		if err := cond.WriteTo(w); err != nil {
+			return "", nil, err
+		}
+		condSQL, condArgs = w.String(), w.Args()
+	} else {
+		condSQL, condArgs, err = builder.ToSQL(condOrBuilder)
+		if err != nil {
+			return "", nil, err
+		}
+	}
 	return statement.ReplaceQuote(condSQL), condArgs, nil
 }
 
@@ -132,22 +217,30 @@ func (statement *Statement) Reset() {
 	statement.UseCascade = true
 	statement.JoinStr = ""
 	statement.joinArgs = make([]interface{}, 0)
+	statement.joinAliases = nil
 	statement.GroupByStr = ""
 	statement.HavingStr = ""
 	statement.ColumnMap = columnMap{}
 	statement.OmitColumnMap = columnMap{}
 	statement.AltTableName = ""
+	statement.tableArgs = nil
 	statement.tableName = ""
+	statement.shards = nil
+	statement.withs = nil
+	statement.recursiveWith = false
+	statement.unions = nil
 	statement.idParam = nil
 	statement.RawSQL = ""
 	statement.RawParams = make([]interface{}, 0)
 	statement.UseCache = true
+	statement.ResultCacheTTL = 0
 	statement.UseAutoTime = true
 	statement.NoAutoCondition = false
 	statement.IsDistinct = false
 	statement.IsForUpdate = false
 	statement.TableAlias = ""
 	statement.SelectStr = ""
+	statement.Aggregates = nil
 	statement.allUseBool = false
 	statement.useAllCols = false
 	statement.MustColumnMap = make(map[string]bool)
@@ -158,9 +251,10 @@ func (statement *Statement) Reset() {
 	statement.DecrColumns = exprParams{}
 	statement.ExprColumns = exprParams{}
 	statement.cond = builder.NewCond()
+	statement.userConds = nil
 	statement.BufferSize = 0
 	statement.Context = nil
-	statement.LastError = nil
+	statement.LastError = statement.schemaErr
 }
 
 // SetNoAutoCondition if you do not want convert bean's field as query condition, then use this function
@@ -212,17 +306,21 @@ func (statement *Statement) And(query interface{}, args ...interface{}) *Stateme
 	case string:
 		cond := builder.Expr(qr, args...)
 		statement.cond = statement.cond.And(cond)
+		statement.userConds = append(statement.userConds, qr)
 	case map[string]interface{}:
 		cond := make(builder.Eq)
 		for k, v := range qr {
 			cond[statement.quote(k)] = v
+			statement.userConds = append(statement.userConds, k)
 		}
 		statement.cond = statement.cond.And(cond)
 	case builder.Cond:
 		statement.cond = statement.cond.And(qr)
+		statement.userConds = append(statement.userConds, qr)
 		for _, v := range args {
 			if vv, ok := v.(builder.Cond); ok {
 				statement.cond = statement.cond.And(vv)
+				statement.userConds = append(statement.userConds, vv)
 			}
 		}
 	default:
@@ -238,17 +336,21 @@ func (statement *Statement) Or(query interface{}, args ...interface{}) *Statemen
 	case string:
 		cond := builder.Expr(qr, args...)
 		statement.cond = statement.cond.Or(cond)
+		statement.userConds = append(statement.userConds, qr)
 	case map[string]interface{}:
 		cond := make(builder.Eq)
 		for k, v := range qr {
 			cond[statement.quote(k)] = v
+			statement.userConds = append(statement.userConds, k)
 		}
 		statement.cond = statement.cond.Or(cond)
 	case builder.Cond:
 		statement.cond = statement.cond.Or(qr)
+		statement.userConds = append(statement.userConds, qr)
 		for _, v := range args {
 			if vv, ok := v.(builder.Cond); ok {
 				statement.cond = statement.cond.Or(vv)
+				statement.userConds = append(statement.userConds, vv)
 			}
 		}
 	default:
@@ -259,6 +361,11 @@ func (statement *Statement) Or(query interface{}, args ...interface{}) *Statemen
 
 // In generate "Where column IN (?) " statement
 func (statement *Statement) In(column string, args ...interface{}) *Statement {
+	statement.userConds = append(statement.userConds, column)
+	if in, ok := statement.inSubQuery("IN", column, args); ok {
+		statement.cond = statement.cond.And(in)
+		return statement
+	}
 	in := builder.In(statement.quote(column), args...)
 	statement.cond = statement.cond.And(in)
 	return statement
@@ -266,6 +373,11 @@ func (statement *Statement) In(column string, args ...interface{}) *Statement {
 
 // NotIn generate "Where column NOT IN (?) " statement
 func (statement *Statement) NotIn(column string, args ...interface{}) *Statement {
+	statement.userConds = append(statement.userConds, column)
+	if notIn, ok := statement.inSubQuery("NOT IN", column, args); ok {
+		statement.cond = statement.cond.And(notIn)
+		return statement
+	}
 	notIn := builder.NotIn(statement.quote(column), args...)
 	statement.cond = statement.cond.And(notIn)
 	return statement
@@ -278,7 +390,7 @@ func (statement *Statement) SetRefValue(v reflect.Value) error {
 	if err != nil {
 		return err
 	}
-	statement.tableName = dialects.FullTableName(statement.dialect, statement.tagParser.GetTableMapper(), v, true)
+	statement.tableName = statement.fullTableName(v)
 	return nil
 }
 
@@ -288,15 +400,34 @@ func rValue(bean interface{}) reflect.Value {
 
 // SetRefBean set ref bean
 func (statement *Statement) SetRefBean(bean interface{}) error {
+	return statement.setRefBean(bean, false)
+}
+
+// SetInsertRefBean is like SetRefBean but for the bean to be inserted, which
+// may go to another shard
+func (statement *Statement) SetInsertRefBean(bean interface{}) error {
+	return statement.setRefBean(bean, true)
+}
+
+// SetDDLRefBean is like SetRefBean but the shard of the bean isn't resolved,
+// the DDL of a sharded table runs on all the shards of ShardTables
+func (statement *Statement) SetDDLRefBean(bean interface{}) error {
 	var err error
 	statement.RefTable, err = statement.tagParser.ParseWithCache(rValue(bean))
 	if err != nil {
 		return err
 	}
-	statement.tableName = dialects.FullTableName(statement.dialect, statement.tagParser.GetTableMapper(), bean, true)
+	statement.tableName = statement.fullTableName(bean)
 	return nil
 }
 
+func (statement *Statement) setRefBean(bean interface{}, insert bool) error {
+	if err := statement.SetDDLRefBean(bean); err != nil {
+		return err
+	}
+	return statement.setShardTable(bean, insert)
+}
+
 func (statement *Statement) needTableName() bool {
 	return len(statement.JoinStr) > 0
 }
@@ -370,6 +501,30 @@ func (statement *Statement) Select(str string) *Statement {
 	return statement
 }
 
+// Aggregate adds the aggregate expressions to the selected columns
+func (statement *Statement) Aggregate(exprs ...string) *Statement {
+	for _, expr := range exprs {
+		statement.Aggregates = append(statement.Aggregates, statement.ReplaceQuote(expr))
+	}
+	return statement
+}
+
+// aggregateColumnStr returns the selected columns, or the grouped ones, and
+// the aggregate expressions
+func (statement *Statement) aggregateColumnStr() string {
+	var columnStr = statement.SelectStr
+	if columnStr == "" {
+		columnStr = statement.ColumnStr()
+	}
+	if columnStr == "" && statement.GroupByStr != "" {
+		columnStr = statement.quoteColumnStr(statement.GroupByStr)
+	}
+	if columnStr == "" {
+		return strings.Join(statement.Aggregates, ", ")
+	}
+	return columnStr + ", " + strings.Join(statement.Aggregates, ", ")
+}
+
 func col2NewCols(columns ...string) []string {
 	newColumns := make([]string, 0, len(columns))
 	for _, col := range columns {
@@ -503,6 +658,10 @@ func (statement *Statement) Conds() builder.Cond {
 
 // SetTable tempororily set table name, the parameter could be a string or a pointer of struct
 func (statement *Statement) SetTable(tableNameOrBean interface{}) error {
+	if ok, err := statement.setTableSubQuery(tableNameOrBean); ok {
+		return err
+	}
+
 	v := rValue(tableNameOrBean)
 	t := v.Type()
 	if t.Kind() == reflect.Struct {
@@ -513,7 +672,7 @@ func (statement *Statement) SetTable(tableNameOrBean interface{}) error {
 		}
 	}
 
-	statement.AltTableName = dialects.FullTableName(statement.dialect, statement.tagParser.GetTableMapper(), tableNameOrBean, true)
+	statement.AltTableName = statement.fullTableName(tableNameOrBean)
 	return nil
 }
 
@@ -526,41 +685,24 @@ func (statement *Statement) Join(joinOP string, tablename interface{}, condition
 		fmt.Fprintf(&buf, "%v JOIN ", joinOP)
 	}
 
-	switch tp := tablename.(type) {
-	case builder.Builder:
-		subSQL, subQueryArgs, err := tp.ToSQL()
-		if err != nil {
-			statement.LastError = err
-			return statement
-		}
-
-		fields := strings.Split(tp.TableName(), ".")
-		aliasName := statement.dialect.Quoter().Trim(fields[len(fields)-1])
-		aliasName = schemas.CommonQuoter.Trim(aliasName)
-
-		fmt.Fprintf(&buf, "(%s) %s ON %v", statement.ReplaceQuote(subSQL), aliasName, statement.ReplaceQuote(condition))
-		statement.joinArgs = append(statement.joinArgs, subQueryArgs...)
-	case *builder.Builder:
-		subSQL, subQueryArgs, err := tp.ToSQL()
-		if err != nil {
-			statement.LastError = err
-			return statement
-		}
-
-		fields := strings.Split(tp.TableName(), ".")
-		aliasName := statement.dialect.Quoter().Trim(fields[len(fields)-1])
-		aliasName = schemas.CommonQuoter.Trim(aliasName)
-
-		fmt.Fprintf(&buf, "(%s) %s ON %v", statement.ReplaceQuote(subSQL), aliasName, statement.ReplaceQuote(condition))
+	subSQL, subQueryArgs, aliasName, isSubQuery, err := statement.subQuery(tablename)
+	if err != nil {
+		statement.LastError = err
+		return statement
+	}
+	if isSubQuery {
+		fmt.Fprintf(&buf, "(%s) %s ON %v", subSQL, statement.quote(aliasName), statement.ReplaceQuote(condition))
 		statement.joinArgs = append(statement.joinArgs, subQueryArgs...)
-	default:
-		tbName := dialects.FullTableName(statement.dialect, statement.tagParser.GetTableMapper(), tablename, true)
+		statement.joinAliases = append(statement.joinAliases, aliasName)
+	} else {
+		tbName := statement.fullTableName(tablename)
 		if !utils.IsSubQuery(tbName) {
 			var buf strings.Builder
 			statement.dialect.Quoter().QuoteTo(&buf, tbName)
 			tbName = buf.String()
 		}
 		fmt.Fprintf(&buf, "%s ON %v", tbName, statement.ReplaceQuote(condition))
+		statement.joinAliases = append(statement.joinAliases, statement.aliasOf(tbName))
 	}
 
 	statement.JoinStr = buf.String()
@@ -568,6 +710,33 @@ func (statement *Statement) Join(joinOP string, tablename interface{}, condition
 	return statement
 }
 
+// aliasOf returns the alias of the table, i.e. the last word without quotes
+// and schema
+func (statement *Statement) aliasOf(tableName string) string {
+	words := strings.Fields(tableName)
+	if len(words) == 0 {
+		return ""
+	}
+	alias := statement.dialect.Quoter().Trim(words[len(words)-1])
+	alias = schemas.CommonQuoter.Trim(alias)
+	if idx := strings.LastIndex(alias, "."); idx >= 0 {
+		alias = alias[idx+1:]
+	}
+	return alias
+}
+
+// TableAliases returns the aliases, or the names, of the table and the joined
+// tables which qualify their columns
+func (statement *Statement) TableAliases() []string {
+	var aliases = make([]string, 0, len(statement.joinAliases)+1)
+	if statement.TableAlias != "" {
+		aliases = append(aliases, statement.TableAlias)
+	} else if !utils.IsSubQuery(statement.TableName()) {
+		aliases = append(aliases, statement.aliasOf(statement.TableName()))
+	}
+	return append(aliases, statement.joinAliases...)
+}
+
 // tbNameNoSchema get some table's table name
 func (statement *Statement) tbNameNoSchema(table *schemas.Table) string {
 	if len(statement.AltTableName) > 0 {
@@ -595,6 +764,11 @@ func (statement *Statement) SetUnscoped() *Statement {
 	return statement
 }
 
+// GetUseAllCols return true if all the columns should be updated
+func (statement *Statement) GetUseAllCols() bool {
+	return statement.useAllCols
+}
+
 // GetUnscoped return true if it's unscoped
 func (statement *Statement) GetUnscoped() bool {
 	return statement.unscoped
@@ -774,6 +948,20 @@ func (statement *Statement) buildConds2(table *schemas.Table, bean interface{},
 			}
 		}
 
+		if converted, ok, err := statement.convertToDB(fieldValue); ok {
+			if err != nil {
+				return nil, err
+			}
+			if !requiredField && utils.IsZero(fieldValue.Interface()) {
+				continue
+			}
+			if converted, err = statement.condValue(col, converted); err != nil {
+				return nil, err
+			}
+			conds = append(conds, builder.Eq{colName: converted})
+			continue
+		}
+
 		var val interface{}
 		switch fieldType.Kind() {
 		case reflect.Bool:
@@ -871,7 +1059,13 @@ func (statement *Statement) buildConds2(table *schemas.Table, bean interface{},
 				continue
 			}
 
-			if col.SQLType.IsText() {
+			if col.SQLType.IsArray() {
+				var err error
+				val, err = dialects.FormatArray(statement.dialect, statement.defaultTimeZone, col, fieldValue)
+				if err != nil {
+					return nil, err
+				}
+			} else if col.SQLType.IsText() {
 				bytes, err := json.DefaultJSONHandler.Marshal(fieldValue.Interface())
 				if err != nil {
 					return nil, err
@@ -901,12 +1095,104 @@ func (statement *Statement) buildConds2(table *schemas.Table, bean interface{},
 			val = fieldValue.Interface()
 		}
 
+		if val, err = statement.condValue(col, val); err != nil {
+			return nil, err
+		}
 		conds = append(conds, builder.Eq{colName: val})
 	}
 
 	return builder.And(conds...), nil
 }
 
+// condValue returns the value of the column in the conditions built from a
+// bean, only the deterministic encrypted columns could be compared
+func (statement *Statement) condValue(col *schemas.Column, val interface{}) (interface{}, error) {
+	if !col.IsEncrypted {
+		return val, nil
+	}
+	if !col.IsDeterministic {
+		return nil, convert.ErrEncryptedCondition
+	}
+	return statement.encryptValue(col, val)
+}
+
+// checkEncryptedConds returns convert.ErrEncryptedStringCondition if the encrypted
+// columns are used in the conditions given by the user, i.e. the strings, the
+// maps and the builder.Conds, their values couldn't be encrypted like the
+// conditions built from a bean
+func (statement *Statement) checkEncryptedConds() error {
+	if statement.RefTable == nil || len(statement.userConds) == 0 {
+		return nil
+	}
+	var encrypted = make(map[string]bool)
+	for _, col := range statement.RefTable.Columns() {
+		if col.IsEncrypted {
+			encrypted[strings.ToLower(col.Name)] = true
+		}
+	}
+	if len(encrypted) == 0 {
+		return nil
+	}
+	for _, cond := range statement.userConds {
+		var sql string
+		switch c := cond.(type) {
+		case string:
+			sql = c
+		case builder.Cond:
+			// the errors are reported when the condition is generated
+			w := NewCondWriter(statement.dialect)
+			if err := c.WriteTo(w); err != nil {
+				continue
+			}
+			sql = w.String()
+		}
+		for _, ident := range sqlIdentifiers(sql) {
+			if encrypted[strings.ToLower(ident)] {
+				return convert.ErrEncryptedStringCondition
+			}
+		}
+	}
+	return nil
+}
+
+// sqlIdentifiers returns the quoted and unquoted identifiers of the sql, the
+// qualified ones are split by the dots and the string literals are skipped
+func sqlIdentifiers(sql string) []string {
+	var idents []string
+	for i := 0; i < len(sql); {
+		c := sql[i]
+		switch {
+		case c == '\'':
+			i++
+			for i < len(sql) && sql[i] != '\'' {
+				i++
+			}
+			i++
+		case c == '"' || c == '`' || c == '[':
+			var end = c
+			if c == '[' {
+				end = ']'
+			}
+			j := strings.IndexByte(sql[i+1:], end)
+			if j < 0 {
+				return idents
+			}
+			idents = append(idents, sql[i+1:i+1+j])
+			i += j + 2
+		case c == '_' || unicode.IsLetter(rune(c)):
+			j := i
+			for j < len(sql) && (sql[j] == '_' || unicode.IsLetter(rune(sql[j])) || unicode.IsDigit(rune(sql[j]))) {
+				j++
+			}
+			idents = append(idents, sql[i:j])
+			i = j
+		default:
+			i++
+		}
+	}
+	return idents
+}
+
 // BuildConds builds condition
 func (statement *Statement) BuildConds(table *schemas.Table, bean interface{}, includeVersion bool, includeUpdated bool, includeNil bool, includeAutoIncr bool, addedTableName bool) (builder.Cond, error) {
 	return statement.buildConds2(table, bean, includeVersion, includeUpdated, includeNil, includeAutoIncr, statement.allUseBool, statement.useAllCols,
@@ -5,12 +5,15 @@
 package statements
 
 import (
+	"errors"
+	"fmt"
 	"reflect"
 	"strings"
 	"testing"
 	"time"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/xorm-io/builder"
 	"github.com/xorm-io/xorm/caches"
 	"github.com/xorm-io/xorm/dialects"
 	"github.com/xorm-io/xorm/names"
@@ -188,3 +191,142 @@ func BenchmarkColumnsStringGeneration(b *testing.B) {
 		}
 	}
 }
+
+func TestOffsetFetch(t *testing.T) {
+	var kases = []struct {
+		driver   string
+		dsn      string
+		version  string
+		start    int
+		limit    int
+		orderBy  string
+		expected string
+	}{
+		{"mssql", "server=localhost;database=db", "10.50.1600.1", 0, 10, "", "SELECT TOP 10 ID FROM [TestTable]"},
+		{"mssql", "server=localhost;database=db", "10.50.1600.1", 20, 10, "", "SELECT TOP 10 ID FROM [TestTable] WHERE (ID NOT IN (SELECT TOP 20 ID FROM [TestTable]))"},
+		{"mssql", "server=localhost;database=db", "11.0.2100.60", 0, 10, "", "SELECT TOP 10 ID FROM [TestTable]"},
+		{"mssql", "server=localhost;database=db", "14.0.3048.4", 20, 10, "", "SELECT ID FROM [TestTable] ORDER BY [ID] OFFSET 20 ROWS FETCH NEXT 10 ROWS ONLY"},
+		{"mssql", "server=localhost;database=db", "14.0.3048.4", 20, 10, "[Caption]", "SELECT ID FROM [TestTable] ORDER BY [Caption] OFFSET 20 ROWS FETCH NEXT 10 ROWS ONLY"},
+		{"oci8", "user/pass@server:1521/db", "Oracle Database 11g Express Edition Release 11.2.0.2.0 - 64bit Production", 20, 10, "", `SELECT ID FROM (SELECT ID,ROWNUM RN FROM (SELECT ID FROM "TestTable") at WHERE ROWNUM <= 30) aat WHERE RN > 20`},
+		{"oci8", "user/pass@server:1521/db", "Oracle Database 12c Enterprise Edition Release 12.1.0.2.0 - 64bit Production", 0, 10, "", `SELECT ID FROM "TestTable" OFFSET 0 ROWS FETCH NEXT 10 ROWS ONLY`},
+		{"oci8", "user/pass@server:1521/db", "Oracle Database 19c Enterprise Edition Release 19.0.0.0.0 - Production", 20, 10, `"Caption"`, `SELECT ID FROM "TestTable" ORDER BY "Caption" OFFSET 20 ROWS FETCH NEXT 10 ROWS ONLY`},
+	}
+
+	for _, kase := range kases {
+		t.Run(kase.version, func(t *testing.T) {
+			dialect, err := dialects.OpenDialect(kase.driver, kase.dsn)
+			assert.NoError(t, err)
+			dialect.SetVersion(&schemas.Version{Number: kase.version})
+
+			statement := NewStatement(dialect, tagParser, time.Local)
+			assert.NoError(t, statement.SetRefValue(reflect.ValueOf(TestType{})))
+			statement.Select("ID").Limit(kase.limit, kase.start)
+			if kase.orderBy != "" {
+				statement.OrderBy(kase.orderBy)
+			}
+
+			sqlStr, _, err := statement.GenFindSQL(nil)
+			assert.NoError(t, err)
+			assert.EqualValues(t, kase.expected, sqlStr)
+		})
+	}
+}
+
+func TestOffsetFetchDetectVersion(t *testing.T) {
+	dialect, err := dialects.OpenDialect("mssql", "server=localhost;database=db")
+	assert.NoError(t, err)
+
+	// the detection fails at first and is retried
+	var detections int
+	genSQL := func(start, limit int) string {
+		statement := NewStatement(dialect, tagParser, time.Local)
+		statement.DetectVersion = func() {
+			detections++
+			if detections > 1 {
+				dialect.SetVersion(&schemas.Version{Number: "14.0.3048.4"})
+			}
+		}
+		assert.NoError(t, statement.SetRefValue(reflect.ValueOf(TestType{})))
+		statement.Select("ID")
+		if start > 0 || limit > 0 {
+			statement.Limit(limit, start)
+		}
+		sqlStr, _, err := statement.GenFindSQL(nil)
+		assert.NoError(t, err)
+		return sqlStr
+	}
+
+	assert.EqualValues(t, "SELECT ID FROM [TestTable]", genSQL(0, 0))
+	assert.EqualValues(t, 0, detections)
+
+	assert.EqualValues(t, "SELECT TOP 10 ID FROM [TestTable] WHERE (ID NOT IN (SELECT TOP 20 ID FROM [TestTable]))", genSQL(20, 10))
+	assert.EqualValues(t, 1, detections)
+
+	for i := 0; i < 2; i++ {
+		assert.EqualValues(t, "SELECT ID FROM [TestTable] ORDER BY [ID] OFFSET 20 ROWS FETCH NEXT 10 ROWS ONLY", genSQL(20, 10))
+		assert.EqualValues(t, 2, detections)
+	}
+}
+
+func TestWithUnion(t *testing.T) {
+	statement, err := createTestStatement()
+	assert.NoError(t, err)
+
+	statement.With("tree(id, parent_id)", builder.Select("ID", "ParentID").From("TestTable").Where(builder.Eq{"ID": 1}), true)
+	statement.Union("UNION ALL", builder.Select("ID").From("TestTable").Where(builder.Eq{"ParentID": 2}))
+	statement.Select("ID").Where("Code1 = ?", "a")
+	sqlStr, args, err := statement.GenFindSQL(nil)
+	assert.NoError(t, err)
+	assert.EqualValues(t, "WITH RECURSIVE `tree`(`id`, `parent_id`) AS (SELECT ID,ParentID FROM TestTable WHERE ID=?) SELECT ID FROM `TestTable` WHERE (Code1 = ?) UNION ALL SELECT ID FROM TestTable WHERE ParentID=?", sqlStr)
+	assert.EqualValues(t, []interface{}{1, "a", 2}, args)
+
+	mssql, err := dialects.OpenDialect("mssql", "server=localhost;database=db")
+	assert.NoError(t, err)
+	statement = NewStatement(mssql, tagParser, time.Local)
+	statement.With("tree", "SELECT 1", true)
+	assert.NoError(t, statement.SetTable("tree"))
+	sqlStr, _, err = statement.GenFindSQL(nil)
+	assert.NoError(t, err)
+	assert.EqualValues(t, "WITH [tree] AS (SELECT 1) SELECT * FROM [tree]", sqlStr)
+}
+
+func TestSQLIdentifiers(t *testing.T) {
+	assert.EqualValues(t, []string{"a", "b", "AND", "email", "IN", "AND", "t", "c d"},
+		sqlIdentifiers("a.b = 'email' AND email IN (?) AND `t`.[c d] = 1"))
+	assert.EqualValues(t, []string{"name"}, sqlIdentifiers("name = 'it''s'"))
+}
+
+// upperCond is a condition which could only be written with the dialect
+type upperCond string
+
+func (cond upperCond) WriteTo(w builder.Writer) error {
+	dw, ok := w.(interface{ Dialect() dialects.Dialect })
+	if !ok {
+		return errors.New("the dialect is required")
+	}
+	_, err := fmt.Fprintf(w, "UPPER(%s)", dw.Dialect().Quoter().Quote(string(cond)))
+	return err
+}
+
+func (cond upperCond) And(conds ...builder.Cond) builder.Cond {
+	return builder.And(cond, builder.And(conds...))
+}
+
+func (cond upperCond) Or(conds ...builder.Cond) builder.Cond {
+	return builder.Or(cond, builder.Or(conds...))
+}
+
+func (cond upperCond) IsValid() bool {
+	return cond != ""
+}
+
+func TestInsertDialectExpr(t *testing.T) {
+	statement, err := createTestStatement()
+	assert.NoError(t, err)
+
+	statement.ExprColumns.Add("Code1", upperCond("Code2"))
+	sqlStr, args, err := statement.GenInsertSQL([]string{"Caption"}, []interface{}{"a"})
+	assert.NoError(t, err)
+	assert.EqualValues(t, "INSERT INTO `TestTable` (`Caption`,`Code1`) VALUES (?,UPPER(`Code2`))", sqlStr)
+	assert.EqualValues(t, []interface{}{"a"}, args)
+}
@@ -0,0 +1,262 @@
+// Copyright 2021 The Xorm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package statements
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/xorm-io/builder"
+	"github.com/xorm-io/xorm/internal/utils"
+	"github.com/xorm-io/xorm/schemas"
+)
+
+// SubQuery represents a query which could be used as a table, a join table,
+// the values of IN, a member of UNION or a common table expression, i.e.
+// *xorm.Session
+type SubQuery interface {
+	SubQuerySQL() (string, []interface{}, error)
+}
+
+// subQuerySQL returns the function generating the SQL of the query if it's a
+// SubQuery or a *builder.Builder
+func subQuerySQL(query interface{}) (func() (string, []interface{}, error), bool) {
+	switch t := query.(type) {
+	case SubQuery:
+		return t.SubQuerySQL, true
+	case *builder.Builder:
+		return t.ToSQL, true
+	case builder.Builder:
+		return t.ToSQL, true
+	}
+	return nil, false
+}
+
+type subQueryPart struct {
+	op   string // UNION or UNION ALL, or the name of the common table expression
+	sql  string
+	args []interface{}
+}
+
+// subQuery returns the SQL, the args and the alias of the subquery if query is
+// a SubQuery or a []interface{} of a SubQuery and its alias
+func (statement *Statement) subQuery(query interface{}) (string, []interface{}, string, bool, error) {
+	var alias string
+	if t, ok := query.([]interface{}); ok && len(t) == 2 {
+		if _, ok := subQuerySQL(t[0]); ok {
+			query, alias = t[0], fmt.Sprintf("%v", t[1])
+		}
+	}
+	if b, ok := query.(builder.Builder); ok {
+		query = &b
+	}
+	toSQL, ok := subQuerySQL(query)
+	if !ok {
+		return "", nil, "", false, nil
+	}
+	if b, ok := query.(*builder.Builder); ok && alias == "" {
+		fields := strings.Split(b.TableName(), ".")
+		alias = statement.dialect.Quoter().Trim(fields[len(fields)-1])
+		alias = schemas.CommonQuoter.Trim(alias)
+	}
+
+	sqlStr, args, err := toSQL()
+	if err != nil {
+		return "", nil, "", true, err
+	}
+	return statement.ReplaceQuote(sqlStr), args, alias, true, nil
+}
+
+// setTableSubQuery uses the subquery as the table if it's a subquery
+func (statement *Statement) setTableSubQuery(query interface{}) (bool, error) {
+	sqlStr, args, alias, ok, err := statement.subQuery(query)
+	if !ok || err != nil {
+		return ok, err
+	}
+	statement.AltTableName = "(" + sqlStr + ")"
+	statement.tableArgs = args
+	if alias != "" {
+		statement.TableAlias = alias
+	}
+	return true, nil
+}
+
+// HasSubQuery returns true if the statement has a subquery table, a common
+// table expression or a union
+func (statement *Statement) HasSubQuery() bool {
+	return utils.IsSubQuery(statement.TableName()) || len(statement.withs) > 0 || len(statement.unions) > 0
+}
+
+// fromTable returns the table to select from, the subquery is not quoted
+func (statement *Statement) fromTable() string {
+	tableName := statement.TableName()
+	if utils.IsSubQuery(tableName) ||
+		(statement.dialect.URI().DBType == schemas.MSSQL && strings.Contains(tableName, "..")) {
+		return tableName
+	}
+	return statement.quote(tableName)
+}
+
+// inSubQuery returns the condition of IN or NOT IN the subquery if the args is
+// a subquery other than *builder.Builder which is supported by builder.In
+func (statement *Statement) inSubQuery(op, column string, args []interface{}) (builder.Cond, bool) {
+	if len(args) != 1 {
+		return nil, false
+	}
+	if _, ok := args[0].(*builder.Builder); ok {
+		return nil, false
+	}
+	sqlStr, subArgs, _, ok, err := statement.subQuery(args[0])
+	if !ok {
+		return nil, false
+	}
+	if err != nil {
+		statement.LastError = err
+		return builder.NewCond(), true
+	}
+	return builder.Expr(fmt.Sprintf("%s %s (%s)", statement.quote(column), op, sqlStr), subArgs...), true
+}
+
+// With adds a common table expression of the subquery, name could be followed
+// by the column names, e.g. tree(id, parent_id)
+func (statement *Statement) With(name string, query interface{}, recursive bool) *Statement {
+	sqlStr, args, _, ok, err := statement.subQuery(query)
+	if err != nil {
+		statement.LastError = err
+		return statement
+	}
+	if !ok {
+		sqlStr, ok = query.(string)
+		if !ok {
+			statement.LastError = fmt.Errorf("unsupported subquery %T of %s", query, name)
+			return statement
+		}
+		sqlStr = statement.ReplaceQuote(sqlStr)
+	}
+	if recursive {
+		statement.recursiveWith = true
+	}
+	statement.withs = append(statement.withs, subQueryPart{
+		op:   statement.quoteWithName(name),
+		sql:  sqlStr,
+		args: args,
+	})
+	return statement
+}
+
+// quoteWithName quotes the name of the common table expression and its columns
+func (statement *Statement) quoteWithName(name string) string {
+	idx := strings.Index(name, "(")
+	if idx < 0 {
+		return statement.quote(strings.TrimSpace(name))
+	}
+	cols := strings.Split(strings.TrimSuffix(strings.TrimSpace(name[idx+1:]), ")"), ",")
+	for i := range cols {
+		cols[i] = strings.TrimSpace(cols[i])
+	}
+	return fmt.Sprintf("%s(%s)", statement.quote(strings.TrimSpace(name[:idx])), statement.dialect.Quoter().Join(cols, ", "))
+}
+
+// Union combines the rows of the subquery, op is UNION or UNION ALL
+func (statement *Statement) Union(op string, query interface{}) *Statement {
+	sqlStr, args, _, ok, err := statement.subQuery(query)
+	if err != nil {
+		statement.LastError = err
+		return statement
+	}
+	if !ok {
+		statement.LastError = fmt.Errorf("unsupported subquery %T of %s", query, op)
+		return statement
+	}
+	statement.unions = append(statement.unions, subQueryPart{
+		op:   op,
+		sql:  sqlStr,
+		args: args,
+	})
+	return statement
+}
+
+// writeWith writes the common table expressions before the query
+func (statement *Statement) writeWith(sqlStr string) string {
+	if len(statement.withs) == 0 {
+		return sqlStr
+	}
+	var buf strings.Builder
+	buf.WriteString("WITH ")
+	// SQL Server and Oracle don't accept the RECURSIVE keyword
+	if statement.recursiveWith &&
+		statement.dialect.URI().DBType != schemas.MSSQL &&
+		statement.dialect.URI().DBType != schemas.ORACLE {
+		buf.WriteString("RECURSIVE ")
+	}
+	for i, with := range statement.withs {
+		if i > 0 {
+			buf.WriteString(", ")
+		}
+		fmt.Fprintf(&buf, "%s AS (%s)", with.op, with.sql)
+	}
+	buf.WriteString(" ")
+	buf.WriteString(sqlStr)
+	return buf.String()
+}
+
+// writeUnions writes the queries combined by UNION
+func (statement *Statement) writeUnions(buf *strings.Builder) {
+	for _, union := range statement.unions {
+		fmt.Fprintf(buf, " %s %s", union.op, union.sql)
+	}
+}
+
+// genRawQuerySQL returns the raw SQL with the common table expressions and the
+// unions of the statement
+func (statement *Statement) genRawQuerySQL() (string, []interface{}) {
+	if len(statement.withs) == 0 && len(statement.unions) == 0 {
+		return statement.GenRawSQL(), statement.RawParams
+	}
+	var buf strings.Builder
+	buf.WriteString(statement.GenRawSQL())
+	statement.writeUnions(&buf)
+	args := append(statement.fromArgs(), statement.RawParams...)
+	return statement.writeWith(buf.String()), append(args, statement.unionArgs()...)
+}
+
+// fromArgs returns the args of the common table expressions, the subquery
+// table and the joins which are before the conditions
+func (statement *Statement) fromArgs() []interface{} {
+	var args = make([]interface{}, 0, len(statement.tableArgs)+len(statement.joinArgs))
+	for _, with := range statement.withs {
+		args = append(args, with.args...)
+	}
+	args = append(args, statement.tableArgs...)
+	return append(args, statement.joinArgs...)
+}
+
+// unionArgs returns the args of the unions which are after the conditions
+func (statement *Statement) unionArgs() []interface{} {
+	var args []interface{}
+	for _, union := range statement.unions {
+		args = append(args, union.args...)
+	}
+	return args
+}
+
+// ViewSQL returns the SELECT which defines a view, the query is a string or a
+// SubQuery and its args are bound to the SQL since a view has no parameters
+func (statement *Statement) ViewSQL(query interface{}) (string, error) {
+	if s, ok := query.(string); ok {
+		return statement.ReplaceQuote(s), nil
+	}
+	sqlStr, args, _, ok, err := statement.subQuery(query)
+	if err != nil {
+		return "", err
+	}
+	if !ok {
+		return "", ErrUnSupportedSQLType
+	}
+	if len(args) == 0 {
+		return sqlStr, nil
+	}
+	return builder.ConvertToBoundSQL(sqlStr, args)
+}
@@ -160,6 +160,17 @@ func (statement *Statement) BuildUpdates(tableValue reflect.Value,
 			}
 		}
 
+		if converted, ok, err := statement.convertToDB(fieldValue); ok {
+			if err != nil {
+				return nil, nil, err
+			}
+			if !requiredField && utils.IsZero(fieldValue.Interface()) {
+				continue
+			}
+			val = converted
+			goto APPEND
+		}
+
 		switch fieldType.Kind() {
 		case reflect.Bool:
 			if allUseBool || requiredField {
@@ -255,7 +266,13 @@ func (statement *Statement) BuildUpdates(tableValue reflect.Value,
 				}
 			}
 
-			if col.SQLType.IsText() {
+			if col.SQLType.IsArray() {
+				var err error
+				val, err = dialects.FormatArray(statement.dialect, statement.defaultTimeZone, col, fieldValue)
+				if err != nil {
+					return nil, nil, err
+				}
+			} else if col.SQLType.IsText() {
 				bytes, err := json.DefaultJSONHandler.Marshal(fieldValue.Interface())
 				if err != nil {
 					return nil, nil, err
@@ -289,6 +306,11 @@ func (statement *Statement) BuildUpdates(tableValue reflect.Value,
 		}
 
 	APPEND:
+		if col.IsEncrypted {
+			if val, err = statement.encryptValue(col, val); err != nil {
+				return nil, nil, err
+			}
+		}
 		args = append(args, val)
 		colNames = append(colNames, fmt.Sprintf("%v = ?", statement.quote(col.Name)))
 	}
@@ -21,8 +21,65 @@ var (
 	nullFloatType = reflect.TypeOf(sql.NullFloat64{})
 )
 
+// convertToDB converts the field value with the registered converter of its
+// type, ok is false if there is no such converter
+func (statement *Statement) convertToDB(fieldValue reflect.Value) (val interface{}, ok bool, err error) {
+	converter := statement.tagParser.Converter(fieldValue.Type())
+	if converter == nil {
+		return nil, false, nil
+	}
+	if fieldValue.Kind() == reflect.Ptr {
+		if fieldValue.IsNil() {
+			return nil, true, nil
+		}
+		fieldValue = fieldValue.Elem()
+	}
+	val, err = converter.ToDB(fieldValue.Interface())
+	return val, true, err
+}
+
+// encryptValue encrypts the value of an encrypted column, nil is kept as it is
+func (statement *Statement) encryptValue(col *schemas.Column, val interface{}) (interface{}, error) {
+	var plaintext []byte
+	switch t := val.(type) {
+	case nil:
+		return nil, nil
+	case []byte:
+		plaintext = t
+	case string:
+		plaintext = []byte(t)
+	case time.Time:
+		plaintext = []byte(t.Format(time.RFC3339Nano))
+	default:
+		plaintext = []byte(fmt.Sprint(t))
+	}
+	ciphertext, err := convert.Encrypt(statement.tagParser.KeyProvider(), col.EncryptKey,
+		statement.RefTable.Name, col.Name, col.IsDeterministic, plaintext)
+	if err != nil {
+		return nil, err
+	}
+	if col.SQLType.IsBlob() {
+		return []byte(ciphertext), nil
+	}
+	return ciphertext, nil
+}
+
 // Value2Interface convert a field value of a struct to interface for puting into database
 func (statement *Statement) Value2Interface(col *schemas.Column, fieldValue reflect.Value) (interface{}, error) {
+	val, err := statement.PlainValue2Interface(col, fieldValue)
+	if err != nil || !col.IsEncrypted {
+		return val, err
+	}
+	return statement.encryptValue(col, val)
+}
+
+// PlainValue2Interface is like Value2Interface but the values of the encrypted
+// columns are not encrypted
+func (statement *Statement) PlainValue2Interface(col *schemas.Column, fieldValue reflect.Value) (interface{}, error) {
+	if val, ok, err := statement.convertToDB(fieldValue); ok {
+		return val, err
+	}
+
 	if fieldValue.CanAddr() {
 		if fieldConvert, ok := fieldValue.Addr().Interface().(convert.Conversion); ok {
 			data, err := fieldConvert.ToDB()
@@ -128,7 +185,9 @@ func (statement *Statement) Value2Interface(col *schemas.Column, fieldValue refl
 			return fieldValue.Interface(), nil
 		}
 
-		if col.SQLType.IsText() {
+		if col.SQLType.IsArray() {
+			return dialects.FormatArray(statement.dialect, statement.defaultTimeZone, col, fieldValue)
+		} else if col.SQLType.IsText() {
 			bytes, err := json.DefaultJSONHandler.Marshal(fieldValue.Interface())
 			if err != nil {
 				return nil, err
@@ -0,0 +1,147 @@
+// Copyright 2021 The Xorm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package xorm
+
+import (
+	"fmt"
+
+	"github.com/xorm-io/builder"
+	"github.com/xorm-io/xorm/dialects"
+)
+
+// dialectCond is a condition rendered by the dialect of the session, it could
+// only be written by the session's condition writer
+type dialectCond func(dialect dialects.Dialect) (string, []interface{}, error)
+
+var _ builder.Cond = dialectCond(nil)
+
+func (cond dialectCond) WriteTo(w builder.Writer) error {
+	dw, ok := w.(interface{ Dialect() dialects.Dialect })
+	if !ok {
+		return ErrDialectRequired
+	}
+	sqlStr, args, err := cond(dw.Dialect())
+	if err != nil {
+		return err
+	}
+	if _, err := fmt.Fprint(w, sqlStr); err != nil {
+		return err
+	}
+	w.Append(args...)
+	return nil
+}
+
+func (cond dialectCond) And(conds ...builder.Cond) builder.Cond {
+	return builder.And(cond, builder.And(conds...))
+}
+
+func (cond dialectCond) Or(conds ...builder.Cond) builder.Cond {
+	return builder.Or(cond, builder.Or(conds...))
+}
+
+func (cond dialectCond) IsValid() bool {
+	return cond != nil
+}
+
+// JSONPathExpr represents the scalar value at a path of a JSON column
+type JSONPathExpr struct {
+	col  string
+	path string
+}
+
+// JSONPath returns the scalar value at the path of the JSON column, i.e.
+// JSONPath("settings", "$.theme").Eq("dark"). The path supports the member
+// keys which are identifiers and the array indexes, like $.a.b[0].
+func JSONPath(col, path string) JSONPathExpr {
+	return JSONPathExpr{col: col, path: path}
+}
+
+// JSONPath returns the scalar value at the path of the JSON column
+func (col ColumnExpr) JSONPath(path string) JSONPathExpr {
+	return JSONPath(col.quoted, path)
+}
+
+func (expr JSONPathExpr) cond(op string, args ...interface{}) builder.Cond {
+	return dialectCond(func(dialect dialects.Dialect) (string, []interface{}, error) {
+		path, err := dialects.ParseJSONPath(expr.path)
+		if err != nil {
+			return "", nil, err
+		}
+		return dialect.JSONExtract(dialect.Quoter().Quote(expr.col), path) + op, args, nil
+	})
+}
+
+// Eq returns the condition value = arg
+func (expr JSONPathExpr) Eq(arg interface{}) builder.Cond {
+	return expr.cond(" = ?", arg)
+}
+
+// Neq returns the condition value <> arg
+func (expr JSONPathExpr) Neq(arg interface{}) builder.Cond {
+	return expr.cond(" <> ?", arg)
+}
+
+// Gt returns the condition value > arg
+func (expr JSONPathExpr) Gt(arg interface{}) builder.Cond {
+	return expr.cond(" > ?", arg)
+}
+
+// Gte returns the condition value >= arg
+func (expr JSONPathExpr) Gte(arg interface{}) builder.Cond {
+	return expr.cond(" >= ?", arg)
+}
+
+// Lt returns the condition value < arg
+func (expr JSONPathExpr) Lt(arg interface{}) builder.Cond {
+	return expr.cond(" < ?", arg)
+}
+
+// Lte returns the condition value <= arg
+func (expr JSONPathExpr) Lte(arg interface{}) builder.Cond {
+	return expr.cond(" <= ?", arg)
+}
+
+// IsNull returns the condition the path is missing or null
+func (expr JSONPathExpr) IsNull() builder.Cond {
+	return expr.cond(" IS NULL")
+}
+
+// NotNull returns the condition the path has a non-null value
+func (expr JSONPathExpr) NotNull() builder.Cond {
+	return expr.cond(" IS NOT NULL")
+}
+
+// JSONContains returns the condition that the JSON column contains the
+// document, which could be a JSON string or a value to be marshaled. The
+// databases without a containment operator compare the leaf values instead.
+func JSONContains(col string, doc interface{}) builder.Cond {
+	return dialectCond(func(dialect dialects.Dialect) (string, []interface{}, error) {
+		return dialect.JSONContains(dialect.Quoter().Quote(col), doc)
+	})
+}
+
+// JSONSet sets the value at the path of the JSON column when updating, the
+// other parts of the JSON document are kept
+func (session *Session) JSONSet(column, path string, value interface{}) *Session {
+	p, err := dialects.ParseJSONPath(path)
+	if err != nil {
+		session.statement.LastError = err
+		return session
+	}
+	dialect := session.engine.dialect
+	sqlStr, args, err := dialect.JSONSet(dialect.Quoter().Quote(column), p, value)
+	if err != nil {
+		session.statement.LastError = err
+		return session
+	}
+	return session.SetExpr(column, builder.Expr(sqlStr, args...))
+}
+
+// JSONSet sets the value at the path of the JSON column when updating
+func (engine *Engine) JSONSet(column, path string, value interface{}) *Session {
+	session := engine.NewSession()
+	session.isAutoClose = true
+	return session.JSONSet(column, path, value)
+}
@@ -44,6 +44,10 @@ var (
 	SessionIDKey      = "__xorm_session_id"
 	SessionKey        = "__xorm_session_key"
 	SessionShowSQLKey = "__xorm_show_sql"
+
+	// SensitiveColumnsKey carries the names of the columns tagged `sensitive`
+	// on the table of the executed statement
+	SensitiveColumnsKey = "__xorm_sensitive_columns"
 )
 
 // LoggerAdapter wraps a Logger interface as LoggerContext interface
@@ -0,0 +1,373 @@
+// Copyright 2021 The Xorm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package log
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// enumerate all the field keys emitted by StructuredLogger
+const (
+	FieldSessionID = "session_id"
+	FieldSQL       = "sql"
+	FieldArgs      = "args"
+	FieldDuration  = "duration"
+	FieldRows      = "rows"
+	FieldError     = "error"
+)
+
+// RedactedValue replaces the arguments masked by a Redactor
+const RedactedValue = "[REDACTED]"
+
+// KVSink represents a key-value log handler, e.g. an adapter of log/slog
+type KVSink interface {
+	Log(ctx context.Context, level LogLevel, msg string, keyvals ...interface{})
+}
+
+// KVSinkFunc wraps a function as KVSink
+type KVSinkFunc func(ctx context.Context, level LogLevel, msg string, keyvals ...interface{})
+
+// Log implements KVSink
+func (f KVSinkFunc) Log(ctx context.Context, level LogLevel, msg string, keyvals ...interface{}) {
+	f(ctx, level, msg, keyvals...)
+}
+
+// Redactor masks the SQL arguments before they are logged
+type Redactor interface {
+	Redact(ctx context.Context, sql string, args []interface{}) []interface{}
+}
+
+var (
+	_ ContextLogger = &StructuredLogger{}
+	_ Redactor      = &ColumnRedactor{}
+)
+
+// StructuredLogger implements ContextLogger and emits fields instead of formatted lines
+type StructuredLogger struct {
+	sink     KVSink
+	redactor Redactor
+	level    LogLevel
+	showSQL  bool
+}
+
+// NewStructuredLogger creates a structured logger which writes to sink
+func NewStructuredLogger(sink KVSink) *StructuredLogger {
+	return &StructuredLogger{
+		sink:     sink,
+		redactor: NewColumnRedactor(),
+		level:    DEFAULT_LOG_LEVEL,
+	}
+}
+
+// SetRedactor sets the redaction policy of SQL arguments, nil disables redaction
+func (l *StructuredLogger) SetRedactor(redactor Redactor) {
+	l.redactor = redactor
+}
+
+// BeforeSQL implements ContextLogger
+func (l *StructuredLogger) BeforeSQL(ctx LogContext) {}
+
+// AfterSQL implements ContextLogger
+func (l *StructuredLogger) AfterSQL(ctx LogContext) {
+	var level = LOG_INFO
+	if ctx.Err != nil {
+		level = LOG_ERR
+	}
+	if l.level > level {
+		return
+	}
+
+	var keyvals = make([]interface{}, 0, 12)
+	if key, ok := ctx.Ctx.Value(SessionIDKey).(string); ok {
+		keyvals = append(keyvals, FieldSessionID, key)
+	}
+
+	var args = ctx.Args
+	if l.redactor != nil {
+		args = l.redactor.Redact(ctx.Ctx, ctx.SQL, ctx.Args)
+	}
+	keyvals = append(keyvals, FieldSQL, ctx.SQL, FieldArgs, args, FieldDuration, ctx.ExecuteTime)
+
+	if ctx.Result != nil {
+		if rows, err := ctx.Result.RowsAffected(); err == nil {
+			keyvals = append(keyvals, FieldRows, rows)
+		}
+	}
+	if ctx.Err != nil {
+		keyvals = append(keyvals, FieldError, ctx.Err)
+	}
+
+	l.sink.Log(ctx.Ctx, level, "[SQL]", keyvals...)
+}
+
+func (l *StructuredLogger) logf(level LogLevel, format string, v ...interface{}) {
+	if l.level <= level {
+		l.sink.Log(context.Background(), level, fmt.Sprintf(format, v...))
+	}
+}
+
+// Debugf implements ContextLogger
+func (l *StructuredLogger) Debugf(format string, v ...interface{}) {
+	l.logf(LOG_DEBUG, format, v...)
+}
+
+// Errorf implements ContextLogger
+func (l *StructuredLogger) Errorf(format string, v ...interface{}) {
+	l.logf(LOG_ERR, format, v...)
+}
+
+// Infof implements ContextLogger
+func (l *StructuredLogger) Infof(format string, v ...interface{}) {
+	l.logf(LOG_INFO, format, v...)
+}
+
+// Warnf implements ContextLogger
+func (l *StructuredLogger) Warnf(format string, v ...interface{}) {
+	l.logf(LOG_WARNING, format, v...)
+}
+
+// Level implements ContextLogger
+func (l *StructuredLogger) Level() LogLevel {
+	return l.level
+}
+
+// SetLevel implements ContextLogger
+func (l *StructuredLogger) SetLevel(lv LogLevel) {
+	l.level = lv
+}
+
+// ShowSQL implements ContextLogger
+func (l *StructuredLogger) ShowSQL(show ...bool) {
+	if len(show) == 0 {
+		l.showSQL = true
+		return
+	}
+	l.showSQL = show[0]
+}
+
+// IsShowSQL implements ContextLogger
+func (l *StructuredLogger) IsShowSQL() bool {
+	return l.showSQL
+}
+
+// ColumnRedactor masks the arguments bound to sensitive columns. The columns
+// are the ones given on creation plus the ones tagged `sensitive` on the
+// table of the executed statement, which xorm passes by SensitiveColumnsKey.
+type ColumnRedactor struct {
+	columns map[string]bool
+}
+
+// NewColumnRedactor creates a redactor masking the given columns
+func NewColumnRedactor(columns ...string) *ColumnRedactor {
+	var r = &ColumnRedactor{
+		columns: make(map[string]bool, len(columns)),
+	}
+	for _, col := range columns {
+		r.columns[strings.ToLower(col)] = true
+	}
+	return r
+}
+
+func (r *ColumnRedactor) isSensitive(ctx context.Context, col string) bool {
+	col = strings.ToLower(col)
+	if r.columns[col] {
+		return true
+	}
+	if ctx == nil {
+		return false
+	}
+	cols, _ := ctx.Value(SensitiveColumnsKey).([]string)
+	for _, c := range cols {
+		if strings.ToLower(c) == col {
+			return true
+		}
+	}
+	return false
+}
+
+// Redact implements Redactor
+func (r *ColumnRedactor) Redact(ctx context.Context, sql string, args []interface{}) []interface{} {
+	if len(args) == 0 {
+		return args
+	}
+	if len(r.columns) == 0 {
+		if ctx == nil {
+			return args
+		}
+		if cols, _ := ctx.Value(SensitiveColumnsKey).([]string); len(cols) == 0 {
+			return args
+		}
+	}
+
+	var res []interface{}
+	for i, col := range ArgColumns(sql, len(args)) {
+		if col == "" || !r.isSensitive(ctx, col) {
+			continue
+		}
+		if res == nil {
+			res = make([]interface{}, len(args))
+			copy(res, args)
+		}
+		res[i] = RedactedValue
+	}
+	if res == nil {
+		return args
+	}
+	return res
+}
+
+var argColumnKeywords = map[string]bool{
+	"AND": true, "OR": true, "NOT": true, "IN": true, "IS": true, "NULL": true,
+	"LIKE": true, "ILIKE": true, "BETWEEN": true, "SET": true, "WHERE": true,
+	"VALUES": true, "LIMIT": true, "OFFSET": true, "ON": true, "HAVING": true,
+	"SELECT": true, "FROM": true, "ESCAPE": true, "CASE": true, "WHEN": true,
+	"THEN": true, "ELSE": true, "END": true, "ROWS": true, "FETCH": true, "NEXT": true,
+}
+
+// ArgColumns guesses the column every placeholder of sql is bound to. It
+// understands `col = ?` style comparisons, IN and BETWEEN lists, UPDATE SET
+// pairs and INSERT column lists, and returns an empty name when no column
+// could be determined. Placeholders could be ?, $n, :n or @pn.
+func ArgColumns(sql string, n int) []string {
+	var (
+		cols       = make([]string, 0, n)
+		lastIdent  string
+		insertCols []string
+		afterInto  bool
+		collecting bool
+		inValues   bool
+		depth      int
+		valueIdx   int
+	)
+
+	var addIdent = func(word string) {
+		if idx := strings.LastIndexByte(word, '.'); idx > -1 {
+			word = word[idx+1:]
+		}
+		if collecting && depth == 1 {
+			insertCols = append(insertCols, word)
+		}
+		lastIdent = word
+	}
+
+	for i := 0; i < len(sql) && len(cols) < n; {
+		c := sql[i]
+		switch {
+		case c == '\'':
+			j := i + 1
+			for j < len(sql) {
+				if sql[j] == '\'' {
+					if j+1 < len(sql) && sql[j+1] == '\'' {
+						j += 2
+						continue
+					}
+					break
+				}
+				j++
+			}
+			i = j + 1
+		case c == '"' || c == '`' || c == '[':
+			var end = c
+			if c == '[' {
+				end = ']'
+			}
+			j := strings.IndexByte(sql[i+1:], end)
+			if j < 0 {
+				return fillArgColumns(cols, n)
+			}
+			addIdent(sql[i+1 : i+1+j])
+			i += j + 2
+		case isPlaceholder(sql, i):
+			j := i + 1
+			if c == '@' {
+				j++
+			}
+			for j < len(sql) && isDigit(sql[j]) {
+				j++
+			}
+			if inValues && depth >= 1 && valueIdx < len(insertCols) {
+				cols = append(cols, insertCols[valueIdx])
+			} else {
+				cols = append(cols, lastIdent)
+			}
+			i = j
+		case c == '(':
+			if afterInto && depth == 0 {
+				collecting = true
+				insertCols = nil
+			}
+			if inValues && depth == 0 {
+				valueIdx = 0
+			}
+			depth++
+			i++
+		case c == ')':
+			depth--
+			if depth == 0 && collecting {
+				collecting = false
+				afterInto = false
+			}
+			i++
+		case c == ',':
+			if inValues && depth == 1 {
+				valueIdx++
+			}
+			i++
+		case isIdentStart(c):
+			j := i
+			for j < len(sql) && (isIdentStart(sql[j]) || isDigit(sql[j]) || sql[j] == '.') {
+				j++
+			}
+			word := sql[i:j]
+			upper := strings.ToUpper(word)
+			switch {
+			case upper == "INTO":
+				afterInto = true
+			case upper == "VALUES":
+				inValues = len(insertCols) > 0
+				afterInto = false
+			case upper == "SELECT" || upper == "SET" || upper == "WHERE":
+				afterInto = false
+				inValues = false
+			case !argColumnKeywords[upper]:
+				addIdent(word)
+			}
+			i = j
+		default:
+			i++
+		}
+	}
+	return fillArgColumns(cols, n)
+}
+
+func isPlaceholder(sql string, i int) bool {
+	switch sql[i] {
+	case '?':
+		return true
+	case '$', ':':
+		return i+1 < len(sql) && isDigit(sql[i+1])
+	case '@':
+		return i+2 < len(sql) && (sql[i+1] == 'p' || sql[i+1] == 'P') && isDigit(sql[i+2])
+	}
+	return false
+}
+
+func fillArgColumns(cols []string, n int) []string {
+	for len(cols) < n {
+		cols = append(cols, "")
+	}
+	return cols
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+func isIdentStart(c byte) bool {
+	return c == '_' || unicode.IsLetter(rune(c))
+}
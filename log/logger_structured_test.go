@@ -0,0 +1,27 @@
+// Copyright 2021 The Xorm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package log
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestArgColumns(t *testing.T) {
+	var kases = []struct {
+		sql  string
+		n    int
+		cols []string
+	}{
+		{"INSERT INTO `user` (`name`,`password`) VALUES (?,?),(?,?)", 4, []string{"name", "password", "name", "password"}},
+		{`UPDATE "user" SET "name" = $1, "password" = $2 WHERE "id" = $3`, 3, []string{"name", "password", "id"}},
+		{"SELECT * FROM user WHERE user.id IN (?,?) AND age BETWEEN ? AND ? AND note = 'a?b'", 4, []string{"id", "id", "age", "age"}},
+		{"SELECT 1", 1, []string{""}},
+	}
+	for _, kase := range kases {
+		assert.EqualValues(t, kase.cols, ArgColumns(kase.sql, kase.n), kase.sql)
+	}
+}
@@ -0,0 +1,167 @@
+// Copyright 2021 The Xorm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package names
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"time"
+)
+
+// ErrZeroShardKey is returned when the shard field of a bean is zero, so that
+// the shard of the bean is unknown
+var ErrZeroShardKey = errors.New("the shard field of the bean is zero")
+
+// Sharder derives the physical tables of a sharded table from the field values
+// of the beans. A bean implementing Sharder shards its own table, otherwise
+// the sharder of the table could be set on the engine.
+type Sharder interface {
+	// ShardTable returns the physical table of the bean
+	ShardTable(table string, bean interface{}) (string, error)
+	// ShardTables returns all the physical tables of the table
+	ShardTables(table string) ([]string, error)
+}
+
+// InsertSharder is a Sharder which derives the physical tables of the beans
+// to be inserted differently, e.g. from the shard fields not filled yet
+type InsertSharder interface {
+	Sharder
+	// ShardInsertTable returns the physical table of the bean to be inserted
+	ShardInsertTable(table string, bean interface{}) (string, error)
+}
+
+// ShardName returns the name of the shard of the table with the suffix
+func ShardName(table string, suffix interface{}) string {
+	return fmt.Sprintf("%s_%v", table, suffix)
+}
+
+// shardField returns the value of the field of the bean
+func shardField(bean interface{}, field string) (reflect.Value, error) {
+	v := reflect.Indirect(reflect.ValueOf(bean))
+	if v.Kind() != reflect.Struct {
+		return reflect.Value{}, fmt.Errorf("unsupported shard bean %T", bean)
+	}
+	fieldValue := v.FieldByName(field)
+	if !fieldValue.IsValid() {
+		return reflect.Value{}, fmt.Errorf("shard field %s is not found in %T", field, bean)
+	}
+	return reflect.Indirect(fieldValue), nil
+}
+
+// TimeSharder shards a table by a time field, e.g. orders_2026_10 by CreatedAt
+// with the layout 2006_01. The zero time of a bean to be inserted is taken as
+// the current time, so that the beans whose created time is filled on insert
+// go to the current shard.
+type TimeSharder struct {
+	Field  string    // the name of the time field
+	Layout string    // the time layout of the suffixes of the shards
+	From   time.Time // the time of the first shard
+	To     time.Time // the time of the last shard
+}
+
+var _ InsertSharder = &TimeSharder{}
+
+func (s *TimeSharder) shardTime(bean interface{}) (time.Time, error) {
+	v, err := shardField(bean, s.Field)
+	if err != nil {
+		return time.Time{}, err
+	}
+	t, ok := v.Interface().(time.Time)
+	if !ok {
+		return time.Time{}, fmt.Errorf("shard field %s of %T is not a time", s.Field, bean)
+	}
+	return t, nil
+}
+
+// ShardTable implements Sharder, it returns ErrZeroShardKey if the time is zero
+func (s *TimeSharder) ShardTable(table string, bean interface{}) (string, error) {
+	t, err := s.shardTime(bean)
+	if err != nil {
+		return "", err
+	}
+	if t.IsZero() {
+		return "", ErrZeroShardKey
+	}
+	return ShardName(table, t.Format(s.Layout)), nil
+}
+
+// ShardInsertTable implements InsertSharder
+func (s *TimeSharder) ShardInsertTable(table string, bean interface{}) (string, error) {
+	t, err := s.shardTime(bean)
+	if err != nil {
+		return "", err
+	}
+	if t.IsZero() {
+		t = time.Now()
+	}
+	return ShardName(table, t.Format(s.Layout)), nil
+}
+
+// ShardTables implements Sharder
+func (s *TimeSharder) ShardTables(table string) ([]string, error) {
+	return s.Range(table, s.From, s.To), nil
+}
+
+// Range returns the shards of the table between from and to, both included
+func (s *TimeSharder) Range(table string, from, to time.Time) []string {
+	var (
+		shards  []string
+		visited = make(map[string]bool)
+	)
+	add := func(t time.Time) {
+		if name := ShardName(table, t.Format(s.Layout)); !visited[name] {
+			visited[name] = true
+			shards = append(shards, name)
+		}
+	}
+	// the layouts finer than an hour are not supported
+	for t := from; !t.After(to); t = t.Add(time.Hour) {
+		add(t)
+	}
+	add(to)
+	return shards
+}
+
+// FieldSharder shards a table by the value of a field, e.g. events_<tenant>
+// by TenantID
+type FieldSharder struct {
+	Field  string        // the name of the field
+	Values []interface{} // the values of all the shards
+}
+
+var _ Sharder = &FieldSharder{}
+
+// ShardTable implements Sharder, it returns ErrZeroShardKey if the value is
+// zero but not one of the values of the shards
+func (s *FieldSharder) ShardTable(table string, bean interface{}) (string, error) {
+	v, err := shardField(bean, s.Field)
+	if err != nil {
+		return "", err
+	}
+	value := v.Interface()
+	if v.IsZero() && !s.hasValue(value) {
+		return "", ErrZeroShardKey
+	}
+	return ShardName(table, value), nil
+}
+
+func (s *FieldSharder) hasValue(value interface{}) bool {
+	for _, v := range s.Values {
+		if fmt.Sprint(v) == fmt.Sprint(value) {
+			return true
+		}
+	}
+	return false
+}
+
+// ShardTables implements Sharder
+func (s *FieldSharder) ShardTables(table string) ([]string, error) {
+	shards := make([]string, 0, len(s.Values))
+	for _, value := range s.Values {
+		shards = append(shards, ShardName(table, value))
+	}
+	return shards, nil
+}
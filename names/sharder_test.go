@@ -0,0 +1,67 @@
+// Copyright 2021 The Xorm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package names
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type ShardOrder struct {
+	Id        int64
+	Tenant    string
+	CreatedAt time.Time
+}
+
+func TestTimeSharder(t *testing.T) {
+	sharder := &TimeSharder{
+		Field:  "CreatedAt",
+		Layout: "2006_01",
+		From:   time.Date(2026, 11, 15, 0, 0, 0, 0, time.UTC),
+		To:     time.Date(2027, 2, 1, 0, 0, 0, 0, time.UTC),
+	}
+
+	name, err := sharder.ShardTable("orders", &ShardOrder{
+		CreatedAt: time.Date(2026, 10, 19, 8, 0, 0, 0, time.UTC),
+	})
+	assert.NoError(t, err)
+	assert.EqualValues(t, "orders_2026_10", name)
+
+	// the zero time is the current time only for the beans to be inserted
+	_, err = sharder.ShardTable("orders", ShardOrder{})
+	assert.EqualError(t, err, ErrZeroShardKey.Error())
+	name, err = sharder.ShardInsertTable("orders", ShardOrder{})
+	assert.NoError(t, err)
+	assert.EqualValues(t, ShardName("orders", time.Now().Format("2006_01")), name)
+
+	_, err = (&TimeSharder{Field: "Tenant"}).ShardTable("orders", &ShardOrder{})
+	assert.Error(t, err)
+	_, err = (&TimeSharder{Field: "Updated"}).ShardTable("orders", &ShardOrder{})
+	assert.Error(t, err)
+
+	shards, err := sharder.ShardTables("orders")
+	assert.NoError(t, err)
+	assert.EqualValues(t, []string{"orders_2026_11", "orders_2026_12", "orders_2027_01", "orders_2027_02"}, shards)
+}
+
+func TestFieldSharder(t *testing.T) {
+	sharder := &FieldSharder{
+		Field:  "Tenant",
+		Values: []interface{}{"a", "b"},
+	}
+
+	name, err := sharder.ShardTable("events", &ShardOrder{Tenant: "a"})
+	assert.NoError(t, err)
+	assert.EqualValues(t, "events_a", name)
+
+	_, err = sharder.ShardTable("events", &ShardOrder{})
+	assert.EqualError(t, err, ErrZeroShardKey.Error())
+
+	shards, err := sharder.ShardTables("events")
+	assert.NoError(t, err)
+	assert.EqualValues(t, []string{"events_a", "events_b"}, shards)
+}
@@ -0,0 +1,328 @@
+// Copyright 2021 The Xorm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// Package reverse generates Go structs with xorm tags from the tables of an
+// existing database.
+package reverse
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"io"
+	"reflect"
+	"sort"
+	"strings"
+	"text/template"
+
+	"github.com/xorm-io/xorm"
+	"github.com/xorm-io/xorm/names"
+	"github.com/xorm-io/xorm/schemas"
+)
+
+// DefaultTemplate is the template used when Options.Template is empty
+const DefaultTemplate = `package {{.Package}}
+
+{{if .Imports}}import (
+{{range .Imports}}	"{{.}}"
+{{end}})
+{{end}}
+{{range .Structs}}
+{{if .Comment}}// {{.Name}} {{.Comment}}
+{{end}}type {{.Name}} struct {
+{{range .Fields}}	{{.Name}} {{.Type}} ` + "`" + `xorm:"{{.Tag}}"` + "`" + `
+{{end}}}
+{{if .CustomTableName}}
+// TableName returns the table name of {{.Name}}
+func ({{.Name}}) TableName() string {
+	return "{{.TableName}}"
+}
+{{end}}{{end}}`
+
+// Options represents the options of the code generation
+type Options struct {
+	// Package is the package name of the generated file, default is models
+	Package string
+	// TableMapper maps the table names to the struct names, default is SnakeMapper
+	TableMapper names.Mapper
+	// ColumnMapper maps the column names to the field names, default is SnakeMapper
+	ColumnMapper names.Mapper
+	// Template is a text/template source to render the Data, default is DefaultTemplate
+	Template string
+	// Funcs are the extra functions available in the template
+	Funcs template.FuncMap
+	// Tables limits the generation to the tables, all tables if it's empty
+	Tables []string
+	// NoFormat disables gofmt of the generated source, i.e. the template is not a Go file
+	NoFormat bool
+}
+
+// Field represents a field of a generated struct
+type Field struct {
+	Name   string
+	Type   string
+	Tag    string // the content of the xorm tag
+	Column *schemas.Column
+}
+
+// Struct represents a generated struct
+type Struct struct {
+	Name            string
+	TableName       string
+	Comment         string
+	CustomTableName bool // true if the table name could not be mapped back from the struct name
+	Fields          []*Field
+	Table           *schemas.Table
+}
+
+// Data is the data passed to the template
+type Data struct {
+	Package string
+	Imports []string
+	Structs []*Struct
+}
+
+var (
+	createdNames = []string{"created", "created_at", "create_at", "created_time", "create_time", "gmt_create"}
+	updatedNames = []string{"updated", "updated_at", "update_at", "updated_time", "update_time", "gmt_modified"}
+	deletedNames = []string{"deleted", "deleted_at", "delete_at", "deleted_time", "delete_time"}
+)
+
+func (opts *Options) withDefaults() *Options {
+	var res Options
+	if opts != nil {
+		res = *opts
+	}
+	if res.Package == "" {
+		res.Package = "models"
+	}
+	if res.TableMapper == nil {
+		res.TableMapper = names.SnakeMapper{}
+	}
+	if res.ColumnMapper == nil {
+		res.ColumnMapper = names.SnakeMapper{}
+	}
+	if res.Template == "" {
+		res.Template = DefaultTemplate
+	}
+	return &res
+}
+
+// FromEngine generates the structs of the tables read by engine.DBMetas. The
+// mappers of the engine are used if the options don't specify them.
+func FromEngine(engine *xorm.Engine, w io.Writer, opts *Options) error {
+	tables, err := engine.DBMetas()
+	if err != nil {
+		return err
+	}
+	var res Options
+	if opts != nil {
+		res = *opts
+	}
+	if res.TableMapper == nil {
+		res.TableMapper = engine.GetTableMapper()
+	}
+	if res.ColumnMapper == nil {
+		res.ColumnMapper = engine.GetColumnMapper()
+	}
+	return Generate(w, tables, &res)
+}
+
+// Generate renders the structs of the tables with the template to w
+func Generate(w io.Writer, tables []*schemas.Table, opts *Options) error {
+	opts = opts.withDefaults()
+
+	tmpl, err := template.New("reverse").Funcs(opts.Funcs).Parse(opts.Template)
+	if err != nil {
+		return err
+	}
+
+	data, err := NewData(tables, opts)
+	if err != nil {
+		return err
+	}
+
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return err
+	}
+
+	src := buf.Bytes()
+	if !opts.NoFormat {
+		src, err = format.Source(src)
+		if err != nil {
+			return fmt.Errorf("format generated source failed: %v", err)
+		}
+	}
+	_, err = w.Write(src)
+	return err
+}
+
+// NewData converts the tables to the data of the template
+func NewData(tables []*schemas.Table, opts *Options) (*Data, error) {
+	opts = opts.withDefaults()
+
+	var filter = make(map[string]bool, len(opts.Tables))
+	for _, name := range opts.Tables {
+		filter[strings.ToLower(name)] = true
+	}
+
+	var data = Data{Package: opts.Package}
+	var imports = make(map[string]bool)
+	for _, table := range tables {
+		if len(filter) > 0 && !filter[strings.ToLower(table.Name)] {
+			continue
+		}
+		st, err := NewStruct(table, opts)
+		if err != nil {
+			return nil, err
+		}
+		for _, field := range st.Fields {
+			if strings.HasPrefix(field.Type, "time.") {
+				imports["time"] = true
+			}
+		}
+		data.Structs = append(data.Structs, st)
+	}
+	for imp := range imports {
+		data.Imports = append(data.Imports, imp)
+	}
+	sort.Strings(data.Imports)
+	return &data, nil
+}
+
+// NewStruct converts the table to a struct
+func NewStruct(table *schemas.Table, opts *Options) (*Struct, error) {
+	opts = opts.withDefaults()
+
+	name := opts.TableMapper.Table2Obj(table.Name)
+	if name == "" {
+		return nil, fmt.Errorf("table %s could not be mapped to a struct name", table.Name)
+	}
+
+	var st = Struct{
+		Name:            name,
+		TableName:       table.Name,
+		Comment:         table.Comment,
+		CustomTableName: opts.TableMapper.Obj2Table(name) != table.Name,
+		Table:           table,
+	}
+	for _, col := range table.Columns() {
+		fieldName := opts.ColumnMapper.Table2Obj(col.Name)
+		st.Fields = append(st.Fields, &Field{
+			Name:   fieldName,
+			Type:   GoType(col),
+			Tag:    Tag(table, col, opts.ColumnMapper.Obj2Table(fieldName) != col.Name),
+			Column: col,
+		})
+	}
+	return &st, nil
+}
+
+// GoType returns the Go type of the column
+func GoType(col *schemas.Column) string {
+	t := schemas.SQLType2Type(col.SQLType)
+	if t.Kind() == reflect.Slice && t.Elem().Kind() == reflect.Uint8 {
+		return "[]byte"
+	}
+	return t.String()
+}
+
+func nameIn(name string, candidates []string) bool {
+	name = strings.ToLower(name)
+	for _, c := range candidates {
+		if name == c {
+			return true
+		}
+	}
+	return false
+}
+
+func sqlTypeTag(col *schemas.Column) string {
+	name := strings.ToUpper(col.SQLType.Name)
+	if _, ok := schemas.SqlTypes[name]; !ok {
+		return ""
+	}
+
+	switch {
+	case len(col.EnumOptions) > 0:
+		return name + "(" + quoteOptions(col.EnumOptions) + ")"
+	case len(col.SetOptions) > 0:
+		return name + "(" + quoteOptions(col.SetOptions) + ")"
+	case col.Length2 > 0:
+		return fmt.Sprintf("%s(%d,%d)", name, col.Length, col.Length2)
+	case col.Length > 0:
+		return fmt.Sprintf("%s(%d)", name, col.Length)
+	}
+	return name
+}
+
+func quoteOptions(options map[string]int) string {
+	var opts = make([]string, len(options))
+	for k, v := range options {
+		if v >= 0 && v < len(opts) {
+			opts[v] = "'" + k + "'"
+		}
+	}
+	return strings.Join(opts, ",")
+}
+
+// Tag returns the content of the xorm tag of the column. withName indicates
+// whether the column name should be written into the tag.
+func Tag(table *schemas.Table, col *schemas.Column, withName bool) string {
+	var res []string
+	if withName {
+		res = append(res, "'"+col.Name+"'")
+	}
+	if tp := sqlTypeTag(col); tp != "" {
+		res = append(res, tp)
+	}
+	if col.IsPrimaryKey {
+		res = append(res, "pk")
+	}
+	if col.IsAutoIncrement {
+		res = append(res, "autoincr")
+	}
+	if !col.Nullable && !col.IsPrimaryKey {
+		res = append(res, "notnull")
+	}
+	if !col.DefaultIsEmpty && col.Default != "" && !col.IsAutoIncrement {
+		res = append(res, "default("+col.Default+")")
+	}
+
+	if col.SQLType.IsTime() {
+		switch {
+		case nameIn(col.Name, createdNames):
+			res = append(res, "created")
+		case nameIn(col.Name, updatedNames):
+			res = append(res, "updated")
+		case nameIn(col.Name, deletedNames):
+			res = append(res, "deleted")
+		}
+	}
+
+	var indexes []string
+	for name := range col.Indexes {
+		index, ok := table.Indexes[name]
+		if !ok {
+			continue
+		}
+		var tp = "index"
+		if index.Type == schemas.UniqueType {
+			tp = "unique"
+		}
+		if index.IsRegular && len(index.Cols) == 1 && index.Name == col.Name {
+			indexes = append(indexes, tp)
+		} else {
+			indexes = append(indexes, tp+"("+index.Name+")")
+		}
+	}
+	sort.Strings(indexes)
+	res = append(res, indexes...)
+
+	if col.Comment != "" {
+		res = append(res, "comment('"+strings.Replace(col.Comment, "'", "", -1)+"')")
+	}
+	return strings.Join(res, " ")
+}
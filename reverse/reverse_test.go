@@ -0,0 +1,97 @@
+// Copyright 2021 The Xorm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package reverse
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	_ "github.com/mattn/go-sqlite3"
+	"github.com/stretchr/testify/assert"
+	"github.com/xorm-io/xorm"
+	"github.com/xorm-io/xorm/names"
+	"github.com/xorm-io/xorm/schemas"
+)
+
+func TestTag(t *testing.T) {
+	table := schemas.NewEmptyTable()
+	table.Name = "user"
+
+	id := schemas.NewColumn("id", "", schemas.SQLType{Name: schemas.BigInt}, 0, 0, false)
+	id.IsPrimaryKey = true
+	id.IsAutoIncrement = true
+	table.AddColumn(id)
+
+	name := schemas.NewColumn("name", "", schemas.SQLType{Name: schemas.Varchar}, 50, 0, false)
+	name.Default = "''"
+	name.DefaultIsEmpty = false
+	name.Comment = "the user's name"
+	table.AddColumn(name)
+
+	created := schemas.NewColumn("created_at", "", schemas.SQLType{Name: schemas.DateTime}, 0, 0, true)
+	table.AddColumn(created)
+
+	index := schemas.NewIndex("name", schemas.UniqueType)
+	index.AddColumn("name")
+	table.AddIndex(index)
+	name.Indexes["name"] = schemas.UniqueType
+
+	group := schemas.NewIndex("s", schemas.IndexType)
+	group.AddColumn("name", "created_at")
+	table.AddIndex(group)
+	name.Indexes["s"] = schemas.IndexType
+	created.Indexes["s"] = schemas.IndexType
+
+	assert.EqualValues(t, "BIGINT pk autoincr", Tag(table, id, false))
+	assert.EqualValues(t, "VARCHAR(50) notnull default('') index(s) unique comment('the users name')", Tag(table, name, false))
+	assert.EqualValues(t, "'created_at' DATETIME created index(s)", Tag(table, created, true))
+	assert.EqualValues(t, "time.Time", GoType(created))
+	assert.EqualValues(t, "int64", GoType(id))
+}
+
+func TestFromEngine(t *testing.T) {
+	dir, err := ioutil.TempDir("", "xorm-reverse")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	engine, err := xorm.NewEngine("sqlite3", filepath.Join(dir, "reverse.db"))
+	assert.NoError(t, err)
+	defer engine.Close()
+
+	type ReverseUser struct {
+		Id      int64
+		Name    string `xorm:"VARCHAR(50) notnull unique"`
+		Age     int    `xorm:"default(18) index"`
+		Avatar  []byte
+		Created time.Time `xorm:"created"`
+	}
+	assert.NoError(t, engine.Sync2(new(ReverseUser)))
+
+	var buf bytes.Buffer
+	assert.NoError(t, FromEngine(engine, &buf, &Options{Package: "models"}))
+
+	src := buf.String()
+	assert.Contains(t, src, "package models")
+	assert.Contains(t, src, "\"time\"")
+	assert.Contains(t, src, "type ReverseUser struct")
+	assert.Regexp(t, "Id +int +`xorm:\"INTEGER pk autoincr\"`", src)
+	assert.Regexp(t, "Name +string +`xorm:\"TEXT notnull unique\"`", src)
+	assert.Regexp(t, "Age +int +`xorm:\"INTEGER default\\(18\\) index\"`", src)
+	assert.Regexp(t, "Avatar +\\[\\]byte", src)
+	assert.Regexp(t, "Created +time.Time +`xorm:\"DATETIME created\"`", src)
+	assert.NotContains(t, src, "TableName()")
+
+	buf.Reset()
+	assert.NoError(t, FromEngine(engine, &buf, &Options{
+		TableMapper: names.NewPrefixMapper(names.SnakeMapper{}, "reverse_"),
+		Template:    "{{range .Structs}}{{.Name}}:{{.TableName}}{{end}}",
+		NoFormat:    true,
+	}))
+	assert.EqualValues(t, "User:reverse_user", buf.String())
+}
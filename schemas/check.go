@@ -0,0 +1,93 @@
+// Copyright 2021 The Xorm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package schemas
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+// Check represents a CHECK constraint of a table
+type Check struct {
+	Name string
+	Expr string
+}
+
+// NewCheck creates a check constraint of the expression
+func NewCheck(name, expr string) *Check {
+	return &Check{Name: name, Expr: expr}
+}
+
+// XName returns the name of the constraint for the table, i.e.
+// CHK_<table>_<name> unless the name is prefixed by CHK_
+func (check *Check) XName(tableName string) string {
+	if strings.HasPrefix(check.Name, "CHK_") {
+		return check.Name
+	}
+	tableParts := strings.Split(strings.Replace(tableName, `"`, "", -1), ".")
+	return fmt.Sprintf("CHK_%v_%v", tableParts[len(tableParts)-1], check.Name)
+}
+
+var (
+	checkCastReg  = regexp.MustCompile(`::\w+(\s+varying)?(\[\])?`)
+	checkIdentReg = regexp.MustCompile(`(^|[^\w])\((\w+)\)`)
+	checkAnyReg   = regexp.MustCompile(`=any\(\(array([^()]*)\)\)`)
+)
+
+// normalizeCheckExpr removes the type casts, the quotes of the identifiers, the
+// spaces and the parentheses around the identifiers or the whole expression
+// which the databases add when the checks are read back, the string literals
+// are kept as they are. The = ANY (ARRAY[...]) of PostgreSQL is read as IN.
+func normalizeCheckExpr(expr string) string {
+	var (
+		buf     strings.Builder
+		literal bool
+	)
+	for _, c := range checkCastReg.ReplaceAllString(expr, "") {
+		if c == '\'' {
+			literal = !literal
+		}
+		if !literal {
+			if unicode.IsSpace(c) || strings.ContainsRune("`\"[]", c) {
+				continue
+			}
+			c = unicode.ToLower(c)
+		}
+		buf.WriteRune(c)
+	}
+	expr = buf.String()
+	for ident := ""; ident != expr; {
+		ident, expr = expr, checkIdentReg.ReplaceAllString(expr, "$1$2")
+	}
+	for len(expr) > 1 && expr[0] == '(' && closingParen(expr) == len(expr)-1 {
+		expr = expr[1 : len(expr)-1]
+	}
+	return checkAnyReg.ReplaceAllString(expr, "in($1)")
+}
+
+// closingParen returns the index of the parenthesis which closes the first one
+func closingParen(expr string) int {
+	var depth int
+	for i, c := range expr {
+		switch c {
+		case '(':
+			depth++
+		case ')':
+			depth--
+			if depth == 0 {
+				return i
+			}
+		}
+	}
+	return -1
+}
+
+// SameExpr returns true if the expressions of the checks are the same after
+// they are normalized
+func (check *Check) SameExpr(other *Check) bool {
+	return normalizeCheckExpr(check.Expr) == normalizeCheckExpr(other.Expr)
+}
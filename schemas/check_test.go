@@ -0,0 +1,34 @@
+// Copyright 2021 The Xorm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package schemas
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCheckSameExpr(t *testing.T) {
+	var kases = []struct {
+		expr, readBack string
+		same           bool
+	}{
+		{"age >= 0", "((age >= 0))", true},
+		{"`age` >= 0", `"age">=0`, true},
+		{"age >= 0", "age > 0", false},
+		{"(a + b) * c", "a + b * c", false},
+		{"(a) + (b)", "a + b", true},
+		{"lower(email) <> ''", "lower email <> ''", false},
+		{"`status` IN ('active', 'disabled')", "[status] in ('active', 'disabled')", true},
+		{"status = 'Active'", "status = 'active'", false},
+		{"status = 'a b'", "status = 'ab'", false},
+		{"status <> ''", "((status)::text <> ''::text)", true},
+		{"`status` IN ('active', 'disabled')",
+			"((status)::text = ANY ((ARRAY['active'::character varying, 'disabled'::character varying])::text[]))", true},
+	}
+	for _, kase := range kases {
+		assert.EqualValues(t, kase.same, NewCheck("c", kase.expr).SameExpr(NewCheck("c", kase.readBack)), kase.expr)
+	}
+}
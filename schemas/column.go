@@ -25,6 +25,7 @@ type Column struct {
 	FieldName       string // Available only when parsed from a struct
 	FieldIndex      []int  // Available only when parsed from a struct
 	SQLType         SQLType
+	ArrayElemType   SQLType // the element type of an array column
 	IsJSON          bool
 	Length          int
 	Length2         int
@@ -39,12 +40,19 @@ type Column struct {
 	IsDeleted       bool
 	IsCascade       bool
 	IsVersion       bool
-	DefaultIsEmpty  bool // false means column has no default set, but not default value is empty
+	IsSensitive     bool   // arguments bound to this column will be masked by log redactors
+	IsEncrypted     bool   // values are encrypted by the key provider of the engine
+	EncryptKey      string // key name of an encrypted column
+	IsDeterministic bool   // encrypted deterministically so that it could be used in equality conditions
+	DefaultIsEmpty  bool   // false means column has no default set, but not default value is empty
 	EnumOptions     map[string]int
 	SetOptions      map[string]int
 	DisableTimeZone bool
 	TimeZone        *time.Location // column specified time zone
 	Comment         string
+	Generated       string // the expression of a generated column
+	GeneratedStored bool   // the generated column is stored but not computed when read
+	Sequence        string // the name of the sequence which generates the values
 }
 
 // NewColumn creates a new column
@@ -15,17 +15,43 @@ const (
 	UniqueType
 )
 
-// Index represents a database index
+// Index represents a database index, the key parts in Cols are the columns or
+// the expressions followed by the optional sort orders
 type Index struct {
 	IsRegular bool
 	Name      string
 	Type      int
 	Cols      []string
+	Where     string // the predicate of a partial index
+}
+
+// IndexKey represents a key part of an index
+type IndexKey struct {
+	Name   string // the column or the expression
+	IsExpr bool
+	Desc   bool
+}
+
+// ParseIndexKey parses a key part of an index like name, name DESC or
+// lower(name), only the key with parentheses is an expression, e.g. (a + b)
+func ParseIndexKey(key string) IndexKey {
+	var desc bool
+	key = strings.TrimSpace(key)
+	if upper := strings.ToUpper(key); strings.HasSuffix(upper, " DESC") {
+		key, desc = strings.TrimSpace(key[:len(key)-5]), true
+	} else if strings.HasSuffix(upper, " ASC") {
+		key = strings.TrimSpace(key[:len(key)-4])
+	}
+	return IndexKey{
+		Name:   key,
+		IsExpr: strings.Contains(key, "("),
+		Desc:   desc,
+	}
 }
 
 // NewIndex new an index object
 func NewIndex(name string, indexType int) *Index {
-	return &Index{true, name, indexType, make([]string, 0)}
+	return &Index{true, name, indexType, make([]string, 0), ""}
 }
 
 // XName returns the special index name for the table
@@ -47,7 +73,28 @@ func (index *Index) AddColumn(cols ...string) {
 	index.Cols = append(index.Cols, cols...)
 }
 
-// Equal return true if the two Index is equal
+// Keys returns the parsed key parts of the index
+func (index *Index) Keys() []IndexKey {
+	keys := make([]IndexKey, 0, len(index.Cols))
+	for _, col := range index.Cols {
+		keys = append(keys, ParseIndexKey(col))
+	}
+	return keys
+}
+
+// HasExpr returns true if any key part of the index is an expression
+func (index *Index) HasExpr() bool {
+	for _, key := range index.Keys() {
+		if key.IsExpr {
+			return true
+		}
+	}
+	return false
+}
+
+// Equal return true if the two Index is equal, the sort orders are ignored and
+// the indexes with expressions are compared by their names since the
+// expressions are rewritten by the databases
 func (index *Index) Equal(dst *Index) bool {
 	if index.Type != dst.Type {
 		return false
@@ -55,11 +102,17 @@ func (index *Index) Equal(dst *Index) bool {
 	if len(index.Cols) != len(dst.Cols) {
 		return false
 	}
+	if (index.Where == "") != (dst.Where == "") {
+		return false
+	}
+	if index.HasExpr() || dst.HasExpr() {
+		return index.Name == dst.Name
+	}
 
 	for i := 0; i < len(index.Cols); i++ {
 		var found bool
 		for j := 0; j < len(dst.Cols); j++ {
-			if index.Cols[i] == dst.Cols[j] {
+			if ParseIndexKey(index.Cols[i]).Name == ParseIndexKey(dst.Cols[j]).Name {
 				found = true
 				break
 			}
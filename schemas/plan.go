@@ -0,0 +1,98 @@
+// Copyright 2021 The Xorm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package schemas
+
+import "strings"
+
+// enumerate all the plan node types
+const (
+	PlanQuery       = "QUERY"        // the root of a plan
+	PlanTableScan   = "TABLE SCAN"   // read all the rows of a table
+	PlanIndexScan   = "INDEX SCAN"   // read a range of an index
+	PlanIndexLookup = "INDEX LOOKUP" // read rows by an index or primary key
+	PlanJoin        = "JOIN"
+	PlanSort        = "SORT"
+	PlanAggregate   = "AGGREGATE"
+	PlanOther       = "OTHER"
+)
+
+// PlanNode represents a dialect neutral node of a query plan
+type PlanNode struct {
+	Type     string
+	Table    string
+	Index    string
+	Rows     int64  // estimated rows, -1 means unknown
+	Detail   string // the original description of the database
+	Children []*PlanNode
+}
+
+// NewPlanNode creates a plan node
+func NewPlanNode(tp string) *PlanNode {
+	return &PlanNode{Type: tp, Rows: -1}
+}
+
+// AddChild adds a child node
+func (node *PlanNode) AddChild(child *PlanNode) {
+	node.Children = append(node.Children, child)
+}
+
+// Walk visits the node and all its descendants in depth-first order
+func (node *PlanNode) Walk(fn func(*PlanNode)) {
+	fn(node)
+	for _, child := range node.Children {
+		child.Walk(fn)
+	}
+}
+
+// UsesIndex returns true if any node reads from the index. If indexName is
+// empty, any index (including the primary key) matches.
+func (node *PlanNode) UsesIndex(indexName string) bool {
+	var found bool
+	node.Walk(func(n *PlanNode) {
+		if n.Type != PlanIndexScan && n.Type != PlanIndexLookup {
+			return
+		}
+		if indexName == "" || strings.EqualFold(n.Index, indexName) {
+			found = true
+		}
+	})
+	return found
+}
+
+// HasTableScan returns true if the table is fully scanned. If tableName is
+// empty, any table matches.
+func (node *PlanNode) HasTableScan(tableName string) bool {
+	var found bool
+	node.Walk(func(n *PlanNode) {
+		if n.Type == PlanTableScan && (tableName == "" || strings.EqualFold(n.Table, tableName)) {
+			found = true
+		}
+	})
+	return found
+}
+
+// String returns an indented text representation of the plan
+func (node *PlanNode) String() string {
+	var buf strings.Builder
+	node.writeTo(&buf, 0)
+	return buf.String()
+}
+
+func (node *PlanNode) writeTo(buf *strings.Builder, depth int) {
+	buf.WriteString(strings.Repeat("  ", depth))
+	buf.WriteString(node.Type)
+	if node.Table != "" {
+		buf.WriteString(" table=")
+		buf.WriteString(node.Table)
+	}
+	if node.Index != "" {
+		buf.WriteString(" index=")
+		buf.WriteString(node.Index)
+	}
+	buf.WriteString("\n")
+	for _, child := range node.Children {
+		child.writeTo(buf, depth+1)
+	}
+}
@@ -0,0 +1,40 @@
+// Copyright 2021 The Xorm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package schemas
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Sequence represents a sequence which generates the values of a column
+type Sequence struct {
+	Name      string
+	Start     int64
+	Increment int64
+}
+
+// NewSequence creates a sequence which starts from 1
+func NewSequence(name string) *Sequence {
+	return &Sequence{Name: name, Start: 1, Increment: 1}
+}
+
+// SequenceName returns the default name of the sequence of the column, i.e.
+// SEQ_<table>_<column>
+func SequenceName(tableName, colName string) string {
+	tableParts := strings.Split(strings.Replace(tableName, `"`, "", -1), ".")
+	return fmt.Sprintf("SEQ_%v_%v", tableParts[len(tableParts)-1], colName)
+}
+
+// SequenceColumns returns the columns whose values are generated by sequences
+func (table *Table) SequenceColumns() []*Column {
+	var cols []*Column
+	for _, col := range table.Columns() {
+		if col.Sequence != "" {
+			cols = append(cols, col)
+		}
+	}
+	return cols
+}
@@ -18,6 +18,7 @@ type Table struct {
 	columnsMap    map[string][]*Column
 	columns       []*Column
 	Indexes       map[string]*Index
+	Checks        map[string]*Check
 	PrimaryKeys   []string
 	AutoIncrement string
 	Created       map[string]bool
@@ -27,6 +28,8 @@ type Table struct {
 	StoreEngine   string
 	Charset       string
 	Comment       string
+	IsView        bool
+	Materialized  bool
 }
 
 // NewEmptyTable creates an empty table
@@ -41,6 +44,7 @@ func NewTable(name string, t reflect.Type) *Table {
 		columns:     make([]*Column, 0),
 		columnsMap:  make(map[string][]*Column),
 		Indexes:     make(map[string]*Index),
+		Checks:      make(map[string]*Check),
 		Created:     make(map[string]bool),
 		PrimaryKeys: make([]string, 0),
 	}
@@ -115,6 +119,17 @@ func (table *Table) DeletedColumn() *Column {
 	return table.GetColumn(table.Deleted)
 }
 
+// SensitiveColumns returns the names of the columns tagged sensitive
+func (table *Table) SensitiveColumns() []string {
+	var cols []string
+	for _, col := range table.columns {
+		if col.IsSensitive {
+			cols = append(cols, col.Name)
+		}
+	}
+	return cols
+}
+
 // AddColumn adds a column to table
 func (table *Table) AddColumn(col *Column) {
 	table.columnsSeq = append(table.columnsSeq, col.Name)
@@ -151,6 +166,11 @@ func (table *Table) AddIndex(index *Index) {
 	table.Indexes[index.Name] = index
 }
 
+// AddCheck adds a check constraint to the table
+func (table *Table) AddCheck(check *Check) {
+	table.Checks[check.Name] = check
+}
+
 // IDOfV get id from one value of struct
 func (table *Table) IDOfV(rv reflect.Value) (PK, error) {
 	v := reflect.Indirect(rv)
@@ -102,3 +102,58 @@ func BenchmarkGetColumnIdx(b *testing.B) {
 		}
 	}
 }
+
+func TestCheckXName(t *testing.T) {
+	var kases = []struct {
+		check     *Check
+		tableName string
+		xname     string
+	}{
+		{NewCheck("age", "age > 0"), "user", "CHK_user_age"},
+		{NewCheck("age", "age > 0"), `"public"."user"`, "CHK_user_age"},
+		{NewCheck("CHK_custom", "age > 0"), "user", "CHK_custom"},
+	}
+	for _, kase := range kases {
+		if xname := kase.check.XName(kase.tableName); xname != kase.xname {
+			t.Errorf("XName of %s on %s should be %s but %s", kase.check.Name, kase.tableName, kase.xname, xname)
+		}
+	}
+}
+
+func TestParseIndexKey(t *testing.T) {
+	var kases = []struct {
+		key      string
+		indexKey IndexKey
+	}{
+		{"name", IndexKey{Name: "name"}},
+		{"name DESC", IndexKey{Name: "name", Desc: true}},
+		{"name asc", IndexKey{Name: "name"}},
+		{"lower(name) desc", IndexKey{Name: "lower(name)", IsExpr: true, Desc: true}},
+		{"(a + b)", IndexKey{Name: "(a + b)", IsExpr: true}},
+		{"idx-name", IndexKey{Name: "idx-name"}},
+		{"my name", IndexKey{Name: "my name"}},
+	}
+	for _, kase := range kases {
+		if indexKey := ParseIndexKey(kase.key); indexKey != kase.indexKey {
+			t.Errorf("%s should be parsed as %v but %v", kase.key, kase.indexKey, indexKey)
+		}
+	}
+}
+
+func TestIndexEqual(t *testing.T) {
+	var kases = []struct {
+		index, dst *Index
+		equal      bool
+	}{
+		{&Index{Name: "a", Cols: []string{"a DESC", "b"}}, &Index{Name: "a", Cols: []string{"b", "a"}}, true},
+		{&Index{Name: "a", Cols: []string{"a"}}, &Index{Name: "a", Cols: []string{"b"}}, false},
+		{&Index{Name: "a", Cols: []string{"lower(a)"}}, &Index{Name: "a", Cols: []string{"lower((a)::text)"}}, true},
+		{&Index{Name: "a", Cols: []string{"lower(a)"}}, &Index{Name: "b", Cols: []string{"lower(a)"}}, false},
+		{&Index{Name: "a", Cols: []string{"a"}, Where: "b > 0"}, &Index{Name: "a", Cols: []string{"a"}}, false},
+	}
+	for i, kase := range kases {
+		if kase.index.Equal(kase.dst) != kase.equal {
+			t.Errorf("the equality of case %d should be %v", i, kase.equal)
+		}
+	}
+}
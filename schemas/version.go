@@ -10,3 +10,19 @@ type Version struct {
 	Level   string
 	Edition string
 }
+
+// Major returns the first number of the version, i.e. 14 of 14.0.3048.4 or
+// 12 of Oracle Database 12c, it returns 0 if there is no number
+func (v *Version) Major() int {
+	var major int
+	var found bool
+	for _, c := range v.Number {
+		if c >= '0' && c <= '9' {
+			major = major*10 + int(c-'0')
+			found = true
+		} else if found {
+			break
+		}
+	}
+	return major
+}
@@ -0,0 +1,18 @@
+// Copyright 2021 The Xorm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package schemas
+
+// View represents a struct mapped to a database view, ViewSQL returns the
+// SELECT which defines the view, i.e. a string, a *builder.Builder or a
+// *xorm.Session
+type View interface {
+	ViewSQL() interface{}
+}
+
+// MaterializedView represents a struct mapped to a materialized view
+type MaterializedView interface {
+	View
+	Materialized() bool
+}
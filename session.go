@@ -21,6 +21,7 @@ import (
 	"github.com/xorm-io/xorm/contexts"
 	"github.com/xorm-io/xorm/convert"
 	"github.com/xorm-io/xorm/core"
+	"github.com/xorm-io/xorm/dialects"
 	"github.com/xorm-io/xorm/internal/json"
 	"github.com/xorm-io/xorm/internal/statements"
 	"github.com/xorm-io/xorm/log"
@@ -84,6 +85,19 @@ type Session struct {
 	lastSQL     string
 	lastSQLArgs []interface{}
 
+	dryRun     bool
+	dryRunSQLs []SQLStatement
+
+	auditing  bool
+	auditLogs []*AuditLog
+
+	tracking      bool
+	snapshots     map[string]map[string]interface{}
+	snapshotTable string // the table of the found beans, the statement is reset before they're read
+
+	// the cache invalidations buffered until the transaction is committed
+	txCacheInvalidations []func()
+
 	ctx         context.Context
 	sessionType sessionType
 }
@@ -136,6 +150,9 @@ func newSession(engine *Engine) *Session {
 
 		sessionType: engineSession,
 	}
+	session.statement.Sharders = engine.sharders
+	session.statement.DetectVersion = engine.detectVersion
+	session.statement.SetSchema(dialects.SchemaFromContext(ctx))
 	if engine.logSessionID {
 		session.ctx = context.WithValue(session.ctx, log.SessionKey, session)
 	}
@@ -160,6 +177,8 @@ func (session *Session) Close() error {
 		}
 		session.tx = nil
 		session.stmtCache = nil
+		session.snapshots = nil
+		session.snapshotTable = ""
 		session.isClosed = true
 	}
 	return nil
@@ -341,10 +360,14 @@ func (session *Session) canCache() bool {
 	if session.statement.RefTable == nil ||
 		session.statement.JoinStr != "" ||
 		session.statement.RawSQL != "" ||
+		session.statement.HasSubQuery() ||
 		!session.statement.UseCache ||
 		session.statement.IsForUpdate ||
-		session.tx != nil ||
-		len(session.statement.SelectStr) > 0 {
+		!session.isAutoCommit ||
+		session.dryRun ||
+		session.tracking ||
+		len(session.statement.SelectStr) > 0 ||
+		len(session.statement.Aggregates) > 0 {
 		return false
 	}
 	return true
@@ -473,6 +496,28 @@ func (session *Session) slice2Bean(scanResults []interface{}, fields []string, b
 			continue
 		}
 
+		if col := table.GetColumnIdx(key, idx); col != nil && col.IsEncrypted {
+			data, err := value2Bytes(&rawValue)
+			if err != nil {
+				return nil, err
+			}
+			plaintext, err := convert.Decrypt(session.engine.tagParser.KeyProvider(), col.EncryptKey, table.Name, col.Name, string(data))
+			if err != nil {
+				return nil, err
+			}
+			if err := session.bytes2Value(col, fieldValue, plaintext); err != nil {
+				return nil, err
+			}
+			continue
+		}
+
+		if ok, err := session.convertFromDB(fieldValue, rawValue.Interface()); ok {
+			if err != nil {
+				return nil, err
+			}
+			continue
+		}
+
 		if fieldValue.CanAddr() {
 			if structConvert, ok := fieldValue.Addr().Interface().(convert.Conversion); ok {
 				if data, err := value2Bytes(&rawValue); err == nil {
@@ -507,6 +552,26 @@ func (session *Session) slice2Bean(scanResults []interface{}, fields []string, b
 		fieldType := fieldValue.Type()
 		hasAssigned := false
 
+		if col.SQLType.IsArray() && fieldType.Kind() == reflect.Slice {
+			var s string
+			if rawValueType.Kind() == reflect.String {
+				s = vv.String()
+			} else if rawValueType.ConvertibleTo(schemas.BytesType) {
+				s = string(vv.Bytes())
+			} else {
+				return nil, fmt.Errorf("unsupported database data type: %s %v", key, rawValueType.Kind())
+			}
+
+			dbTZ := session.engine.DatabaseTZ
+			if col.TimeZone != nil {
+				dbTZ = col.TimeZone
+			}
+			if err := dialects.ParseArray(s, dbTZ, *fieldValue); err != nil {
+				return nil, err
+			}
+			continue
+		}
+
 		if col.IsJSON {
 			var bs []byte
 			if rawValueType.Kind() == reflect.String {
@@ -870,6 +935,9 @@ func (session *Session) slice2Bean(scanResults []interface{}, fields []string, b
 			}
 		}
 	}
+	if err := session.takeSnapshot(table, bean); err != nil {
+		return nil, err
+	}
 	return pk, nil
 }
 
@@ -902,9 +970,17 @@ func (session *Session) incrVersionFieldValue(fieldValue *reflect.Value) {
 // Context sets the context on this session
 func (session *Session) Context(ctx context.Context) *Session {
 	session.ctx = ctx
+	session.statement.SetSchema(dialects.SchemaFromContext(ctx))
 	return session
 }
 
+// Schema qualifies the tables of the session by the schema instead of the
+// schema of the engine, so that an engine could serve the tenants of many
+// schemas. It's the database of MySQL and the attached database of SQLite.
+func (session *Session) Schema(schema string) *Session {
+	return session.Context(dialects.WithSchema(session.ctx, schema))
+}
+
 // PingContext test if database is ok
 func (session *Session) PingContext(ctx context.Context) error {
 	if session.isAutoClose {
@@ -78,8 +78,35 @@ func (session *Session) byte2Time(col *schemas.Column, data []byte) (outTime tim
 	return session.str2Time(col, string(data))
 }
 
+// convertFromDB converts the value read from database with the registered
+// converter of the field type, ok is false if there is no such converter
+func (session *Session) convertFromDB(fieldValue *reflect.Value, src interface{}) (ok bool, err error) {
+	converter := session.engine.tagParser.Converter(fieldValue.Type())
+	if converter == nil {
+		return false, nil
+	}
+	fieldType := fieldValue.Type()
+	if fieldType.Kind() == reflect.Ptr {
+		fieldType = fieldType.Elem()
+	}
+	v := reflect.New(fieldType)
+	if err := converter.FromDB(src, v.Interface()); err != nil {
+		return true, err
+	}
+	if fieldValue.Kind() == reflect.Ptr {
+		fieldValue.Set(v)
+	} else {
+		fieldValue.Set(v.Elem())
+	}
+	return true, nil
+}
+
 // convert a db data([]byte) to a field value
 func (session *Session) bytes2Value(col *schemas.Column, fieldValue *reflect.Value, data []byte) error {
+	if ok, err := session.convertFromDB(fieldValue, data); ok {
+		return err
+	}
+
 	if structConvert, ok := fieldValue.Addr().Interface().(convert.Conversion); ok {
 		return structConvert.FromDB(data)
 	}
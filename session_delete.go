@@ -23,7 +23,7 @@ var (
 
 func (session *Session) cacheDelete(table *schemas.Table, tableName, sqlStr string, args ...interface{}) error {
 	if table == nil ||
-		session.tx != nil {
+		session.dryRun {
 		return ErrCacheFailed
 	}
 
@@ -36,7 +36,7 @@ func (session *Session) cacheDelete(table *schemas.Table, tableName, sqlStr stri
 		return ErrCacheFailed
 	}
 
-	cacher := session.engine.cacherMgr.GetCacher(tableName)
+	cacher := session.cacher(tableName)
 	pkColumns := table.PKColumns()
 	ids, err := caches.GetCacheSql(cacher, tableName, newsql, args)
 	if err != nil {
@@ -88,6 +88,15 @@ func (session *Session) Delete(bean interface{}) (int64, error) {
 		defer session.Close()
 	}
 
+	if session.needAudit(bean) {
+		return session.audit(func() (int64, error) {
+			return session.delete(bean)
+		})
+	}
+	return session.delete(bean)
+}
+
+func (session *Session) delete(bean interface{}) (int64, error) {
 	if session.statement.LastError != nil {
 		return 0, session.statement.LastError
 	}
@@ -95,6 +104,9 @@ func (session *Session) Delete(bean interface{}) (int64, error) {
 	if err := session.statement.SetRefBean(bean); err != nil {
 		return 0, err
 	}
+	if session.statement.RefTable.IsView {
+		return 0, ErrViewReadOnly
+	}
 
 	executeBeforeClosures(session, bean)
 
@@ -114,11 +126,9 @@ func (session *Session) Delete(bean interface{}) (int64, error) {
 	var tableNameNoQuote = session.statement.TableName()
 	var tableName = session.engine.Quote(tableNameNoQuote)
 	var table = session.statement.RefTable
-	var deleteSQL string
+	var deleteSQL = fmt.Sprintf("DELETE FROM %v", tableName)
 	if len(condSQL) > 0 {
-		deleteSQL = fmt.Sprintf("DELETE FROM %v WHERE %v", tableName, condSQL)
-	} else {
-		deleteSQL = fmt.Sprintf("DELETE FROM %v", tableName)
+		deleteSQL += " WHERE " + condSQL
 	}
 
 	var orderSQL string
@@ -130,27 +140,22 @@ func (session *Session) Delete(bean interface{}) (int64, error) {
 		orderSQL += fmt.Sprintf(" LIMIT %d", limitNValue)
 	}
 
-	if len(orderSQL) > 0 {
-		switch session.engine.dialect.URI().DBType {
-		case schemas.POSTGRES:
-			inSQL := fmt.Sprintf("ctid IN (SELECT ctid FROM %s%s)", tableName, orderSQL)
-			if len(condSQL) > 0 {
-				deleteSQL += " AND " + inSQL
-			} else {
-				deleteSQL += " WHERE " + inSQL
-			}
-		case schemas.SQLITE:
-			inSQL := fmt.Sprintf("rowid IN (SELECT rowid FROM %s%s)", tableName, orderSQL)
-			if len(condSQL) > 0 {
-				deleteSQL += " AND " + inSQL
-			} else {
-				deleteSQL += " WHERE " + inSQL
-			}
-			// TODO: how to handle delete limit on mssql?
-		case schemas.MSSQL:
-			return 0, ErrNotImplemented
-		default:
-			deleteSQL += orderSQL
+	deleteSQL, err = session.limitDeleteSQL(deleteSQL, tableName, condSQL, orderSQL)
+	if err != nil {
+		return 0, err
+	}
+
+	var auditRows []map[string]interface{}
+	if session.auditing {
+		var auditSQL = fmt.Sprintf("SELECT * FROM %v", tableName)
+		if len(condSQL) > 0 {
+			auditSQL += " WHERE " + condSQL
+		}
+		if auditSQL, err = session.limitDeleteSQL(auditSQL, tableName, condSQL, orderSQL); err != nil {
+			return 0, err
+		}
+		if auditRows, err = session.auditRows(bean, auditSQL, condArgs); err != nil {
+			return 0, err
 		}
 	}
 
@@ -171,28 +176,9 @@ func (session *Session) Delete(bean interface{}) (int64, error) {
 			session.engine.Quote(deletedColumn.Name),
 			condSQL)
 
-		if len(orderSQL) > 0 {
-			switch session.engine.dialect.URI().DBType {
-			case schemas.POSTGRES:
-				inSQL := fmt.Sprintf("ctid IN (SELECT ctid FROM %s%s)", tableName, orderSQL)
-				if len(condSQL) > 0 {
-					realSQL += " AND " + inSQL
-				} else {
-					realSQL += " WHERE " + inSQL
-				}
-			case schemas.SQLITE:
-				inSQL := fmt.Sprintf("rowid IN (SELECT rowid FROM %s%s)", tableName, orderSQL)
-				if len(condSQL) > 0 {
-					realSQL += " AND " + inSQL
-				} else {
-					realSQL += " WHERE " + inSQL
-				}
-				// TODO: how to handle delete limit on mssql?
-			case schemas.MSSQL:
-				return 0, ErrNotImplemented
-			default:
-				realSQL += orderSQL
-			}
+		realSQL, err = session.limitDeleteSQL(realSQL, tableName, condSQL, orderSQL)
+		if err != nil {
+			return 0, err
 		}
 
 		// !oinume! Insert nowTime to the head of session.statement.Params
@@ -210,7 +196,7 @@ func (session *Session) Delete(bean interface{}) (int64, error) {
 		})
 	}
 
-	if cacher := session.engine.GetCacher(tableNameNoQuote); cacher != nil && session.statement.UseCache {
+	if cacher := session.cacher(tableNameNoQuote); cacher != nil && session.statement.UseCache {
 		session.cacheDelete(table, tableNameNoQuote, deleteSQL, argsForCache...)
 	}
 
@@ -220,6 +206,14 @@ func (session *Session) Delete(bean interface{}) (int64, error) {
 		return 0, err
 	}
 
+	if realSQL == deleteSQL {
+		session.auditDelete(table, tableNameNoQuote, auditRows)
+	} else if err := session.auditUpdate(table, tableNameNoQuote, auditRows); err != nil {
+		// a soft delete is an update of the deleted column
+		return 0, err
+	}
+	session.invalidateResults(tableNameNoQuote)
+
 	// handle after delete processors
 	if session.isAutoCommit {
 		for _, closure := range session.afterClosures {
@@ -249,3 +243,28 @@ func (session *Session) Delete(bean interface{}) (int64, error) {
 
 	return res.RowsAffected()
 }
+
+// limitDeleteSQL limits the rows of a delete, a soft delete or the query of
+// the deleted rows by the order and the limit
+func (session *Session) limitDeleteSQL(sqlStr, tableName, condSQL, orderSQL string) (string, error) {
+	if len(orderSQL) == 0 {
+		return sqlStr, nil
+	}
+
+	var inSQL string
+	switch session.engine.dialect.URI().DBType {
+	case schemas.POSTGRES:
+		inSQL = fmt.Sprintf("ctid IN (SELECT ctid FROM %s%s)", tableName, orderSQL)
+	case schemas.SQLITE:
+		inSQL = fmt.Sprintf("rowid IN (SELECT rowid FROM %s%s)", tableName, orderSQL)
+		// TODO: how to handle delete limit on mssql?
+	case schemas.MSSQL:
+		return "", ErrNotImplemented
+	default:
+		return sqlStr + orderSQL, nil
+	}
+	if len(condSQL) > 0 {
+		return sqlStr + " AND " + inSQL, nil
+	}
+	return sqlStr + " WHERE " + inSQL, nil
+}
@@ -0,0 +1,37 @@
+// Copyright 2021 The Xorm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package xorm
+
+// SQLStatement represents a SQL and its arguments
+type SQLStatement struct {
+	SQL  string
+	Args []interface{}
+}
+
+// dryRunResult is the sql.Result of a statement which is not executed
+type dryRunResult struct{}
+
+func (dryRunResult) LastInsertId() (int64, error) { return 0, nil }
+func (dryRunResult) RowsAffected() (int64, error) { return 0, nil }
+
+// DryRun makes the session record the SQLs instead of executing them. Exec
+// operations report no affected rows and queries return ErrDryRun. The cache
+// is neither read nor cleared.
+func (session *Session) DryRun() *Session {
+	session.dryRun = true
+	return session
+}
+
+// DryRunSQLs returns the SQLs recorded by the dry run session
+func (session *Session) DryRunSQLs() []SQLStatement {
+	return session.dryRunSQLs
+}
+
+func (session *Session) recordDryRun(sqlStr string, args ...interface{}) {
+	session.dryRunSQLs = append(session.dryRunSQLs, SQLStatement{
+		SQL:  sqlStr,
+		Args: args,
+	})
+}
@@ -14,16 +14,35 @@ func (session *Session) Exist(bean ...interface{}) (bool, error) {
 		return false, session.statement.LastError
 	}
 
+	if len(bean) > 0 {
+		shards, err := session.statement.QueryShards(bean[0])
+		if err != nil {
+			return false, err
+		}
+		if len(shards) > 0 {
+			return session.existShards(shards, bean[0])
+		}
+	}
+
 	sqlStr, args, err := session.statement.GenExistSQL(bean...)
 	if err != nil {
 		return false, err
 	}
+	return session.exist(sqlStr, args)
+}
 
-	rows, err := session.queryRows(sqlStr, args...)
+func (session *Session) exist(sqlStr string, args []interface{}) (bool, error) {
+	res, _, err := session.cachedRead("exist", sqlStr, args, func() (interface{}, error) {
+		rows, err := session.queryRows(sqlStr, args...)
+		if err != nil {
+			return false, err
+		}
+		defer rows.Close()
+
+		return rows.Next(), nil
+	})
 	if err != nil {
 		return false, err
 	}
-	defer rows.Close()
-
-	return rows.Next(), nil
+	return res.(bool), nil
 }
@@ -0,0 +1,67 @@
+// Copyright 2021 The Xorm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package xorm
+
+import (
+	"reflect"
+
+	"github.com/xorm-io/xorm/schemas"
+)
+
+// Explain returns the query plan of the SQL which Get(bean) will execute
+func (session *Session) Explain(bean interface{}) (*schemas.PlanNode, error) {
+	if session.isAutoClose {
+		defer session.Close()
+	}
+	defer session.resetStatement()
+	if session.statement.LastError != nil {
+		return nil, session.statement.LastError
+	}
+
+	sqlStr, args, err := session.genGetSQL(bean)
+	if err != nil {
+		return nil, err
+	}
+	return session.explain(sqlStr, args...)
+}
+
+// ExplainFind returns the query plan of the SQL which Find(rowsSlicePtr, condiBean...) will execute
+func (session *Session) ExplainFind(rowsSlicePtr interface{}, condiBean ...interface{}) (*schemas.PlanNode, error) {
+	if session.isAutoClose {
+		defer session.Close()
+	}
+	defer session.resetStatement()
+	if session.statement.LastError != nil {
+		return nil, session.statement.LastError
+	}
+
+	sqlStr, args, err := session.genFindSQL(reflect.Indirect(reflect.ValueOf(rowsSlicePtr)), condiBean...)
+	if err != nil {
+		return nil, err
+	}
+	return session.explain(sqlStr, args...)
+}
+
+// ExplainCount returns the query plan of the SQL which Count(bean...) will execute
+func (session *Session) ExplainCount(bean ...interface{}) (*schemas.PlanNode, error) {
+	if session.isAutoClose {
+		defer session.Close()
+	}
+	defer session.resetStatement()
+	if session.statement.LastError != nil {
+		return nil, session.statement.LastError
+	}
+
+	sqlStr, args, err := session.statement.GenCountSQL(bean...)
+	if err != nil {
+		return nil, err
+	}
+	return session.explain(sqlStr, args...)
+}
+
+func (session *Session) explain(sqlStr string, args ...interface{}) (*schemas.PlanNode, error) {
+	session.queryPreprocess(&sqlStr, args...)
+	return session.engine.dialect.Explain(session.getQueryer(), session.sqlContext(), sqlStr, args...)
+}
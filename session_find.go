@@ -81,10 +81,70 @@ func (session *Session) find(rowsSlicePtr interface{}, condiBean ...interface{})
 	}
 
 	sliceValue := reflect.Indirect(reflect.ValueOf(rowsSlicePtr))
+	shards, err := session.findShardTables(sliceValue, condiBean...)
+	if err != nil {
+		return err
+	}
+	if len(shards) > 0 {
+		return session.findShards(sliceValue, shards, condiBean...)
+	}
+
+	sqlStr, args, err := session.genFindSQL(sliceValue, condiBean...)
+	if err != nil {
+		return err
+	}
+
+	var (
+		table            = session.statement.RefTable
+		sliceElementType = sliceValue.Type().Elem()
+	)
+	session.snapshotTable = session.statement.TableName()
+
+	if session.statement.ResultCacheTTL > 0 {
+		return session.cachedFind(table, sliceValue, sqlStr, args)
+	}
+
+	if session.statement.ColumnMap.IsEmpty() && session.canCache() {
+		if cacher := session.engine.GetCacher(session.statement.TableName()); cacher != nil &&
+			!session.statement.IsDistinct &&
+			!session.statement.GetUnscoped() {
+			err = session.cacheFind(sliceElementType, sqlStr, rowsSlicePtr, args...)
+			if err != ErrCacheFailed {
+				return err
+			}
+			err = nil // !nashtsai! reset err to nil for ErrCacheFailed
+			session.engine.logger.Warnf("Cache Find Failed")
+		}
+	}
+
+	return session.noCacheFind(table, sliceValue, sqlStr, args...)
+}
+
+// findShardTables returns the shards which Find runs on, i.e. the shards of
+// the condition bean as Count does, or all the shards of the table
+func (session *Session) findShardTables(sliceValue reflect.Value, condiBean ...interface{}) ([]string, error) {
+	if _, err := session.findRefTable(sliceValue); err != nil {
+		return nil, err
+	}
+	table := session.statement.RefTable
+	if table == nil {
+		return nil, nil
+	}
+	if len(condiBean) > 0 && condiBean[0] != nil {
+		if v := reflect.Indirect(reflect.ValueOf(condiBean[0])); v.Type() == table.Type {
+			return session.statement.QueryShards(condiBean[0])
+		}
+	}
+	return session.statement.ShardTables()
+}
+
+// findRefTable sets the table of the elements of the slice or the map which
+// Find fills unless it's set, and returns the type of the elements
+func (session *Session) findRefTable(sliceValue reflect.Value) (int, error) {
 	var isSlice = sliceValue.Kind() == reflect.Slice
 	var isMap = sliceValue.Kind() == reflect.Map
 	if !isSlice && !isMap {
-		return errors.New("needs a pointer to a slice or a map")
+		return 0, errors.New("needs a pointer to a slice or a map")
 	}
 
 	sliceElementType := sliceValue.Type().Elem()
@@ -95,7 +155,7 @@ func (session *Session) find(rowsSlicePtr interface{}, condiBean ...interface{})
 			if sliceElementType.Elem().Kind() == reflect.Struct {
 				pv := reflect.New(sliceElementType.Elem())
 				if err := session.statement.SetRefValue(pv); err != nil {
-					return err
+					return 0, err
 				}
 			} else {
 				tp = tpNonStruct
@@ -103,27 +163,38 @@ func (session *Session) find(rowsSlicePtr interface{}, condiBean ...interface{})
 		} else if sliceElementType.Kind() == reflect.Struct {
 			pv := reflect.New(sliceElementType)
 			if err := session.statement.SetRefValue(pv); err != nil {
-				return err
+				return 0, err
 			}
 		} else {
 			tp = tpNonStruct
 		}
 	}
+	return tp, nil
+}
+
+// genFindSQL generates the SQL and args which Find will execute
+func (session *Session) genFindSQL(sliceValue reflect.Value, condiBean ...interface{}) (string, []interface{}, error) {
+	tp, err := session.findRefTable(sliceValue)
+	if err != nil {
+		return "", nil, err
+	}
 
 	var (
-		table          = session.statement.RefTable
-		addedTableName = (len(session.statement.JoinStr) > 0)
-		autoCond       builder.Cond
+		isMap            = sliceValue.Kind() == reflect.Map
+		sliceElementType = sliceValue.Type().Elem()
+		table            = session.statement.RefTable
+		addedTableName   = (len(session.statement.JoinStr) > 0)
+		autoCond         builder.Cond
 	)
 	if tp == tpStruct {
 		if !session.statement.NoAutoCondition && len(condiBean) > 0 {
 			condTable, err := session.engine.tagParser.Parse(reflect.ValueOf(condiBean[0]))
 			if err != nil {
-				return err
+				return "", nil, err
 			}
 			autoCond, err = session.statement.BuildConds(condTable, condiBean[0], true, true, false, true, addedTableName)
 			if err != nil {
-				return err
+				return "", nil, err
 			}
 		} else {
 			if col := table.DeletedColumn(); col != nil && !session.statement.GetUnscoped() { // tag "deleted" is enabled
@@ -132,6 +203,25 @@ func (session *Session) find(rowsSlicePtr interface{}, condiBean ...interface{})
 		}
 	}
 
+	// select the columns of the joined tables for the nested struct fields
+	if tp == tpStruct && addedTableName && session.statement.SelectStr == "" &&
+		session.statement.ColumnMap.IsEmpty() && len(session.statement.Aggregates) == 0 {
+		if sliceElementType.Kind() == reflect.Ptr {
+			sliceElementType = sliceElementType.Elem()
+		}
+		if sliceElementType.Kind() == reflect.Struct {
+			resultTable, err := session.engine.tagParser.ParseWithCache(reflect.New(sliceElementType).Elem())
+			if err != nil {
+				return "", nil, err
+			}
+			selectStr, err := session.genJoinedSelect(resultTable)
+			if err != nil {
+				return "", nil, err
+			}
+			session.statement.SelectStr = selectStr
+		}
+	}
+
 	// if it's a map with Cols but primary key not in column list, we still need the primary key
 	if isMap && !session.statement.ColumnMap.IsEmpty() {
 		for _, k := range session.statement.RefTable.PrimaryKeys {
@@ -139,25 +229,7 @@ func (session *Session) find(rowsSlicePtr interface{}, condiBean ...interface{})
 		}
 	}
 
-	sqlStr, args, err := session.statement.GenFindSQL(autoCond)
-	if err != nil {
-		return err
-	}
-
-	if session.statement.ColumnMap.IsEmpty() && session.canCache() {
-		if cacher := session.engine.GetCacher(session.statement.TableName()); cacher != nil &&
-			!session.statement.IsDistinct &&
-			!session.statement.GetUnscoped() {
-			err = session.cacheFind(sliceElementType, sqlStr, rowsSlicePtr, args...)
-			if err != ErrCacheFailed {
-				return err
-			}
-			err = nil // !nashtsai! reset err to nil for ErrCacheFailed
-			session.engine.logger.Warnf("Cache Find Failed")
-		}
-	}
-
-	return session.noCacheFind(table, sliceValue, sqlStr, args...)
+	return session.statement.GenFindSQL(autoCond)
 }
 
 func (session *Session) noCacheFind(table *schemas.Table, containerValue reflect.Value, sqlStr string, args ...interface{}) error {
@@ -241,7 +313,15 @@ func (session *Session) noCacheFind(table *schemas.Table, containerValue reflect
 		if err != nil {
 			return err
 		}
-		err = session.rows2Beans(rows, fields, tb, newElemFunc, containerValueSetFunc)
+		joined, err := session.resultJoinedFields(tb, fields)
+		if err != nil {
+			return err
+		}
+		if len(joined) > 0 {
+			err = session.rows2JoinedBeans(rows, fields, tb, joined, newElemFunc, containerValueSetFunc)
+		} else {
+			err = session.rows2Beans(rows, fields, tb, newElemFunc, containerValueSetFunc)
+		}
 		rows.Close()
 		if err != nil {
 			return err
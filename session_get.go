@@ -38,40 +38,18 @@ func (session *Session) get(bean interface{}) (bool, error) {
 	}
 
 	beanValue := reflect.ValueOf(bean)
-	if beanValue.Kind() != reflect.Ptr {
-		return false, errors.New("needs a pointer to a value")
-	} else if beanValue.Elem().Kind() == reflect.Ptr {
-		return false, errors.New("a pointer to a pointer is not allowed")
-	} else if beanValue.IsNil() {
-		return false, ErrObjectIsNil
-	}
-
-	if beanValue.Elem().Kind() == reflect.Struct {
-		if err := session.statement.SetRefBean(bean); err != nil {
-			return false, err
-		}
+	sqlStr, args, err := session.genGetSQL(bean)
+	if err != nil {
+		return false, err
 	}
 
-	var sqlStr string
-	var args []interface{}
-	var err error
+	table := session.statement.RefTable
+	session.snapshotTable = session.statement.TableName()
 
-	if session.statement.RawSQL == "" {
-		if len(session.statement.TableName()) <= 0 {
-			return false, ErrTableNotFound
-		}
-		session.statement.Limit(1)
-		sqlStr, args, err = session.statement.GenGetSQL(bean)
-		if err != nil {
-			return false, err
-		}
-	} else {
-		sqlStr = session.statement.GenRawSQL()
-		args = session.statement.RawParams
+	if session.statement.ResultCacheTTL > 0 {
+		return session.cachedGet(table, bean, sqlStr, args)
 	}
 
-	table := session.statement.RefTable
-
 	if session.statement.ColumnMap.IsEmpty() && session.canCache() && beanValue.Elem().Kind() == reflect.Struct {
 		if cacher := session.engine.GetCacher(session.statement.TableName()); cacher != nil &&
 			!session.statement.GetUnscoped() {
@@ -108,6 +86,33 @@ func (session *Session) get(bean interface{}) (bool, error) {
 	return true, nil
 }
 
+// genGetSQL generates the SQL and args which Get will execute
+func (session *Session) genGetSQL(bean interface{}) (string, []interface{}, error) {
+	beanValue := reflect.ValueOf(bean)
+	if beanValue.Kind() != reflect.Ptr {
+		return "", nil, errors.New("needs a pointer to a value")
+	} else if beanValue.Elem().Kind() == reflect.Ptr {
+		return "", nil, errors.New("a pointer to a pointer is not allowed")
+	} else if beanValue.IsNil() {
+		return "", nil, ErrObjectIsNil
+	}
+
+	if beanValue.Elem().Kind() == reflect.Struct {
+		if err := session.statement.SetRefBean(bean); err != nil {
+			return "", nil, err
+		}
+	}
+
+	if session.statement.RawSQL == "" {
+		if len(session.statement.TableName()) <= 0 {
+			return "", nil, ErrTableNotFound
+		}
+		session.statement.Limit(1)
+		return session.statement.GenGetSQL(bean)
+	}
+	return session.statement.GenRawSQL(), session.statement.RawParams, nil
+}
+
 func (session *Session) nocacheGet(beanKind reflect.Kind, table *schemas.Table, bean interface{}, sqlStr string, args ...interface{}) (bool, error) {
 	rows, err := session.queryRows(sqlStr, args...)
 	if err != nil {
@@ -351,6 +356,11 @@ func (session *Session) cacheGet(bean interface{}, sqlStr string, args ...interf
 				return has, err
 			}
 
+			// cache a copy so that the changes of the bean are not cached
+			cacheBeanValue := reflect.New(structValue.Type())
+			cacheBeanValue.Elem().Set(structValue)
+			cacheBean = cacheBeanValue.Interface()
+
 			session.engine.logger.Debugf("[cache] cache bean: %s, %v, %v", tableName, id, cacheBean)
 			cacher.PutBean(tableName, sid, cacheBean)
 		} else {
@@ -22,13 +22,22 @@ var ErrNoElementsOnSlice = errors.New("No element on slice when insert")
 
 // Insert insert one or more beans
 func (session *Session) Insert(beans ...interface{}) (int64, error) {
-	var affected int64
-	var err error
-
 	if session.isAutoClose {
 		defer session.Close()
 	}
 
+	if session.needAudit(beans...) {
+		return session.audit(func() (int64, error) {
+			return session.insert(beans...)
+		})
+	}
+	return session.insert(beans...)
+}
+
+func (session *Session) insert(beans ...interface{}) (int64, error) {
+	var affected int64
+	var err error
+
 	session.autoResetStatement = false
 	defer func() {
 		session.autoResetStatement = true
@@ -42,6 +51,7 @@ func (session *Session) Insert(beans ...interface{}) (int64, error) {
 			if err != nil {
 				return affected, err
 			}
+			session.auditInsertMap(bean.(map[string]interface{}))
 			affected += cnt
 		case []map[string]interface{}:
 			s := bean.([]map[string]interface{})
@@ -50,6 +60,7 @@ func (session *Session) Insert(beans ...interface{}) (int64, error) {
 				if err != nil {
 					return affected, err
 				}
+				session.auditInsertMap(s[i])
 				affected += cnt
 			}
 		case map[string]string:
@@ -57,6 +68,7 @@ func (session *Session) Insert(beans ...interface{}) (int64, error) {
 			if err != nil {
 				return affected, err
 			}
+			session.auditInsertMapString(bean.(map[string]string))
 			affected += cnt
 		case []map[string]string:
 			s := bean.([]map[string]string)
@@ -65,6 +77,7 @@ func (session *Session) Insert(beans ...interface{}) (int64, error) {
 				if err != nil {
 					return affected, err
 				}
+				session.auditInsertMapString(s[i])
 				affected += cnt
 			}
 		default:
@@ -79,12 +92,18 @@ func (session *Session) Insert(beans ...interface{}) (int64, error) {
 				if err != nil {
 					return affected, err
 				}
+				if err := session.auditInsert(bean); err != nil {
+					return affected, err
+				}
 				affected += cnt
 			} else {
 				cnt, err := session.innerInsert(bean)
 				if err != nil {
 					return affected, err
 				}
+				if err := session.auditInsert(bean); err != nil {
+					return affected, err
+				}
 				affected += cnt
 			}
 		}
@@ -103,9 +122,20 @@ func (session *Session) innerInsertMulti(rowsSlicePtr interface{}) (int64, error
 		return 0, errors.New("could not insert a empty slice")
 	}
 
-	if err := session.statement.SetRefBean(sliceValue.Index(0).Interface()); err != nil {
+	if err := session.statement.SetInsertRefBean(sliceValue.Index(0).Interface()); err != nil {
 		return 0, err
 	}
+	if session.statement.RefTable.IsView {
+		return 0, ErrViewReadOnly
+	}
+
+	groups, err := session.shardSlice(sliceValue)
+	if err != nil {
+		return 0, err
+	}
+	if len(groups) > 0 {
+		return session.insertShards(groups)
+	}
 
 	tableName := session.statement.TableName()
 	if len(tableName) <= 0 {
@@ -150,6 +180,11 @@ func (session *Session) innerInsertMulti(rowsSlicePtr interface{}) (int64, error
 				return 0, err
 			}
 			fieldValue := *ptrFieldValue
+			if col.Sequence != "" && utils.IsZero(fieldValue.Interface()) {
+				if err := session.setSequenceValue(col, fieldValue); err != nil {
+					return 0, err
+				}
+			}
 			if col.IsAutoIncrement && utils.IsZero(fieldValue.Interface()) {
 				continue
 			}
@@ -277,9 +312,12 @@ func (session *Session) InsertMulti(rowsSlicePtr interface{}) (int64, error) {
 }
 
 func (session *Session) innerInsert(bean interface{}) (int64, error) {
-	if err := session.statement.SetRefBean(bean); err != nil {
+	if err := session.statement.SetInsertRefBean(bean); err != nil {
 		return 0, err
 	}
+	if session.statement.RefTable.IsView {
+		return 0, ErrViewReadOnly
+	}
 	if len(session.statement.TableName()) <= 0 {
 		return 0, ErrTableNotFound
 	}
@@ -336,47 +374,9 @@ func (session *Session) innerInsert(bean interface{}) (int64, error) {
 	}
 
 	// for postgres, many of them didn't implement lastInsertId, so we should
-	// implemented it ourself.
-	if session.engine.dialect.URI().DBType == schemas.ORACLE && len(table.AutoIncrement) > 0 {
-		res, err := session.queryBytes("select seq_atable.currval from dual", args...)
-		if err != nil {
-			return 0, err
-		}
-
-		defer handleAfterInsertProcessorFunc(bean)
-
-		session.cacheInsert(tableName)
-
-		if table.Version != "" && session.statement.CheckVersion {
-			verValue, err := table.VersionColumn().ValueOf(bean)
-			if err != nil {
-				session.engine.logger.Errorf("%v", err)
-			} else if verValue.IsValid() && verValue.CanSet() {
-				session.incrVersionFieldValue(verValue)
-			}
-		}
-
-		if len(res) < 1 {
-			return 0, errors.New("insert no error but not returned id")
-		}
-
-		idByte := res[0][table.AutoIncrement]
-		id, err := strconv.ParseInt(string(idByte), 10, 64)
-		if err != nil || id <= 0 {
-			return 1, err
-		}
-
-		aiValue, err := table.AutoIncrColumn().ValueOf(bean)
-		if err != nil {
-			session.engine.logger.Errorf("%v", err)
-		}
-
-		if aiValue == nil || !aiValue.IsValid() || !aiValue.CanSet() {
-			return 1, nil
-		}
-
-		return 1, convertAssignV(aiValue.Addr(), id)
-	} else if len(table.AutoIncrement) > 0 && (session.engine.dialect.URI().DBType == schemas.POSTGRES ||
+	// implemented it ourself. The SQL of a dry run is recorded by exec since
+	// no id could be returned.
+	if len(table.AutoIncrement) > 0 && !session.dryRun && (session.engine.dialect.URI().DBType == schemas.POSTGRES ||
 		session.engine.dialect.URI().DBType == schemas.MSSQL) {
 		res, err := session.queryBytes(sqlStr, args...)
 
@@ -474,11 +474,12 @@ func (session *Session) InsertOne(bean interface{}) (int64, error) {
 }
 
 func (session *Session) cacheInsert(table string) error {
-	if !session.statement.UseCache {
+	if session.dryRun {
 		return nil
 	}
-	cacher := session.engine.cacherMgr.GetCacher(table)
-	if cacher == nil {
+	session.invalidateResults(table)
+	cacher := session.cacher(table)
+	if cacher == nil || !session.statement.UseCache {
 		return nil
 	}
 	session.engine.logger.Debugf("[cache] clear SQL: %v", table)
@@ -533,6 +534,12 @@ func (session *Session) genInsertColumns(bean interface{}) ([]string, []interfac
 		}
 		fieldValue := *fieldValuePtr
 
+		if col.Sequence != "" && utils.IsValueZero(fieldValue) {
+			if err := session.setSequenceValue(col, fieldValue); err != nil {
+				return nil, nil, err
+			}
+		}
+
 		if col.IsAutoIncrement && utils.IsValueZero(fieldValue) {
 			continue
 		}
@@ -570,6 +577,33 @@ func (session *Session) genInsertColumns(bean interface{}) ([]string, []interfac
 	return colNames, args, nil
 }
 
+// setSequenceValue assigns the next value of the sequence of the column to the
+// field, the field is kept if the dialect has no sequences
+func (session *Session) setSequenceValue(col *schemas.Column, fieldValue reflect.Value) error {
+	sqlStr := session.engine.dialect.NextSequenceSQL(session.statement.TableNameWithSchema(col.Sequence))
+	if sqlStr == "" || session.dryRun {
+		return nil
+	}
+
+	rows, err := session.getQueryer().QueryContext(session.ctx, sqlStr)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	if !rows.Next() {
+		if err := rows.Err(); err != nil {
+			return err
+		}
+		return fmt.Errorf("sequence %s returned no value", col.Sequence)
+	}
+	var id int64
+	if err := rows.Scan(&id); err != nil {
+		return err
+	}
+	return convertAssignV(fieldValue.Addr(), id)
+}
+
 func (session *Session) insertMapInterface(m map[string]interface{}) (int64, error) {
 	if len(m) == 0 {
 		return 0, ErrParamsType
@@ -579,6 +613,9 @@ func (session *Session) insertMapInterface(m map[string]interface{}) (int64, err
 	if len(tableName) <= 0 {
 		return 0, ErrTableNotFound
 	}
+	if table := session.statement.RefTable; table != nil && table.IsView {
+		return 0, ErrViewReadOnly
+	}
 
 	var columns = make([]string, 0, len(m))
 	exprs := session.statement.ExprColumns
@@ -606,6 +643,9 @@ func (session *Session) insertMapString(m map[string]string) (int64, error) {
 	if len(tableName) <= 0 {
 		return 0, ErrTableNotFound
 	}
+	if table := session.statement.RefTable; table != nil && table.IsView {
+		return 0, ErrViewReadOnly
+	}
 
 	var columns = make([]string, 0, len(m))
 	exprs := session.statement.ExprColumns
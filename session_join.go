@@ -0,0 +1,233 @@
+// Copyright 2021 The Xorm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package xorm
+
+import (
+	"reflect"
+	"strings"
+
+	"github.com/xorm-io/xorm/core"
+	"github.com/xorm-io/xorm/internal/utils"
+	"github.com/xorm-io/xorm/schemas"
+)
+
+// joinedField is a nested struct field of a join result mapped to the columns
+// of a table or an alias of the join by its column name, e.g. the field User
+// is mapped to the columns user.*
+type joinedField struct {
+	col   *schemas.Column // the column of the field in the result table
+	table *schemas.Table  // the table of the nested struct
+	alias string
+}
+
+// joinedFields returns the nested struct fields of the result table whose
+// names are one of the aliases
+func (session *Session) joinedFields(table *schemas.Table, aliases []string) ([]joinedField, error) {
+	var joined []joinedField
+	for _, col := range table.Columns() {
+		if col.IsJSON || len(col.FieldIndex) != 1 {
+			continue
+		}
+		fieldType := table.Type.Field(col.FieldIndex[0]).Type
+		if fieldType.Kind() == reflect.Ptr {
+			fieldType = fieldType.Elem()
+		}
+		if fieldType.Kind() != reflect.Struct || fieldType.ConvertibleTo(schemas.TimeType) ||
+			session.engine.tagParser.Converter(fieldType) != nil {
+			continue
+		}
+
+		var alias string
+		for _, a := range aliases {
+			if strings.EqualFold(a, col.Name) {
+				alias = a
+				break
+			}
+		}
+		if alias == "" {
+			continue
+		}
+
+		nestedTable, err := session.engine.tagParser.ParseWithCache(reflect.New(fieldType).Elem())
+		if err != nil {
+			return nil, err
+		}
+		joined = append(joined, joinedField{
+			col:   col,
+			table: nestedTable,
+			alias: alias,
+		})
+	}
+	return joined, nil
+}
+
+// joinedColumnAlias returns the alias of the column of the joined table
+func joinedColumnAlias(alias, colName string) string {
+	return alias + "." + colName
+}
+
+// genJoinedSelect returns the selected columns of the nested struct fields,
+// e.g. `user`.`id` AS `user.id`, and the other columns of the result which
+// should be the columns of the table, e.g. `team`.`name`. It's empty if there
+// is no nested field.
+func (session *Session) genJoinedSelect(table *schemas.Table) (string, error) {
+	aliases := session.statement.TableAliases()
+	joined, err := session.joinedFields(table, aliases)
+	if err != nil || len(joined) == 0 {
+		return "", err
+	}
+
+	// the first alias is the one of the table unless it's a sub query
+	var tableAlias string
+	if session.statement.TableAlias != "" || !utils.IsSubQuery(session.statement.TableName()) {
+		tableAlias = aliases[0]
+	}
+	var nestedCols = make(map[*schemas.Column]bool, len(joined))
+	for _, field := range joined {
+		nestedCols[field.col] = true
+	}
+
+	quoter := session.engine.dialect.Quoter()
+	var buf strings.Builder
+	for _, col := range table.Columns() {
+		if nestedCols[col] || col.MapType == schemas.ONLYTODB {
+			continue
+		}
+		refTable := session.statement.RefTable
+		if refTable == nil || refTable.GetColumn(col.Name) == nil {
+			return "", ErrJoinedColumn
+		}
+		if buf.Len() > 0 {
+			buf.WriteString(", ")
+		}
+		if tableAlias != "" {
+			buf.WriteString(quoter.Quote(tableAlias))
+			buf.WriteString(".")
+		}
+		buf.WriteString(quoter.Quote(col.Name))
+	}
+	for _, field := range joined {
+		for _, col := range field.table.Columns() {
+			if col.MapType == schemas.ONLYTODB {
+				continue
+			}
+			if buf.Len() > 0 {
+				buf.WriteString(", ")
+			}
+			buf.WriteString(quoter.Quote(field.alias))
+			buf.WriteString(".")
+			buf.WriteString(quoter.Quote(col.Name))
+			buf.WriteString(" AS ")
+			// the alias is quoted as a whole since it contains a dot
+			buf.WriteByte(quoter.Prefix)
+			buf.WriteString(joinedColumnAlias(field.alias, col.Name))
+			buf.WriteByte(quoter.Suffix)
+		}
+	}
+	return buf.String(), nil
+}
+
+// resultJoinedFields returns the nested struct fields of the result table
+// which have columns in fields
+func (session *Session) resultJoinedFields(table *schemas.Table, fields []string) ([]joinedField, error) {
+	var aliases []string
+	var visited = make(map[string]bool)
+	for _, field := range fields {
+		if idx := strings.Index(field, "."); idx > 0 && !visited[field[:idx]] {
+			visited[field[:idx]] = true
+			aliases = append(aliases, field[:idx])
+		}
+	}
+	if len(aliases) == 0 {
+		return nil, nil
+	}
+	return session.joinedFields(table, aliases)
+}
+
+// rows2JoinedBeans scans the rows into the results whose nested struct fields
+// are set by the columns prefixed by their aliases. The pointer fields are
+// kept nil if all their columns are null, e.g. the misses of LEFT JOIN.
+func (session *Session) rows2JoinedBeans(rows *core.Rows, fields []string, table *schemas.Table,
+	joined []joinedField, newElemFunc func([]string) reflect.Value,
+	sliceValueSetFunc func(*reflect.Value, schemas.PK) error) error {
+	var (
+		nestedIdxes = make([][]int, len(joined))
+		nestedNames = make([][]string, len(joined))
+		restIdxes   []int
+		restFields  []string
+	)
+	for i, field := range fields {
+		var found bool
+		for j, f := range joined {
+			prefix := f.alias + "."
+			if len(field) > len(prefix) && strings.EqualFold(field[:len(prefix)], prefix) {
+				nestedIdxes[j] = append(nestedIdxes[j], i)
+				nestedNames[j] = append(nestedNames[j], field[len(prefix):])
+				found = true
+				break
+			}
+		}
+		if !found {
+			restIdxes = append(restIdxes, i)
+			restFields = append(restFields, field)
+		}
+	}
+
+	for rows.Next() {
+		var newValue = newElemFunc(fields)
+		bean := newValue.Interface()
+		dataStruct := newValue.Elem()
+
+		scanResults, err := session.row2Slice(rows, fields, bean)
+		if err != nil {
+			return err
+		}
+
+		for j, f := range joined {
+			var results = make([]interface{}, 0, len(nestedIdxes[j]))
+			var isNull = true
+			for _, idx := range nestedIdxes[j] {
+				results = append(results, scanResults[idx])
+				if reflect.Indirect(reflect.ValueOf(scanResults[idx])).Interface() != nil {
+					isNull = false
+				}
+			}
+			if isNull {
+				continue
+			}
+
+			fieldValue := dataStruct.Field(f.col.FieldIndex[0])
+			nestedValue := reflect.New(f.table.Type)
+			nestedStruct := nestedValue.Elem()
+			if _, err := session.slice2Bean(results, nestedNames[j], nestedValue.Interface(), &nestedStruct, f.table); err != nil {
+				return err
+			}
+			if fieldValue.Kind() == reflect.Ptr {
+				fieldValue.Set(nestedValue)
+			} else {
+				fieldValue.Set(nestedStruct)
+			}
+		}
+
+		var pk schemas.PK
+		if len(restFields) > 0 {
+			var results = make([]interface{}, 0, len(restIdxes))
+			for _, idx := range restIdxes {
+				results = append(results, scanResults[idx])
+			}
+			if pk, err = session.slice2Bean(results, restFields, bean, &dataStruct, table); err != nil {
+				return err
+			}
+		}
+		session.afterProcessors = append(session.afterProcessors, executedProcessor{
+			fun: func(*Session, interface{}) error {
+				return sliceValueSetFunc(&newValue, pk)
+			},
+			session: session,
+			bean:    bean,
+		})
+	}
+	return nil
+}
@@ -25,7 +25,13 @@ func (session *Session) Query(sqlOrArgs ...interface{}) ([]map[string][]byte, er
 		return nil, err
 	}
 
-	return session.queryBytes(sqlStr, args...)
+	res, _, err := session.cachedRead("query", sqlStr, args, func() (interface{}, error) {
+		return session.queryBytes(sqlStr, args...)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return res.([]map[string][]byte), nil
 }
 
 func value2String(rawValue *reflect.Value) (str string, err error) {
@@ -128,13 +134,19 @@ func (session *Session) QueryString(sqlOrArgs ...interface{}) ([]map[string]stri
 		return nil, err
 	}
 
-	rows, err := session.queryRows(sqlStr, args...)
+	res, _, err := session.cachedRead("query-string", sqlStr, args, func() (interface{}, error) {
+		rows, err := session.queryRows(sqlStr, args...)
+		if err != nil {
+			return nil, err
+		}
+		defer rows.Close()
+
+		return session.rows2Strings(rows)
+	})
 	if err != nil {
 		return nil, err
 	}
-	defer rows.Close()
-
-	return session.rows2Strings(rows)
+	return res.([]map[string]string), nil
 }
 
 // QuerySliceString runs a raw sql and return records as [][]string
@@ -148,13 +160,19 @@ func (session *Session) QuerySliceString(sqlOrArgs ...interface{}) ([][]string,
 		return nil, err
 	}
 
-	rows, err := session.queryRows(sqlStr, args...)
+	res, _, err := session.cachedRead("query-slice-string", sqlStr, args, func() (interface{}, error) {
+		rows, err := session.queryRows(sqlStr, args...)
+		if err != nil {
+			return nil, err
+		}
+		defer rows.Close()
+
+		return session.rows2SliceString(rows)
+	})
 	if err != nil {
 		return nil, err
 	}
-	defer rows.Close()
-
-	return session.rows2SliceString(rows)
+	return res.([][]string), nil
 }
 
 func row2mapInterface(rows *core.Rows, fields []string) (resultsMap map[string]interface{}, err error) {
@@ -201,11 +219,17 @@ func (session *Session) QueryInterface(sqlOrArgs ...interface{}) ([]map[string]i
 		return nil, err
 	}
 
-	rows, err := session.queryRows(sqlStr, args...)
+	res, _, err := session.cachedRead("query-interface", sqlStr, args, func() (interface{}, error) {
+		rows, err := session.queryRows(sqlStr, args...)
+		if err != nil {
+			return nil, err
+		}
+		defer rows.Close()
+
+		return rows2Interfaces(rows)
+	})
 	if err != nil {
 		return nil, err
 	}
-	defer rows.Close()
-
-	return rows2Interfaces(rows)
+	return res.([]map[string]interface{}), nil
 }
@@ -5,10 +5,12 @@
 package xorm
 
 import (
+	"context"
 	"database/sql"
 	"reflect"
 
 	"github.com/xorm-io/xorm/core"
+	"github.com/xorm-io/xorm/log"
 )
 
 func (session *Session) queryPreprocess(sqlStr *string, paramStr ...interface{}) {
@@ -20,6 +22,19 @@ func (session *Session) queryPreprocess(sqlStr *string, paramStr ...interface{})
 	session.lastSQLArgs = paramStr
 }
 
+// sqlContext returns the context to execute SQL with, it carries the sensitive
+// columns of the statement's table so that the logger could redact them.
+func (session *Session) sqlContext() context.Context {
+	if session.statement.RefTable == nil {
+		return session.ctx
+	}
+	cols := session.statement.RefTable.SensitiveColumns()
+	if len(cols) == 0 {
+		return session.ctx
+	}
+	return context.WithValue(session.ctx, log.SensitiveColumnsKey, cols)
+}
+
 func (session *Session) queryRows(sqlStr string, args ...interface{}) (*core.Rows, error) {
 	defer session.resetStatement()
 	if session.statement.LastError != nil {
@@ -31,6 +46,12 @@ func (session *Session) queryRows(sqlStr string, args ...interface{}) (*core.Row
 	session.lastSQL = sqlStr
 	session.lastSQLArgs = args
 
+	if session.dryRun {
+		session.recordDryRun(sqlStr, args...)
+		return nil, ErrDryRun
+	}
+
+	ctx := session.sqlContext()
 	if session.isAutoCommit {
 		var db *core.DB
 		if session.sessionType == groupSession {
@@ -46,21 +67,21 @@ func (session *Session) queryRows(sqlStr string, args ...interface{}) (*core.Row
 				return nil, err
 			}
 
-			rows, err := stmt.QueryContext(session.ctx, args...)
+			rows, err := stmt.QueryContext(ctx, args...)
 			if err != nil {
 				return nil, err
 			}
 			return rows, nil
 		}
 
-		rows, err := db.QueryContext(session.ctx, sqlStr, args...)
+		rows, err := db.QueryContext(ctx, sqlStr, args...)
 		if err != nil {
 			return nil, err
 		}
 		return rows, nil
 	}
 
-	rows, err := session.tx.QueryContext(session.ctx, sqlStr, args...)
+	rows, err := session.tx.QueryContext(ctx, sqlStr, args...)
 	if err != nil {
 		return nil, err
 	}
@@ -111,14 +132,24 @@ func (session *Session) queryBytes(sqlStr string, args ...interface{}) ([]map[st
 
 func (session *Session) exec(sqlStr string, args ...interface{}) (sql.Result, error) {
 	defer session.resetStatement()
+	if session.statement.LastError != nil {
+		return nil, session.statement.LastError
+	}
 
 	session.queryPreprocess(&sqlStr, args...)
 
 	session.lastSQL = sqlStr
 	session.lastSQLArgs = args
 
+	if session.dryRun {
+		session.recordDryRun(sqlStr, args...)
+		return dryRunResult{}, nil
+	}
+	defer session.invalidateExecResults(sqlStr)
+
+	ctx := session.sqlContext()
 	if !session.isAutoCommit {
-		return session.tx.ExecContext(session.ctx, sqlStr, args...)
+		return session.tx.ExecContext(ctx, sqlStr, args...)
 	}
 
 	if session.prepareStmt {
@@ -127,14 +158,14 @@ func (session *Session) exec(sqlStr string, args ...interface{}) (sql.Result, er
 			return nil, err
 		}
 
-		res, err := stmt.ExecContext(session.ctx, args...)
+		res, err := stmt.ExecContext(ctx, args...)
 		if err != nil {
 			return nil, err
 		}
 		return res, nil
 	}
 
-	return session.DB().ExecContext(session.ctx, sqlStr, args...)
+	return session.DB().ExecContext(ctx, sqlStr, args...)
 }
 
 // Exec raw sql
@@ -0,0 +1,270 @@
+// Copyright 2021 The Xorm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package xorm
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/xorm-io/xorm/schemas"
+)
+
+var (
+	readTablesRegexp  = regexp.MustCompile(`(?i)\b(?:FROM|JOIN)\s+([^\s,()]+)`)
+	writeTablesRegexp = regexp.MustCompile(`(?i)^\s*(?:INSERT\s+(?:IGNORE\s+)?INTO|REPLACE\s+INTO|MERGE\s+INTO|UPDATE|DELETE\s+FROM|DELETE|TRUNCATE\s+TABLE|TRUNCATE|DROP\s+TABLE\s+IF\s+EXISTS|DROP\s+TABLE|ALTER\s+TABLE)\s+([^\s,()]+)`)
+)
+
+// CacheFor caches the result of the next read of the session, i.e. Get, Find,
+// Count, Sum, Exist or Query, for the duration. The results are keyed by the
+// SQL and the args, and invalidated when one of the tables referenced by the
+// SQL is written through xorm.
+func (session *Session) CacheFor(ttl time.Duration) *Session {
+	session.statement.ResultCacheTTL = ttl
+	return session
+}
+
+// resultTableName normalizes the table name to match the read ones with the
+// written ones, the quotes and the schema are removed
+func resultTableName(name string) string {
+	name = strings.Trim(name, "`\"[]")
+	if i := strings.LastIndex(name, "."); i >= 0 {
+		name = strings.Trim(name[i+1:], "`\"[]")
+	}
+	return strings.ToLower(name)
+}
+
+// resultTables returns the tables referenced by the statement and the SQL
+func (session *Session) resultTables(sqlStr string) []string {
+	var tables []string
+	var visited = make(map[string]bool)
+	add := func(name string) {
+		name = resultTableName(name)
+		if name != "" && !visited[name] {
+			visited[name] = true
+			tables = append(tables, name)
+		}
+	}
+	if tableName := session.statement.TableName(); tableName != "" {
+		add(tableName)
+	}
+	for _, matches := range readTablesRegexp.FindAllStringSubmatch(sqlStr, -1) {
+		add(matches[1])
+	}
+	return tables
+}
+
+// cachedRead returns the cached result of the read or runs it and caches its
+// result if the result cache is enabled for the statement, hit is true if
+// the result is from the cache. The cached results are copied in and out, so
+// that they are never shared with the callers.
+func (session *Session) cachedRead(kind string, sqlStr string, args []interface{}, read func() (interface{}, error)) (result interface{}, hit bool, err error) {
+	ttl := session.statement.ResultCacheTTL
+	// the results read in a transaction may be not committed or not visible
+	// to the other sessions
+	if ttl <= 0 || session.dryRun || session.statement.IsForUpdate || !session.isAutoCommit {
+		result, err = read()
+		return result, false, err
+	}
+	tables := session.resultTables(sqlStr)
+
+	cache := session.engine.resultCache
+	key := resultCacheKey(kind, sqlStr, args)
+	if v, ok := cache.Get(key); ok {
+		session.engine.logger.Debugf("[cache] result hit: %s, %v", sqlStr, args)
+		return copyResult(v), true, nil
+	}
+
+	generation := cache.Generation()
+	result, err = read()
+	if err != nil {
+		return result, false, err
+	}
+	cache.Put(key, tables, copyResult(result), ttl, generation)
+	return result, false, nil
+}
+
+// resultCacheKey encodes the kind, the SQL and the args of a read without
+// ambiguity, the type and the value of every arg are prefixed by their
+// lengths so that i.e. the args ("a b", "c") and ("a", "b c") have different
+// keys
+func resultCacheKey(kind, sqlStr string, args []interface{}) string {
+	var buf strings.Builder
+	writeString := func(s string) {
+		buf.WriteString(strconv.Itoa(len(s)))
+		buf.WriteByte(':')
+		buf.WriteString(s)
+	}
+	writeString(kind)
+	writeString(sqlStr)
+	for _, arg := range args {
+		writeString(fmt.Sprintf("%T", arg))
+		writeString(fmt.Sprintf("%v", arg))
+	}
+	return buf.String()
+}
+
+// invalidateResults removes the cached results of the written table, it's
+// repeated on commit if the session is in a transaction since the other
+// sessions may have read the rows not committed yet
+func (session *Session) invalidateResults(tableName string) {
+	if tableName == "" || session.dryRun {
+		return
+	}
+	table := resultTableName(tableName)
+	cache := session.engine.resultCache
+	cache.Invalidate(table)
+	if !session.isAutoCommit {
+		session.txCacheInvalidations = append(session.txCacheInvalidations, func() {
+			cache.Invalidate(table)
+		})
+	}
+}
+
+// invalidateExecResults removes the cached results of the table written by a raw SQL
+func (session *Session) invalidateExecResults(sqlStr string) {
+	if matches := writeTablesRegexp.FindStringSubmatch(sqlStr); len(matches) > 1 {
+		session.invalidateResults(matches[1])
+	}
+}
+
+// copyResult returns a deep copy of the result
+func copyResult(result interface{}) interface{} {
+	if result == nil {
+		return nil
+	}
+	return copyValue(reflect.ValueOf(result)).Interface()
+}
+
+// copyValue returns a deep copy of the value, the pointers, slices, maps and
+// interfaces are copied through their types. The unexported fields of the
+// structs are shallow copied, and the pointers are expected to have no cycles
+// like the beans read from the database.
+func copyValue(v reflect.Value) reflect.Value {
+	switch v.Kind() {
+	case reflect.Ptr:
+		if v.IsNil() {
+			return v
+		}
+		res := reflect.New(v.Type().Elem())
+		res.Elem().Set(copyValue(v.Elem()))
+		return res
+	case reflect.Interface:
+		if v.IsNil() {
+			return v
+		}
+		res := reflect.New(v.Type()).Elem()
+		res.Set(copyValue(v.Elem()))
+		return res
+	case reflect.Slice:
+		if v.IsNil() {
+			return v
+		}
+		res := reflect.MakeSlice(v.Type(), v.Len(), v.Len())
+		copyElems(res, v)
+		return res
+	case reflect.Array:
+		res := reflect.New(v.Type()).Elem()
+		copyElems(res, v)
+		return res
+	case reflect.Map:
+		if v.IsNil() {
+			return v
+		}
+		res := reflect.MakeMapWithSize(v.Type(), v.Len())
+		iter := v.MapRange()
+		for iter.Next() {
+			res.SetMapIndex(iter.Key(), copyValue(iter.Value()))
+		}
+		return res
+	case reflect.Struct:
+		res := reflect.New(v.Type()).Elem()
+		res.Set(v)
+		for i := 0; i < v.NumField(); i++ {
+			if field := res.Field(i); field.CanSet() {
+				field.Set(copyValue(v.Field(i)))
+			}
+		}
+		return res
+	}
+	return v
+}
+
+// copyElems copies the elements of the slice or the array src to dst
+func copyElems(dst, src reflect.Value) {
+	switch src.Type().Elem().Kind() {
+	case reflect.Ptr, reflect.Interface, reflect.Slice, reflect.Array, reflect.Map, reflect.Struct:
+		for i := 0; i < src.Len(); i++ {
+			dst.Index(i).Set(copyValue(src.Index(i)))
+		}
+	default:
+		reflect.Copy(dst, src)
+	}
+}
+
+// getResult is the cached result of a get, the fields are exported to be
+// deep copied
+type getResult struct {
+	Has   bool
+	Value interface{}
+}
+
+// cachedGet gets the bean via the result cache
+func (session *Session) cachedGet(table *schemas.Table, bean interface{}, sqlStr string, args []interface{}) (bool, error) {
+	beanValue := reflect.ValueOf(bean)
+	res, hit, err := session.cachedRead(fmt.Sprintf("get-%v", beanValue.Type()), sqlStr, args, func() (interface{}, error) {
+		has, err := session.nocacheGet(beanValue.Elem().Kind(), table, bean, sqlStr, args...)
+		return getResult{has, beanValue.Elem().Interface()}, err
+	})
+	if err != nil || !hit {
+		return res != nil && res.(getResult).Has, err
+	}
+
+	result := res.(getResult)
+	if result.Has {
+		beanValue.Elem().Set(reflect.ValueOf(result.Value))
+		if beanValue.Elem().Kind() == reflect.Struct {
+			if err := session.takeSnapshot(table, bean); err != nil {
+				return false, err
+			}
+		}
+	}
+	return result.Has, nil
+}
+
+// cachedFind finds the beans via the result cache, the cached ones are the
+// beans appended to the slice or set to the map by the find
+func (session *Session) cachedFind(table *schemas.Table, containerValue reflect.Value, sqlStr string, args []interface{}) error {
+	var start int
+	if containerValue.Kind() == reflect.Slice {
+		start = containerValue.Len()
+	}
+	res, hit, err := session.cachedRead(fmt.Sprintf("find-%v", containerValue.Type()), sqlStr, args, func() (interface{}, error) {
+		if err := session.noCacheFind(table, containerValue, sqlStr, args...); err != nil {
+			return nil, err
+		}
+		if containerValue.Kind() == reflect.Slice {
+			return containerValue.Slice(start, containerValue.Len()).Interface(), nil
+		}
+		return containerValue.Interface(), nil
+	})
+	if err != nil || !hit {
+		return err
+	}
+
+	cached := reflect.ValueOf(res)
+	if containerValue.Kind() == reflect.Slice {
+		containerValue.Set(reflect.AppendSlice(containerValue, cached))
+		return nil
+	}
+	iter := cached.MapRange()
+	for iter.Next() {
+		containerValue.SetMapIndex(iter.Key(), iter.Value())
+	}
+	return nil
+}
@@ -6,12 +6,16 @@ package xorm
 
 import (
 	"bufio"
+	"context"
 	"database/sql"
 	"fmt"
 	"io"
 	"os"
+	"reflect"
 	"strings"
 
+	"github.com/xorm-io/xorm/core"
+	"github.com/xorm-io/xorm/dialects"
 	"github.com/xorm-io/xorm/internal/utils"
 	"github.com/xorm-io/xorm/schemas"
 )
@@ -36,18 +40,7 @@ func (session *Session) CreateTable(bean interface{}) error {
 }
 
 func (session *Session) createTable(bean interface{}) error {
-	if err := session.statement.SetRefBean(bean); err != nil {
-		return err
-	}
-
-	sqlStrs := session.statement.GenCreateTableSQL()
-	for _, s := range sqlStrs {
-		_, err := session.exec(s)
-		if err != nil {
-			return err
-		}
-	}
-	return nil
+	return session.execDDL(bean, session.statement.GenCreateTableSQL)
 }
 
 // CreateIndexes create indexes
@@ -60,18 +53,7 @@ func (session *Session) CreateIndexes(bean interface{}) error {
 }
 
 func (session *Session) createIndexes(bean interface{}) error {
-	if err := session.statement.SetRefBean(bean); err != nil {
-		return err
-	}
-
-	sqls := session.statement.GenIndexSQL()
-	for _, sqlStr := range sqls {
-		_, err := session.exec(sqlStr)
-		if err != nil {
-			return err
-		}
-	}
-	return nil
+	return session.execDDL(bean, session.statement.GenIndexSQL)
 }
 
 // CreateUniques create uniques
@@ -83,18 +65,7 @@ func (session *Session) CreateUniques(bean interface{}) error {
 }
 
 func (session *Session) createUniques(bean interface{}) error {
-	if err := session.statement.SetRefBean(bean); err != nil {
-		return err
-	}
-
-	sqls := session.statement.GenUniqueSQL()
-	for _, sqlStr := range sqls {
-		_, err := session.exec(sqlStr)
-		if err != nil {
-			return err
-		}
-	}
-	return nil
+	return session.execDDL(bean, session.statement.GenUniqueSQL)
 }
 
 // DropIndexes drop indexes
@@ -107,21 +78,45 @@ func (session *Session) DropIndexes(bean interface{}) error {
 }
 
 func (session *Session) dropIndexes(bean interface{}) error {
-	if err := session.statement.SetRefBean(bean); err != nil {
+	return session.execDDL(bean, session.statement.GenDelIndexSQL)
+}
+
+// execDDL executes the DDL generated by gen for the table of the bean, on each
+// shard if the table is sharded as Sync2 does
+func (session *Session) execDDL(bean interface{}, gen func() []string) error {
+	if err := session.statement.SetDDLRefBean(bean); err != nil {
+		return err
+	}
+	if err := dialects.CheckIndexes(session.engine.dialect, session.statement.RefTable); err != nil {
 		return err
 	}
 
-	sqls := session.statement.GenDelIndexSQL()
+	shards, err := session.statement.ShardTables()
+	if err != nil {
+		return err
+	}
+
+	// the SQLs are generated before any of them is executed, which resets the
+	// statement
+	var sqls []string
+	if len(shards) == 0 {
+		sqls = gen()
+	}
+	for _, shard := range shards {
+		session.statement.AltTableName = shard
+		sqls = append(sqls, gen()...)
+		session.statement.AltTableName = ""
+	}
 	for _, sqlStr := range sqls {
-		_, err := session.exec(sqlStr)
-		if err != nil {
+		if _, err := session.exec(sqlStr); err != nil {
 			return err
 		}
 	}
 	return nil
 }
 
-// DropTable drop table will drop table if exist, if drop failed, it will return error
+// DropTable drop table will drop table if exist, if drop failed, it will return error.
+// The sequences of the columns of the bean are dropped too.
 func (session *Session) DropTable(beanOrTableName interface{}) error {
 	if session.isAutoClose {
 		defer session.Close()
@@ -132,7 +127,7 @@ func (session *Session) DropTable(beanOrTableName interface{}) error {
 
 func (session *Session) dropTable(beanOrTableName interface{}) error {
 	tableName := session.engine.TableName(beanOrTableName)
-	sqlStr, checkIfExist := session.engine.dialect.DropTableSQL(session.engine.TableName(tableName, true))
+	sqlStr, checkIfExist := session.engine.dialect.DropTableSQL(session.statement.TableNameWithSchema(tableName))
 	if !checkIfExist {
 		exist, err := session.engine.dialect.IsTableExist(session.getQueryer(), session.ctx, tableName)
 		if err != nil {
@@ -142,9 +137,43 @@ func (session *Session) dropTable(beanOrTableName interface{}) error {
 	}
 
 	if checkIfExist {
-		_, err := session.exec(sqlStr)
+		if _, err := session.exec(sqlStr); err != nil {
+			return err
+		}
+	}
+	return session.dropSequences(beanOrTableName)
+}
+
+// dropSequences drops the existing sequences of the columns of the bean, the
+// sequences of a table name are unknown
+func (session *Session) dropSequences(beanOrTableName interface{}) error {
+	v := utils.ReflectValue(beanOrTableName)
+	if v.Kind() != reflect.Struct {
+		return nil
+	}
+	table, err := session.engine.tagParser.ParseWithCache(v)
+	if err != nil {
 		return err
 	}
+	cols := table.SequenceColumns()
+	if len(cols) == 0 {
+		return nil
+	}
+
+	engine := session.engine
+	seqs, err := engine.dialect.GetSequences(session.getQueryer(), session.ctx)
+	if err != nil {
+		return err
+	}
+	for _, col := range cols {
+		sql := engine.dialect.DropSequenceSQL(session.statement.TableNameWithSchema(col.Sequence))
+		if sql == "" || !containsFold(seqs, col.Sequence) {
+			continue
+		}
+		if _, err := session.exec(sql); err != nil {
+			return err
+		}
+	}
 	return nil
 }
 
@@ -160,6 +189,9 @@ func (session *Session) IsTableExist(beanOrTableName interface{}) (bool, error)
 }
 
 func (session *Session) isTableExist(tableName string) (bool, error) {
+	if session.statement.LastError != nil {
+		return false, session.statement.LastError
+	}
 	return session.engine.dialect.IsTableExist(session.getQueryer(), session.ctx, tableName)
 }
 
@@ -173,7 +205,7 @@ func (session *Session) IsTableEmpty(bean interface{}) (bool, error) {
 
 func (session *Session) isTableEmpty(tableName string) (bool, error) {
 	var total int64
-	sqlStr := fmt.Sprintf("select count(*) from %s", session.engine.Quote(session.engine.TableName(tableName, true)))
+	sqlStr := fmt.Sprintf("select count(*) from %s", session.engine.Quote(session.statement.TableNameWithSchema(tableName)))
 	err := session.queryRow(sqlStr).Scan(&total)
 	if err != nil {
 		if err == sql.ErrNoRows {
@@ -185,19 +217,16 @@ func (session *Session) isTableEmpty(tableName string) (bool, error) {
 	return total == 0, nil
 }
 
-// find if index is exist according cols
-func (session *Session) isIndexExist2(tableName string, cols []string, unique bool) (bool, error) {
+// find if the index is exist on the table
+func (session *Session) isIndexExist2(tableName string, index *schemas.Index) (bool, error) {
 	indexes, err := session.engine.dialect.GetIndexes(session.getQueryer(), session.ctx, tableName)
 	if err != nil {
 		return false, err
 	}
 
-	for _, index := range indexes {
-		if utils.SliceEq(index.Cols, cols) {
-			if unique {
-				return index.Type == schemas.UniqueType, nil
-			}
-			return index.Type == schemas.IndexType, nil
+	for _, index2 := range indexes {
+		if index.Equal(index2) {
+			return true, nil
 		}
 	}
 	return false, nil
@@ -232,6 +261,9 @@ func (session *Session) Sync2(beans ...interface{}) error {
 		session.isAutoClose = false
 		defer session.Close()
 	}
+	if session.statement.LastError != nil {
+		return session.statement.LastError
+	}
 
 	tables, err := engine.dialect.GetTables(session.getQueryer(), session.ctx)
 	if err != nil {
@@ -250,97 +282,131 @@ func (session *Session) Sync2(beans ...interface{}) error {
 		if err != nil {
 			return err
 		}
-		var tbName string
-		if len(session.statement.AltTableName) > 0 {
-			tbName = session.statement.AltTableName
-		} else {
-			tbName = engine.TableName(bean)
+		if err := dialects.CheckIndexes(engine.dialect, table); err != nil {
+			return err
 		}
-		tbNameWithSchema := engine.tbNameWithSchema(tbName)
 
-		var oriTable *schemas.Table
-		for _, tb := range tables {
-			if strings.EqualFold(engine.tbNameWithSchema(tb.Name), engine.tbNameWithSchema(tbName)) {
-				oriTable = tb
-				break
+		if table.IsView {
+			viewName := session.statement.AltTableName
+			if viewName == "" {
+				viewName = engine.TableName(bean)
+			}
+			if err := session.syncView(tables, bean, table, viewName); err != nil {
+				return err
 			}
+			continue
 		}
 
-		// this is a new table
-		if oriTable == nil {
-			err = session.StoreEngine(session.statement.StoreEngine).createTable(bean)
-			if err != nil {
+		if len(session.statement.AltTableName) > 0 {
+			if err := session.sync2Table(tables, bean, table, session.statement.AltTableName); err != nil {
 				return err
 			}
+			continue
+		}
 
-			err = session.createUniques(bean)
-			if err != nil {
+		// all the shards of a sharded table are synchronized
+		session.statement.RefTable = table
+		shards, err := session.statement.ShardTables()
+		if err != nil {
+			return err
+		}
+		if len(shards) == 0 {
+			if err := session.sync2Table(tables, bean, table, engine.TableName(bean)); err != nil {
 				return err
 			}
-
-			err = session.createIndexes(bean)
+			continue
+		}
+		for _, shard := range shards {
+			session.statement.AltTableName = shard
+			err := session.sync2Table(tables, bean, table, shard)
+			session.statement.AltTableName = ""
 			if err != nil {
 				return err
 			}
-			continue
 		}
+	}
+
+	return nil
+}
+
+// sync2Table synchronizes the struct to the table tbName
+func (session *Session) sync2Table(tables []*schemas.Table, bean interface{}, table *schemas.Table, tbName string) error {
+	var (
+		engine = session.engine
+		err    error
+	)
+
+	tbNameWithSchema := session.statement.TableNameWithSchema(tbName)
+
+	var oriTable *schemas.Table
+	for _, tb := range tables {
+		if strings.EqualFold(session.statement.TableNameWithSchema(tb.Name), session.statement.TableNameWithSchema(tbName)) {
+			oriTable = tb
+			break
+		}
+	}
 
-		// this will modify an old table
-		if err = engine.loadTableInfo(oriTable); err != nil {
+	// this is a new table
+	if oriTable == nil {
+		err = session.StoreEngine(session.statement.StoreEngine).createTable(bean)
+		if err != nil {
 			return err
 		}
 
-		// check columns
-		for _, col := range table.Columns() {
-			var oriCol *schemas.Column
-			for _, col2 := range oriTable.Columns() {
-				if strings.EqualFold(col.Name, col2.Name) {
-					oriCol = col2
-					break
-				}
+		err = session.createUniques(bean)
+		if err != nil {
+			return err
+		}
+
+		err = session.createIndexes(bean)
+		if err != nil {
+			return err
+		}
+		return session.syncSequences(tbNameWithSchema, table, false)
+	}
+
+	// this will modify an old table
+	if err = engine.loadTableInfo(session.ctx, oriTable); err != nil {
+		return err
+	}
+
+	// check columns
+	for _, col := range table.Columns() {
+		var oriCol *schemas.Column
+		for _, col2 := range oriTable.Columns() {
+			if strings.EqualFold(col.Name, col2.Name) {
+				oriCol = col2
+				break
 			}
+		}
 
-			// column is not exist on table
-			if oriCol == nil {
-				session.statement.RefTable = table
-				session.statement.SetTableName(tbNameWithSchema)
-				if err = session.addColumn(col.Name); err != nil {
-					return err
-				}
-				continue
+		// column is not exist on table
+		if oriCol == nil {
+			session.statement.RefTable = table
+			session.statement.SetTableName(tbNameWithSchema)
+			if err = session.addColumn(col.Name); err != nil {
+				return err
 			}
+			continue
+		}
 
-			err = nil
-			expectedType := engine.dialect.SQLType(col)
-			curType := engine.dialect.SQLType(oriCol)
-			if expectedType != curType {
-				if expectedType == schemas.Text &&
-					strings.HasPrefix(curType, schemas.Varchar) {
-					// currently only support mysql & postgres
-					if engine.dialect.URI().DBType == schemas.MYSQL ||
-						engine.dialect.URI().DBType == schemas.POSTGRES {
-						engine.logger.Infof("Table %s column %s change type from %s to %s\n",
-							tbNameWithSchema, col.Name, curType, expectedType)
-						_, err = session.exec(engine.dialect.ModifyColumnSQL(tbNameWithSchema, col))
-					} else {
-						engine.logger.Warnf("Table %s column %s db type is %s, struct type is %s\n",
-							tbNameWithSchema, col.Name, curType, expectedType)
-					}
-				} else if strings.HasPrefix(curType, schemas.Varchar) && strings.HasPrefix(expectedType, schemas.Varchar) {
-					if engine.dialect.URI().DBType == schemas.MYSQL {
-						if oriCol.Length < col.Length {
-							engine.logger.Infof("Table %s column %s change type from varchar(%d) to varchar(%d)\n",
-								tbNameWithSchema, col.Name, oriCol.Length, col.Length)
-							_, err = session.exec(engine.dialect.ModifyColumnSQL(tbNameWithSchema, col))
-						}
-					}
+		err = nil
+		expectedType := engine.dialect.SQLType(col)
+		curType := engine.dialect.SQLType(oriCol)
+		if expectedType != curType {
+			if expectedType == schemas.Text &&
+				strings.HasPrefix(curType, schemas.Varchar) {
+				// currently only support mysql & postgres
+				if engine.dialect.URI().DBType == schemas.MYSQL ||
+					engine.dialect.URI().DBType == schemas.POSTGRES {
+					engine.logger.Infof("Table %s column %s change type from %s to %s\n",
+						tbNameWithSchema, col.Name, curType, expectedType)
+					_, err = session.exec(engine.dialect.ModifyColumnSQL(tbNameWithSchema, col))
 				} else {
-					if !(strings.HasPrefix(curType, expectedType) && curType[len(expectedType)] == '(') {
-						engine.logger.Warnf("Table %s column %s db type is %s, struct type is %s",
-							tbNameWithSchema, col.Name, curType, expectedType)
-					}
+					engine.logger.Warnf("Table %s column %s db type is %s, struct type is %s\n",
+						tbNameWithSchema, col.Name, curType, expectedType)
 				}
-			} else if expectedType == schemas.Varchar {
+			} else if strings.HasPrefix(curType, schemas.Varchar) && strings.HasPrefix(expectedType, schemas.Varchar) {
 				if engine.dialect.URI().DBType == schemas.MYSQL {
 					if oriCol.Length < col.Length {
 						engine.logger.Infof("Table %s column %s change type from varchar(%d) to varchar(%d)\n",
@@ -348,94 +414,236 @@ func (session *Session) Sync2(beans ...interface{}) error {
 						_, err = session.exec(engine.dialect.ModifyColumnSQL(tbNameWithSchema, col))
 					}
 				}
-			}
-
-			if col.Default != oriCol.Default {
-				switch {
-				case col.IsAutoIncrement: // For autoincrement column, don't check default
-				case (col.SQLType.Name == schemas.Bool || col.SQLType.Name == schemas.Boolean) &&
-					((strings.EqualFold(col.Default, "true") && oriCol.Default == "1") ||
-						(strings.EqualFold(col.Default, "false") && oriCol.Default == "0")):
-				default:
-					engine.logger.Warnf("Table %s Column %s db default is %s, struct default is %s",
-						tbName, col.Name, oriCol.Default, col.Default)
+			} else {
+				if !(strings.HasPrefix(curType, expectedType) && curType[len(expectedType)] == '(') {
+					engine.logger.Warnf("Table %s column %s db type is %s, struct type is %s",
+						tbNameWithSchema, col.Name, curType, expectedType)
 				}
 			}
-			if col.Nullable != oriCol.Nullable {
-				engine.logger.Warnf("Table %s Column %s db nullable is %v, struct nullable is %v",
-					tbName, col.Name, oriCol.Nullable, col.Nullable)
+		} else if expectedType == schemas.Varchar {
+			if engine.dialect.URI().DBType == schemas.MYSQL {
+				if oriCol.Length < col.Length {
+					engine.logger.Infof("Table %s column %s change type from varchar(%d) to varchar(%d)\n",
+						tbNameWithSchema, col.Name, oriCol.Length, col.Length)
+					_, err = session.exec(engine.dialect.ModifyColumnSQL(tbNameWithSchema, col))
+				}
 			}
+		}
 
-			if err != nil {
-				return err
+		if col.Default != oriCol.Default {
+			switch {
+			case col.IsAutoIncrement: // For autoincrement column, don't check default
+			case (col.SQLType.Name == schemas.Bool || col.SQLType.Name == schemas.Boolean) &&
+				((strings.EqualFold(col.Default, "true") && oriCol.Default == "1") ||
+					(strings.EqualFold(col.Default, "false") && oriCol.Default == "0")):
+			default:
+				engine.logger.Warnf("Table %s Column %s db default is %s, struct default is %s",
+					tbName, col.Name, oriCol.Default, col.Default)
 			}
 		}
+		if col.Nullable != oriCol.Nullable {
+			engine.logger.Warnf("Table %s Column %s db nullable is %v, struct nullable is %v",
+				tbName, col.Name, oriCol.Nullable, col.Nullable)
+		}
 
-		var foundIndexNames = make(map[string]bool)
-		var addedNames = make(map[string]*schemas.Index)
-
-		for name, index := range table.Indexes {
-			var oriIndex *schemas.Index
-			for name2, index2 := range oriTable.Indexes {
-				if index.Equal(index2) {
-					oriIndex = index2
-					foundIndexNames[name2] = true
-					break
-				}
-			}
+		if err != nil {
+			return err
+		}
+	}
 
-			if oriIndex != nil {
-				if oriIndex.Type != index.Type {
-					sql := engine.dialect.DropIndexSQL(tbNameWithSchema, oriIndex)
-					_, err = session.exec(sql)
-					if err != nil {
-						return err
-					}
-					oriIndex = nil
-				}
-			}
+	var foundIndexNames = make(map[string]bool)
+	var addedNames = make(map[string]*schemas.Index)
 
-			if oriIndex == nil {
-				addedNames[name] = index
+	for name, index := range table.Indexes {
+		var oriIndex *schemas.Index
+		for name2, index2 := range oriTable.Indexes {
+			if index.Equal(index2) {
+				oriIndex = index2
+				foundIndexNames[name2] = true
+				break
 			}
 		}
 
-		for name2, index2 := range oriTable.Indexes {
-			if _, ok := foundIndexNames[name2]; !ok {
-				sql := engine.dialect.DropIndexSQL(tbNameWithSchema, index2)
+		if oriIndex != nil {
+			if oriIndex.Type != index.Type {
+				sql := engine.dialect.DropIndexSQL(tbNameWithSchema, oriIndex)
 				_, err = session.exec(sql)
 				if err != nil {
 					return err
 				}
+				oriIndex = nil
 			}
 		}
 
-		for name, index := range addedNames {
-			if index.Type == schemas.UniqueType {
-				session.statement.RefTable = table
-				session.statement.SetTableName(tbNameWithSchema)
-				err = session.addUnique(tbNameWithSchema, name)
-			} else if index.Type == schemas.IndexType {
-				session.statement.RefTable = table
-				session.statement.SetTableName(tbNameWithSchema)
-				err = session.addIndex(tbNameWithSchema, name)
-			}
+		if oriIndex == nil {
+			addedNames[name] = index
+		}
+	}
+
+	for name2, index2 := range oriTable.Indexes {
+		if _, ok := foundIndexNames[name2]; !ok {
+			sql := engine.dialect.DropIndexSQL(tbNameWithSchema, index2)
+			_, err = session.exec(sql)
 			if err != nil {
 				return err
 			}
 		}
+	}
+
+	for name, index := range addedNames {
+		if index.Type == schemas.UniqueType {
+			session.statement.RefTable = table
+			session.statement.SetTableName(tbNameWithSchema)
+			err = session.addUnique(tbNameWithSchema, name)
+		} else if index.Type == schemas.IndexType {
+			session.statement.RefTable = table
+			session.statement.SetTableName(tbNameWithSchema)
+			err = session.addIndex(tbNameWithSchema, name)
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	if err = session.syncChecks(tbNameWithSchema, table, oriTable); err != nil {
+		return err
+	}
+
+	if err = session.syncSequences(tbNameWithSchema, table, true); err != nil {
+		return err
+	}
+
+	// check all the columns which removed from struct fields but left on database tables.
+	for _, colName := range oriTable.ColumnsSeq() {
+		if table.GetColumn(colName) == nil {
+			engine.logger.Warnf("Table %s has column %s but struct has not related field", session.statement.TableNameWithSchema(oriTable.Name), colName)
+		}
+	}
+	return nil
+}
+
+// syncChecks adds the check constraints of the struct which aren't on the
+// database table, drops and re-adds the ones whose expressions are changed and
+// drops the ones named by xorm which are removed from the struct, the checks
+// are matched by their names
+func (session *Session) syncChecks(tbNameWithSchema string, table, oriTable *schemas.Table) error {
+	// the dialect can't read the checks back
+	if oriTable.Checks == nil {
+		return nil
+	}
+
+	var (
+		engine    = session.engine
+		expected  = make(map[string]bool)
+		oriChecks = make(map[string]*schemas.Check, len(oriTable.Checks))
+	)
+	for name, check := range oriTable.Checks {
+		oriChecks[strings.ToUpper(name)] = check
+	}
 
-		// check all the columns which removed from struct fields but left on database tables.
-		for _, colName := range oriTable.ColumnsSeq() {
-			if table.GetColumn(colName) == nil {
-				engine.logger.Warnf("Table %s has column %s but struct has not related field", engine.TableName(oriTable.Name, true), colName)
+	for _, check := range dialects.TableChecks(engine.dialect, table) {
+		name := strings.ToUpper(check.XName(tbNameWithSchema))
+		expected[name] = true
+		oriCheck, ok := oriChecks[name]
+		if ok && oriCheck.SameExpr(check) {
+			continue
+		}
+		sql := engine.dialect.AddCheckSQL(tbNameWithSchema, check)
+		if ok {
+			dropSQL := engine.dialect.DropCheckSQL(tbNameWithSchema, oriCheck)
+			if sql == "" || dropSQL == "" {
+				engine.logger.Warnf("Table %s has check %s with the expression %s but it couldn't be changed to %s",
+					tbNameWithSchema, check.XName(tbNameWithSchema), oriCheck.Expr, check.Expr)
+				continue
 			}
+			if _, err := session.exec(dropSQL); err != nil {
+				return err
+			}
+		} else if sql == "" {
+			engine.logger.Warnf("Table %s has no check %s but it couldn't be added", tbNameWithSchema, check.XName(tbNameWithSchema))
+			continue
 		}
+		if _, err := session.exec(sql); err != nil {
+			return err
+		}
+	}
+
+	for name, check := range oriTable.Checks {
+		if expected[strings.ToUpper(name)] || !strings.HasPrefix(strings.ToUpper(name), "CHK_") {
+			continue
+		}
+		sql := engine.dialect.DropCheckSQL(tbNameWithSchema, check)
+		if sql == "" {
+			engine.logger.Warnf("Table %s has check %s but struct has not related tag", tbNameWithSchema, name)
+			continue
+		}
+		if _, err := session.exec(sql); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// syncSequences creates the missing sequences of the columns, the sequences of
+// an existing table start after the max values of the columns
+func (session *Session) syncSequences(tbNameWithSchema string, table *schemas.Table, tableExists bool) error {
+	cols := table.SequenceColumns()
+	if len(cols) == 0 {
+		return nil
 	}
 
+	engine := session.engine
+	seqs, err := engine.dialect.GetSequences(session.getQueryer(), session.ctx)
+	if err != nil {
+		return err
+	}
+
+	for _, col := range cols {
+		// the sequences are qualified by the schema as the tables are
+		seq := schemas.NewSequence(session.statement.TableNameWithSchema(col.Sequence))
+		if containsFold(seqs, col.Sequence) || engine.dialect.CreateSequenceSQL(seq) == "" {
+			continue
+		}
+		if tableExists {
+			max, err := maxColumnValue(session.ctx, session.getQueryer(), engine.dialect.Quoter(), tbNameWithSchema, col.Name)
+			if err != nil {
+				return err
+			}
+			seq.Start = max + 1
+		}
+		if _, err := session.exec(engine.dialect.CreateSequenceSQL(seq)); err != nil {
+			return err
+		}
+	}
 	return nil
 }
 
+func containsFold(names []string, name string) bool {
+	for _, n := range names {
+		if strings.EqualFold(n, name) {
+			return true
+		}
+	}
+	return false
+}
+
+// maxColumnValue returns the max value of the integer column of the table
+func maxColumnValue(ctx context.Context, queryer core.Queryer, quoter schemas.Quoter, tableName, colName string) (int64, error) {
+	rows, err := queryer.QueryContext(ctx, fmt.Sprintf("SELECT MAX(%s) FROM %s", quoter.Quote(colName), quoter.Quote(tableName)))
+	if err != nil {
+		return 0, err
+	}
+	defer rows.Close()
+
+	var max sql.NullInt64
+	if rows.Next() {
+		if err := rows.Scan(&max); err != nil {
+			return 0, err
+		}
+	}
+	return max.Int64, rows.Err()
+}
+
 // ImportFile SQL DDL file
 func (session *Session) ImportFile(ddlPath string) ([]sql.Result, error) {
 	file, err := os.Open(ddlPath)
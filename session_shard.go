@@ -0,0 +1,301 @@
+// Copyright 2021 The Xorm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package xorm
+
+import (
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/xorm-io/xorm/internal/statements"
+)
+
+// Shards restricts the shards which Find of a sharded table runs on, e.g. the
+// shards of a time range given by names.TimeSharder.Range. Find runs on all
+// the shards of the table by default, and Sync2 creates all of them.
+func (session *Session) Shards(shards ...string) *Session {
+	session.statement.Shards(shards...)
+	return session
+}
+
+// findShards runs the query on each of the shards and appends the results.
+// The rows of the shards are merged by the order, which could only be the
+// columns of the table, and then the offset and the limit are applied.
+func (session *Session) findShards(sliceValue reflect.Value, shards []string, condiBean ...interface{}) error {
+	var (
+		table  = session.statement.RefTable
+		start  = session.statement.Start
+		limit  = session.statement.LimitN
+		offset int
+	)
+	orders, err := session.statement.ShardOrders()
+	if err != nil {
+		return err
+	}
+	if sliceValue.Kind() == reflect.Slice {
+		offset = sliceValue.Len()
+	} else if len(orders) > 0 || limit != nil || start > 0 {
+		return ErrShardMapOrder
+	}
+	// every shard returns the rows until the end of the limit
+	if limit != nil {
+		n := start + *limit
+		session.statement.LimitN = &n
+	}
+	session.statement.Start = 0
+
+	// the SQLs are generated before any query since a query resets the statement
+	sqlStrs, argss, err := session.statement.GenShardSQLs(shards, func() (string, []interface{}, error) {
+		return session.genFindSQL(sliceValue, condiBean...)
+	})
+	if err != nil {
+		return err
+	}
+
+	for i, sqlStr := range sqlStrs {
+		session.snapshotTable = shards[i]
+		if err := session.noCacheFind(table, sliceValue, sqlStr, argss[i]...); err != nil {
+			return err
+		}
+	}
+	if sliceValue.Kind() != reflect.Slice {
+		return nil
+	}
+
+	rows := sliceValue.Slice(offset, sliceValue.Len())
+	if len(orders) > 0 {
+		if err := sortShardRows(rows, orders); err != nil {
+			return err
+		}
+	}
+	end := rows.Len()
+	if limit != nil && start+*limit < end {
+		end = start + *limit
+	}
+	if start > end {
+		start = end
+	}
+	merged := reflect.MakeSlice(rows.Type(), end-start, end-start)
+	reflect.Copy(merged, rows.Slice(start, end))
+	sliceValue.Set(reflect.AppendSlice(sliceValue.Slice(0, offset), merged))
+	return nil
+}
+
+// sortShardRows sorts the rows of the shards by the order columns, the nils
+// are less than the others
+func sortShardRows(rows reflect.Value, orders []statements.ShardOrder) error {
+	var (
+		keys = make([][]reflect.Value, rows.Len())
+		idxs = make([]int, rows.Len())
+	)
+	for i := range keys {
+		idxs[i] = i
+		row := reflect.Indirect(rows.Index(i))
+		keys[i] = make([]reflect.Value, len(orders))
+		for j, order := range orders {
+			v, err := order.Column.ValueOfV(&row)
+			if err != nil {
+				return err
+			}
+			keys[i][j] = *v
+		}
+	}
+
+	var sortErr error
+	sort.SliceStable(idxs, func(a, b int) bool {
+		for j, order := range orders {
+			c, err := compareShardValues(keys[idxs[a]][j], keys[idxs[b]][j])
+			if err != nil {
+				sortErr = err
+				return false
+			}
+			if c != 0 {
+				return (c < 0) != order.Desc
+			}
+		}
+		return false
+	})
+	if sortErr != nil {
+		return sortErr
+	}
+
+	sorted := reflect.MakeSlice(rows.Type(), rows.Len(), rows.Len())
+	for i, idx := range idxs {
+		sorted.Index(i).Set(rows.Index(idx))
+	}
+	reflect.Copy(rows, sorted)
+	return nil
+}
+
+var timeType = reflect.TypeOf(time.Time{})
+
+// compareShardValues compares the values of a column of two rows
+func compareShardValues(a, b reflect.Value) (int, error) {
+	if a.Kind() == reflect.Ptr {
+		switch {
+		case a.IsNil() && b.IsNil():
+			return 0, nil
+		case a.IsNil():
+			return -1, nil
+		case b.IsNil():
+			return 1, nil
+		}
+		a, b = a.Elem(), b.Elem()
+	}
+
+	switch a.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return compareOrdered(a.Int() < b.Int(), a.Int() > b.Int()), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return compareOrdered(a.Uint() < b.Uint(), a.Uint() > b.Uint()), nil
+	case reflect.Float32, reflect.Float64:
+		return compareOrdered(a.Float() < b.Float(), a.Float() > b.Float()), nil
+	case reflect.String:
+		return strings.Compare(a.String(), b.String()), nil
+	case reflect.Bool:
+		return compareOrdered(!a.Bool() && b.Bool(), a.Bool() && !b.Bool()), nil
+	case reflect.Struct:
+		if a.Type().ConvertibleTo(timeType) {
+			ta := a.Convert(timeType).Interface().(time.Time)
+			tb := b.Convert(timeType).Interface().(time.Time)
+			return compareOrdered(ta.Before(tb), ta.After(tb)), nil
+		}
+	}
+	return 0, statements.ErrShardOrder
+}
+
+func compareOrdered(less, greater bool) int {
+	switch {
+	case less:
+		return -1
+	case greater:
+		return 1
+	}
+	return 0
+}
+
+// countShards counts the rows of each shard and adds them up
+func (session *Session) countShards(shards []string, bean interface{}) (int64, error) {
+	sqlStrs, argss, err := session.statement.GenShardSQLs(shards, func() (string, []interface{}, error) {
+		return session.statement.GenCountSQL(bean)
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	var total int64
+	for i, sqlStr := range sqlStrs {
+		cnt, err := session.count(sqlStr, argss[i])
+		if err != nil {
+			return 0, err
+		}
+		total += cnt
+	}
+	return total, nil
+}
+
+// existShards returns true if the record exists in any of the shards
+func (session *Session) existShards(shards []string, bean interface{}) (bool, error) {
+	sqlStrs, argss, err := session.statement.GenShardSQLs(shards, func() (string, []interface{}, error) {
+		return session.statement.GenExistSQL(bean)
+	})
+	if err != nil {
+		return false, err
+	}
+
+	for i, sqlStr := range sqlStrs {
+		has, err := session.exist(sqlStr, argss[i])
+		if err != nil || has {
+			return has, err
+		}
+	}
+	return false, nil
+}
+
+// sumShards sums the columns of each shard and adds them up, v is the
+// pointer of a float64, an int64 or a slice of them
+func (session *Session) sumShards(v reflect.Value, shards []string, bean interface{}, columnNames ...string) error {
+	sqlStrs, argss, err := session.statement.GenShardSQLs(shards, func() (string, []interface{}, error) {
+		return session.statement.GenSumSQL(bean, columnNames...)
+	})
+	if err != nil {
+		return err
+	}
+
+	res := v.Elem()
+	for i, sqlStr := range sqlStrs {
+		shardRes := reflect.New(res.Type())
+		if res.Kind() == reflect.Slice {
+			shardRes.Elem().Set(reflect.MakeSlice(res.Type(), res.Len(), res.Len()))
+		}
+		if err := session.queryAggregate(shardRes, sqlStr, argss[i]); err != nil {
+			return err
+		}
+		if res.Kind() == reflect.Slice {
+			for j := 0; j < res.Len(); j++ {
+				addSum(res.Index(j), shardRes.Elem().Index(j))
+			}
+		} else {
+			addSum(res, shardRes.Elem())
+		}
+	}
+	return nil
+}
+
+func addSum(sum, v reflect.Value) {
+	switch sum.Kind() {
+	case reflect.Float32, reflect.Float64:
+		sum.SetFloat(sum.Float() + v.Float())
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		sum.SetInt(sum.Int() + v.Int())
+	}
+}
+
+// shardSlice splits the beans of the slice by their shards, it returns nil if
+// they're not sharded or all of them are in the same shard
+func (session *Session) shardSlice(sliceValue reflect.Value) ([]reflect.Value, error) {
+	if len(session.statement.AltTableName) > 0 {
+		return nil, nil
+	}
+
+	var (
+		groups []reflect.Value
+		idxes  = make(map[string]int)
+	)
+	for i := 0; i < sliceValue.Len(); i++ {
+		v := sliceValue.Index(i)
+		shard, err := session.statement.InsertShardTable(v.Interface())
+		if err != nil || shard == "" {
+			return nil, err
+		}
+		idx, ok := idxes[shard]
+		if !ok {
+			idx = len(groups)
+			idxes[shard] = idx
+			groups = append(groups, reflect.MakeSlice(sliceValue.Type(), 0, 1))
+		}
+		groups[idx] = reflect.Append(groups[idx], v)
+	}
+	if len(groups) <= 1 {
+		return nil, nil
+	}
+	return groups, nil
+}
+
+// insertShards inserts the beans of each shard
+func (session *Session) insertShards(groups []reflect.Value) (int64, error) {
+	var affected int64
+	for _, group := range groups {
+		groupPtr := reflect.New(group.Type())
+		groupPtr.Elem().Set(group)
+		cnt, err := session.innerInsertMulti(groupPtr.Interface())
+		if err != nil {
+			return affected, err
+		}
+		affected += cnt
+	}
+	return affected, nil
+}
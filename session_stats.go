@@ -7,6 +7,7 @@ package xorm
 import (
 	"database/sql"
 	"errors"
+	"fmt"
 	"reflect"
 )
 
@@ -17,22 +18,48 @@ func (session *Session) Count(bean ...interface{}) (int64, error) {
 		defer session.Close()
 	}
 
+	if len(bean) > 0 {
+		shards, err := session.statement.QueryShards(bean[0])
+		if err != nil {
+			return 0, err
+		}
+		if len(shards) > 0 {
+			return session.countShards(shards, bean[0])
+		}
+	}
+
 	sqlStr, args, err := session.statement.GenCountSQL(bean...)
 	if err != nil {
 		return 0, err
 	}
+	return session.count(sqlStr, args)
+}
 
-	var total int64
-	err = session.queryRow(sqlStr, args...).Scan(&total)
-	if err == sql.ErrNoRows || err == nil {
-		return total, nil
+func (session *Session) count(sqlStr string, args []interface{}) (int64, error) {
+	res, _, err := session.cachedRead("count", sqlStr, args, func() (interface{}, error) {
+		var total int64
+		err := session.queryRow(sqlStr, args...).Scan(&total)
+		if err == sql.ErrNoRows {
+			err = nil
+		}
+		return total, err
+	})
+	if err != nil {
+		return 0, err
 	}
-
-	return 0, err
+	return res.(int64), nil
 }
 
+const sumFormat = "COALESCE(sum(%s),0)"
+
 // sum call sum some column. bean's non-empty fields are conditions.
 func (session *Session) sum(res interface{}, bean interface{}, columnNames ...string) error {
+	return session.aggregate(res, bean, sumFormat, columnNames...)
+}
+
+// aggregate scans the aggregate function of the columns into res, format is
+// the aggregate expression of a column. bean's non-empty fields are conditions.
+func (session *Session) aggregate(res interface{}, bean interface{}, format string, columnNames ...string) error {
 	if session.isAutoClose {
 		defer session.Close()
 	}
@@ -42,20 +69,52 @@ func (session *Session) sum(res interface{}, bean interface{}, columnNames ...st
 		return errors.New("need a pointer to a variable")
 	}
 
-	sqlStr, args, err := session.statement.GenSumSQL(bean, columnNames...)
+	// only the sums of the shards could be added up
+	shards, err := session.statement.QueryShards(bean)
 	if err != nil {
 		return err
 	}
+	if len(shards) > 0 {
+		if format != sumFormat {
+			return ErrShardAggregate
+		}
+		return session.sumShards(v, shards, bean, columnNames...)
+	}
+
+	sqlStr, args, err := session.statement.GenAggregateSQL(bean, format, columnNames...)
+	if err != nil {
+		return err
+	}
+	return session.queryAggregate(v, sqlStr, args)
+}
 
-	if v.Elem().Kind() == reflect.Slice {
-		err = session.queryRow(sqlStr, args...).ScanSlice(res)
-	} else {
-		err = session.queryRow(sqlStr, args...).Scan(res)
+// queryAggregate scans the aggregate of the SQL into the pointer v
+func (session *Session) queryAggregate(v reflect.Value, sqlStr string, args []interface{}) error {
+	res := v.Interface()
+	cached, hit, err := session.cachedRead(fmt.Sprintf("aggregate-%v", v.Type()), sqlStr, args, func() (interface{}, error) {
+		var err error
+		if v.Elem().Kind() == reflect.Slice {
+			err = session.queryRow(sqlStr, args...).ScanSlice(res)
+		} else {
+			err = session.queryRow(sqlStr, args...).Scan(res)
+		}
+		if err == sql.ErrNoRows {
+			err = nil
+		}
+		return v.Elem().Interface(), err
+	})
+	if err != nil {
+		return err
 	}
-	if err == sql.ErrNoRows || err == nil {
-		return nil
+	if hit {
+		if v.Elem().Kind() == reflect.Slice && v.Elem().Len() > 0 {
+			// the results are returned by the slice given by Sums and SumsInt
+			reflect.Copy(v.Elem(), reflect.ValueOf(cached))
+		} else {
+			v.Elem().Set(reflect.ValueOf(cached))
+		}
 	}
-	return err
+	return nil
 }
 
 // Sum call sum some column. bean's non-empty fields are conditions.
@@ -79,3 +138,45 @@ func (session *Session) SumsInt(bean interface{}, columnNames ...string) ([]int6
 	var res = make([]int64, len(columnNames), len(columnNames))
 	return res, session.sum(&res, bean, columnNames...)
 }
+
+// Avg returns the average of the column, it's 0 if there is no record. bean's
+// non-empty fields are conditions.
+func (session *Session) Avg(bean interface{}, columnName string) (res float64, err error) {
+	return res, session.aggregate(&res, bean, "COALESCE(avg(%s),0)", columnName)
+}
+
+// Min scans the minimum of the column into res, res should be a sql.NullXXX
+// or a pointer of pointer if there could be no record. bean's non-empty fields
+// are conditions.
+func (session *Session) Min(bean interface{}, columnName string, res interface{}) error {
+	return session.aggregate(res, bean, "min(%s)", columnName)
+}
+
+// Max scans the maximum of the column into res, res should be a sql.NullXXX
+// or a pointer of pointer if there could be no record. bean's non-empty fields
+// are conditions.
+func (session *Session) Max(bean interface{}, columnName string, res interface{}) error {
+	return session.aggregate(res, bean, "max(%s)", columnName)
+}
+
+// CountDistinct counts the distinct values of the column. bean's non-empty
+// fields are conditions.
+func (session *Session) CountDistinct(bean interface{}, columnName string) (res int64, err error) {
+	return res, session.aggregate(&res, bean, "count(DISTINCT %s)", columnName)
+}
+
+// Aggregate adds the aggregate or window function expressions to the selected
+// columns, so that the grouped results could be found into a slice of result
+// structs, or queried into maps, e.g.
+//
+//	engine.Table(new(Employee)).Cols("depart_id").
+//	    Aggregate(xorm.Avg("salary").As("avg_salary"), xorm.Count("*").As("total")).
+//	    GroupBy("depart_id").Find(&stats)
+func (session *Session) Aggregate(exprs ...AggrExpr) *Session {
+	var strs = make([]string, 0, len(exprs))
+	for _, expr := range exprs {
+		strs = append(strs, expr.String())
+	}
+	session.statement.Aggregate(strs...)
+	return session
+}
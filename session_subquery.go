@@ -0,0 +1,49 @@
+// Copyright 2021 The Xorm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package xorm
+
+// SubQuerySQL returns the SQL and the args of the query built by the session
+// without executing it, so that the session could be a subquery of Table,
+// Join, In, NotIn, Union, UnionAll and With. The session is reset after it.
+func (session *Session) SubQuerySQL() (string, []interface{}, error) {
+	if session.isAutoClose {
+		defer session.Close()
+	}
+	defer session.resetStatement()
+
+	if session.statement.LastError != nil {
+		return "", nil, session.statement.LastError
+	}
+	return session.statement.GenQuerySQL()
+}
+
+// Union combines the distinct rows of the subquery, which is a *Session or a
+// *builder.Builder, with the rows of the session
+func (session *Session) Union(subQuery interface{}) *Session {
+	session.statement.Union("UNION", subQuery)
+	return session
+}
+
+// UnionAll combines all the rows of the subquery, which is a *Session or a
+// *builder.Builder, with the rows of the session
+func (session *Session) UnionAll(subQuery interface{}) *Session {
+	session.statement.Union("UNION ALL", subQuery)
+	return session
+}
+
+// With adds a common table expression named name of the subquery, which is a
+// *Session, a *builder.Builder or a SQL string. The name could be followed by
+// the column names, e.g. tree(id, parent_id).
+func (session *Session) With(name string, subQuery interface{}) *Session {
+	session.statement.With(name, subQuery, false)
+	return session
+}
+
+// WithRecursive adds a recursive common table expression which could
+// reference itself
+func (session *Session) WithRecursive(name string, subQuery interface{}) *Session {
+	session.statement.With(name, subQuery, true)
+	return session
+}
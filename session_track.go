@@ -0,0 +1,175 @@
+// Copyright 2021 The Xorm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package xorm
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+
+	"github.com/xorm-io/builder"
+	"github.com/xorm-io/xorm/schemas"
+)
+
+// ErrNotTracked is returned when the bean has not been loaded by a tracking session
+var ErrNotTracked = errors.New("The bean has not been loaded by a tracking session")
+
+// ColumnChange represents the value of a column in the snapshot and the current one
+type ColumnChange struct {
+	Old interface{}
+	New interface{}
+}
+
+// Track enables the snapshots of the beans loaded by Get, Find, Iterate or
+// Rows of the session, keyed by their tables and primary keys. Update of a tracked bean
+// writes only the columns changed since it's loaded, and the conditions of its
+// primary keys are added.
+func (session *Session) Track() *Session {
+	session.tracking = true
+	if session.snapshots == nil {
+		session.snapshots = make(map[string]map[string]interface{})
+	}
+	return session
+}
+
+// snapshotTableName returns the table the bean is read from or written to,
+// which differs from the name of the table for the shards, the schemas and
+// the tables set by Table
+func (session *Session) snapshotTableName(table *schemas.Table) string {
+	if name := session.statement.TableName(); name != "" {
+		return name
+	}
+	if session.snapshotTable != "" {
+		return session.snapshotTable
+	}
+	return table.Name
+}
+
+// snapshotKey returns the key of the bean in the snapshots, the pk is empty if
+// the bean could not be identified
+func (session *Session) snapshotKey(table *schemas.Table, bean interface{}) (string, schemas.PK, error) {
+	if table == nil || len(table.PrimaryKeys) == 0 {
+		return "", nil, nil
+	}
+	var pk = make(schemas.PK, 0, len(table.PrimaryKeys))
+	for _, col := range table.PKColumns() {
+		fieldValue, err := col.ValueOf(bean)
+		if err != nil {
+			return "", nil, err
+		}
+		pk = append(pk, fieldValue.Interface())
+	}
+	if pk.IsZero() {
+		return "", nil, nil
+	}
+	return fmt.Sprintf("%s%v", session.snapshotTableName(table), []interface{}(pk)), pk, nil
+}
+
+// columnValues returns the values of the columns to be written into database,
+// the updated columns are excluded since they are written by every update
+func (session *Session) columnValues(table *schemas.Table, bean interface{}) (map[string]interface{}, error) {
+	var values = make(map[string]interface{}, len(table.ColumnsSeq()))
+	for _, col := range table.Columns() {
+		if col.MapType == schemas.ONLYFROMDB || col.IsUpdated {
+			continue
+		}
+		fieldValue, err := col.ValueOf(bean)
+		if err != nil {
+			return nil, err
+		}
+		v, err := session.statement.PlainValue2Interface(col, *fieldValue)
+		if err != nil {
+			return nil, err
+		}
+		values[col.Name] = v
+	}
+	return values, nil
+}
+
+// takeSnapshot stores the current values of the bean if the session is tracking
+func (session *Session) takeSnapshot(table *schemas.Table, bean interface{}) error {
+	if !session.tracking || table == nil {
+		return nil
+	}
+	key, _, err := session.snapshotKey(table, bean)
+	if err != nil || key == "" {
+		return err
+	}
+	values, err := session.columnValues(table, bean)
+	if err != nil {
+		return err
+	}
+	session.snapshots[key] = values
+	return nil
+}
+
+func (session *Session) changes(table *schemas.Table, bean interface{}) (map[string]*ColumnChange, schemas.PK, error) {
+	key, pk, err := session.snapshotKey(table, bean)
+	if err != nil {
+		return nil, nil, err
+	}
+	snapshot, ok := session.snapshots[key]
+	if !ok {
+		return nil, nil, ErrNotTracked
+	}
+	values, err := session.columnValues(table, bean)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var changes = make(map[string]*ColumnChange)
+	for name, v := range values {
+		if old := snapshot[name]; !reflect.DeepEqual(old, v) {
+			changes[name] = &ColumnChange{Old: old, New: v}
+		}
+	}
+	return changes, pk, nil
+}
+
+// Changes returns the columns of the bean changed since it's loaded by the
+// tracking session, the values are the ones written into database. The bean
+// is looked up in the table set by Table, or else in its shard or its table.
+func (session *Session) Changes(bean interface{}) (map[string]*ColumnChange, error) {
+	defer session.resetStatement()
+	if !session.tracking {
+		return nil, ErrNotTracked
+	}
+	if err := session.statement.SetRefBean(bean); err != nil {
+		return nil, err
+	}
+	changes, _, err := session.changes(session.statement.RefTable, bean)
+	return changes, err
+}
+
+// trackUpdate restricts the update of a tracked bean to the changed columns
+// and its primary keys, tracked is false if the bean is not tracked
+func (session *Session) trackUpdate(bean interface{}) (tracked, changed bool, err error) {
+	if !session.tracking || session.statement.ColumnStr() != "" || session.statement.GetUseAllCols() {
+		return false, false, nil
+	}
+	table := session.statement.RefTable
+	changes, pk, err := session.changes(table, bean)
+	if err == ErrNotTracked {
+		return false, false, nil
+	} else if err != nil {
+		return false, false, err
+	}
+
+	var cond = builder.Eq{}
+	for i, col := range table.PKColumns() {
+		cond[session.engine.Quote(col.Name)] = pk[i]
+	}
+	session.statement.And(cond)
+
+	if len(changes) == 0 {
+		return true, false, nil
+	}
+	var cols = make([]string, 0, len(changes))
+	for name := range changes {
+		cols = append(cols, name)
+	}
+	session.statement.Cols(cols...)
+	return true, true, nil
+}
@@ -4,6 +4,8 @@
 
 package xorm
 
+import "github.com/xorm-io/xorm/caches"
+
 // Begin a transaction
 func (session *Session) Begin() error {
 	if session.isAutoCommit {
@@ -26,6 +28,7 @@ func (session *Session) Rollback() error {
 		session.saveLastSQL("ROLL BACK")
 		session.isCommitedOrRollbacked = true
 		session.isAutoCommit = true
+		session.resetTxCache()
 
 		return session.tx.Rollback()
 	}
@@ -40,9 +43,16 @@ func (session *Session) Commit() error {
 		session.isAutoCommit = true
 
 		if err := session.tx.Commit(); err != nil {
+			session.resetTxCache()
 			return err
 		}
 
+		// apply the cache invalidations after tx committed
+		for _, invalidate := range session.txCacheInvalidations {
+			invalidate()
+		}
+		session.resetTxCache()
+
 		// handle processors after tx committed
 		closureCallFunc := func(closuresPtr *[]func(interface{}), bean interface{}) {
 			if closuresPtr != nil {
@@ -89,3 +99,57 @@ func (session *Session) Commit() error {
 func (session *Session) IsInTx() bool {
 	return !session.isAutoCommit
 }
+
+func (session *Session) resetTxCache() {
+	session.txCacheInvalidations = nil
+}
+
+// cacher returns the cacher of the table to be written, the invalidations
+// are buffered until commit if the session is in a transaction
+func (session *Session) cacher(tableName string) caches.Cacher {
+	cacher := session.engine.GetCacher(tableName)
+	if cacher == nil || session.isAutoCommit {
+		return cacher
+	}
+	return &txCacher{Cacher: cacher, session: session}
+}
+
+// txCacher buffers the invalidations of a cacher in a transaction, the
+// entries are never read or put since the rows of a transaction may be
+// not committed or not visible to the other sessions
+type txCacher struct {
+	caches.Cacher
+	session *Session
+}
+
+func (c *txCacher) GetIds(tableName, sql string) interface{} {
+	return nil
+}
+
+func (c *txCacher) GetBean(tableName string, id string) interface{} {
+	return nil
+}
+
+func (c *txCacher) PutIds(tableName, sql string, ids interface{}) {}
+
+func (c *txCacher) PutBean(tableName string, id string, obj interface{}) {}
+
+func (c *txCacher) invalidate(f func()) {
+	c.session.txCacheInvalidations = append(c.session.txCacheInvalidations, f)
+}
+
+func (c *txCacher) DelIds(tableName, sql string) {
+	c.invalidate(func() { c.Cacher.DelIds(tableName, sql) })
+}
+
+func (c *txCacher) DelBean(tableName string, id string) {
+	c.invalidate(func() { c.Cacher.DelBean(tableName, id) })
+}
+
+func (c *txCacher) ClearIds(tableName string) {
+	c.invalidate(func() { c.Cacher.ClearIds(tableName) })
+}
+
+func (c *txCacher) ClearBeans(tableName string) {
+	c.invalidate(func() { c.Cacher.ClearBeans(tableName) })
+}
@@ -24,7 +24,7 @@ var (
 
 func (session *Session) cacheUpdate(table *schemas.Table, tableName, sqlStr string, args ...interface{}) error {
 	if table == nil ||
-		session.tx != nil {
+		session.dryRun {
 		return ErrCacheFailed
 	}
 
@@ -47,7 +47,7 @@ func (session *Session) cacheUpdate(table *schemas.Table, tableName, sqlStr stri
 		}
 	}
 
-	cacher := session.engine.GetCacher(tableName)
+	cacher := session.cacher(tableName)
 	session.engine.logger.Debugf("[cache] get cache sql: %v, %v", newsql, args[nStart:])
 	ids, err := caches.GetCacheSql(cacher, tableName, newsql, args[nStart:])
 	if err != nil {
@@ -149,6 +149,15 @@ func (session *Session) Update(bean interface{}, condiBean ...interface{}) (int6
 		defer session.Close()
 	}
 
+	if session.needAudit(bean) {
+		return session.audit(func() (int64, error) {
+			return session.update(bean, condiBean...)
+		})
+	}
+	return session.update(bean, condiBean...)
+}
+
+func (session *Session) update(bean interface{}, condiBean ...interface{}) (int64, error) {
 	defer session.resetStatement()
 
 	if session.statement.LastError != nil {
@@ -172,18 +181,30 @@ func (session *Session) Update(bean interface{}, condiBean ...interface{}) (int6
 	// --
 
 	var err error
+	var tracked, changed bool
 	var isMap = t.Kind() == reflect.Map
 	var isStruct = t.Kind() == reflect.Struct
 	if isStruct {
 		if err := session.statement.SetRefBean(bean); err != nil {
 			return 0, err
 		}
+		if session.statement.RefTable.IsView {
+			return 0, ErrViewReadOnly
+		}
 
 		if len(session.statement.TableName()) <= 0 {
 			return 0, ErrTableNotFound
 		}
 
-		if session.statement.ColumnStr() == "" {
+		tracked, changed, err = session.trackUpdate(bean)
+		if err != nil {
+			return 0, err
+		}
+		if tracked && !changed {
+			if len(session.statement.IncrColumns)+len(session.statement.DecrColumns)+len(session.statement.ExprColumns) == 0 {
+				return 0, nil
+			}
+		} else if session.statement.ColumnStr() == "" {
 			colNames, args, err = session.statement.BuildUpdates(v, false, false,
 				false, false, true)
 		} else {
@@ -193,6 +214,10 @@ func (session *Session) Update(bean interface{}, condiBean ...interface{}) (int6
 			return 0, err
 		}
 	} else if isMap {
+		if table := session.statement.RefTable; table != nil && table.IsView {
+			return 0, ErrViewReadOnly
+		}
+
 		colNames = make([]string, 0)
 		args = make([]interface{}, 0)
 		bValue := reflect.Indirect(reflect.ValueOf(bean))
@@ -257,6 +282,13 @@ func (session *Session) Update(bean interface{}, condiBean ...interface{}) (int6
 			}
 			colNames = append(colNames, session.engine.Quote(expr.ColName)+"=("+subQuery+")")
 			args = append(args, subArgs...)
+		case builder.Cond:
+			exprSQL, exprArgs, err := session.statement.GenCondSQL(tp)
+			if err != nil {
+				return 0, err
+			}
+			colNames = append(colNames, session.engine.Quote(expr.ColName)+"="+exprSQL)
+			args = append(args, exprArgs...)
 		default:
 			colNames = append(colNames, session.engine.Quote(expr.ColName)+"=?")
 			args = append(args, expr.Arg)
@@ -418,6 +450,16 @@ func (session *Session) Update(bean interface{}, condiBean ...interface{}) (int6
 		fromSQL,
 		condSQL)
 
+	auditFromSQL := tableAlias
+	if fromSQL != "" {
+		auditFromSQL = strings.TrimPrefix(fromSQL, "FROM ")
+	}
+	auditRows, err := session.auditRows(bean, fmt.Sprintf("SELECT %s* FROM %s %s",
+		top, auditFromSQL, condSQL), condArgs)
+	if err != nil {
+		return 0, err
+	}
+
 	res, err := session.exec(sqlStr, append(args, condArgs...)...)
 	if err != nil {
 		return 0, err
@@ -427,7 +469,12 @@ func (session *Session) Update(bean interface{}, condiBean ...interface{}) (int6
 		}
 	}
 
-	if cacher := session.engine.GetCacher(tableName); cacher != nil && session.statement.UseCache {
+	if err := session.auditUpdate(table, tableName, auditRows); err != nil {
+		return 0, err
+	}
+
+	session.invalidateResults(tableName)
+	if cacher := session.cacher(tableName); cacher != nil && session.statement.UseCache && !session.dryRun {
 		// session.cacheUpdate(table, tableName, sqlStr, args...)
 		session.engine.logger.Debugf("[cache] clear table: %v", tableName)
 		cacher.ClearIds(tableName)
@@ -463,6 +510,12 @@ func (session *Session) Update(bean interface{}, condiBean ...interface{}) (int6
 	cleanupProcessorsClosures(&session.afterClosures) // cleanup after used
 	// --
 
+	if tracked {
+		if err := session.takeSnapshot(table, bean); err != nil {
+			return 0, err
+		}
+	}
+
 	return res.RowsAffected()
 }
 
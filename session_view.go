@@ -0,0 +1,163 @@
+// Copyright 2021 The Xorm Authors. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package xorm
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/xorm-io/xorm/dialects"
+	"github.com/xorm-io/xorm/schemas"
+)
+
+// CreateView creates or replaces the view of the bean which implements
+// schemas.View, the beans of a view are read only
+func (session *Session) CreateView(bean interface{}) error {
+	if session.isAutoClose {
+		defer session.Close()
+	}
+
+	return session.createView(bean)
+}
+
+// viewOf returns the schemas.View of the bean, ViewSQL could be a method of
+// the pointer of the bean
+func viewOf(bean interface{}) (schemas.View, bool) {
+	if view, ok := bean.(schemas.View); ok {
+		return view, true
+	}
+	v := reflect.ValueOf(bean)
+	if v.Kind() == reflect.Ptr {
+		return nil, false
+	}
+	ptr := reflect.New(v.Type())
+	ptr.Elem().Set(v)
+	view, ok := ptr.Interface().(schemas.View)
+	return view, ok
+}
+
+func (session *Session) createView(bean interface{}) error {
+	query, err := session.viewQuery(bean)
+	if err != nil {
+		return err
+	}
+	return session.execCreateView(query)
+}
+
+// viewQuery returns the query which defines the view of the bean
+func (session *Session) viewQuery(bean interface{}) (string, error) {
+	if err := session.statement.SetRefBean(bean); err != nil {
+		return "", err
+	}
+	view, ok := viewOf(bean)
+	if !ok {
+		return "", fmt.Errorf("%T is not a view", bean)
+	}
+	return session.statement.ViewSQL(view.ViewSQL())
+}
+
+// execCreateView creates the view of the statement by the query
+func (session *Session) execCreateView(query string) error {
+	table := session.statement.RefTable
+	sqlStrs := session.engine.dialect.CreateViewSQL(session.statement.TableName(), query, table.Materialized)
+	for _, s := range sqlStrs {
+		if _, err := session.exec(s); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// DropView drops the view of the bean or the view name
+func (session *Session) DropView(beanOrViewName interface{}) error {
+	if session.isAutoClose {
+		defer session.Close()
+	}
+
+	return session.dropView(beanOrViewName)
+}
+
+func (session *Session) dropView(beanOrViewName interface{}) error {
+	viewName := session.engine.TableName(beanOrViewName)
+
+	var materialized bool
+	if _, ok := beanOrViewName.(string); ok {
+		// the kind of the view is read from the database
+		views, err := session.engine.dialect.GetViews(session.getQueryer(), session.ctx)
+		if err != nil {
+			return err
+		}
+		for _, view := range views {
+			if strings.EqualFold(view.Name, viewName) {
+				materialized = view.Materialized
+				break
+			}
+		}
+	} else {
+		table, err := session.engine.TableInfo(beanOrViewName)
+		if err != nil {
+			return err
+		}
+		materialized = table.Materialized
+	}
+
+	_, err := session.exec(session.engine.dialect.DropViewSQL(session.statement.TableNameWithSchema(viewName), materialized))
+	return err
+}
+
+// RefreshMaterializedView refreshes the rows of the materialized view of the
+// bean or the view name, it does nothing if the dialect has no materialized
+// views since they are created as the plain views
+func (session *Session) RefreshMaterializedView(beanOrViewName interface{}) error {
+	if session.isAutoClose {
+		defer session.Close()
+	}
+
+	viewName := session.statement.TableNameWithSchema(session.engine.TableName(beanOrViewName))
+	sqlStr := session.engine.dialect.RefreshViewSQL(viewName)
+	if sqlStr == "" {
+		return nil
+	}
+	_, err := session.exec(sqlStr)
+	return err
+}
+
+// syncView creates the view or replaces the existing one, the existing
+// materialized view is kept unless its query is changed, otherwise it's
+// dropped and created again since it couldn't be replaced, i.e. its rows are
+// computed again by the query of the bean
+func (session *Session) syncView(tables []*schemas.Table, bean interface{}, table *schemas.Table, viewName string) error {
+	for _, tb := range tables {
+		if strings.EqualFold(tb.Name, viewName) {
+			return fmt.Errorf("%s is a table but not a view", viewName)
+		}
+	}
+
+	query, err := session.viewQuery(bean)
+	if err != nil {
+		return err
+	}
+	views, err := session.engine.dialect.GetViews(session.getQueryer(), session.ctx)
+	if err != nil {
+		return err
+	}
+	for _, view := range views {
+		if !strings.EqualFold(view.Name, viewName) {
+			continue
+		}
+		if view.Materialized && table.Materialized && view.Comment == dialects.ViewComment(query) {
+			return nil
+		}
+		// the materialized view or the view whose kind is changed
+		if view.Materialized || view.Materialized != table.Materialized {
+			if _, err := session.exec(session.engine.dialect.DropViewSQL(session.statement.TableNameWithSchema(viewName), view.Materialized)); err != nil {
+				return err
+			}
+		}
+		break
+	}
+	return session.execCreateView(query)
+}
@@ -7,6 +7,7 @@ package tags
 import (
 	"encoding/gob"
 	"errors"
+	"fmt"
 	"reflect"
 	"strings"
 	"sync"
@@ -33,6 +34,8 @@ type Parser struct {
 	tableMapper  names.Mapper
 	handlers     map[string]Handler
 	cacherMgr    *caches.Manager
+	converters   *convert.Converters
+	keyProvider  convert.KeyProvider
 	tableCache   sync.Map // map[reflect.Type]*schemas.Table
 }
 
@@ -45,6 +48,7 @@ func NewParser(identifier string, dialect dialects.Dialect, tableMapper, columnM
 		columnMapper: columnMapper,
 		handlers:     defaultTagHandlers,
 		cacherMgr:    cacherMgr,
+		converters:   convert.NewConverters(),
 	}
 }
 
@@ -76,6 +80,27 @@ func (parser *Parser) SetIdentifier(identifier string) {
 	parser.identifier = identifier
 }
 
+// RegisterConverter registers the converter of the type
+func (parser *Parser) RegisterConverter(t reflect.Type, converter convert.Converter) {
+	parser.ClearCaches()
+	parser.converters.Register(t, converter)
+}
+
+// Converter returns the registered converter of the type or nil
+func (parser *Parser) Converter(t reflect.Type) convert.Converter {
+	return parser.converters.Get(t)
+}
+
+// SetKeyProvider sets the key provider of the encrypted columns
+func (parser *Parser) SetKeyProvider(provider convert.KeyProvider) {
+	parser.keyProvider = provider
+}
+
+// KeyProvider returns the key provider of the encrypted columns
+func (parser *Parser) KeyProvider() convert.KeyProvider {
+	return parser.keyProvider
+}
+
 // ParseWithCache parse a struct with cache
 func (parser *Parser) ParseWithCache(v reflect.Value) (*schemas.Table, error) {
 	t := v.Type()
@@ -112,16 +137,17 @@ func (parser *Parser) ClearCaches() {
 	parser.tableCache = sync.Map{}
 }
 
-func addIndex(indexName string, table *schemas.Table, col *schemas.Column, indexType int) {
+func addIndex(indexName string, table *schemas.Table, col *schemas.Column, indexType int, key string) *schemas.Index {
 	if index, ok := table.Indexes[indexName]; ok {
-		index.AddColumn(col.Name)
-		col.Indexes[index.Name] = indexType
-	} else {
-		index := schemas.NewIndex(indexName, indexType)
-		index.AddColumn(col.Name)
-		table.AddIndex(index)
+		index.AddColumn(key)
 		col.Indexes[index.Name] = indexType
+		return index
 	}
+	index := schemas.NewIndex(indexName, indexType)
+	index.AddColumn(key)
+	table.AddIndex(index)
+	col.Indexes[index.Name] = indexType
+	return index
 }
 
 var ErrIgnoreField = errors.New("field will be ignored")
@@ -133,7 +159,9 @@ func (parser *Parser) parseFieldWithNoTag(fieldIndex int, field reflect.StructFi
 			sqlType = schemas.SQLType{Name: schemas.Text}
 		}
 	}
-	if _, ok := fieldValue.Interface().(convert.Conversion); ok {
+	if converter := parser.Converter(field.Type); converter != nil {
+		sqlType = converter.SQLType()
+	} else if _, ok := fieldValue.Interface().(convert.Conversion); ok {
 		sqlType = schemas.SQLType{Name: schemas.Text}
 	} else {
 		sqlType = schemas.Type2SQLType(field.Type)
@@ -168,10 +196,14 @@ func (parser *Parser) parseFieldWithTags(table *schemas.Table, fieldIndex int, f
 		col:        col,
 		fieldValue: fieldValue,
 		indexNames: make(map[string]int),
+		indexKeys:  make(map[string]string),
 		parser:     parser,
 	}
 
 	for j, tag := range tags {
+		if ctx.ignoreRest {
+			break
+		}
 		if ctx.ignoreNext {
 			ctx.ignoreNext = false
 			continue
@@ -188,6 +220,7 @@ func (parser *Parser) parseFieldWithTags(table *schemas.Table, fieldIndex int, f
 		} else {
 			ctx.nextTag = ""
 		}
+		ctx.restTags = tags[j+1:]
 
 		if h, ok := parser.handlers[ctx.tagUname]; ok {
 			if err := h(&ctx); err != nil {
@@ -202,11 +235,16 @@ func (parser *Parser) parseFieldWithTags(table *schemas.Table, fieldIndex int, f
 		}
 
 		if ctx.hasCacheTag {
-			if parser.cacherMgr.GetDefaultCacher() != nil {
-				parser.cacherMgr.SetCacher(table.Name, parser.cacherMgr.GetDefaultCacher())
-			} else {
-				parser.cacherMgr.SetCacher(table.Name, caches.NewLRUCacher2(caches.NewMemoryStore(), time.Hour, 10000))
+			cacher := parser.cacherMgr.GetDefaultCacher()
+			if cacher == nil {
+				cacher = caches.NewLRUCacher2(caches.NewMemoryStore(), time.Hour, 10000)
+			}
+			if ctx.cacheOptions != (caches.TableOptions{}) {
+				if tableCacher, ok := cacher.(caches.TableCacher); ok {
+					tableCacher.SetTableOptions(table.Name, ctx.cacheOptions)
+				}
 			}
+			parser.cacherMgr.SetCacher(table.Name, cacher)
 		}
 		if ctx.hasNoCacheTag {
 			parser.cacherMgr.SetCacher(table.Name, nil)
@@ -214,7 +252,11 @@ func (parser *Parser) parseFieldWithTags(table *schemas.Table, fieldIndex int, f
 	}
 
 	if col.SQLType.Name == "" {
-		col.SQLType = schemas.Type2SQLType(field.Type)
+		if converter := parser.Converter(field.Type); converter != nil {
+			col.SQLType = converter.SQLType()
+		} else {
+			col.SQLType = schemas.Type2SQLType(field.Type)
+		}
 	}
 	parser.dialect.SQLType(col)
 	if col.Length == 0 {
@@ -232,9 +274,31 @@ func (parser *Parser) parseFieldWithTags(table *schemas.Table, fieldIndex int, f
 	} else if ctx.isIndex {
 		ctx.indexNames[col.Name] = schemas.IndexType
 	}
+	if key, ok := ctx.indexKeys[""]; ok {
+		ctx.indexKeys[col.Name] = key
+	}
 
 	for indexName, indexType := range ctx.indexNames {
-		addIndex(indexName, table, col, indexType)
+		key, ok := ctx.indexKeys[indexName]
+		if !ok {
+			key = col.Name
+		}
+		index := addIndex(indexName, table, col, indexType, key)
+		if ctx.indexWhere != "" {
+			index.Where = ctx.indexWhere
+		}
+	}
+
+	if ctx.isSequence && col.Sequence == "" {
+		col.Sequence = schemas.SequenceName(table.Name, col.Name)
+	}
+
+	for i, expr := range ctx.checks {
+		name := col.Name
+		if i > 0 {
+			name = fmt.Sprintf("%s_%d", col.Name, i+1)
+		}
+		table.AddCheck(schemas.NewCheck(name, expr))
 	}
 
 	return col, nil
@@ -258,6 +322,11 @@ func (parser *Parser) parseField(table *schemas.Table, fieldIndex int, field ref
 	return parser.parseFieldWithTags(table, fieldIndex, field, fieldValue, tags)
 }
 
+func isOracle(dialect dialects.Dialect) bool {
+	uri := dialect.URI()
+	return uri != nil && uri.DBType == schemas.ORACLE
+}
+
 func isNotTitle(n string) bool {
 	for _, c := range n {
 		return unicode.IsLower(c)
@@ -279,6 +348,12 @@ func (parser *Parser) Parse(v reflect.Value) (*schemas.Table, error) {
 	table := schemas.NewEmptyTable()
 	table.Type = t
 	table.Name = names.GetTableName(parser.tableMapper, v)
+	if view, ok := reflect.New(t).Interface().(schemas.View); ok {
+		table.IsView = true
+		if materialized, ok := view.(schemas.MaterializedView); ok {
+			table.Materialized = materialized.Materialized()
+		}
+	}
 
 	for i := 0; i < t.NumField(); i++ {
 		var field = t.Field(i)
@@ -293,6 +368,15 @@ func (parser *Parser) Parse(v reflect.Value) (*schemas.Table, error) {
 			return nil, err
 		}
 
+		// Oracle has no autoincrement columns, the values come from the sequences
+		if col.IsAutoIncrement && col.Sequence == "" && isOracle(parser.dialect) {
+			col.Sequence = schemas.SequenceName(table.Name, col.Name)
+		}
+		// the values of the sequence columns are fetched before inserting
+		if col.Sequence != "" && parser.dialect.NextSequenceSQL(col.Sequence) != "" {
+			col.IsAutoIncrement = false
+		}
+
 		table.AddColumn(col)
 	} // end for
 
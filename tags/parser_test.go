@@ -286,6 +286,37 @@ func TestParseWithCache(t *testing.T) {
 	assert.NotNil(t, cacher)
 }
 
+func TestParseWithCacheOptions(t *testing.T) {
+	parser := NewParser(
+		"db",
+		dialects.QueryDialect("mysql"),
+		names.SnakeMapper{},
+		names.GonicMapper{},
+		caches.NewManager(),
+	)
+
+	type StructWithCacheOptions struct {
+		Name string `db:"cache(1ms,1)"`
+	}
+
+	table, err := parser.Parse(reflect.ValueOf(new(StructWithCacheOptions)))
+	assert.NoError(t, err)
+	cacher, ok := parser.cacherMgr.GetCacher(table.Name).(*caches.LRUCacher)
+	if assert.True(t, ok) {
+		cacher.PutBean(table.Name, "1", "bean1")
+		cacher.PutBean(table.Name, "2", "bean2")
+		assert.Nil(t, cacher.GetBean(table.Name, "1"))
+		time.Sleep(5 * time.Millisecond)
+		assert.Nil(t, cacher.GetBean(table.Name, "2"))
+	}
+
+	type StructWithInvalidCacheOptions struct {
+		Name string `db:"cache(a)"`
+	}
+	_, err = parser.Parse(reflect.ValueOf(new(StructWithInvalidCacheOptions)))
+	assert.Error(t, err)
+}
+
 func TestParseWithNoCache(t *testing.T) {
 	parser := NewParser(
 		"db",
@@ -335,6 +366,36 @@ func TestParseWithEnum(t *testing.T) {
 	}, table.Columns()[0].EnumOptions)
 }
 
+func TestParseWithCheck(t *testing.T) {
+	parser := NewParser(
+		"db",
+		dialects.QueryDialect("sqlite3"),
+		names.SnakeMapper{},
+		names.GonicMapper{},
+		caches.NewManager(),
+	)
+
+	type StructWithCheck struct {
+		Age  int    `db:"check(age >= 0) check(age < 200)"`
+		Name string `db:"'user_name' check(length(user_name) > 0)"`
+	}
+
+	table, err := parser.Parse(reflect.ValueOf(new(StructWithCheck)))
+	assert.NoError(t, err)
+	assert.EqualValues(t, 2, len(table.Columns()))
+	assert.EqualValues(t, map[string]*schemas.Check{
+		"age":       schemas.NewCheck("age", "age >= 0"),
+		"age_2":     schemas.NewCheck("age_2", "age < 200"),
+		"user_name": schemas.NewCheck("user_name", "length(user_name) > 0"),
+	}, table.Checks)
+
+	type StructWithEmptyCheck struct {
+		Age int `db:"check()"`
+	}
+	_, err = parser.Parse(reflect.ValueOf(new(StructWithEmptyCheck)))
+	assert.Error(t, err)
+}
+
 func TestParseWithSet(t *testing.T) {
 	parser := NewParser(
 		"db",
@@ -391,6 +452,66 @@ func TestParseWithIndex(t *testing.T) {
 	assert.EqualValues(t, 1, len(table.Columns()[2].Indexes))
 }
 
+func TestParseWithIndexExpr(t *testing.T) {
+	parser := NewParser(
+		"db",
+		dialects.QueryDialect("postgres"),
+		names.SnakeMapper{},
+		names.GonicMapper{},
+		caches.NewManager(),
+	)
+
+	type StructWithIndexExpr struct {
+		Email   string `db:"index(lower(email))"`
+		Code    string `db:"unique(code) where deleted IS NULL AND length(code) > 0"`
+		Created int64  `db:"index(created DESC) where(created > 0)"`
+		A       string `db:"index(idx_a_b, a DESC)"`
+		B       string `db:"index(idx_a_b)"`
+		Slug    string `db:"index(idx-slug)"`
+		Total   int    `db:"index(total ASC)"`
+	}
+
+	table, err := parser.Parse(reflect.ValueOf(new(StructWithIndexExpr)))
+	assert.NoError(t, err)
+	assert.EqualValues(t, 7, len(table.Columns()))
+	assert.EqualValues(t, map[string]*schemas.Index{
+		"email":    {IsRegular: true, Name: "email", Type: schemas.IndexType, Cols: []string{"lower(email)"}},
+		"code":     {IsRegular: true, Name: "code", Type: schemas.UniqueType, Cols: []string{"code"}, Where: "deleted IS NULL AND length(code) > 0"},
+		"created":  {IsRegular: true, Name: "created", Type: schemas.IndexType, Cols: []string{"created DESC"}, Where: "created > 0"},
+		"idx_a_b":  {IsRegular: true, Name: "idx_a_b", Type: schemas.IndexType, Cols: []string{"a DESC", "b"}},
+		"idx-slug": {IsRegular: true, Name: "idx-slug", Type: schemas.IndexType, Cols: []string{"slug"}},
+		"total":    {IsRegular: true, Name: "total", Type: schemas.IndexType, Cols: []string{"total ASC"}},
+	}, table.Indexes)
+	assert.EqualValues(t, map[string]int{"email": schemas.IndexType}, table.GetColumn("email").Indexes)
+}
+
+func TestParseWithGenerated(t *testing.T) {
+	parser := NewParser(
+		"db",
+		dialects.QueryDialect("sqlite3"),
+		names.SnakeMapper{},
+		names.GonicMapper{},
+		caches.NewManager(),
+	)
+
+	type StructWithGenerated struct {
+		FullName string `db:"generated(first_name || ' ' || last_name) stored notnull"`
+		Total    int    `db:"generated(price * amount)"`
+	}
+
+	table, err := parser.Parse(reflect.ValueOf(new(StructWithGenerated)))
+	assert.NoError(t, err)
+	assert.EqualValues(t, 2, len(table.Columns()))
+	col := table.GetColumn("full_name")
+	assert.EqualValues(t, "first_name || ' ' || last_name", col.Generated)
+	assert.True(t, col.GeneratedStored)
+	assert.False(t, col.Nullable)
+	assert.EqualValues(t, schemas.ONLYFROMDB, col.MapType)
+	col = table.GetColumn("total")
+	assert.EqualValues(t, "price * amount", col.Generated)
+	assert.False(t, col.GeneratedStored)
+}
+
 func TestParseWithVersion(t *testing.T) {
 	parser := NewParser(
 		"db",
@@ -550,3 +671,169 @@ func TestParseWithSQLType(t *testing.T) {
 	assert.EqualValues(t, "DATETIME", table.Columns()[3].SQLType.Name)
 	assert.EqualValues(t, "UUID", table.Columns()[4].SQLType.Name)
 }
+
+func TestParseWithSensitive(t *testing.T) {
+	parser := NewParser(
+		"db",
+		dialects.QueryDialect("mysql"),
+		names.SnakeMapper{},
+		names.GonicMapper{},
+		caches.NewManager(),
+	)
+
+	type StructWithSensitive struct {
+		Name     string
+		Password string `db:"varchar(64) sensitive"`
+	}
+
+	table, err := parser.Parse(reflect.ValueOf(new(StructWithSensitive)))
+	assert.NoError(t, err)
+	assert.False(t, table.Columns()[0].IsSensitive)
+	assert.True(t, table.Columns()[1].IsSensitive)
+	assert.EqualValues(t, []string{"password"}, table.SensitiveColumns())
+}
+
+func TestParseWithArray(t *testing.T) {
+	type StructWithArray struct {
+		Tags   []string    `db:"array"`
+		Scores []int64     `db:"array"`
+		Times  []time.Time `db:"array"`
+		Codes  []string    `db:"array(varchar)"`
+	}
+
+	postgres := dialects.QueryDialect(schemas.POSTGRES)
+	assert.NoError(t, postgres.Init(&dialects.URI{DBType: schemas.POSTGRES}))
+	parser := NewParser("db", postgres, names.SnakeMapper{}, names.SnakeMapper{}, caches.NewManager())
+
+	table, err := parser.Parse(reflect.ValueOf(new(StructWithArray)))
+	assert.NoError(t, err)
+	cols := table.Columns()
+	assert.EqualValues(t, 4, len(cols))
+	for _, col := range cols {
+		assert.True(t, col.SQLType.IsArray())
+		assert.False(t, col.IsJSON)
+	}
+	assert.EqualValues(t, "TEXT[]", postgres.SQLType(cols[0]))
+	assert.EqualValues(t, "BIGINT[]", postgres.SQLType(cols[1]))
+	assert.EqualValues(t, "TIMESTAMP[]", postgres.SQLType(cols[2]))
+	assert.EqualValues(t, "VARCHAR[]", postgres.SQLType(cols[3]))
+
+	mysql := dialects.QueryDialect(schemas.MYSQL)
+	assert.NoError(t, mysql.Init(&dialects.URI{DBType: schemas.MYSQL}))
+	parser = NewParser("db", mysql, names.SnakeMapper{}, names.SnakeMapper{}, caches.NewManager())
+
+	table, err = parser.Parse(reflect.ValueOf(new(StructWithArray)))
+	assert.NoError(t, err)
+	for _, col := range table.Columns() {
+		assert.EqualValues(t, schemas.Text, col.SQLType.Name)
+		assert.True(t, col.IsJSON)
+	}
+}
+
+func TestParseWithEncrypted(t *testing.T) {
+	type StructWithEncrypted struct {
+		Email  string `db:"encrypted(pii,deterministic)"`
+		Phone  string `db:"varchar(255) encrypted(pii)"`
+		Age    int    `db:"encrypted"`
+		Secret []byte `db:"encrypted"`
+	}
+
+	parser := NewParser("db", dialects.QueryDialect("mysql"), names.SnakeMapper{}, names.SnakeMapper{}, caches.NewManager())
+	table, err := parser.Parse(reflect.ValueOf(new(StructWithEncrypted)))
+	assert.NoError(t, err)
+	cols := table.Columns()
+	assert.EqualValues(t, 4, len(cols))
+	for _, col := range cols {
+		assert.True(t, col.IsEncrypted)
+	}
+	assert.EqualValues(t, "pii", cols[0].EncryptKey)
+	assert.True(t, cols[0].IsDeterministic)
+	assert.EqualValues(t, schemas.Text, cols[0].SQLType.Name)
+	assert.EqualValues(t, "pii", cols[1].EncryptKey)
+	assert.False(t, cols[1].IsDeterministic)
+	assert.EqualValues(t, schemas.Varchar, cols[1].SQLType.Name)
+	assert.EqualValues(t, "", cols[2].EncryptKey)
+	assert.EqualValues(t, schemas.Text, cols[2].SQLType.Name)
+	assert.EqualValues(t, schemas.Blob, cols[3].SQLType.Name)
+}
+
+func TestParseWithSequence(t *testing.T) {
+	type StructWithSequence struct {
+		Id     int64
+		Number int64 `db:"sequence(number_seq)"`
+		Serial int64 `db:"notnull sequence"`
+	}
+
+	parse := func(dbType schemas.DBType) *schemas.Table {
+		dialect := dialects.QueryDialect(dbType)
+		assert.NoError(t, dialect.Init(&dialects.URI{DBType: dbType}))
+		parser := NewParser("db", dialect, names.SnakeMapper{}, names.SnakeMapper{}, caches.NewManager())
+		table, err := parser.Parse(reflect.ValueOf(new(StructWithSequence)))
+		assert.NoError(t, err)
+		return table
+	}
+
+	// the autoincrement columns of Oracle use the sequences
+	table := parse(schemas.ORACLE)
+	assert.EqualValues(t, "", table.AutoIncrement)
+	assert.EqualValues(t, "SEQ_struct_with_sequence_id", table.GetColumn("id").Sequence)
+	assert.EqualValues(t, "number_seq", table.GetColumn("number").Sequence)
+	assert.EqualValues(t, "SEQ_struct_with_sequence_serial", table.GetColumn("serial").Sequence)
+	assert.EqualValues(t, 3, len(table.SequenceColumns()))
+
+	table = parse(schemas.POSTGRES)
+	assert.EqualValues(t, "id", table.AutoIncrement)
+	assert.EqualValues(t, "", table.GetColumn("id").Sequence)
+	assert.EqualValues(t, 2, len(table.SequenceColumns()))
+
+	// the sequences are ignored by the dialects without sequences
+	table = parse(schemas.SQLITE)
+	assert.EqualValues(t, "id", table.AutoIncrement)
+	assert.EqualValues(t, "number_seq", table.GetColumn("number").Sequence)
+}
+
+type ViewStruct struct {
+	Id   int64
+	Name string
+}
+
+func (ViewStruct) ViewSQL() interface{} {
+	return "SELECT id, name FROM t"
+}
+
+type MaterializedViewStruct struct {
+	Id   int64
+	Name string
+}
+
+func (MaterializedViewStruct) ViewSQL() interface{} {
+	return "SELECT id, name FROM t"
+}
+
+func (MaterializedViewStruct) Materialized() bool {
+	return true
+}
+
+func TestParseWithView(t *testing.T) {
+	parser := NewParser(
+		"db",
+		dialects.QueryDialect("mysql"),
+		names.SnakeMapper{},
+		names.SnakeMapper{},
+		caches.NewManager(),
+	)
+
+	table, err := parser.Parse(reflect.ValueOf(new(ViewStruct)))
+	assert.NoError(t, err)
+	assert.True(t, table.IsView)
+	assert.False(t, table.Materialized)
+
+	table, err = parser.Parse(reflect.ValueOf(new(MaterializedViewStruct)))
+	assert.NoError(t, err)
+	assert.True(t, table.IsView)
+	assert.True(t, table.Materialized)
+
+	table, err = parser.Parse(reflect.ValueOf(new(ParseTableName1)))
+	assert.NoError(t, err)
+	assert.False(t, table.IsView)
+}
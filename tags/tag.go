@@ -11,6 +11,8 @@ import (
 	"strings"
 	"time"
 
+	"github.com/xorm-io/xorm/caches"
+	"github.com/xorm-io/xorm/dialects"
 	"github.com/xorm-io/xorm/schemas"
 )
 
@@ -23,7 +25,7 @@ func splitTag(tagStr string) ([]tag, error) {
 	tagStr = strings.TrimSpace(tagStr)
 	var (
 		inQuote    bool
-		inBigQuote bool
+		depth      int
 		lastIdx    int
 		curTag     tag
 		paramStart int
@@ -34,7 +36,7 @@ func splitTag(tagStr string) ([]tag, error) {
 		case '\'':
 			inQuote = !inQuote
 		case ' ':
-			if !inQuote && !inBigQuote {
+			if !inQuote && depth == 0 {
 				if lastIdx < i {
 					if curTag.name == "" {
 						curTag.name = tagStr[lastIdx:i]
@@ -45,27 +47,31 @@ func splitTag(tagStr string) ([]tag, error) {
 				} else if lastIdx == i {
 					lastIdx = i + 1
 				}
-			} else if inBigQuote && !inQuote {
+			} else if depth > 0 && !inQuote && paramStart == i {
 				paramStart = i + 1
 			}
 		case ',':
-			if !inQuote && !inBigQuote {
+			if !inQuote && depth == 0 {
 				return nil, fmt.Errorf("comma[%d] of %s should be in quote or big quote", i, tagStr)
 			}
-			if !inQuote && inBigQuote {
+			if !inQuote && depth == 1 {
 				curTag.params = append(curTag.params, strings.TrimSpace(tagStr[paramStart:i]))
 				paramStart = i + 1
 			}
 		case '(':
-			inBigQuote = true
 			if !inQuote {
-				curTag.name = tagStr[lastIdx:i]
-				paramStart = i + 1
+				depth++
+				if depth == 1 {
+					curTag.name = tagStr[lastIdx:i]
+					paramStart = i + 1
+				}
 			}
 		case ')':
-			inBigQuote = false
-			if !inQuote {
-				curTag.params = append(curTag.params, tagStr[paramStart:i])
+			if !inQuote && depth > 0 {
+				depth--
+				if depth == 0 {
+					curTag.params = append(curTag.params, tagStr[paramStart:i])
+				}
 			}
 		}
 	}
@@ -89,10 +95,17 @@ type Context struct {
 	isIndex         bool
 	isUnique        bool
 	indexNames      map[string]int
+	indexKeys       map[string]string // the key parts of the indexes other than the column
+	indexWhere      string
+	restTags        []tag
+	ignoreRest      bool
 	parser          *Parser
 	hasCacheTag     bool
 	hasNoCacheTag   bool
+	cacheOptions    caches.TableOptions
 	ignoreNext      bool
+	checks          []string
+	isSequence      bool
 }
 
 // Handler describes tag handler for XORM
@@ -101,26 +114,32 @@ type Handler func(ctx *Context) error
 var (
 	// defaultTagHandlers enumerates all the default tag handler
 	defaultTagHandlers = map[string]Handler{
-		"<-":       OnlyFromDBTagHandler,
-		"->":       OnlyToDBTagHandler,
-		"PK":       PKTagHandler,
-		"NULL":     NULLTagHandler,
-		"NOT":      IgnoreTagHandler,
-		"AUTOINCR": AutoIncrTagHandler,
-		"DEFAULT":  DefaultTagHandler,
-		"CREATED":  CreatedTagHandler,
-		"UPDATED":  UpdatedTagHandler,
-		"DELETED":  DeletedTagHandler,
-		"VERSION":  VersionTagHandler,
-		"UTC":      UTCTagHandler,
-		"LOCAL":    LocalTagHandler,
-		"NOTNULL":  NotNullTagHandler,
-		"INDEX":    IndexTagHandler,
-		"UNIQUE":   UniqueTagHandler,
-		"CACHE":    CacheTagHandler,
-		"NOCACHE":  NoCacheTagHandler,
-		"COMMENT":  CommentTagHandler,
-		"EXTENDS":  ExtendsTagHandler,
+		"<-":        OnlyFromDBTagHandler,
+		"->":        OnlyToDBTagHandler,
+		"PK":        PKTagHandler,
+		"NULL":      NULLTagHandler,
+		"NOT":       IgnoreTagHandler,
+		"AUTOINCR":  AutoIncrTagHandler,
+		"DEFAULT":   DefaultTagHandler,
+		"CREATED":   CreatedTagHandler,
+		"UPDATED":   UpdatedTagHandler,
+		"DELETED":   DeletedTagHandler,
+		"VERSION":   VersionTagHandler,
+		"UTC":       UTCTagHandler,
+		"LOCAL":     LocalTagHandler,
+		"NOTNULL":   NotNullTagHandler,
+		"INDEX":     IndexTagHandler,
+		"UNIQUE":    UniqueTagHandler,
+		"CACHE":     CacheTagHandler,
+		"NOCACHE":   NoCacheTagHandler,
+		"COMMENT":   CommentTagHandler,
+		"EXTENDS":   ExtendsTagHandler,
+		"SENSITIVE": SensitiveTagHandler,
+		"ENCRYPTED": EncryptedTagHandler,
+		"CHECK":     CheckTagHandler,
+		"GENERATED": GeneratedTagHandler,
+		"WHERE":     WhereTagHandler,
+		"SEQUENCE":  SequenceTagHandler,
 	}
 )
 
@@ -209,6 +228,35 @@ func VersionTagHandler(ctx *Context) error {
 	return nil
 }
 
+// SensitiveTagHandler describes sensitive tag handler
+func SensitiveTagHandler(ctx *Context) error {
+	ctx.col.IsSensitive = true
+	return nil
+}
+
+// EncryptedTagHandler describes encrypted tag handler, the parameters are the
+// key name and "deterministic", i.e. encrypted(pii,deterministic). The lookups
+// of a deterministic column only match the values encrypted by the current key.
+func EncryptedTagHandler(ctx *Context) error {
+	ctx.col.IsEncrypted = true
+	for _, param := range ctx.params {
+		param = strings.Trim(param, "' ")
+		if strings.EqualFold(param, "deterministic") {
+			ctx.col.IsDeterministic = true
+		} else {
+			ctx.col.EncryptKey = param
+		}
+	}
+	if ctx.col.SQLType.Name == "" {
+		if ctx.fieldValue.Type() == schemas.BytesType {
+			ctx.col.SQLType = schemas.SQLType{Name: schemas.Blob}
+		} else {
+			ctx.col.SQLType = schemas.SQLType{Name: schemas.Text}
+		}
+	}
+	return nil
+}
+
 // UTCTagHandler describes utc tag handler
 func UTCTagHandler(ctx *Context) error {
 	ctx.col.TimeZone = time.UTC
@@ -244,9 +292,7 @@ func DeletedTagHandler(ctx *Context) error {
 
 // IndexTagHandler describes index tag handler
 func IndexTagHandler(ctx *Context) error {
-	if len(ctx.params) > 0 {
-		ctx.indexNames[ctx.params[0]] = schemas.IndexType
-	} else {
+	if indexTag(ctx, schemas.IndexType) {
 		ctx.isIndex = true
 	}
 	return nil
@@ -254,10 +300,70 @@ func IndexTagHandler(ctx *Context) error {
 
 // UniqueTagHandler describes unique tag handler
 func UniqueTagHandler(ctx *Context) error {
+	if indexTag(ctx, schemas.UniqueType) {
+		ctx.isUnique = true
+	}
+	return nil
+}
+
+// indexTag records the index of index(name), index(name, key) or index(key)
+// whose key is an expression or has a sort order, e.g. index(lower(email)) or
+// index(email DESC), any other single parameter like index(idx-name) is the
+// name of the index. It returns true if the index is named by the column.
+func indexTag(ctx *Context, indexType int) bool {
+	if len(ctx.params) == 0 {
+		return true
+	}
+	if key := schemas.ParseIndexKey(ctx.params[0]); len(ctx.params) == 1 &&
+		(key.IsExpr || key.Name != strings.TrimSpace(ctx.params[0])) {
+		ctx.indexKeys[""] = ctx.params[0]
+		return true
+	}
+	ctx.indexNames[ctx.params[0]] = indexType
+	if len(ctx.params) > 1 {
+		ctx.indexKeys[ctx.params[0]] = ctx.params[1]
+	}
+	return false
+}
+
+// WhereTagHandler describes the predicate tag of the partial indexes of the
+// column, i.e. where(predicate) or where followed by the predicate
+func WhereTagHandler(ctx *Context) error {
 	if len(ctx.params) > 0 {
-		ctx.indexNames[ctx.params[0]] = schemas.UniqueType
+		ctx.indexWhere = strings.TrimSpace(strings.Join(ctx.params, ", "))
 	} else {
-		ctx.isUnique = true
+		names := make([]string, 0, len(ctx.restTags))
+		for _, tag := range ctx.restTags {
+			if tag.params != nil {
+				tag.name += "(" + strings.Join(tag.params, ", ") + ")"
+			}
+			names = append(names, tag.name)
+		}
+		ctx.indexWhere = strings.Join(names, " ")
+		ctx.ignoreRest = true
+	}
+	if ctx.indexWhere == "" {
+		return fmt.Errorf("where tag of %s should have a predicate", ctx.col.FieldName)
+	}
+	return nil
+}
+
+// GeneratedTagHandler describes generated column tag handler, i.e.
+// generated(expr) followed by the optional stored or virtual, the column is
+// only read from the database
+func GeneratedTagHandler(ctx *Context) error {
+	expr := strings.TrimSpace(strings.Join(ctx.params, ", "))
+	if expr == "" {
+		return fmt.Errorf("generated tag of %s should have an expression", ctx.col.FieldName)
+	}
+	ctx.col.Generated = expr
+	ctx.col.MapType = schemas.ONLYFROMDB
+	switch strings.ToUpper(ctx.nextTag) {
+	case "STORED":
+		ctx.col.GeneratedStored = true
+		ctx.ignoreNext = true
+	case "VIRTUAL":
+		ctx.ignoreNext = true
 	}
 	return nil
 }
@@ -270,8 +376,31 @@ func CommentTagHandler(ctx *Context) error {
 	return nil
 }
 
+// CheckTagHandler describes check tag handler
+func CheckTagHandler(ctx *Context) error {
+	expr := strings.TrimSpace(strings.Join(ctx.params, ", "))
+	if expr == "" {
+		return fmt.Errorf("check tag of %s should have an expression", ctx.col.FieldName)
+	}
+	ctx.checks = append(ctx.checks, expr)
+	return nil
+}
+
+// SequenceTagHandler describes sequence tag handler, the sequence is named
+// SEQ_<table>_<column> if the name is omitted
+func SequenceTagHandler(ctx *Context) error {
+	ctx.isSequence = true
+	if len(ctx.params) > 0 {
+		ctx.col.Sequence = strings.Trim(ctx.params[0], "'\" ")
+	}
+	return nil
+}
+
 // SQLTypeTagHandler describes SQL Type tag handler
 func SQLTypeTagHandler(ctx *Context) error {
+	if ctx.tagUname == schemas.Array {
+		return ArrayTagHandler(ctx)
+	}
 	ctx.col.SQLType = schemas.SQLType{Name: ctx.tagUname}
 	if ctx.tagUname == "JSON" {
 		ctx.col.IsJSON = true
@@ -316,6 +445,31 @@ func SQLTypeTagHandler(ctx *Context) error {
 	return nil
 }
 
+// ArrayTagHandler describes array tag handler, the element type is the
+// parameter or derived from the field type. The column is stored as JSON text
+// if the database has no array types.
+func ArrayTagHandler(ctx *Context) error {
+	var elemType schemas.SQLType
+	if len(ctx.params) > 0 {
+		elemType = schemas.SQLType{Name: strings.ToUpper(strings.TrimSpace(ctx.params[0]))}
+	} else {
+		var ok bool
+		elemType, ok = dialects.ArrayElemType(ctx.fieldValue.Type())
+		if !ok {
+			return fmt.Errorf("array tag is not supported on %v", ctx.fieldValue.Type())
+		}
+	}
+
+	if uri := ctx.parser.dialect.URI(); uri == nil || uri.DBType != schemas.POSTGRES {
+		ctx.col.SQLType = schemas.SQLType{Name: schemas.Text}
+		ctx.col.IsJSON = true
+		return nil
+	}
+	ctx.col.SQLType = schemas.SQLType{Name: schemas.Array}
+	ctx.col.ArrayElemType = elemType
+	return nil
+}
+
 // ExtendsTagHandler describes extends tag handler
 func ExtendsTagHandler(ctx *Context) error {
 	var fieldValue = ctx.fieldValue
@@ -338,6 +492,8 @@ func ExtendsTagHandler(ctx *Context) error {
 			return err
 		}
 		for _, col := range parentTable.Columns() {
+			// the default sequence is named by the table which the column belongs to
+			isDefaultSequence := col.Sequence == schemas.SequenceName(parentTable.Name, col.Name)
 			col.FieldName = fmt.Sprintf("%v.%v", ctx.col.FieldName, col.FieldName)
 			col.FieldIndex = append(ctx.col.FieldIndex, col.FieldIndex...)
 
@@ -356,11 +512,14 @@ func ExtendsTagHandler(ctx *Context) error {
 			if col.Nullable {
 				col.IsAutoIncrement = false
 				col.IsPrimaryKey = false
+				col.Sequence = ""
+			} else if isDefaultSequence {
+				col.Sequence = schemas.SequenceName(ctx.table.Name, col.Name)
 			}
 
 			ctx.table.AddColumn(col)
 			for indexName, indexType := range col.Indexes {
-				addIndex(indexName, ctx.table, col, indexType)
+				addIndex(indexName, ctx.table, col, indexType, col.Name)
 			}
 		}
 	default:
@@ -369,11 +528,26 @@ func ExtendsTagHandler(ctx *Context) error {
 	return ErrIgnoreField
 }
 
-// CacheTagHandler describes cache tag handler
+// CacheTagHandler describes cache tag handler, the optional parameters are
+// the expired time and the max element size of the table, i.e. cache(10m,1000)
 func CacheTagHandler(ctx *Context) error {
 	if !ctx.hasCacheTag {
 		ctx.hasCacheTag = true
 	}
+	if len(ctx.params) > 0 && ctx.params[0] != "" {
+		expired, err := time.ParseDuration(ctx.params[0])
+		if err != nil {
+			return err
+		}
+		ctx.cacheOptions.Expired = expired
+	}
+	if len(ctx.params) > 1 && ctx.params[1] != "" {
+		size, err := strconv.Atoi(ctx.params[1])
+		if err != nil {
+			return err
+		}
+		ctx.cacheOptions.MaxElementSize = size
+	}
 	return nil
 }
 
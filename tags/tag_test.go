@@ -74,6 +74,16 @@ func TestSplitTag(t *testing.T) {
 			},
 		},
 		},
+		{"check(length(name) > 0 AND kind IN ('a', 'b')) notnull", []tag{
+			{
+				name:   "check",
+				params: []string{"length(name) > 0 AND kind IN ('a', 'b')"},
+			},
+			{
+				name: "notnull",
+			},
+		},
+		},
 	}
 
 	for _, kase := range cases {